@@ -160,6 +160,37 @@ func TestUpdateProposerDuties(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, *mockResponse, *duties)
 	})
+
+	t.Run("keeps using the beacon node that responded last", func(t *testing.T) {
+		mockResponse := &ProposerDutiesResponse{
+			Data: []ProposerDutiesResponseData{
+				{
+					Pubkey: testPubKey,
+					Slot:   2,
+				},
+			},
+		}
+
+		backend := newTestBackend(t, 3)
+		backend.beaconInstances[0].MockProposerDutiesErr = errTest
+		backend.beaconInstances[1].MockProposerDutiesErr = errTest
+		backend.beaconInstances[2].MockProposerDuties = mockResponse
+
+		duties, err := backend.beaconClient.GetProposerDuties(2)
+		require.NoError(t, err)
+		require.Equal(t, *mockResponse, *duties)
+
+		// beacon 2 should now be the first one queried, even though beacon 0 recovered
+		backend.beaconInstances[0].MockProposerDutiesErr = nil
+		duties, err = backend.beaconClient.GetProposerDuties(2)
+		require.NoError(t, err)
+		require.Equal(t, *mockResponse, *duties)
+
+		// and again after a second successful call
+		duties, err = backend.beaconClient.GetProposerDuties(2)
+		require.NoError(t, err)
+		require.Equal(t, *mockResponse, *duties)
+	})
 }
 
 func TestFetchValidators(t *testing.T) {
@@ -243,3 +274,97 @@ func TestGetForkSchedule(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 4, len(forkSchedule.Data))
 }
+
+func TestFetchNetworkConfig(t *testing.T) {
+	r := mux.NewRouter()
+	srv := httptest.NewServer(r)
+	bc := NewProdBeaconInstance(common.TestLog, srv.URL)
+
+	r.HandleFunc("/eth/v1/beacon/genesis", func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"data": {
+			"genesis_time": "1606824023",
+			"genesis_validators_root": "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95",
+			"genesis_fork_version": "0x00000000"
+		}}`))
+		require.NoError(t, err)
+	})
+	r.HandleFunc("/eth/v1/config/spec", func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"data": {
+			"CONFIG_NAME": "mainnet",
+			"SECONDS_PER_SLOT": "12",
+			"GENESIS_FORK_VERSION": "0x00000000",
+			"BELLATRIX_FORK_VERSION": "0x02000000",
+			"BELLATRIX_FORK_EPOCH": "144896",
+			"CAPELLA_FORK_VERSION": "0x03000000",
+			"CAPELLA_FORK_EPOCH": "194048",
+			"DENEB_FORK_VERSION": "0x04000000",
+			"DENEB_FORK_EPOCH": "269568",
+			"BLOB_SCHEDULE": [{"EPOCH": "269568", "MAX_BLOBS_PER_BLOCK": "6"}]
+		}}`))
+		require.NoError(t, err)
+	})
+
+	cfg, err := FetchNetworkConfig(bc)
+	require.NoError(t, err)
+	require.Equal(t, "mainnet", cfg.Name)
+	require.Equal(t, uint64(1606824023), cfg.GenesisTime)
+	require.Equal(t, uint64(12), cfg.SecondsPerSlot)
+	require.Equal(t, "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95", cfg.GenesisValidatorsRoot)
+	require.Equal(t, "0x03000000", cfg.CapellaForkVersion)
+	require.Equal(t, uint64(269568), cfg.ForkEpochs["deneb"])
+
+	// The details are the ones of the mainnet preset
+	mainnet, err := common.NewEthNetworkDetails(common.EthNetworkMainnet)
+	require.NoError(t, err)
+	networkDetails, err := common.NewEthNetworkDetailsWithConfig("", cfg)
+	require.NoError(t, err)
+	require.Equal(t, mainnet.DomainBuilder, networkDetails.DomainBuilder)
+	require.Equal(t, mainnet.DomainBeaconProposerDeneb, networkDetails.DomainBeaconProposerDeneb)
+}
+
+func TestGetNodeVersion(t *testing.T) {
+	r := mux.NewRouter()
+	srv := httptest.NewServer(r)
+	bc := NewProdBeaconInstance(common.TestLog, srv.URL)
+
+	r.HandleFunc("/eth/v1/node/version", func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"data":{"version":"Lighthouse/v4.1.0-693886b/x86_64-linux"}}`))
+		require.NoError(t, err)
+	})
+	r.HandleFunc("/eth/v1/node/syncing", func(w http.ResponseWriter, _ *http.Request) {
+		_, err := w.Write([]byte(`{"data":{"head_slot":251114}}`))
+		require.NoError(t, err)
+	})
+
+	version, err := bc.GetNodeVersion()
+	require.NoError(t, err)
+	require.Equal(t, "Lighthouse/v4.1.0-693886b/x86_64-linux", version)
+
+	// Schema errors mention the endpoint and the expected type
+	_, err = bc.SyncStatus()
+	require.ErrorContains(t, err, "/eth/v1/node/syncing into *beaconclient.SyncStatusPayload")
+}
+
+func TestCheckNodeVersion(t *testing.T) {
+	testCases := []struct {
+		version   string
+		supported bool
+	}{
+		{"Lighthouse/v4.1.0-693886b/x86_64-linux", true},
+		{"Lighthouse/v3.5.1-319cc61/x86_64-linux", false},
+		{"Prysm/v4.0.3/9fc2ebc6217e1ac34ab9ea4a5f611a1f905ab555", true},
+		{"Prysm/v3.2.2/bbbc2a5e1d26a6c7e08b6a5b6df4a5c5b1e3e3e3", false},
+		{"teku/v23.4.0/linux-x86_64/-eclipseadoptium-openjdk64bitservervm-java-17", true},
+		{"unknown", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			err := checkNodeVersion(tc.version)
+			if tc.supported {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrUnsupportedNodeVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package beaconclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var ErrNoDateHeader = errors.New("beacon node response without Date header")
+
+// ClockOffset returns how far the beacon node's clock is ahead of the local clock (negative if behind), from the Date
+// header of a node version response. The header has a resolution of one second, so the offset is only accurate to
+// about half a second, which is enough to detect a misconfigured clock.
+func (c *ProdBeaconInstance) ClockOffset() (time.Duration, error) {
+	uri := c.beaconURI + "/eth/v1/node/version"
+	client := &http.Client{Timeout: 5 * time.Second} //nolint:exhaustruct
+	start := time.Now()
+	resp, err := client.Get(uri)
+	if err != nil {
+		return 0, fmt.Errorf("client refused for %s: %w", uri, err)
+	}
+	defer resp.Body.Close()
+	end := time.Now()
+
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return 0, fmt.Errorf("%w: %s", ErrNoDateHeader, uri)
+	}
+	beaconTime, err := http.ParseTime(date)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Date header from %s: %w", uri, err)
+	}
+
+	// The header is truncated to the second, on average the beacon node's time is half a second later. Compare it to
+	// the local time halfway through the request.
+	beaconTime = beaconTime.Add(500 * time.Millisecond)
+	localTime := start.Add(end.Sub(start) / 2)
+	return beaconTime.Sub(localTime), nil
+}
+
+// ClockOffset returns the clock offset of the first beacon node which responds
+func (c *MultiBeaconClient) ClockOffset() (offset time.Duration, err error) {
+	clients := c.beaconInstancesByLastResponse()
+	for _, client := range clients {
+		log := c.log.WithField("uri", client.GetURI())
+		if offset, err = client.ClockOffset(); err != nil {
+			log.WithError(err).Warn("failed to get clock offset")
+			continue
+		}
+		return offset, nil
+	}
+
+	c.log.WithError(err).Error("failed to get clock offset on any CL node")
+	return 0, err
+}
@@ -0,0 +1,29 @@
+package beaconclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockOffset(t *testing.T) {
+	date := time.Now()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Date", date.UTC().Format(http.TimeFormat))
+	}))
+	t.Cleanup(srv.Close)
+	bc := NewProdBeaconInstance(common.TestLog, srv.URL)
+
+	offset, err := bc.ClockOffset()
+	require.NoError(t, err)
+	require.Less(t, offset.Abs(), time.Second)
+
+	date = time.Now().Add(-10 * time.Second)
+	offset, err = bc.ClockOffset()
+	require.NoError(t, err)
+	require.InDelta(t, -10*time.Second, offset, float64(time.Second))
+}
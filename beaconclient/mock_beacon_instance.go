@@ -1,6 +1,7 @@
 package beaconclient
 
 import (
+	"net/http"
 	"sync"
 	"time"
 
@@ -17,8 +18,11 @@ type MockBeaconInstance struct {
 	MockProposerDuties     *ProposerDutiesResponse
 	MockProposerDutiesErr  error
 	MockFetchValidatorsErr error
+	MockClockOffset        time.Duration
 
 	ResponseDelay time.Duration
+
+	numPublishedBlocks int
 }
 
 func NewMockBeaconInstance() *MockBeaconInstance {
@@ -83,6 +87,10 @@ func (c *MockBeaconInstance) SyncStatus() (*SyncStatusPayloadData, error) {
 	return c.MockSyncStatus, c.MockSyncStatusErr
 }
 
+func (c *MockBeaconInstance) GetNodeVersion() (string, error) {
+	return "Mock/v0.0.0", nil
+}
+
 func (c *MockBeaconInstance) CurrentSlot() (uint64, error) {
 	c.addDelay()
 	return c.MockSyncStatus.HeadSlot, nil
@@ -108,7 +116,16 @@ func (c *MockBeaconInstance) addDelay() {
 }
 
 func (c *MockBeaconInstance) PublishBlock(block *common.SignedBeaconBlock) (code int, err error) {
-	return 0, nil
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.numPublishedBlocks++
+	return http.StatusOK, nil
+}
+
+func (c *MockBeaconInstance) NumPublishedBlocks() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.numPublishedBlocks
 }
 
 func (c *MockBeaconInstance) GetGenesis() (*GetGenesisResponse, error) {
@@ -134,3 +151,7 @@ func (c *MockBeaconInstance) GetRandao(slot uint64) (spec *GetRandaoResponse, er
 func (c *MockBeaconInstance) GetWithdrawals(slot uint64) (spec *GetWithdrawalsResponse, err error) {
 	return nil, nil
 }
+
+func (c *MockBeaconInstance) ClockOffset() (time.Duration, error) {
+	return c.MockClockOffset, nil
+}
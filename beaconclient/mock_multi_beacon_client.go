@@ -1,6 +1,8 @@
 package beaconclient
 
 import (
+	"time"
+
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/flashbots/mev-boost-relay/common"
 )
@@ -75,3 +77,7 @@ func (*MockMultiBeaconClient) GetWithdrawals(slot uint64) (spec *GetWithdrawalsR
 	resp.Data.Withdrawals = append(resp.Data.Withdrawals, &capella.Withdrawal{}) //nolint:exhaustruct
 	return resp, nil
 }
+
+func (*MockMultiBeaconClient) ClockOffset() (time.Duration, error) {
+	return 0, nil
+}
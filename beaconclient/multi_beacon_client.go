@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/sirupsen/logrus"
@@ -18,6 +19,7 @@ var (
 	ErrBeaconNodesUnavailable   = errors.New("all beacon nodes responded with error")
 	ErrWithdrawalsBeforeCapella = errors.New("withdrawals are not supported before capella")
 	ErrBeaconBlock202           = errors.New("beacon block failed validation but was still broadcast (202)")
+	ErrUnsupportedNodeVersion   = errors.New("unsupported beacon node version")
 )
 
 // IMultiBeaconClient is the interface for the MultiBeaconClient, which can manage several beacon client instances under the hood
@@ -37,11 +39,14 @@ type IMultiBeaconClient interface {
 	GetBlock(blockID string) (block *GetBlockResponse, err error)
 	GetRandao(slot uint64) (spec *GetRandaoResponse, err error)
 	GetWithdrawals(slot uint64) (spec *GetWithdrawalsResponse, err error)
+	// ClockOffset returns how far the beacon node's clock is ahead of the local clock
+	ClockOffset() (time.Duration, error)
 }
 
 // IBeaconInstance is the interface for a single beacon client instance
 type IBeaconInstance interface {
 	SyncStatus() (*SyncStatusPayloadData, error)
+	GetNodeVersion() (string, error)
 	CurrentSlot() (uint64, error)
 	SubscribeToHeadEvents(slotC chan HeadEventData)
 	SubscribeToPayloadAttributesEvents(slotC chan PayloadAttributesEvent)
@@ -55,6 +60,7 @@ type IBeaconInstance interface {
 	GetBlock(blockID string) (*GetBlockResponse, error)
 	GetRandao(slot uint64) (spec *GetRandaoResponse, err error)
 	GetWithdrawals(slot uint64) (spec *GetWithdrawalsResponse, err error)
+	ClockOffset() (time.Duration, error)
 }
 
 type MultiBeaconClient struct {
@@ -151,7 +157,7 @@ func (c *MultiBeaconClient) SubscribeToPayloadAttributesEvents(slotC chan Payloa
 
 // GetStateValidators returns all known validators, and queries the beacon nodes in reverse order (because it is a heavy request for the CL client)
 func (c *MultiBeaconClient) GetStateValidators(stateID string) (*GetStateValidatorsResponse, error) {
-	for i, client := range c.beaconInstancesByLeastUsed() {
+	for _, client := range c.beaconInstancesByLeastUsed() {
 		log := c.log.WithField("uri", client.GetURI())
 		log.Debug("fetching validators")
 
@@ -161,9 +167,8 @@ func (c *MultiBeaconClient) GetStateValidators(stateID string) (*GetStateValidat
 			continue
 		}
 
-		c.bestBeaconIndex.Store(int64(i))
-
 		// Received successful response. Set this index as last successful beacon node
+		c.setBestBeaconInstance(client)
 		return validators, nil
 	}
 
@@ -175,7 +180,7 @@ func (c *MultiBeaconClient) GetProposerDuties(epoch uint64) (*ProposerDutiesResp
 	clients := c.beaconInstancesByLastResponse()
 	log := c.log.WithField("epoch", epoch)
 
-	for i, client := range clients {
+	for _, client := range clients {
 		log := log.WithField("uri", client.GetURI())
 		log.Debug("fetching proposer duties")
 
@@ -185,9 +190,8 @@ func (c *MultiBeaconClient) GetProposerDuties(epoch uint64) (*ProposerDutiesResp
 			continue
 		}
 
-		c.bestBeaconIndex.Store(int64(i))
-
 		// Received successful response. Set this index as last successful beacon node
+		c.setBestBeaconInstance(client)
 		return duties, nil
 	}
 
@@ -209,6 +213,25 @@ func (c *MultiBeaconClient) beaconInstancesByLastResponse() []IBeaconInstance {
 	return instances
 }
 
+// setBestBeaconInstance remembers the given instance as the one with the last successful response, so
+// that it is tried first on the next request. Logs whenever the active beacon node changes.
+func (c *MultiBeaconClient) setBestBeaconInstance(instance IBeaconInstance) {
+	for i, _instance := range c.beaconInstances {
+		if _instance != instance {
+			continue
+		}
+
+		prevIndex := c.bestBeaconIndex.Swap(int64(i))
+		if prevIndex != int64(i) {
+			c.log.WithFields(logrus.Fields{
+				"uri":     instance.GetURI(),
+				"prevURI": c.beaconInstances[prevIndex].GetURI(),
+			}).Info("switched active beacon node")
+		}
+		return
+	}
+}
+
 // beaconInstancesByLeastUsed returns a list of beacon clients that has the client
 // with the last successful response as the last element of the slice (used only by
 // GetStateValidators, because it's a heavy call on the CL)
@@ -268,7 +291,7 @@ func (c *MultiBeaconClient) PublishBlock(block *common.SignedBeaconBlock) (code
 			continue
 		}
 
-		c.bestBeaconIndex.Store(int64(res.index))
+		c.setBestBeaconInstance(clients[res.index])
 
 		log.WithField("statusCode", res.code).Info("published block")
 		return res.code, nil
@@ -281,14 +304,14 @@ func (c *MultiBeaconClient) PublishBlock(block *common.SignedBeaconBlock) (code
 // GetGenesis returns the genesis info - https://ethereum.github.io/beacon-APIs/#/Beacon/getGenesis
 func (c *MultiBeaconClient) GetGenesis() (genesisInfo *GetGenesisResponse, err error) {
 	clients := c.beaconInstancesByLastResponse()
-	for i, client := range clients {
+	for _, client := range clients {
 		log := c.log.WithField("uri", client.GetURI())
 		if genesisInfo, err = client.GetGenesis(); err != nil {
 			log.WithError(err).Warn("failed to get genesis info")
 			continue
 		}
 
-		c.bestBeaconIndex.Store(int64(i))
+		c.setBestBeaconInstance(client)
 
 		return genesisInfo, nil
 	}
@@ -307,6 +330,8 @@ func (c *MultiBeaconClient) GetSpec() (spec *GetSpecResponse, err error) {
 			continue
 		}
 
+		c.setBestBeaconInstance(client)
+
 		return spec, nil
 	}
 
@@ -317,14 +342,14 @@ func (c *MultiBeaconClient) GetSpec() (spec *GetSpecResponse, err error) {
 // GetForkSchedule - https://ethereum.github.io/beacon-APIs/#/Config/getForkSchedule
 func (c *MultiBeaconClient) GetForkSchedule() (spec *GetForkScheduleResponse, err error) {
 	clients := c.beaconInstancesByLastResponse()
-	for i, client := range clients {
+	for _, client := range clients {
 		log := c.log.WithField("uri", client.GetURI())
 		if spec, err = client.GetForkSchedule(); err != nil {
 			log.WithError(err).Warn("failed to get fork schedule")
 			continue
 		}
 
-		c.bestBeaconIndex.Store(int64(i))
+		c.setBestBeaconInstance(client)
 
 		return spec, nil
 	}
@@ -343,6 +368,8 @@ func (c *MultiBeaconClient) GetBlock(blockID string) (block *GetBlockResponse, e
 			continue
 		}
 
+		c.setBestBeaconInstance(client)
+
 		return block, nil
 	}
 
@@ -353,14 +380,14 @@ func (c *MultiBeaconClient) GetBlock(blockID string) (block *GetBlockResponse, e
 // GetRandao - 3500/eth/v1/beacon/states/<slot>/randao
 func (c *MultiBeaconClient) GetRandao(slot uint64) (randaoResp *GetRandaoResponse, err error) {
 	clients := c.beaconInstancesByLastResponse()
-	for i, client := range clients {
+	for _, client := range clients {
 		log := c.log.WithField("uri", client.GetURI())
 		if randaoResp, err = client.GetRandao(slot); err != nil {
 			log.WithField("slot", slot).WithError(err).Warn("failed to get randao")
 			continue
 		}
 
-		c.bestBeaconIndex.Store(int64(i))
+		c.setBestBeaconInstance(client)
 
 		return randaoResp, nil
 	}
@@ -372,7 +399,7 @@ func (c *MultiBeaconClient) GetRandao(slot uint64) (randaoResp *GetRandaoRespons
 // GetWithdrawals - 3500/eth/v1/beacon/states/<slot>/withdrawals
 func (c *MultiBeaconClient) GetWithdrawals(slot uint64) (withdrawalsResp *GetWithdrawalsResponse, err error) {
 	clients := c.beaconInstancesByLastResponse()
-	for i, client := range clients {
+	for _, client := range clients {
 		log := c.log.WithField("uri", client.GetURI())
 		if withdrawalsResp, err = client.GetWithdrawals(slot); err != nil {
 			if strings.Contains(err.Error(), "Withdrawals not enabled before capella") {
@@ -382,7 +409,7 @@ func (c *MultiBeaconClient) GetWithdrawals(slot uint64) (withdrawalsResp *GetWit
 			continue
 		}
 
-		c.bestBeaconIndex.Store(int64(i))
+		c.setBestBeaconInstance(client)
 
 		return withdrawalsResp, nil
 	}
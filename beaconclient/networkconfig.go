@@ -0,0 +1,33 @@
+package beaconclient
+
+import (
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+type networkConfigSource interface {
+	GetGenesis() (*GetGenesisResponse, error)
+	GetSpec() (spec *GetSpecResponse, err error)
+}
+
+// FetchNetworkConfig returns the network config of the beacon node: the genesis validators root, time and fork version
+// from the genesis endpoint, and the fork versions and epochs from the spec. Values the node doesn't provide are empty.
+func FetchNetworkConfig(beacon networkConfigSource) (*common.NetworkConfig, error) {
+	genesis, err := beacon.GetGenesis()
+	if err != nil {
+		return nil, err
+	}
+	spec, err := beacon.GetSpec()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := common.NetworkConfigFromSpec(spec.Values())
+	if err != nil {
+		return nil, err
+	}
+	cfg.GenesisValidatorsRoot = genesis.Data.GenesisValidatorsRoot
+	cfg.GenesisTime = genesis.Data.GenesisTime
+	if genesis.Data.GenesisForkVersion != "" {
+		cfg.GenesisForkVersion = genesis.Data.GenesisForkVersion
+	}
+	return cfg, nil
+}
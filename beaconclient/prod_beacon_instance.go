@@ -32,6 +32,9 @@ type HeadEventData struct {
 	Slot  uint64 `json:"slot,string"`
 	Block string `json:"block"`
 	State string `json:"state"`
+
+	// Root the proposer duties of the current epoch depend on, changes with a reorg across the epoch boundary
+	CurrentDutyDependentRoot string `json:"current_duty_dependent_root"`
 }
 
 // PayloadAttributesEvent represents the data of a payload_attributes event
@@ -55,6 +58,7 @@ type PayloadAttributes struct {
 	PrevRandao            string                `json:"prev_randao"`
 	SuggestedFeeRecipient string                `json:"suggested_fee_recipient"`
 	Withdrawals           []*capella.Withdrawal `json:"withdrawals"`
+	ParentBeaconBlockRoot string                `json:"parent_beacon_block_root"` // deneb only
 }
 
 func (c *ProdBeaconInstance) SubscribeToHeadEvents(slotC chan HeadEventData) {
@@ -63,6 +67,9 @@ func (c *ProdBeaconInstance) SubscribeToHeadEvents(slotC chan HeadEventData) {
 	log.Info("subscribing to head events")
 
 	client := sse.NewClient(eventsURL)
+	client.ReconnectNotify = func(err error, backoff time.Duration) {
+		log.WithError(err).Warnf("head event stream failed, retrying in %s", backoff.String())
+	}
 
 	for {
 		err := client.SubscribeRaw(func(msg *sse.Event) {
@@ -89,6 +96,9 @@ func (c *ProdBeaconInstance) SubscribeToPayloadAttributesEvents(payloadAttribute
 	log.Info("subscribing to payload_attributes events")
 
 	client := sse.NewClient(eventsURL)
+	client.ReconnectNotify = func(err error, backoff time.Duration) {
+		log.WithError(err).Warnf("payload_attributes event stream failed, retrying in %s", backoff.String())
+	}
 
 	for {
 		err := client.SubscribeRaw(func(msg *sse.Event) {
@@ -158,12 +168,28 @@ type SyncStatusPayloadData struct {
 func (c *ProdBeaconInstance) SyncStatus() (*SyncStatusPayloadData, error) {
 	uri := c.beaconURI + "/eth/v1/node/syncing"
 	timeout := 5 * time.Second
-	resp := new(SyncStatusPayload)
+	resp := new(json.RawMessage)
 	_, err := fetchBeacon(http.MethodGet, uri, nil, resp, &timeout)
 	if err != nil {
 		return nil, err
 	}
-	return &resp.Data, nil
+	return parseSyncStatus(uri, *resp)
+}
+
+type GetNodeVersionResponse struct {
+	Data struct {
+		Version string `json:"version"`
+	}
+}
+
+// GetNodeVersion returns the version string of the beacon node, i.e. "Lighthouse/v4.1.0-693886b/x86_64-linux"
+// https://ethereum.github.io/beacon-APIs/#/Node/getNodeVersion
+func (c *ProdBeaconInstance) GetNodeVersion() (string, error) {
+	uri := c.beaconURI + "/eth/v1/node/version"
+	timeout := 5 * time.Second
+	resp := new(GetNodeVersionResponse)
+	_, err := fetchBeacon(http.MethodGet, uri, nil, resp, &timeout)
+	return resp.Data.Version, err
 }
 
 func (c *ProdBeaconInstance) CurrentSlot() (uint64, error) {
@@ -175,7 +201,8 @@ func (c *ProdBeaconInstance) CurrentSlot() (uint64, error) {
 }
 
 type ProposerDutiesResponse struct {
-	Data []ProposerDutiesResponseData
+	DependentRoot string `json:"dependent_root"`
+	Data          []ProposerDutiesResponseData
 }
 
 type ProposerDutiesResponseData struct {
@@ -286,6 +313,20 @@ type GetSpecResponse struct {
 	DomainAggregateAndProof         string `json:"DOMAIN_AGGREGATE_AND_PROOF"`         //nolint:tagliatelle
 	InactivityPenaltyQuotient       string `json:"INACTIVITY_PENALTY_QUOTIENT"`        //nolint:tagliatelle
 	InactivityPenaltyQuotientAltair string `json:"INACTIVITY_PENALTY_QUOTIENT_ALTAIR"` //nolint:tagliatelle
+
+	// Data has all values of the spec, most of them strings
+	Data map[string]any `json:"data"`
+}
+
+// Values returns the string values of the spec (i.e. the fork versions and epochs), by key
+func (s *GetSpecResponse) Values() map[string]string {
+	values := make(map[string]string, len(s.Data))
+	for key, value := range s.Data {
+		if str, ok := value.(string); ok {
+			values[key] = str
+		}
+	}
+	return values
 }
 
 // GetSpec - https://ethereum.github.io/beacon-APIs/#/Config/getSpec
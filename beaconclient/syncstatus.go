@@ -0,0 +1,73 @@
+package beaconclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidSyncStatus = errors.New("invalid sync status response from beacon node")
+
+	// startup waits this long for a beacon node to report its sync status, retrying with backoff in between
+	syncStatusStartupTimeout    = time.Duration(cli.GetEnvInt("BEACON_STARTUP_TIMEOUT_SEC", 60)) * time.Second
+	syncStatusRetryBackoff      = 500 * time.Millisecond
+	syncStatusRetryBackoffLimit = 8 * time.Second
+)
+
+// parseSyncStatus parses the sync status response. Nodes which don't report their head slot or whether they're
+// syncing are treated as syncing, responses without data or which don't match the schema are errors.
+func parseSyncStatus(uri string, body []byte) (*SyncStatusPayloadData, error) {
+	payload := new(SyncStatusPayload)
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal response for %s into %T (unexpected schema, check the beacon node version) from %s: %s", ErrInvalidSyncStatus, uri, payload, truncateBody(body), err.Error())
+	}
+
+	// The schema allows missing fields, which only show with pointers
+	resp := struct {
+		Data *struct {
+			HeadSlot  *string `json:"head_slot"`
+			IsSyncing *bool   `json:"is_syncing"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("%w from %s: %s: %s", ErrInvalidSyncStatus, uri, err.Error(), truncateBody(body))
+	}
+	if resp.Data == nil {
+		return nil, fmt.Errorf("%w from %s: missing data: %s", ErrInvalidSyncStatus, uri, truncateBody(body))
+	}
+
+	status := &SyncStatusPayloadData{HeadSlot: payload.Data.HeadSlot, IsSyncing: true}
+	if resp.Data.HeadSlot != nil && resp.Data.IsSyncing != nil {
+		status.IsSyncing = *resp.Data.IsSyncing
+	}
+	return status, nil
+}
+
+// WaitForSyncStatus calls BestSyncStatus until it succeeds, backing off exponentially between attempts, so that a
+// briefly unavailable or syncing beacon node doesn't stop a service on startup. It gives up after
+// BEACON_STARTUP_TIMEOUT_SEC and returns the last error.
+func WaitForSyncStatus(log *logrus.Entry, client IMultiBeaconClient) (*SyncStatusPayloadData, error) {
+	deadline := time.Now().Add(syncStatusStartupTimeout)
+	backoff := syncStatusRetryBackoff
+	for {
+		syncStatus, err := client.BestSyncStatus()
+		if err == nil {
+			return syncStatus, nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, fmt.Errorf("no beacon node sync status within %s: %w", syncStatusStartupTimeout, err)
+		}
+
+		log.WithError(err).Warnf("failed to get beacon node sync status, retrying in %s", backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > syncStatusRetryBackoffLimit {
+			backoff = syncStatusRetryBackoffLimit
+		}
+	}
+}
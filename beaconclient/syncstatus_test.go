@@ -0,0 +1,82 @@
+package beaconclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSyncStatus(t *testing.T) {
+	status, err := parseSyncStatus("/eth/v1/node/syncing", []byte(`{"data": {"head_slot": "123", "sync_distance": "0", "is_syncing": false}}`))
+	require.NoError(t, err)
+	require.Equal(t, &SyncStatusPayloadData{HeadSlot: 123, IsSyncing: false}, status)
+
+	// Missing fields are treated as syncing
+	for _, body := range []string{`{"data": {"head_slot": "123"}}`, `{"data": {"is_syncing": false}}`, `{"data": {"head_slot": null, "is_syncing": false}}`} {
+		status, err = parseSyncStatus("/eth/v1/node/syncing", []byte(body))
+		require.NoError(t, err, body)
+		require.True(t, status.IsSyncing, body)
+	}
+
+	// Malformed responses are errors
+	for _, body := range []string{``, `{"data": `, `{}`, `{"data": null}`, `{"data": {"head_slot": "abc", "is_syncing": false}}`, `{"data": {"head_slot": "1", "is_syncing": "no"}}`} {
+		_, err = parseSyncStatus("/eth/v1/node/syncing", []byte(body))
+		require.ErrorIs(t, err, ErrInvalidSyncStatus, body)
+	}
+}
+
+func TestSyncStatusPartialResponse(t *testing.T) {
+	body := `{"data": {}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	status, err := NewProdBeaconInstance(common.TestLog, srv.URL).SyncStatus()
+	require.NoError(t, err)
+	require.True(t, status.IsSyncing)
+
+	body = `{"data": {"head_slot": -1}}`
+	_, err = NewProdBeaconInstance(common.TestLog, srv.URL).SyncStatus()
+	require.ErrorIs(t, err, ErrInvalidSyncStatus)
+}
+
+// flakyBeaconClient fails BestSyncStatus numFailures times
+type flakyBeaconClient struct {
+	*MockMultiBeaconClient
+	numFailures int
+	numCalls    int
+}
+
+func (c *flakyBeaconClient) BestSyncStatus() (*SyncStatusPayloadData, error) {
+	c.numCalls++
+	if c.numCalls <= c.numFailures {
+		return nil, ErrBeaconNodesUnavailable
+	}
+	return c.MockMultiBeaconClient.BestSyncStatus()
+}
+
+func TestWaitForSyncStatus(t *testing.T) {
+	prevBackoff, prevTimeout := syncStatusRetryBackoff, syncStatusStartupTimeout
+	t.Cleanup(func() {
+		syncStatusRetryBackoff, syncStatusStartupTimeout = prevBackoff, prevTimeout
+	})
+	syncStatusRetryBackoff = time.Millisecond
+	syncStatusStartupTimeout = 100 * time.Millisecond
+
+	// Retries until a beacon node is available
+	client := &flakyBeaconClient{MockMultiBeaconClient: NewMockMultiBeaconClient(), numFailures: 3}
+	status, err := WaitForSyncStatus(common.TestLog, client)
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	require.Equal(t, 4, client.numCalls)
+
+	// and gives up after the timeout
+	client = &flakyBeaconClient{MockMultiBeaconClient: NewMockMultiBeaconClient(), numFailures: 1000}
+	_, err = WaitForSyncStatus(common.TestLog, client)
+	require.ErrorIs(t, err, ErrBeaconNodesUnavailable)
+}
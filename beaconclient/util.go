@@ -17,8 +17,19 @@ var (
 	StateIDGenesis   = "genesis"
 	StateIDFinalized = "finalized"
 	StateIDJustified = "justified"
+
+	// maximum number of bytes of a response body included in error messages
+	maxErrorBodyLen = 500
 )
 
+// truncateBody returns the response body for error messages, shortened to maxErrorBodyLen
+func truncateBody(body []byte) string {
+	if len(body) > maxErrorBodyLen {
+		return string(body[:maxErrorBodyLen]) + "..."
+	}
+	return string(body)
+}
+
 func fetchBeacon(method, url string, payload, dst any, timeout *time.Duration) (code int, err error) {
 	var req *http.Request
 
@@ -63,15 +74,16 @@ func fetchBeacon(method, url string, payload, dst any, timeout *time.Duration) (
 			Message string `json:"message"`
 		}{}
 		if err = json.Unmarshal(bodyBytes, ec); err != nil {
-			return resp.StatusCode, fmt.Errorf("could not unmarshal error response from beacon node for %s from %s: %w", url, string(bodyBytes), err)
+			return resp.StatusCode, fmt.Errorf("could not unmarshal error response from beacon node for %s (status %d) from %s: %w", url, resp.StatusCode, truncateBody(bodyBytes), err)
 		}
-		return resp.StatusCode, fmt.Errorf("%w: %s", ErrHTTPErrorResponse, ec.Message)
+		return resp.StatusCode, fmt.Errorf("%w for %s (status %d): %s", ErrHTTPErrorResponse, url, resp.StatusCode, ec.Message)
 	}
 
 	if dst != nil {
 		err = json.Unmarshal(bodyBytes, dst)
 		if err != nil {
-			return resp.StatusCode, fmt.Errorf("could not unmarshal response for %s from %s: %w", url, string(bodyBytes), err)
+			// Most likely the beacon node version uses a different schema for this endpoint
+			return resp.StatusCode, fmt.Errorf("could not unmarshal response for %s into %T (unexpected schema, check the beacon node version) from %s: %w", url, dst, truncateBody(bodyBytes), err)
 		}
 	}
 
@@ -0,0 +1,69 @@
+package beaconclient
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// minNodeVersions are the oldest beacon node versions known to work with the relay (Capella support and
+// payload_attributes events). Other clients are not checked.
+var minNodeVersions = map[string][3]int{
+	"lighthouse": {4, 0, 0},
+	"prysm":      {4, 0, 0},
+}
+
+var reNodeVersion = regexp.MustCompile(`^([A-Za-z-]+)/v?([0-9]+)\.([0-9]+)\.([0-9]+)`)
+
+// parseNodeVersion extracts the client name (lowercase) and semantic version from a node version
+// string like "Lighthouse/v4.1.0-693886b/x86_64-linux"
+func parseNodeVersion(version string) (client string, semver [3]int, ok bool) {
+	match := reNodeVersion.FindStringSubmatch(version)
+	if match == nil {
+		return "", semver, false
+	}
+	for i := range semver {
+		semver[i], _ = strconv.Atoi(match[i+2])
+	}
+	return strings.ToLower(match[1]), semver, true
+}
+
+// checkNodeVersion returns an error if the version string belongs to a known client older than its minimum version
+func checkNodeVersion(version string) error {
+	client, semver, ok := parseNodeVersion(version)
+	if !ok {
+		return nil
+	}
+	minVersion, found := minNodeVersions[client]
+	if !found {
+		return nil
+	}
+	for i := range semver {
+		if semver[i] > minVersion[i] {
+			return nil
+		} else if semver[i] < minVersion[i] {
+			return fmt.Errorf("%w: %s, need at least v%d.%d.%d", ErrUnsupportedNodeVersion, version, minVersion[0], minVersion[1], minVersion[2])
+		}
+	}
+	return nil
+}
+
+// CheckNodeVersions logs the version of each beacon node, with a warning for unsupported versions. Unreachable nodes are only logged.
+func (c *MultiBeaconClient) CheckNodeVersions() {
+	for _, instance := range c.beaconInstances {
+		log := c.log.WithField("uri", instance.GetURI())
+		version, err := instance.GetNodeVersion()
+		if err != nil {
+			log.WithError(err).Warn("failed to get beacon node version")
+			continue
+		}
+
+		log = log.WithField("version", version)
+		if err := checkNodeVersion(version); err != nil {
+			log.WithError(err).Warn("unsupported beacon node version, requests to this node might fail")
+		} else {
+			log.Info("beacon node version")
+		}
+	}
+}
@@ -1,14 +1,22 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
 	"net/url"
 	"os"
 	"os/signal"
+	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/go-utils/cli"
 	"github.com/flashbots/mev-boost-relay/beaconclient"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/flashbots/mev-boost-relay/database"
@@ -19,29 +27,156 @@ import (
 )
 
 var (
-	apiDefaultListenAddr = common.GetEnv("LISTEN_ADDR", "localhost:9062")
-	apiDefaultBlockSim   = common.GetEnv("BLOCKSIM_URI", "http://localhost:8545")
-	apiDefaultSecretKey  = common.GetEnv("SECRET_KEY", "")
-	apiDefaultLogTag     = os.Getenv("LOG_TAG")
+	apiDefaultListenAddr    = common.GetEnv("LISTEN_ADDR", "localhost:9062")
+	apiDefaultBlockSim      = common.GetEnv("BLOCKSIM_URI", "http://localhost:8545")
+	apiDefaultSecretKey     = common.GetEnv("SECRET_KEY", "")
+	apiDefaultSecretKeyFile = common.GetEnv("SECRET_KEY_FILE", "")
+	apiDefaultKeyProvider   = common.GetEnv("KEY_PROVIDER", "local")
+	apiDefaultVaultKeyPath  = common.GetEnv("VAULT_KEY_PATH", "")
+	apiDefaultKMSKeyFile    = common.GetEnv("KMS_CIPHERTEXT_FILE", "")
+	apiDefaultLogTag        = os.Getenv("LOG_TAG")
 
 	apiDefaultPprofEnabled       = os.Getenv("PPROF") == "1"
+	apiDefaultPprofToken         = os.Getenv("PPROF_TOKEN")
+	apiDefaultPprofListenAddr    = os.Getenv("PPROF_LISTEN_ADDR")
 	apiDefaultInternalAPIEnabled = os.Getenv("ENABLE_INTERNAL_API") == "1"
+	apiDefaultMetricsEnabled     = os.Getenv("METRICS") == "1"
+	apiDefaultMetricsListenAddr  = os.Getenv("METRICS_LISTEN_ADDR")
+	apiDefaultRegRateLimit       = cli.GetEnvInt("REG_RATE_LIMIT", 0)
+	apiDefaultRegRateLimitBurst  = cli.GetEnvInt("REG_RATE_LIMIT_BURST", 10)
+	apiDefaultGetPayloadTimeout  = cli.GetEnvInt("GETPAYLOAD_TIMEOUT_MS", 2000)
+	apiDefaultGetPayloadCutoff   = cli.GetEnvInt("GETPAYLOAD_REQUEST_CUTOFF_MS", 4000)
+	apiDefaultSubmitStartMs      = cli.GetEnvInt("SUBMIT_START_MS", 0)
+	apiDefaultMinBidWei          = common.GetEnv("MIN_BID_WEI", "")
+	apiDefaultAdminToken         = os.Getenv("ADMIN_TOKEN")
+	apiDefaultPayloadDataToken   = os.Getenv("PAYLOAD_DATA_TOKEN")
+	apiDefaultAdminAllowIPs      = common.GetSliceEnv("ADMIN_ALLOW_IPS", nil)
+	apiDefaultTrustedProxies     = common.GetSliceEnv("TRUSTED_PROXIES", nil)
+	apiDefaultBidCacheSize       = cli.GetEnvInt("BID_CACHE_SIZE", 0)
+	apiDefaultDryRun             = os.Getenv("DRY_RUN") == "1"
+	apiDefaultEnforceFeeRecip    = os.Getenv("ENFORCE_FEE_RECIPIENT") == "1"
+	apiDefaultOptimistic         = os.Getenv("OPTIMISTIC") != "0"
+	apiDefaultSingleHeader       = os.Getenv("SINGLE_HEADER_PER_SLOT") == "1"
+	apiDefaultProposerOnly       = os.Getenv("GETHEADER_PROPOSER_ONLY") == "1"
+	apiDefaultGetHeaderDeadline  = cli.GetEnvInt("GETHEADER_DEADLINE_INTO_SLOT_MS", 0)
+	apiDefaultDebugHeaders       = os.Getenv("DEBUG_HEADERS") == "1"
+	apiDefaultBidStreamEnabled   = os.Getenv("BID_STREAM") == "1"
+	apiDefaultSigVerifyWorkers   = cli.GetEnvInt("SIG_VERIFY_WORKERS", runtime.NumCPU())
+	apiDefaultRegSigCacheMs      = cli.GetEnvInt("REGISTRATION_SIG_CACHE_MS", 0)
+	apiDefaultGetPayloadConc     = cli.GetEnvInt("GETPAYLOAD_CONCURRENCY", 0)
+	apiDefaultCORSOrigins        = common.GetSliceEnv("CORS_ORIGINS", nil)
+	apiDefaultMinGasLimit        = cli.GetEnvInt("MIN_GAS_LIMIT", 5000)          // protocol minimum
+	apiDefaultMaxGasLimit        = cli.GetEnvInt("MAX_GAS_LIMIT", 1_000_000_000) // far above any mainnet gas limit
+	apiDefaultPreviousPubkeys    = common.GetSliceEnv("PREVIOUS_PUBKEYS", nil)
+	apiDefaultPeerRelays         = common.GetSliceEnv("PEER_RELAYS", nil)
+	apiDefaultBuilderAllowlist   = os.Getenv("BUILDER_ALLOWLIST")
+	apiDefaultMaxSubmitBytes     = cli.GetEnvInt("MAX_SUBMIT_BYTES", api.DefaultMaxSubmitBytes)
+	apiDefaultMaxRegBytes        = cli.GetEnvInt("MAX_REG_BYTES", api.DefaultMaxRegistrationBytes)
+	apiDefaultMaxRegsPerRequest  = cli.GetEnvInt("MAX_REGISTRATIONS_PER_REQUEST", 0)
+	apiDefaultTLSCert            = os.Getenv("TLS_CERT")
+	apiDefaultTLSKey             = os.Getenv("TLS_KEY")
+	apiDefaultBuilderCA          = os.Getenv("BUILDER_CA")
+	apiDefaultUnixSocketMode     = common.GetEnv("UNIX_SOCKET_MODE", "0660")
+	apiDefaultAuditLog           = os.Getenv("AUDIT_LOG") == "1"
+	apiDefaultAlertWebhook       = os.Getenv("ALERT_WEBHOOK")
+	apiDefaultRejectSlashed      = os.Getenv("REJECT_SLASHED_VALIDATORS") == "1"
+	apiDefaultRetentionSlots     = cli.GetEnvInt("RETENTION_SLOTS", 0)
+	apiDefaultSubmitBatchMs      = cli.GetEnvInt("SUBMISSION_BATCH_WINDOW_MS", 0)
+	apiDefaultRetentionDir       = os.Getenv("RETENTION_ARCHIVE_DIR")
+	apiDefaultBidHistorySize     = cli.GetEnvInt("BID_HISTORY_SIZE", 0)
+	apiDefaultBidHistorySlots    = cli.GetEnvInt("BID_HISTORY_RETENTION_SLOTS", 7200)
+	apiDefaultReadTimeoutMs      = cli.GetEnvInt("API_TIMEOUT_READ_MS", int(api.DefaultReadTimeout.Milliseconds()))
+	apiDefaultReadHeaderTimeout  = cli.GetEnvInt("API_TIMEOUT_READHEADER_MS", int(api.DefaultReadHeaderTimeout.Milliseconds()))
+	apiDefaultWriteTimeoutMs     = cli.GetEnvInt("API_TIMEOUT_WRITE_MS", int(api.DefaultWriteTimeout.Milliseconds()))
+	apiDefaultIdleTimeoutMs      = cli.GetEnvInt("API_TIMEOUT_IDLE_MS", int(api.DefaultIdleTimeout.Milliseconds()))
+	apiDefaultCompress           = os.Getenv("DISABLE_COMPRESSION") != "1"
+	apiDefaultStrict             = os.Getenv("STRICT_STARTUP") == "1"
+	apiDefaultAutoForkVersion    = os.Getenv("AUTO_FORK_VERSION") == "1"
+	apiDefaultCompressMinSize    = cli.GetEnvInt("COMPRESS_MIN_SIZE", api.DefaultCompressMinSize)
+
+	// An invalid DEBUG_SAMPLE_RATE fails the startup, unless --debug-sample-rate is given
+	apiDefaultDebugSampleRate, errDebugSampleRate = strconv.ParseFloat(common.GetEnv("DEBUG_SAMPLE_RATE", "0"), 64)
 
 	// Default Builder, Data, and Proposer API as true.
 	apiDefaultBuilderAPIEnabled  = os.Getenv("DISABLE_BUILDER_API") != "1"
 	apiDefaultDataAPIEnabled     = os.Getenv("DISABLE_DATA_API") != "1"
 	apiDefaultProposerAPIEnabled = os.Getenv("DISABLE_PROPOSER_API") != "1"
 
-	apiListenAddr   string
-	apiPprofEnabled bool
-	apiSecretKey    string
-	apiBlockSimURL  string
-	apiDebug        bool
-	apiBuilderAPI   bool
-	apiDataAPI      bool
-	apiInternalAPI  bool
-	apiProposerAPI  bool
-	apiLogTag       string
+	apiListenAddr    string
+	apiPprofEnabled  bool
+	apiSecretKey     string
+	apiSecretKeyFile string
+	apiKeyProvider   string
+	apiVaultKeyPath  string
+	apiKMSKeyFile    string
+	apiBlockSimURL   string
+	apiDebug         bool
+	apiBuilderAPI    bool
+	apiDataAPI       bool
+	apiInternalAPI   bool
+	apiProposerAPI   bool
+	apiLogTag        string
+
+	apiMetricsEnabled    bool
+	apiMetricsListenAddr string
+
+	apiRegRateLimit      float64
+	apiRegRateLimitBurst int
+
+	apiGetPayloadTimeoutMs int
+	apiGetPayloadCutoffMs  int
+	apiSubmitStartMs       int
+	apiMinBidWei           string
+
+	apiCapellaForkVersion string
+	apiAdminToken         string
+	apiPayloadDataToken   string
+	apiAdminAllowIPs      []string
+	apiTrustedProxies     []string
+	apiBidCacheSize       int
+	apiPprofToken         string
+	apiPprofListenAddr    string
+	apiDryRun             bool
+	apiEnforceFeeRecip    bool
+	apiDebugHeaders       bool
+	apiDebugSampleRate    float64
+	apiMinGasLimit        uint64
+	apiBidStreamEnabled   bool
+	apiSigVerifyWorkers   int
+	apiRegSigCacheMs      int
+	apiGetPayloadConc     int
+	apiCORSOrigins        []string
+	apiMaxGasLimit        uint64
+	apiPreviousPubkeys    []string
+	apiPeerRelays         []string
+	apiBuilderAllowlist   string
+	apiMaxSubmitBytes     int64
+	apiMaxRegBytes        int64
+	apiMaxRegsPerRequest  int
+	apiTLSCert            string
+	apiTLSKey             string
+	apiBuilderCA          string
+	apiUnixSocketMode     string
+	apiAuditLog           bool
+	apiAlertWebhook       string
+	apiRejectSlashed      bool
+	apiOptimistic         bool
+	apiSingleHeader       bool
+	apiProposerOnly       bool
+	apiGetHeaderDeadline  int
+	apiRetentionSlots     uint64
+	apiSubmitBatchMs      int
+	apiRetentionDir       string
+	apiBidHistorySize     int
+	apiBidHistorySlots    uint64
+	apiReadTimeoutMs      int
+	apiReadHeaderTimeout  int
+	apiWriteTimeoutMs     int
+	apiCompress           bool
+	apiCompressMinSize    int
+	apiIdleTimeoutMs      int
+	apiStrict             bool
+	apiAutoForkVersion    bool
 )
 
 func init() {
@@ -50,23 +185,85 @@ func init() {
 	apiCmd.Flags().StringVar(&logLevel, "loglevel", defaultLogLevel, "log-level: trace, debug, info, warn/warning, error, fatal, panic")
 	apiCmd.Flags().StringVar(&apiLogTag, "log-tag", apiDefaultLogTag, "if set, a 'tag' field will be added to all log entries")
 	apiCmd.Flags().BoolVar(&apiDebug, "debug", false, "debug logging")
+	apiCmd.Flags().BoolVar(&apiStrict, "strict", apiDefaultStrict, "exit at the first failed startup check, instead of running all checks and exiting with a summary")
 
-	apiCmd.Flags().StringVar(&apiListenAddr, "listen-addr", apiDefaultListenAddr, "listen address for webserver")
-	apiCmd.Flags().StringSliceVar(&beaconNodeURIs, "beacon-uris", defaultBeaconURIs, "beacon endpoints")
+	apiCmd.Flags().StringVar(&apiListenAddr, "listen-addr", apiDefaultListenAddr, "listen address for webserver (host:port, or unix:/path/to/sock for a Unix domain socket)")
+	apiCmd.Flags().StringVar(&apiUnixSocketMode, "unix-socket-mode", apiDefaultUnixSocketMode, "file permissions of the Unix domain socket (octal)")
+	apiCmd.Flags().StringVar(&apiTLSCert, "tls-cert", apiDefaultTLSCert, "TLS certificate file, to terminate TLS in the relay (requires --tls-key, reloaded on SIGHUP)")
+	apiCmd.Flags().StringVar(&apiTLSKey, "tls-key", apiDefaultTLSKey, "TLS private key file (requires --tls-cert)")
+	apiCmd.Flags().StringVar(&apiBuilderCA, "builder-ca", apiDefaultBuilderCA, "CA bundle for builder client certificates, required for block submissions with the builder pubkey or ID as common name (requires --tls-cert)")
+	apiCmd.Flags().StringSliceVar(&beaconNodeURIs, "beacon-uris", defaultBeaconURIs, "beacon endpoints (comma-separated or repeated), requests fail over to the next node on error")
 	apiCmd.Flags().StringVar(&redisURI, "redis-uri", defaultRedisURI, "redis uri")
 	apiCmd.Flags().StringVar(&redisReadonlyURI, "redis-readonly-uri", defaultRedisReadonlyURI, "redis readonly uri")
+	addRedisOptionsFlags(apiCmd)
 	apiCmd.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
 	apiCmd.Flags().StringSliceVar(&memcachedURIs, "memcached-uris", defaultMemcachedURIs,
 		"Enable memcached, typically used as secondary backup to Redis for redundancy")
 	apiCmd.Flags().StringVar(&apiSecretKey, "secret-key", apiDefaultSecretKey, "secret key for signing bids")
-	apiCmd.Flags().StringVar(&apiBlockSimURL, "blocksim", apiDefaultBlockSim, "URL for block simulator")
-	apiCmd.Flags().StringVar(&network, "network", defaultNetwork, "Which network to use")
+	apiCmd.Flags().StringVar(&apiSecretKeyFile, "secret-key-file", apiDefaultSecretKeyFile, "file containing the hex-encoded secret key for signing bids (takes precedence over --secret-key)")
+	apiCmd.Flags().StringVar(&apiKeyProvider, "key-provider", apiDefaultKeyProvider, "where to fetch the secret key from at startup: local (--secret-key/--secret-key-file), vault or kms")
+	apiCmd.Flags().StringVar(&apiVaultKeyPath, "vault-key-path", apiDefaultVaultKeyPath, "path of the Vault secret with the hex-encoded key in its VAULT_KEY_FIELD field (with --key-provider vault)")
+	apiCmd.Flags().StringVar(&apiKMSKeyFile, "kms-ciphertext-file", apiDefaultKMSKeyFile, "file with the base64-encoded KMS ciphertext of the secret key (with --key-provider kms)")
+	apiCmd.Flags().StringSliceVar(&apiPreviousPubkeys, "previous-pubkeys", apiDefaultPreviousPubkeys, "pubkeys of previous signing keys, accepted in place of the current one when rotating the key")
+	apiCmd.Flags().StringVar(&apiBuilderAllowlist, "builder-allowlist", apiDefaultBuilderAllowlist, "only accept block submissions from these builders: comma-separated pubkeys, or a file with one pubkey per line (reloaded on SIGHUP)")
+	apiCmd.Flags().StringVar(&apiBlockSimURL, "blocksim", apiDefaultBlockSim, "URL for block simulator (empty: accept block submissions without simulation)")
+	addNetworkFlags(apiCmd)
+	apiCmd.Flags().BoolVar(&apiAutoForkVersion, "auto-fork-version", apiDefaultAutoForkVersion, "use the genesis validators root and fork versions of the beacon node, over the ones of --network and --network-config (which are only needed for values the node doesn't provide)")
+	apiCmd.Flags().StringVar(&apiCapellaForkVersion, "capella-fork-version", "", "override the Capella fork version of the network (i.e. 0x03000000)")
 
 	apiCmd.Flags().BoolVar(&apiPprofEnabled, "pprof", apiDefaultPprofEnabled, "enable pprof API")
+	apiCmd.Flags().StringVar(&apiPprofToken, "pprof-token", apiDefaultPprofToken, "bearer token required for the pprof API, unless --pprof-addr is a loopback address (prefer the PPROF_TOKEN env var)")
+	apiCmd.Flags().StringVar(&apiPprofListenAddr, "pprof-addr", apiDefaultPprofListenAddr, "separate listen address for the pprof API (default: same as listen-addr)")
 	apiCmd.Flags().BoolVar(&apiBuilderAPI, "builder-api", apiDefaultBuilderAPIEnabled, "enable builder API (/builder/...)")
 	apiCmd.Flags().BoolVar(&apiDataAPI, "data-api", apiDefaultDataAPIEnabled, "enable data API (/data/...)")
 	apiCmd.Flags().BoolVar(&apiInternalAPI, "internal-api", apiDefaultInternalAPIEnabled, "enable internal API (/internal/...)")
+	apiCmd.Flags().BoolVar(&apiDryRun, "dry-run", apiDefaultDryRun, "validate registrations and block submissions without storing them, and don't serve bids")
+	apiCmd.Flags().BoolVar(&apiEnforceFeeRecip, "enforce-fee-recipient", apiDefaultEnforceFeeRecip, "refuse to serve bids on getHeader which don't pay the fee recipient of the proposer's registration (mismatches of delivered blocks are always logged)")
+	apiCmd.Flags().BoolVar(&apiOptimistic, "optimistic", apiDefaultOptimistic, "accept submissions of optimistic builders before the block simulation completes, if their collateral covers the value (failed simulations demote the builder, --optimistic=false to simulate all submissions first)")
+	apiCmd.Flags().BoolVar(&apiSingleHeader, "single-header-per-slot", apiDefaultSingleHeader, "serve each proposer only the first served header of a slot, even if a higher bid arrives later (per instance)")
+	apiCmd.Flags().BoolVar(&apiProposerOnly, "getheader-proposer-only", apiDefaultProposerOnly, "only serve getHeader to the proposer of the slot according to the beacon node, 204 to other pubkeys (not if a proxy requests headers for other pubkeys)")
+	apiCmd.Flags().IntVar(&apiGetHeaderDeadline, "getheader-deadline-into-slot-ms", apiDefaultGetHeaderDeadline, "getHeader waits for more bids until this many ms into the slot, regardless of when the request arrives (0: use GETHEADER_MAX_WAIT_MS, which it excludes)")
+	apiCmd.Flags().BoolVar(&apiRejectSlashed, "reject-slashed-validators", apiDefaultRejectSlashed, "refuse registrations and getHeader of validators the beacon node reports as slashed or exited (as of the last known validators refresh)")
+	apiCmd.Flags().Uint64Var(&apiRetentionSlots, "retention-slots", uint64(apiDefaultRetentionSlots), "periodically delete bids, bid traces and payloads older than this many slots from redis (0 to disable)")
+	apiCmd.Flags().StringVar(&apiRetentionDir, "retention-archive-dir", apiDefaultRetentionDir, "append the bid traces deleted by --retention-slots to bidtraces.jsonl in this directory")
+	apiCmd.Flags().Uint64Var(&apiMinGasLimit, "min-gas-limit", uint64(apiDefaultMinGasLimit), "reject validator registrations with a lower gas limit (0: no bound)")
+	apiCmd.Flags().Uint64Var(&apiMaxGasLimit, "max-gas-limit", uint64(apiDefaultMaxGasLimit), "reject validator registrations with a higher gas limit (0: no bound)")
+	apiCmd.Flags().Int64Var(&apiMaxSubmitBytes, "max-submit-bytes", int64(apiDefaultMaxSubmitBytes), "maximum size of a block submission in bytes (after decompression), larger ones are rejected with 413")
+	apiCmd.Flags().IntVar(&apiMaxRegsPerRequest, "max-registrations-per-request", apiDefaultMaxRegsPerRequest, "maximum number of validator registrations in one request, larger batches are rejected with 400 (0: no limit)")
+	apiCmd.Flags().Int64Var(&apiMaxRegBytes, "max-reg-bytes", int64(apiDefaultMaxRegBytes), "maximum size of a validator registration request in bytes, larger ones are rejected with 413")
+	apiCmd.Flags().StringSliceVar(&apiCORSOrigins, "cors-origins", apiDefaultCORSOrigins, "origins allowed to query the data API from the browser (\"*\" for any, default: CORS disabled)")
+	apiCmd.Flags().BoolVar(&apiBidStreamEnabled, "bid-stream", apiDefaultBidStreamEnabled, "serve a websocket feed of accepted block submissions at /relay/v1/builder/bids/stream (requires --admin-token)")
+	apiCmd.Flags().IntVar(&apiSigVerifyWorkers, "sig-verify-workers", apiDefaultSigVerifyWorkers, "number of workers verifying BLS signatures, getPayload takes precedence over registrations and submissions (0: verify in the request goroutine)")
+	apiCmd.Flags().IntVar(&apiRegSigCacheMs, "registration-sig-cache-ms", apiDefaultRegSigCacheMs, "cache the signature verification results of validator registrations for this long, so identical re-submissions skip the BLS verification (0: disabled)")
+	apiCmd.Flags().IntVar(&apiGetPayloadConc, "getpayload-concurrency", apiDefaultGetPayloadConc, "maximum number of concurrent getPayload requests, further requests queue for up to GETPAYLOAD_QUEUE_TIMEOUT_MS and then get 503 (0: no limit)")
+	apiCmd.Flags().BoolVar(&apiAuditLog, "audit-log", apiDefaultAuditLog, "record every served bid and delivered payload in the audit log table (see 'tool audit-log-export')")
+	apiCmd.Flags().StringVar(&apiAlertWebhook, "alert-webhook", apiDefaultAlertWebhook, "URL to post getPayload failures to (i.e. a Slack incoming webhook), failures are saved in the database regardless")
+	apiCmd.Flags().BoolVar(&apiDebugHeaders, "debug-headers", apiDefaultDebugHeaders, "add the builder pubkey and value of the served bid as headers to getHeader responses")
+	apiCmd.Flags().Float64Var(&apiDebugSampleRate, "debug-sample-rate", apiDefaultDebugSampleRate, "fraction of requests (0.0-1.0) whose full request and response bodies are logged")
+	apiCmd.Flags().StringVar(&apiAdminToken, "admin-token", apiDefaultAdminToken, "bearer token required for the internal API (prefer the ADMIN_TOKEN env var)")
+	apiCmd.Flags().StringVar(&apiPayloadDataToken, "payload-data-token", apiDefaultPayloadDataToken, "serve delivered execution payloads at /relay/v1/data/payload to requests with this bearer token (prefer the PAYLOAD_DATA_TOKEN env var)")
+	apiCmd.Flags().StringSliceVar(&apiAdminAllowIPs, "admin-allow-ip", apiDefaultAdminAllowIPs, "only accept internal API, admin and pprof requests from these IPs or CIDRs (comma-separated or repeated), 403 for others")
+	apiCmd.Flags().StringSliceVar(&apiTrustedProxies, "trusted-proxies", apiDefaultTrustedProxies, "IPs or CIDRs of proxies whose X-Forwarded-For header is used for client IPs (admin allowlist, rate limits and logs)")
 	apiCmd.Flags().BoolVar(&apiProposerAPI, "proposer-api", apiDefaultProposerAPIEnabled, "enable proposer API (/proposer/...)")
+	apiCmd.Flags().BoolVar(&apiMetricsEnabled, "metrics", apiDefaultMetricsEnabled, "enable Prometheus metrics (/metrics)")
+	apiCmd.Flags().StringVar(&apiMetricsListenAddr, "metrics-addr", apiDefaultMetricsListenAddr, "separate listen address for /metrics (default: same as listen-addr)")
+	apiCmd.Flags().Float64Var(&apiRegRateLimit, "reg-rate-limit", float64(apiDefaultRegRateLimit), "max validator registration requests per second per IP (0 to disable)")
+	apiCmd.Flags().IntVar(&apiRegRateLimitBurst, "reg-rate-limit-burst", apiDefaultRegRateLimitBurst, "burst allowance for --reg-rate-limit")
+	apiCmd.Flags().IntVar(&apiGetPayloadTimeoutMs, "getpayload-timeout-ms", apiDefaultGetPayloadTimeout, "log an error if loading a getPayload response takes longer than this")
+	apiCmd.Flags().IntVar(&apiSubmitStartMs, "submit-start-ms", apiDefaultSubmitStartMs, "reject block submissions arriving earlier than this into the slot in which the block is built, to drop blocks on stale parents (0 to disable)")
+	apiCmd.Flags().IntVar(&apiGetPayloadCutoffMs, "getpayload-cutoff-ms", apiDefaultGetPayloadCutoff, "refuse getPayload requests arriving later than this into the slot, so the proposer falls back to a local block (0 to disable)")
+	apiCmd.Flags().IntVar(&apiSubmitBatchMs, "submission-batch-window-ms", apiDefaultSubmitBatchMs, "save block submissions together at the end of windows of this duration before the slot start, so the top bid only changes at window boundaries (0 to disable)")
+	apiCmd.Flags().IntVar(&apiBidHistorySize, "bid-history-size", apiDefaultBidHistorySize, "keep the latest this many received bids of each slot, served at /relay/v1/data/bid_history (0 to disable)")
+	apiCmd.Flags().Uint64Var(&apiBidHistorySlots, "bid-history-retention-slots", uint64(apiDefaultBidHistorySlots), "number of slots for which the bid history is kept")
+	apiCmd.Flags().IntVar(&apiReadTimeoutMs, "read-timeout-ms", apiDefaultReadTimeoutMs, "maximum duration for reading an entire request, including the body")
+	apiCmd.Flags().IntVar(&apiReadHeaderTimeout, "read-header-timeout-ms", apiDefaultReadHeaderTimeout, "maximum duration for reading the request headers")
+	apiCmd.Flags().IntVar(&apiWriteTimeoutMs, "write-timeout-ms", apiDefaultWriteTimeoutMs, "maximum duration from reading the request headers to writing the response, needs to exceed GETHEADER_MAX_WAIT_MS and --submission-batch-window-ms")
+	apiCmd.Flags().IntVar(&apiIdleTimeoutMs, "idle-timeout-ms", apiDefaultIdleTimeoutMs, "maximum duration to keep an idle keep-alive connection open")
+	apiCmd.Flags().BoolVar(&apiCompress, "compress", apiDefaultCompress, "compress responses with gzip or deflate for clients accepting it (--compress=false to disable)")
+	apiCmd.Flags().IntVar(&apiCompressMinSize, "compress-min-size", apiDefaultCompressMinSize, "only compress responses of at least this many bytes")
+	apiCmd.Flags().IntVar(&apiBidCacheSize, "bid-cache-size", apiDefaultBidCacheSize, "number of best bids to cache in memory for getHeader (0 to disable, only useful if builder and proposer API run in the same instance)")
+	apiCmd.Flags().StringVar(&apiMinBidWei, "min-bid-wei", apiDefaultMinBidWei, "getHeader only returns bids with at least this value in wei (per slot, regardless of builder)")
+	apiCmd.Flags().StringSliceVar(&apiPeerRelays, "peer-relay", apiDefaultPeerRelays, "peer relay URL (https://0xPUBKEY@host, comma-separated or repeated) whose bids are also served, getPayload for them is proxied to the peer")
 }
 
 var apiCmd = &cobra.Command{
@@ -88,23 +285,99 @@ var apiCmd = &cobra.Command{
 		}
 		log.Infof("boost-relay %s", Version)
 
-		networkInfo, err := common.NewEthNetworkDetails(network)
-		if err != nil {
-			log.WithError(err).Fatalf("error getting network details")
+		// Check the network, secret key, beacon nodes and Redis, and report all problems before exiting
+		checks := &preflight{log: log, strict: apiStrict}
+
+		networkInfo, err := getNetworkDetails()
+		if apiAutoForkVersion && network == "" && networkConfigFile == "" {
+			networkInfo, err = nil, nil // all details from the beacon node
+		}
+		// without a configured genesis validators root, the one of the beacon node is used (also with --auto-fork-version,
+		// to keep the other values of --network and --network-config)
+		fetchGenesisValidatorsRoot := errors.Is(err, common.ErrMissingGenesisValidatorsRoot)
+		if fetchGenesisValidatorsRoot {
+			err = nil
+		}
+		setCapellaForkVersion := func() {
+			if networkInfo == nil || apiCapellaForkVersion == "" {
+				return
+			}
+			if err := networkInfo.SetCapellaForkVersion(apiCapellaForkVersion); err != nil {
+				checks.check("capella fork version", fmt.Errorf("invalid --capella-fork-version %s: %w", apiCapellaForkVersion, err))
+			}
+		}
+		if checks.check("network details", err) {
+			setCapellaForkVersion()
+		}
+
+		// Decode the private key
+		if cmd.Flags().Changed("secret-key") {
+			log.Warn("--secret-key exposes the key in process listings and shell history, use SECRET_KEY or --secret-key-file instead")
+		}
+		var secretKey *bls.SecretKey
+		keyProvider, err := common.NewKeyProvider(apiKeyProvider,
+			&common.LocalKeyProvider{Hex: apiSecretKey, File: apiSecretKeyFile},
+			&common.VaultKeyProvider{
+				Addr:  os.Getenv("VAULT_ADDR"),
+				Token: os.Getenv("VAULT_TOKEN"),
+				Path:  apiVaultKeyPath,
+				Field: common.GetEnv("VAULT_KEY_FIELD", "secret_key"),
+			},
+			&common.KMSKeyProvider{
+				Region:         os.Getenv("AWS_REGION"),
+				Endpoint:       os.Getenv("KMS_ENDPOINT"),
+				CiphertextFile: apiKMSKeyFile,
+			},
+		)
+		if checks.check("key provider", err) {
+			secretKey, err = keyProvider.SecretKey(context.Background())
+			if errors.Is(err, common.ErrNoSecretKey) {
+				log.Warn("No secret key specified, block builder API is disabled")
+			} else {
+				checks.check("secret key", err)
+			}
 		}
-		log.Infof("Using network: %s", networkInfo.Name)
-		log.Debug(networkInfo.String())
 
 		// Connect to beacon clients and ensure it's synced
+		var beaconClient *beaconclient.MultiBeaconClient
+		var syncStatus *beaconclient.SyncStatusPayloadData
 		if len(beaconNodeURIs) == 0 {
-			log.Fatalf("no beacon endpoints specified")
+			checks.check("beacon nodes", errNoBeaconURIs)
+		} else {
+			log.Infof("Using beacon endpoints: %s", strings.Join(beaconNodeURIs, ", "))
+			var beaconInstances []beaconclient.IBeaconInstance
+			for _, uri := range beaconNodeURIs {
+				beaconInstances = append(beaconInstances, beaconclient.NewProdBeaconInstance(log, uri))
+			}
+			beaconClient = beaconclient.NewMultiBeaconClient(log, beaconInstances)
+			beaconClient.CheckNodeVersions()
+
+			syncStatus, err = beaconclient.WaitForSyncStatus(log, beaconClient)
+			if err == nil && syncStatus.IsSyncing {
+				err = fmt.Errorf("%w (head slot %d)", errBeaconNodeSyncing, syncStatus.HeadSlot)
+			}
+			checks.check("beacon node sync status", err)
+
+			if fetchGenesisValidatorsRoot {
+				networkInfo, err = networkDetailsWithBeaconGenesisValidatorsRoot(beaconClient)
+				if checks.check("network details with the beacon node's genesis validators root", err) {
+					log.Infof("Using the genesis validators root of the beacon node: %s", networkInfo.GenesisValidatorsRootHex)
+					setCapellaForkVersion()
+				}
+			}
+
+			if apiAutoForkVersion {
+				networkInfo, err = networkDetailsFromBeacon(log, beaconClient, networkInfo)
+				checks.check("network details from beacon node", err)
+			}
 		}
-		log.Infof("Using beacon endpoints: %s", strings.Join(beaconNodeURIs, ", "))
-		var beaconInstances []beaconclient.IBeaconInstance
-		for _, uri := range beaconNodeURIs {
-			beaconInstances = append(beaconInstances, beaconclient.NewProdBeaconInstance(log, uri))
+
+		networkName := network
+		if networkInfo != nil {
+			networkName = networkInfo.Name
+			log.Infof("Using network: %s", networkInfo.Name)
+			log.Debug(networkInfo.String())
 		}
-		beaconClient := beaconclient.NewMultiBeaconClient(log, beaconInstances)
 
 		// Connect to Redis
 		if redisReadonlyURI == "" {
@@ -112,10 +385,14 @@ var apiCmd = &cobra.Command{
 		} else {
 			log.Infof("Connecting to Redis at %s / readonly: %s ...", redisURI, redisReadonlyURI)
 		}
-		redis, err := datastore.NewRedisCache(networkInfo.Name, redisURI, redisReadonlyURI)
+		redisOpts.Log = log
+		redis, err := datastore.NewRedisCacheWithOptions(networkName, redisURI, redisReadonlyURI, redisOpts)
 		if err != nil {
-			log.WithError(err).Fatalf("Failed to connect to Redis at %s", redisURI)
+			err = fmt.Errorf("failed to connect to Redis at %s: %w", redisURI, err)
 		}
+		checks.check("redis", err)
+
+		checks.exitOnFailure()
 
 		// Connect to Memcached if it exists
 		var mem *datastore.Memcached
@@ -146,35 +423,112 @@ var apiCmd = &cobra.Command{
 
 		opts := api.RelayAPIOpts{
 			Log:           log,
+			Version:       Version,
 			ListenAddr:    apiListenAddr,
 			BeaconClient:  beaconClient,
+			SyncStatus:    syncStatus,
 			Datastore:     ds,
 			Redis:         redis,
 			Memcached:     mem,
 			DB:            db,
 			EthNetDetails: *networkInfo,
 			BlockSimURL:   apiBlockSimURL,
+			TLSCertFile:   apiTLSCert,
+			TLSKeyFile:    apiTLSKey,
+			BuilderCAFile: apiBuilderCA,
+
+			GetPayloadTimeout:     time.Duration(apiGetPayloadTimeoutMs) * time.Millisecond,
+			GetPayloadCutoffMs:    apiGetPayloadCutoffMs,
+			SubmitStartMs:         apiSubmitStartMs,
+			SubmissionBatchWindow: time.Duration(apiSubmitBatchMs) * time.Millisecond,
+			ReadTimeout:           time.Duration(apiReadTimeoutMs) * time.Millisecond,
+			ReadHeaderTimeout:     time.Duration(apiReadHeaderTimeout) * time.Millisecond,
+			WriteTimeout:          time.Duration(apiWriteTimeoutMs) * time.Millisecond,
+			IdleTimeout:           time.Duration(apiIdleTimeoutMs) * time.Millisecond,
+			BidCacheSize:          apiBidCacheSize,
+			DisableCompression:    !apiCompress,
+			CompressMinSize:       apiCompressMinSize,
+
+			BlockBuilderAPI:  apiBuilderAPI,
+			DataAPI:          apiDataAPI,
+			InternalAPI:      apiInternalAPI,
+			ProposerAPI:      apiProposerAPI,
+			PprofAPI:         apiPprofEnabled,
+			PprofToken:       apiPprofToken,
+			PprofListenAddr:  apiPprofListenAddr,
+			AdminToken:       apiAdminToken,
+			PayloadDataToken: apiPayloadDataToken,
+			AdminAllowIPs:    apiAdminAllowIPs,
+			TrustedProxies:   apiTrustedProxies,
+			DryRun:           apiDryRun,
+			DebugHeaders:     apiDebugHeaders,
+			DebugSampleRate:  apiDebugSampleRate,
+			BidStreamEnabled: apiBidStreamEnabled,
+			SigVerifyWorkers: apiSigVerifyWorkers,
+			CORSOrigins:      apiCORSOrigins,
+			MinGasLimit:      apiMinGasLimit,
+			MaxGasLimit:      apiMaxGasLimit,
+			PeerRelayURLs:    apiPeerRelays,
+			BuilderAllowlist: apiBuilderAllowlist,
+			AuditLog:         apiAuditLog,
+			AlertWebhook:     apiAlertWebhook,
+
+			MetricsEnabled:    apiMetricsEnabled,
+			MetricsListenAddr: apiMetricsListenAddr,
 
-			BlockBuilderAPI: apiBuilderAPI,
-			DataAPI:         apiDataAPI,
-			InternalAPI:     apiInternalAPI,
-			ProposerAPI:     apiProposerAPI,
-			PprofAPI:        apiPprofEnabled,
+			RegistrationRateLimit:      apiRegRateLimit,
+			RegistrationRateLimitBurst: apiRegRateLimitBurst,
+			EnforceFeeRecipient:        apiEnforceFeeRecip,
+			Optimistic:                 apiOptimistic,
+			SingleHeaderPerSlot:        apiSingleHeader,
+			ProposerOnlyHeaders:        apiProposerOnly,
+			GetHeaderDeadlineIntoSlot:  time.Duration(apiGetHeaderDeadline) * time.Millisecond,
+			MaxSubmitBytes:             apiMaxSubmitBytes,
+			MaxRegistrationBytes:       apiMaxRegBytes,
+			MaxRegistrationsPerRequest: apiMaxRegsPerRequest,
+			RegistrationSigCacheTTL:    time.Duration(apiRegSigCacheMs) * time.Millisecond,
+			GetPayloadConcurrency:      apiGetPayloadConc,
+			RejectSlashedValidators:    apiRejectSlashed,
+			RetentionSlots:             apiRetentionSlots,
+			RetentionArchiveDir:        apiRetentionDir,
+			BidHistorySize:             apiBidHistorySize,
+			BidHistoryRetentionSlots:   apiBidHistorySlots,
 		}
 
-		// Decode the private key
-		if apiSecretKey == "" {
-			log.Warn("No secret key specified, block builder API is disabled")
-			opts.BlockBuilderAPI = false
-		} else {
-			envSkBytes, err := hexutil.Decode(apiSecretKey)
-			if err != nil {
-				log.WithError(err).Fatal("incorrect secret key provided")
+		if errDebugSampleRate != nil && !cmd.Flags().Changed("debug-sample-rate") {
+			log.WithError(errDebugSampleRate).Fatalf("invalid DEBUG_SAMPLE_RATE: %s", os.Getenv("DEBUG_SAMPLE_RATE"))
+		}
+		if apiDebugSampleRate < 0 || apiDebugSampleRate > 1 {
+			log.Fatalf("invalid --debug-sample-rate: %f (needs to be between 0 and 1)", apiDebugSampleRate)
+		}
+
+		unixSocketMode, err := strconv.ParseUint(apiUnixSocketMode, 8, 32)
+		if err != nil {
+			log.WithError(err).Fatalf("invalid --unix-socket-mode: %s", apiUnixSocketMode)
+		}
+		opts.UnixSocketMode = os.FileMode(unixSocketMode)
+
+		// Parse the minimum bid value
+		if apiMinBidWei != "" {
+			minBidValue, ok := new(big.Int).SetString(apiMinBidWei, 10)
+			if !ok || minBidValue.Sign() < 0 {
+				log.Fatalf("invalid --min-bid-wei: %s", apiMinBidWei)
 			}
-			opts.SecretKey, err = bls.SecretKeyFromBytes(envSkBytes[:])
-			if err != nil {
-				log.WithError(err).Fatal("incorrect builder API secret key provided")
+			log.Infof("Using minimum bid value: %s wei", minBidValue.String())
+			opts.MinBidValue = minBidValue
+		}
+
+		opts.SecretKey = secretKey
+		if secretKey == nil {
+			opts.BlockBuilderAPI = false
+		}
+
+		for _, pubkeyHex := range apiPreviousPubkeys {
+			var pubkey boostTypes.PublicKey
+			if err := pubkey.UnmarshalText([]byte(pubkeyHex)); err != nil {
+				log.WithError(err).Fatalf("invalid --previous-pubkeys entry: %s", pubkeyHex)
 			}
+			opts.PreviousPublicKeys = append(opts.PreviousPublicKeys, pubkey)
 		}
 
 		// Create the relay service
@@ -184,7 +538,9 @@ var apiCmd = &cobra.Command{
 			log.WithError(err).Fatal("failed to create service")
 		}
 
-		// Create a signal handler
+		// Create a signal handler. StartServer returns as soon as the shutdown begins, so the main goroutine waits
+		// for stopped to be closed to let in-flight requests be drained.
+		stopped := make(chan struct{})
 		sigs := make(chan os.Signal, 1)
 		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 		go func() {
@@ -192,16 +548,34 @@ var apiCmd = &cobra.Command{
 			log.Infof("signal received: %s", sig)
 			err := srv.StopServer()
 			if err != nil {
-				log.WithError(err).Fatal("error stopping server")
+				log.WithError(err).Error("error stopping server")
 			}
+			close(stopped)
 		}()
 
+		// Reload the TLS certificate and the builder allowlist on SIGHUP, to change them without a restart
+		if apiTLSCert != "" || apiBuilderAllowlist != "" {
+			hups := make(chan os.Signal, 1)
+			signal.Notify(hups, syscall.SIGHUP)
+			go func() {
+				for range hups {
+					if err := srv.ReloadTLSCertificate(); err != nil {
+						log.WithError(err).Error("failed to reload TLS certificate, keeping the previous one")
+					}
+					if err := srv.ReloadBuilderAllowlist(); err != nil {
+						log.WithError(err).Error("failed to reload builder allowlist, keeping the previous one")
+					}
+				}
+			}()
+		}
+
 		// Start the server
 		log.Infof("Webserver starting on %s ...", apiListenAddr)
 		err = srv.StartServer()
 		if err != nil {
 			log.WithError(err).Fatal("server error")
 		}
+		<-stopped
 		log.Info("bye")
 	},
 }
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/services/bench"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchDefaultRelayURI   = common.GetEnv("BENCH_RELAY_URI", "http://localhost:9062")
+	benchDefaultProposerSk = os.Getenv("BENCH_PROPOSER_SECRET_KEY")
+
+	benchProposerSk string
+	benchOpts       bench.Opts
+)
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().BoolVar(&logJSON, "json", defaultLogJSON, "log in JSON format instead of text")
+	benchCmd.Flags().StringVar(&logLevel, "loglevel", defaultLogLevel, "log-level: trace, debug, info, warn/warning, error, fatal, panic")
+
+	benchCmd.Flags().StringVar(&benchOpts.RelayURL, "relay-uri", benchDefaultRelayURI, "relay URL")
+	addNetworkFlags(benchCmd)
+	benchCmd.Flags().StringVar(&benchProposerSk, "proposer-secret-key", benchDefaultProposerSk, "secret key (hex) of the proposer of the requests")
+	benchCmd.Flags().Uint64Var(&benchOpts.ProposerIndex, "proposer-index", 0, "validator index of the proposer, for getPayload")
+	benchCmd.Flags().Uint64Var(&benchOpts.Slot, "slot", 0, "slot of the requests")
+	benchCmd.Flags().StringVar(&benchOpts.ParentHash, "parent-hash", "0x0000000000000000000000000000000000000000000000000000000000000000", "parent hash of the getHeader requests")
+	benchCmd.Flags().IntVar(&benchOpts.Concurrency, "concurrency", 10, "number of concurrent requests")
+	benchCmd.Flags().IntVar(&benchOpts.Requests, "requests", 1000, "number of getHeader requests")
+	benchCmd.Flags().BoolVar(&benchOpts.GetPayload, "get-payload", false, "send a getPayload request for each served bid, all with one blinded block signed for the first bid (test networks only)")
+	benchCmd.Flags().BoolVar(&benchOpts.Force, "force", false, "send getPayload requests on a public network, although the signed proposal can get the proposer slashed")
+	benchCmd.Flags().DurationVar(&benchOpts.Timeout, "timeout", 5*time.Second, "timeout of a single request")
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure getHeader and getPayload latency of a running relay under concurrent load",
+	Run: func(cmd *cobra.Command, args []string) {
+		log := common.LogSetup(logJSON, logLevel).WithFields(logrus.Fields{
+			"service": "relay/bench",
+			"version": Version,
+		})
+
+		networkInfo, err := getNetworkDetails()
+		if err != nil {
+			log.WithError(err).Fatalf("error getting network details")
+		}
+		log.Infof("Using network: %s", networkInfo.Name)
+
+		if benchProposerSk == "" {
+			log.Fatal("--proposer-secret-key is required")
+		}
+		benchOpts.ProposerSk, err = common.SecretKeyFromHex(benchProposerSk)
+		if err != nil {
+			log.WithError(err).Fatal("incorrect proposer secret key provided")
+		}
+		if benchOpts.Slot == 0 {
+			log.Fatal("--slot is required")
+		}
+
+		benchOpts.Log = log
+		benchOpts.EthNetDetails = *networkInfo
+
+		log.Infof("sending %d getHeader requests with %d concurrent requests", benchOpts.Requests, benchOpts.Concurrency)
+		results, err := bench.Run(context.Background(), &benchOpts)
+		if err != nil {
+			log.WithError(err).Fatal("could not run the benchmark")
+		}
+
+		for _, stats := range results {
+			log.WithFields(logrus.Fields{
+				"endpoint":          stats.Name,
+				"requests":          stats.Requests,
+				"errors":            stats.Errors,
+				"errorRate":         stats.ErrorRate(),
+				"statusCodes":       stats.StatusCodes,
+				"requestsPerSecond": stats.RequestsPerSecond(),
+				"p50":               stats.P50.String(),
+				"p90":               stats.P90.String(),
+				"p99":               stats.P99.String(),
+				"max":               stats.Max.String(),
+			}).Info("benchmark results")
+		}
+	},
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/flashbots/mev-boost-relay/services/api"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dataAPIDefaultListenAddr   = common.GetEnv("LISTEN_ADDR", "localhost:9066")
+	dataAPIDefaultCORSOrigins  = common.GetSliceEnv("CORS_ORIGINS", nil)
+	dataAPIDefaultPayloadToken = os.Getenv("PAYLOAD_DATA_TOKEN")
+
+	dataAPIListenAddr   string
+	dataAPICORSOrigins  []string
+	dataAPIPayloadToken string
+)
+
+func init() {
+	rootCmd.AddCommand(dataAPICmd)
+	dataAPICmd.Flags().BoolVar(&logJSON, "json", defaultLogJSON, "log in JSON format instead of text")
+	dataAPICmd.Flags().StringVar(&logLevel, "loglevel", defaultLogLevel, "log-level: trace, debug, info, warn/warning, error, fatal, panic")
+
+	dataAPICmd.Flags().StringVar(&dataAPIListenAddr, "listen-addr", dataAPIDefaultListenAddr, "listen address for webserver")
+	dataAPICmd.Flags().StringSliceVar(&dataAPICORSOrigins, "cors-origins", dataAPIDefaultCORSOrigins, "origins allowed to query the data API from the browser (\"*\" for any, default: CORS disabled)")
+	dataAPICmd.Flags().StringVar(&dataAPIPayloadToken, "payload-data-token", dataAPIDefaultPayloadToken, "serve delivered execution payloads at /relay/v1/data/payload to requests with this bearer token (prefer the PAYLOAD_DATA_TOKEN env var)")
+	dataAPICmd.Flags().StringSliceVar(&beaconNodeURIs, "beacon-uris", defaultBeaconURIs, "beacon endpoints")
+	dataAPICmd.Flags().StringVar(&redisURI, "redis-uri", defaultRedisURI, "redis uri")
+	dataAPICmd.Flags().StringVar(&redisReadonlyURI, "redis-readonly-uri", defaultRedisReadonlyURI, "redis readonly uri")
+	addRedisOptionsFlags(dataAPICmd)
+	dataAPICmd.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+	addNetworkFlags(dataAPICmd)
+}
+
+var dataAPICmd = &cobra.Command{
+	Use:   "data-api",
+	Short: "Start a read-only server with only the data API (/relay/v1/data/...)",
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+
+		log := common.LogSetup(logJSON, logLevel).WithFields(logrus.Fields{
+			"service": "relay/data-api",
+			"version": Version,
+		})
+		log.Infof("boost-relay %s", Version)
+
+		// Connect to beacon clients (needed to track the head slot)
+		if len(beaconNodeURIs) == 0 {
+			log.Fatalf("no beacon endpoints specified")
+		}
+		log.Infof("Using beacon endpoints: %s", strings.Join(beaconNodeURIs, ", "))
+		var beaconInstances []beaconclient.IBeaconInstance
+		for _, uri := range beaconNodeURIs {
+			beaconInstances = append(beaconInstances, beaconclient.NewProdBeaconInstance(log, uri))
+		}
+		beaconClient := beaconclient.NewMultiBeaconClient(log, beaconInstances)
+
+		networkInfo, err := getNetworkDetailsWithBeacon(log, beaconClient)
+		if err != nil {
+			log.WithError(err).Fatalf("error getting network details")
+		}
+		log.Infof("Using network: %s", networkInfo.Name)
+		log.Debug(networkInfo.String())
+
+		// Connect to Redis
+		log.Infof("Connecting to Redis at %s ...", redisURI)
+		redisOpts.Log = log
+		redis, err := datastore.NewRedisCacheWithOptions(networkInfo.Name, redisURI, redisReadonlyURI, redisOpts)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to connect to Redis at %s", redisURI)
+		}
+
+		// Connect to Postgres
+		dbURL, err := url.Parse(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("couldn't read db URL")
+		}
+		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
+		db, err := database.NewDatabaseService(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
+		}
+
+		ds, err := datastore.NewDatastore(log, redis, nil, db)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed setting up prod datastore")
+		}
+
+		opts := api.RelayAPIOpts{
+			Log:           log,
+			ListenAddr:    dataAPIListenAddr,
+			BeaconClient:  beaconClient,
+			Datastore:     ds,
+			Redis:         redis,
+			DB:            db,
+			EthNetDetails: *networkInfo,
+			DataAPI:       true,
+			CORSOrigins:   dataAPICORSOrigins,
+
+			PayloadDataToken: dataAPIPayloadToken,
+		}
+
+		srv, err := api.NewRelayAPI(opts)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create service")
+		}
+
+		stopped := make(chan struct{})
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			sig := <-sigs
+			log.Infof("signal received: %s", sig)
+			err := srv.StopServer()
+			if err != nil {
+				log.WithError(err).Error("error stopping server")
+			}
+			close(stopped)
+		}()
+
+		log.Infof("Webserver starting on %s ...", dataAPIListenAddr)
+		err = srv.StartServer()
+		if err != nil {
+			log.WithError(err).Fatal("server error")
+		}
+		<-stopped
+		log.Info("bye")
+	},
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var datastoreCheckFix bool
+
+func init() {
+	rootCmd.AddCommand(datastoreCheckCmd)
+	datastoreCheckCmd.Flags().BoolVar(&logJSON, "json", defaultLogJSON, "log in JSON format instead of text")
+	datastoreCheckCmd.Flags().StringVar(&logLevel, "loglevel", defaultLogLevel, "log-level: trace, debug, info, warn/warning, error, fatal, panic")
+
+	datastoreCheckCmd.Flags().StringSliceVar(&beaconNodeURIs, "beacon-uris", defaultBeaconURIs, "beacon endpoints (to only check the slots before the head slot)")
+	datastoreCheckCmd.Flags().StringVar(&redisURI, "redis-uri", defaultRedisURI, "redis uri")
+	addRedisOptionsFlags(datastoreCheckCmd)
+	addNetworkFlags(datastoreCheckCmd)
+	datastoreCheckCmd.Flags().BoolVar(&datastoreCheckFix, "fix", false, "delete the orphaned and malformed keys")
+}
+
+var datastoreCheckCmd = &cobra.Command{
+	Use:   "datastore-check",
+	Short: "Check the Redis datastore for orphaned and malformed keys, and optionally delete them",
+	Run: func(cmd *cobra.Command, args []string) {
+		log := common.LogSetup(logJSON, logLevel).WithFields(logrus.Fields{
+			"service": "relay/datastore-check",
+			"version": Version,
+		})
+
+		networkInfo, err := getNetworkDetails()
+		if err != nil {
+			log.WithError(err).Fatalf("error getting network details")
+		}
+		log.Infof("Using network: %s", networkInfo.Name)
+
+		// Bids of the current slots are written while the keys are scanned, so only the slots before the head are checked
+		if len(beaconNodeURIs) == 0 {
+			log.Fatalf("no beacon endpoints specified")
+		}
+		var beaconInstances []beaconclient.IBeaconInstance
+		for _, uri := range beaconNodeURIs {
+			beaconInstances = append(beaconInstances, beaconclient.NewProdBeaconInstance(log, uri))
+		}
+		syncStatus, err := beaconclient.NewMultiBeaconClient(log, beaconInstances).BestSyncStatus()
+		if err != nil {
+			log.WithError(err).Fatal("failed to get the head slot from the beacon node")
+		}
+		log.Infof("Checking the slots before the head slot %d", syncStatus.HeadSlot)
+
+		log.Infof("Connecting to Redis at %s ...", redisURI)
+		redisOpts.Log = log
+		redis, err := datastore.NewRedisCacheWithOptions(networkInfo.Name, redisURI, "", redisOpts)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to connect to Redis at %s", redisURI)
+		}
+
+		report, err := redis.CheckIntegrity(context.Background(), syncStatus.HeadSlot, datastoreCheckFix)
+		if err != nil {
+			log.WithError(err).Fatal("datastore check failed")
+		}
+
+		for _, problem := range report.Orphaned {
+			log.WithField("key", problem.Key).Warnf("orphaned key: %s", problem.Reason)
+		}
+		for _, problem := range report.Malformed {
+			log.WithField("key", problem.Key).Warnf("malformed key: %s", problem.Reason)
+		}
+
+		log.WithFields(logrus.Fields{
+			"numKeysScanned": report.NumKeysScanned,
+			"numOrphaned":    len(report.Orphaned),
+			"numMalformed":   len(report.Malformed),
+			"numDeleted":     report.NumDeleted,
+		}).Info("datastore check done")
+		if !datastoreCheckFix && len(report.Orphaned)+len(report.Malformed) > 0 {
+			log.Info("run with --fix to delete the orphaned and malformed keys")
+		}
+	},
+}
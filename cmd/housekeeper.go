@@ -29,9 +29,10 @@ func init() {
 
 	housekeeperCmd.Flags().StringSliceVar(&beaconNodeURIs, "beacon-uris", defaultBeaconURIs, "beacon endpoints")
 	housekeeperCmd.Flags().StringVar(&redisURI, "redis-uri", defaultRedisURI, "redis uri")
+	addRedisConnectionFlags(housekeeperCmd)
 	housekeeperCmd.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
 
-	housekeeperCmd.Flags().StringVar(&network, "network", defaultNetwork, "Which network to use")
+	addNetworkFlags(housekeeperCmd)
 
 	housekeeperCmd.Flags().BoolVar(&hkPprofEnabled, "pprof", hkDefaultPprofEnabled, "enable pprof API")
 	housekeeperCmd.Flags().StringVar(&hkPprofListenAddr, "pprof-listen-addr", hkDefaultPprofListenAddr, "listen address for pprof server")
@@ -49,13 +50,6 @@ var housekeeperCmd = &cobra.Command{
 		})
 		log.Infof("boost-relay %s", Version)
 
-		networkInfo, err := common.NewEthNetworkDetails(network)
-		if err != nil {
-			log.WithError(err).Fatalf("error getting network details")
-		}
-		log.Infof("Using network: %s", networkInfo.Name)
-		log.Debug(networkInfo.String())
-
 		// Connect to beacon clients and ensure it's synced
 		if len(beaconNodeURIs) == 0 {
 			log.Fatalf("no beacon endpoints specified")
@@ -67,8 +61,16 @@ var housekeeperCmd = &cobra.Command{
 		}
 		beaconClient := beaconclient.NewMultiBeaconClient(log, beaconInstances)
 
+		networkInfo, err := getNetworkDetailsWithBeacon(log, beaconClient)
+		if err != nil {
+			log.WithError(err).Fatalf("error getting network details")
+		}
+		log.Infof("Using network: %s", networkInfo.Name)
+		log.Debug(networkInfo.String())
+		beaconClient.CheckNodeVersions()
+
 		// Connect to Redis and setup the datastore
-		redis, err := datastore.NewRedisCache(networkInfo.Name, redisURI, "")
+		redis, err := datastore.NewRedisCacheWithOptions(networkInfo.Name, redisURI, "", redisConnectionOpts())
 		if err != nil {
 			log.WithError(err).Fatalf("Failed to connect to Redis at %s", redisURI)
 		}
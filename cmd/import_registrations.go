@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var importRegistrationsFile string
+
+func init() {
+	rootCmd.AddCommand(importRegistrationsCmd)
+	importRegistrationsCmd.Flags().BoolVar(&logJSON, "json", defaultLogJSON, "log in JSON format instead of text")
+	importRegistrationsCmd.Flags().StringVar(&logLevel, "loglevel", defaultLogLevel, "log-level: trace, debug, info, warn/warning, error, fatal, panic")
+
+	importRegistrationsCmd.Flags().StringVar(&importRegistrationsFile, "file", "", "JSON file with a list of signed validator registrations")
+	importRegistrationsCmd.Flags().StringVar(&redisURI, "redis-uri", defaultRedisURI, "redis uri")
+	addRedisConnectionFlags(importRegistrationsCmd)
+	importRegistrationsCmd.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+	addNetworkFlags(importRegistrationsCmd)
+	_ = importRegistrationsCmd.MarkFlagRequired("file")
+}
+
+var importRegistrationsCmd = &cobra.Command{
+	Use:   "import-registrations",
+	Short: "Import signed validator registrations from a JSON file (i.e. when migrating from another relay)",
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+
+		log := common.LogSetup(logJSON, logLevel).WithFields(logrus.Fields{
+			"service": "relay/import-registrations",
+			"version": Version,
+		})
+
+		networkInfo, err := getNetworkDetails()
+		if err != nil {
+			log.WithError(err).Fatalf("error getting network details")
+		}
+		log.Infof("Using network: %s", networkInfo.Name)
+
+		// Load the registrations
+		content, err := os.ReadFile(importRegistrationsFile)
+		if err != nil {
+			log.WithError(err).Fatalf("failed reading %s", importRegistrationsFile)
+		}
+		registrations := []boostTypes.SignedValidatorRegistration{}
+		err = json.Unmarshal(content, &registrations)
+		if err != nil {
+			log.WithError(err).Fatalf("failed decoding %s", importRegistrationsFile)
+		}
+		log.Infof("Loaded %d registrations from %s", len(registrations), importRegistrationsFile)
+
+		// Connect to Redis
+		log.Infof("Connecting to Redis at %s ...", redisURI)
+		redis, err := datastore.NewRedisCacheWithOptions(networkInfo.Name, redisURI, "", redisConnectionOpts())
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to connect to Redis at %s", redisURI)
+		}
+
+		// Connect to Postgres
+		dbURL, err := url.Parse(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("couldn't read db URL")
+		}
+		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
+		db, err := database.NewDatabaseService(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
+		}
+
+		ds, err := datastore.NewDatastore(log, redis, nil, db)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed setting up prod datastore")
+		}
+
+		numAccepted, numRejected, numSkipped := importRegistrations(log, registrations, networkInfo.DomainBuilder, redis, ds)
+
+		log.WithFields(logrus.Fields{
+			"numRegistrations": len(registrations),
+			"numAccepted":      numAccepted,
+			"numRejected":      numRejected,
+			"numSkipped":       numSkipped,
+		}).Info("import done")
+	},
+}
+
+// registrationStore has the timestamps of the saved registrations (Redis)
+type registrationStore interface {
+	GetValidatorRegistrationTimestamp(proposerPubkey boostTypes.PubkeyHex) (uint64, error)
+}
+
+// registrationSaver saves the imported registrations (the datastore)
+type registrationSaver interface {
+	SaveValidatorRegistration(entry boostTypes.SignedValidatorRegistration) error
+}
+
+// importRegistrations validates and saves the registrations. Registrations which are not newer than the stored one are
+// skipped, so importing the same file again doesn't change anything.
+func importRegistrations(log *logrus.Entry, registrations []boostTypes.SignedValidatorRegistration, domain boostTypes.Domain, store registrationStore, saver registrationSaver) (numAccepted, numRejected, numSkipped int) {
+	for i, reg := range registrations {
+		if reg.Message == nil {
+			log.WithField("index", i).Warn("registration without a message")
+			numRejected++
+			continue
+		}
+		regLog := log.WithField("pubkey", reg.Message.Pubkey.String())
+
+		ok, err := boostTypes.VerifySignature(reg.Message, domain, reg.Message.Pubkey[:], reg.Signature[:])
+		if err != nil || !ok {
+			regLog.WithError(err).Warn("invalid registration signature")
+			numRejected++
+			continue
+		}
+
+		prevTimestamp, err := store.GetValidatorRegistrationTimestamp(reg.Message.Pubkey.PubkeyHex())
+		if err != nil {
+			regLog.WithError(err).Error("error getting last registration timestamp")
+		} else if prevTimestamp >= reg.Message.Timestamp {
+			numSkipped++
+			continue
+		}
+
+		err = saver.SaveValidatorRegistration(reg)
+		if err != nil {
+			regLog.WithError(err).Error("failed saving registration")
+			numRejected++
+			continue
+		}
+		numAccepted++
+	}
+	return numAccepted, numRejected, numSkipped
+}
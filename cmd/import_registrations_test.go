@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type testRegistrationStore struct {
+	timestamps map[boostTypes.PubkeyHex]uint64
+}
+
+func (s *testRegistrationStore) GetValidatorRegistrationTimestamp(pubkey boostTypes.PubkeyHex) (uint64, error) {
+	return s.timestamps[pubkey], nil
+}
+
+func (s *testRegistrationStore) SaveValidatorRegistration(entry boostTypes.SignedValidatorRegistration) error {
+	s.timestamps[entry.Message.Pubkey.PubkeyHex()] = entry.Message.Timestamp
+	return nil
+}
+
+func TestImportRegistrations(t *testing.T) {
+	networkInfo, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
+	require.NoError(t, err)
+
+	sk, blsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkey, err := boostTypes.BlsPublicKeyToPublicKey(blsPubkey)
+	require.NoError(t, err)
+	msg := &boostTypes.RegisterValidatorRequestMessage{GasLimit: 30_000_000, Timestamp: 100, Pubkey: pubkey} //nolint:exhaustruct
+	sig, err := boostTypes.SignMessage(msg, networkInfo.DomainBuilder, sk)
+	require.NoError(t, err)
+	registration, err := json.Marshal(boostTypes.SignedValidatorRegistration{Message: msg, Signature: sig})
+	require.NoError(t, err)
+
+	// A valid registration, one with an invalid signature and one without a message
+	registrations := []boostTypes.SignedValidatorRegistration{}
+	invalidSig := `{"message":{"fee_recipient":"0x0000000000000000000000000000000000000000","gas_limit":"1","timestamp":"1","pubkey":"` + pubkey.String() + `"},"signature":"` + boostTypes.Signature{}.String() + `"}`
+	require.NoError(t, json.Unmarshal([]byte(`[`+string(registration)+`,`+invalidSig+`,{"signature":"`+sig.String()+`"}]`), &registrations))
+
+	store := &testRegistrationStore{timestamps: make(map[boostTypes.PubkeyHex]uint64)}
+	log := logrus.NewEntry(logrus.New())
+	numAccepted, numRejected, numSkipped := importRegistrations(log, registrations, networkInfo.DomainBuilder, store, store)
+	require.Equal(t, 1, numAccepted)
+	require.Equal(t, 2, numRejected)
+	require.Equal(t, 0, numSkipped)
+
+	// Importing again skips the saved registration
+	numAccepted, _, numSkipped = importRegistrations(log, registrations, networkInfo.DomainBuilder, store, store)
+	require.Equal(t, 0, numAccepted)
+	require.Equal(t, 1, numSkipped)
+}
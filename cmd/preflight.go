@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	errNoBeaconURIs      = errors.New("no beacon endpoints specified")
+	errBeaconNodeSyncing = errors.New("beacon node is syncing")
+)
+
+type preflightCheck struct {
+	name string
+	err  error
+}
+
+// preflight collects the results of the startup checks, so that an operator sees all problems of the setup at once
+// instead of fixing them one Fatal at a time. With strict set, the first failed check exits right away.
+type preflight struct {
+	log    *logrus.Entry
+	strict bool
+	checks []preflightCheck
+}
+
+// check records the result of a check and returns whether it passed
+func (p *preflight) check(name string, err error) bool {
+	if err != nil && p.strict {
+		p.log.WithError(err).Fatalf("preflight check failed: %s", name)
+	}
+	p.checks = append(p.checks, preflightCheck{name: name, err: err})
+	return err == nil
+}
+
+// exitOnFailure logs a summary of all checks and exits if any of them failed
+func (p *preflight) exitOnFailure() {
+	numFailed := 0
+	for _, check := range p.checks {
+		if check.err != nil {
+			numFailed++
+			p.log.WithError(check.err).Errorf("preflight FAIL: %s", check.name)
+		} else {
+			p.log.Infof("preflight OK: %s", check.name)
+		}
+	}
+	if numFailed > 0 {
+		p.log.Fatalf("%d of %d preflight checks failed, see above", numFailed, len(p.checks))
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"math/big"
+	"os"
+
+	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/services/selftest"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	selftestDefaultRelayURI     = common.GetEnv("SELFTEST_RELAY_URI", "http://localhost:9062")
+	selftestDefaultValidatorSk  = os.Getenv("SELFTEST_VALIDATOR_SECRET_KEY")
+	selftestDefaultBuilderSk    = os.Getenv("SELFTEST_BUILDER_SECRET_KEY")
+	selftestDefaultFeeRecipient = common.GetEnv("SELFTEST_FEE_RECIPIENT", "0x0000000000000000000000000000000000000001")
+
+	selftestRelayURI     string
+	selftestBeaconURI    string
+	selftestValidatorSk  string
+	selftestBuilderSk    string
+	selftestFeeRecipient string
+	selftestGasLimit     uint64
+	selftestBidValueWei  string
+	selftestOpts         selftest.Opts
+)
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().BoolVar(&logJSON, "json", defaultLogJSON, "log in JSON format instead of text")
+	selftestCmd.Flags().StringVar(&logLevel, "loglevel", defaultLogLevel, "log-level: trace, debug, info, warn/warning, error, fatal, panic")
+
+	selftestCmd.Flags().StringVar(&selftestRelayURI, "relay-uri", selftestDefaultRelayURI, "relay URL, with the relay pubkey as user to verify the bid signature (https://0xPUBKEY@host)")
+	selftestCmd.Flags().StringVar(&selftestBeaconURI, "beacon-uri", defaultBeaconURIs[0], "beacon endpoint of the relay's network, for the slot, parent block, prev_randao and withdrawals")
+	addNetworkFlags(selftestCmd)
+	selftestCmd.Flags().StringVar(&selftestValidatorSk, "validator-secret-key", selftestDefaultValidatorSk, "secret key (hex) of an active validator of the network, which registers and proposes")
+	selftestCmd.Flags().StringVar(&selftestBuilderSk, "builder-secret-key", selftestDefaultBuilderSk, "secret key (hex) of the builder submitting the block (default: a random key)")
+	selftestCmd.Flags().StringVar(&selftestFeeRecipient, "fee-recipient", selftestDefaultFeeRecipient, "fee recipient of the validator registration")
+	selftestCmd.Flags().Uint64Var(&selftestGasLimit, "gas-limit", 30_000_000, "gas limit of the validator registration")
+	selftestCmd.Flags().StringVar(&selftestBidValueWei, "bid-value-wei", "1", "value of the submitted bid in wei")
+	selftestCmd.Flags().DurationVar(&selftestOpts.DutyTimeout, "duty-timeout", 3*common.DurationPerEpoch, "maximum time to wait for a proposer duty of the validator")
+	selftestCmd.Flags().BoolVar(&selftestOpts.Force, "force", false, "run on a public network, although the signed proposal can get the validator slashed")
+}
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Run registerValidator, submitBlock, getHeader and getPayload against a running relay on a test network",
+	Run: func(cmd *cobra.Command, args []string) {
+		log := common.LogSetup(logJSON, logLevel).WithFields(logrus.Fields{
+			"service": "relay/selftest",
+			"version": Version,
+		})
+
+		beacon := beaconclient.NewProdBeaconInstance(log, selftestBeaconURI)
+		networkInfo, err := getNetworkDetailsWithBeacon(log, beacon)
+		if err != nil {
+			log.WithError(err).Fatalf("error getting network details")
+		}
+		log.Infof("Using network: %s", networkInfo.Name)
+
+		if selftestValidatorSk == "" {
+			log.Fatal("--validator-secret-key is required")
+		}
+		selftestOpts.ValidatorSk, err = common.SecretKeyFromHex(selftestValidatorSk)
+		if err != nil {
+			log.WithError(err).Fatal("incorrect validator secret key provided")
+		}
+		if selftestBuilderSk != "" {
+			selftestOpts.BuilderSk, err = common.SecretKeyFromHex(selftestBuilderSk)
+		} else {
+			selftestOpts.BuilderSk, err = bls.GenerateRandomSecretKey()
+		}
+		if err != nil {
+			log.WithError(err).Fatal("incorrect builder secret key provided")
+		}
+		selftestOpts.FeeRecipient, err = boostTypes.HexToAddress(selftestFeeRecipient)
+		if err != nil {
+			log.WithError(err).Fatalf("invalid --fee-recipient: %s", selftestFeeRecipient)
+		}
+		bidValue, ok := new(big.Int).SetString(selftestBidValueWei, 10)
+		if !ok || bidValue.Sign() <= 0 {
+			log.Fatalf("invalid --bid-value-wei: %s", selftestBidValueWei)
+		}
+
+		selftestOpts.Log = log
+		selftestOpts.RelayURL = selftestRelayURI
+		selftestOpts.Beacon = beacon
+		selftestOpts.EthNetDetails = *networkInfo
+		selftestOpts.GasLimit = selftestGasLimit
+		selftestOpts.BidValue = bidValue
+
+		results, err := selftest.Run(context.Background(), &selftestOpts)
+		if err != nil {
+			log.WithError(err).Fatal("could not start the self-test")
+		}
+
+		failed := false
+		for _, result := range results {
+			stepLog := log.WithFields(logrus.Fields{
+				"step":       result.Name,
+				"durationMs": result.Duration.Milliseconds(),
+			})
+			if result.Err != nil {
+				failed = true
+				stepLog.WithError(result.Err).Error("FAIL")
+			} else {
+				stepLog.Info("PASS")
+			}
+		}
+		if failed {
+			log.Fatal("self-test failed")
+		}
+		log.Info("self-test passed")
+	},
+}
@@ -0,0 +1,98 @@
+package tool
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	AuditLogExport.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+	AuditLogExport.Flags().Uint64Var(&slotFrom, "slot-from", 0, "start slot (inclusive)")
+	AuditLogExport.Flags().Uint64Var(&slotTo, "slot-to", 0, "end slot (inclusive)")
+	AuditLogExport.Flags().StringSliceVar(&outFiles, "out", []string{}, "output filename (.csv or .json)")
+	_ = AuditLogExport.MarkFlagRequired("slot-to")
+	_ = AuditLogExport.MarkFlagRequired("out")
+}
+
+var auditLogCSVHeader = []string{"slot", "action", "proposer_pubkey", "builder_pubkey", "value", "block_hash", "event_at"}
+
+func auditLogCSVRecord(entry *database.AuditLogEntry) []string {
+	return []string{
+		fmt.Sprint(entry.Slot),
+		entry.Action,
+		entry.ProposerPubkey,
+		entry.BuilderPubkey,
+		entry.Value,
+		entry.BlockHash,
+		entry.EventAt.Format(time.RFC3339Nano),
+	}
+}
+
+var AuditLogExport = &cobra.Command{
+	Use:   "audit-log-export",
+	Short: "export the audit log of served bids and delivered payloads for a slot range",
+	Run: func(cmd *cobra.Command, args []string) {
+		if slotTo < slotFrom {
+			log.Fatal("--slot-to must not be before --slot-from")
+		}
+
+		// Connect to Postgres
+		dbURL, err := url.Parse(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("couldn't read db URL")
+		}
+		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
+		db, err := database.NewDatabaseService(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
+		}
+
+		entries, err := db.GetAuditLogEntries(slotFrom, slotTo)
+		if err != nil {
+			log.WithError(err).Fatal("error getting audit log")
+		}
+		log.Infof("got %d audit log entries for slots %d to %d", len(entries), slotFrom, slotTo)
+
+		writeToFile := func(outFile string) {
+			f, err := os.Create(outFile)
+			if err != nil {
+				log.WithError(err).Fatal("failed to open file")
+			}
+			defer f.Close()
+
+			if strings.HasSuffix(outFile, ".csv") {
+				// write CSV
+				w := csv.NewWriter(f)
+				defer w.Flush()
+				if err := w.Write(auditLogCSVHeader); err != nil {
+					log.WithError(err).Fatal("error writing record to file")
+				}
+				for _, entry := range entries {
+					if err := w.Write(auditLogCSVRecord(entry)); err != nil {
+						log.WithError(err).Fatal("error writing record to file")
+					}
+				}
+			} else {
+				// write JSON
+				encoder := json.NewEncoder(f)
+				err = encoder.Encode(entries)
+				if err != nil {
+					log.WithError(err).Fatal("failed to write json to file")
+				}
+			}
+			log.Infof("Wrote %d entries to %s", len(entries), outFile)
+		}
+
+		for _, outFile := range outFiles {
+			writeToFile(outFile)
+		}
+	},
+}
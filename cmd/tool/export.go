@@ -0,0 +1,158 @@
+package tool
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	goparquet "github.com/fraugster/parquet-go"
+	"github.com/fraugster/parquet-go/floor"
+	"github.com/fraugster/parquet-go/parquet"
+	"github.com/fraugster/parquet-go/parquetschema"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Export.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
+	Export.Flags().Uint64Var(&slotFrom, "from-slot", 0, "start slot (inclusive)")
+	Export.Flags().Uint64Var(&slotTo, "to-slot", 0, "end slot (inclusive)")
+	Export.Flags().StringSliceVar(&outFiles, "out", []string{}, "output filename (.csv, .json or .parquet)")
+	_ = Export.MarkFlagRequired("to-slot")
+	_ = Export.MarkFlagRequired("out")
+}
+
+var deliveredPayloadCSVHeader = []string{"slot", "proposer_pubkey", "builder_pubkey", "value", "block_hash", "gas_used"}
+
+func deliveredPayloadCSVRecord(entry *database.DeliveredPayloadEntry) []string {
+	return []string{
+		fmt.Sprint(entry.Slot),
+		entry.ProposerPubkey,
+		entry.BuilderPubkey,
+		entry.Value,
+		entry.BlockHash,
+		fmt.Sprint(entry.GasUsed),
+	}
+}
+
+// deliveredPayloadParquetSchema has the columns of the CSV export. The value is in wei, as a decimal string, since it
+// doesn't fit into an int64.
+const deliveredPayloadParquetSchema = `message delivered_payload {
+	required int64 slot (INT(64, false));
+	required binary proposer_pubkey (STRING);
+	required binary builder_pubkey (STRING);
+	required binary value (STRING);
+	required binary block_hash (STRING);
+	required int64 gas_used (INT(64, false));
+}`
+
+type deliveredPayloadParquetRecord struct {
+	Slot           uint64 `parquet:"slot"`
+	ProposerPubkey string `parquet:"proposer_pubkey"`
+	BuilderPubkey  string `parquet:"builder_pubkey"`
+	Value          string `parquet:"value"`
+	BlockHash      string `parquet:"block_hash"`
+	GasUsed        uint64 `parquet:"gas_used"`
+}
+
+func writeDeliveredPayloadsParquet(out io.Writer, entries []*database.DeliveredPayloadEntry) error {
+	schema, err := parquetschema.ParseSchemaDefinition(deliveredPayloadParquetSchema)
+	if err != nil {
+		return err
+	}
+	w := floor.NewWriter(goparquet.NewFileWriter(out, goparquet.WithSchemaDefinition(schema), goparquet.WithCompressionCodec(parquet.CompressionCodec_SNAPPY)))
+	for _, entry := range entries {
+		err := w.Write(&deliveredPayloadParquetRecord{
+			Slot:           entry.Slot,
+			ProposerPubkey: entry.ProposerPubkey,
+			BuilderPubkey:  entry.BuilderPubkey,
+			Value:          entry.Value,
+			BlockHash:      entry.BlockHash,
+			GasUsed:        entry.GasUsed,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+var Export = &cobra.Command{
+	Use:   "export",
+	Short: "export the delivered payloads of a slot range to a CSV, JSON or Parquet file, for offline analysis",
+	Run: func(cmd *cobra.Command, args []string) {
+		if slotTo < slotFrom {
+			log.Fatal("--to-slot must not be before --from-slot")
+		}
+		for _, outFile := range outFiles {
+			if !strings.HasSuffix(outFile, ".csv") && !strings.HasSuffix(outFile, ".json") && !strings.HasSuffix(outFile, ".parquet") {
+				log.Fatalf("unsupported output format: %s (only .csv, .json and .parquet are supported)", outFile)
+			}
+		}
+
+		// Connect to Postgres
+		dbURL, err := url.Parse(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("couldn't read db URL")
+		}
+		log.Infof("Connecting to Postgres database at %s%s ...", dbURL.Host, dbURL.Path)
+		db, err := database.NewDatabaseService(postgresDSN)
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to connect to Postgres database at %s%s", dbURL.Host, dbURL.Path)
+		}
+
+		entries, err := db.GetDeliveredPayloadsBySlots(slotFrom, slotTo)
+		if err != nil {
+			log.WithError(err).Fatal("error getting delivered payloads")
+		}
+		log.Infof("got %d delivered payloads for slots %d to %d", len(entries), slotFrom, slotTo)
+
+		writeToFile := func(outFile string) {
+			f, err := os.Create(outFile)
+			if err != nil {
+				log.WithError(err).Fatal("failed to open file")
+			}
+			defer f.Close()
+
+			if strings.HasSuffix(outFile, ".csv") {
+				// write CSV
+				w := csv.NewWriter(f)
+				defer w.Flush()
+				if err := w.Write(deliveredPayloadCSVHeader); err != nil {
+					log.WithError(err).Fatal("error writing record to file")
+				}
+				for _, entry := range entries {
+					if err := w.Write(deliveredPayloadCSVRecord(entry)); err != nil {
+						log.WithError(err).Fatal("error writing record to file")
+					}
+				}
+			} else if strings.HasSuffix(outFile, ".parquet") {
+				// write Parquet, with the columns of the CSV
+				if err := writeDeliveredPayloadsParquet(f, entries); err != nil {
+					log.WithError(err).Fatal("error writing records to file")
+				}
+			} else {
+				// write JSON, in the format of the data API
+				bidTraces := make([]common.BidTraceV2JSON, len(entries))
+				for i, entry := range entries {
+					bidTraces[i] = database.DeliveredPayloadEntryToBidTraceV2JSON(entry)
+				}
+				encoder := json.NewEncoder(f)
+				err = encoder.Encode(bidTraces)
+				if err != nil {
+					log.WithError(err).Fatal("failed to write json to file")
+				}
+			}
+			log.Infof("Wrote %d entries to %s", len(entries), outFile)
+		}
+
+		for _, outFile := range outFiles {
+			writeToFile(outFile)
+		}
+	},
+}
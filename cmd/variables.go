@@ -1,16 +1,26 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 
+	"github.com/flashbots/mev-boost-relay/beaconclient"
 	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
 )
 
 var (
 	defaultNetwork          = common.GetEnv("NETWORK", "")
+	defaultNetworkConfig    = common.GetEnv("NETWORK_CONFIG", "")
+	defaultGenesisValRoot   = common.GetEnv("GENESIS_VALIDATORS_ROOT", "")
 	defaultBeaconURIs       = common.GetSliceEnv("BEACON_URIS", []string{"http://localhost:3500"})
 	defaultRedisURI         = common.GetEnv("REDIS_URI", "localhost:6379")
 	defaultRedisReadonlyURI = common.GetEnv("REDIS_READONLY_URI", "")
+	defaultRedisOpts        = datastore.DefaultRedisOptions()
 	defaultPostgresDSN      = common.GetEnv("POSTGRES_DSN", "")
 	defaultMemcachedURIs    = common.GetSliceEnv("MEMCACHED_URIS", nil)
 	defaultLogJSON          = os.Getenv("LOG_JSON") != ""
@@ -19,11 +29,157 @@ var (
 	beaconNodeURIs   []string
 	redisURI         string
 	redisReadonlyURI string
+	redisOpts        datastore.RedisOptions
 	postgresDSN      string
 	memcachedURIs    []string
 
 	logJSON  bool
 	logLevel string
 
-	network string
+	network               string
+	networkConfigFile     string
+	genesisValidatorsRoot string
 )
+
+// genesisValidatorsRootHint is the hint added to common.ErrMissingGenesisValidatorsRoot for the commands which can't
+// get the root from a beacon node
+var genesisValidatorsRootHint = "set --genesis-validators-root (GENESIS_VALIDATORS_ROOT) or GENESIS_VALIDATORS_ROOT in --network-config"
+
+// getNetworkDetails returns the details of --network, with the values of --network-config and
+// --genesis-validators-root if set. SEC_PER_SLOT, if set, takes precedence over the config's SECONDS_PER_SLOT.
+func getNetworkDetails() (*common.EthNetworkDetails, error) {
+	networkInfo, err := loadNetworkDetails()
+	if errors.Is(err, common.ErrMissingGenesisValidatorsRoot) {
+		return nil, fmt.Errorf("%w: %s", err, genesisValidatorsRootHint)
+	}
+	return networkInfo, err
+}
+
+func loadNetworkDetails() (*common.EthNetworkDetails, error) {
+	if networkConfigFile == "" && (genesisValidatorsRoot == "" || network == "") {
+		return common.NewEthNetworkDetails(network)
+	}
+	cfg := new(common.NetworkConfig)
+	if networkConfigFile != "" {
+		var err error
+		cfg, err = common.LoadNetworkConfig(networkConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.SecondsPerSlot != 0 && os.Getenv("SEC_PER_SLOT") == "" {
+			common.SetSecondsPerSlot(cfg.SecondsPerSlot)
+		}
+	}
+	if genesisValidatorsRoot != "" {
+		if err := common.ValidateGenesisValidatorsRoot(genesisValidatorsRoot); err != nil {
+			return nil, fmt.Errorf("--genesis-validators-root: %w", err)
+		}
+		cfg.GenesisValidatorsRoot = genesisValidatorsRoot
+	}
+	return common.NewEthNetworkDetailsWithConfig(network, cfg)
+}
+
+type genesisSource interface {
+	GetGenesis() (*beaconclient.GetGenesisResponse, error)
+}
+
+// networkDetailsWithBeaconGenesisValidatorsRoot returns the network details with the genesis validators root of the
+// beacon node, for networks without a configured one
+func networkDetailsWithBeaconGenesisValidatorsRoot(beaconClient genesisSource) (*common.EthNetworkDetails, error) {
+	genesis, err := beaconClient.GetGenesis()
+	if err != nil {
+		return nil, fmt.Errorf("could not get the genesis validators root of the beacon node: %w", err)
+	}
+	genesisValidatorsRoot = genesis.Data.GenesisValidatorsRoot
+	return getNetworkDetails()
+}
+
+// getNetworkDetailsWithBeacon returns the network details like getNetworkDetails, and uses the genesis validators root
+// of the beacon node if none is configured (i.e. for --network custom)
+func getNetworkDetailsWithBeacon(log *logrus.Entry, beaconClient genesisSource) (*common.EthNetworkDetails, error) {
+	networkInfo, err := getNetworkDetails()
+	if !errors.Is(err, common.ErrMissingGenesisValidatorsRoot) {
+		return networkInfo, err
+	}
+	networkInfo, err = networkDetailsWithBeaconGenesisValidatorsRoot(beaconClient)
+	if err == nil {
+		log.Infof("Using the genesis validators root of the beacon node: %s", networkInfo.GenesisValidatorsRootHex)
+	}
+	return networkInfo, err
+}
+
+// addNetworkFlags adds the flags for the network details, from which the signing domains are computed
+func addNetworkFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&network, "network", defaultNetwork, "Which network to use")
+	cmd.Flags().StringVar(&networkConfigFile, "network-config", defaultNetworkConfig, "YAML or JSON file with the network details (fork versions, genesis), overriding the --network preset")
+	cmd.Flags().StringVar(&genesisValidatorsRoot, "genesis-validators-root", defaultGenesisValRoot, "genesis validators root (32 bytes hex) for the signing domains, overriding the one of --network and --network-config")
+}
+
+// networkDetailsFromBeacon returns the network details with the genesis and fork versions of the beacon node, which take
+// precedence over the ones of networkInfo (nil if neither --network nor --network-config are set). If the node doesn't
+// provide them, networkInfo is used as is.
+func networkDetailsFromBeacon(log *logrus.Entry, beaconClient beaconclient.IMultiBeaconClient, networkInfo *common.EthNetworkDetails) (*common.EthNetworkDetails, error) {
+	cfg, err := beaconclient.FetchNetworkConfig(beaconClient)
+	if err != nil {
+		if networkInfo != nil {
+			log.WithError(err).Warn("could not get the network config of the beacon node, using the configured network")
+			return networkInfo, nil
+		}
+		return nil, err
+	}
+	if cfg.SecondsPerSlot != 0 && os.Getenv("SEC_PER_SLOT") == "" {
+		common.SetSecondsPerSlot(cfg.SecondsPerSlot)
+	}
+	if networkInfo == nil {
+		return common.NewEthNetworkDetailsWithConfig(network, cfg)
+	}
+
+	for _, value := range []struct{ name, configured, detected string }{
+		{"genesis fork version", networkInfo.GenesisForkVersionHex, cfg.GenesisForkVersion},
+		{"genesis validators root", networkInfo.GenesisValidatorsRootHex, cfg.GenesisValidatorsRoot},
+		{"bellatrix fork version", networkInfo.BellatrixForkVersionHex, cfg.BellatrixForkVersion},
+		{"capella fork version", networkInfo.CapellaForkVersionHex, cfg.CapellaForkVersion},
+		{"deneb fork version", networkInfo.DenebForkVersionHex, cfg.DenebForkVersion},
+	} {
+		if value.detected != "" && !strings.EqualFold(value.configured, value.detected) {
+			log.Warnf("the %s of the beacon node (%s) differs from the configured one (%s), using the beacon node's", value.name, value.detected, value.configured)
+		}
+	}
+	return networkInfo, networkInfo.ApplyNetworkConfig(cfg)
+}
+
+// addRedisConnectionFlags adds the flags for the prefix of all Redis keys and the sentinel or cluster mode, which need to
+// be the same for all services of a relay
+func addRedisConnectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&redisOpts.KeyPrefix, "redis-prefix", defaultRedisOpts.KeyPrefix, "prefix of all redis keys, to share a redis between relays of different networks or deployments")
+	cmd.Flags().StringSliceVar(&redisOpts.SentinelAddrs, "redis-sentinel", defaultRedisOpts.SentinelAddrs, "redis sentinel addresses (comma-separated), to connect to the primary of --redis-sentinel-master and follow failovers (--redis-uri only provides credentials, DB and TLS)")
+	cmd.Flags().StringVar(&redisOpts.SentinelMasterName, "redis-sentinel-master", defaultRedisOpts.SentinelMasterName, "name of the redis primary monitored by the sentinels")
+	cmd.Flags().StringVar(&redisOpts.SentinelPassword, "redis-sentinel-password", defaultRedisOpts.SentinelPassword, "password of the redis sentinels (prefer the REDIS_SENTINEL_PASSWORD env var)")
+	cmd.Flags().StringSliceVar(&redisOpts.ClusterAddrs, "redis-cluster", defaultRedisOpts.ClusterAddrs, "redis cluster seed node addresses (comma-separated), to connect in cluster mode (--redis-uri only provides credentials and TLS)")
+}
+
+// redisConnectionOpts returns the Redis options of the environment with the connection flags, for the services without
+// the other --redis-* flags
+func redisConnectionOpts() datastore.RedisOptions {
+	opts := datastore.DefaultRedisOptions()
+	opts.KeyPrefix = redisOpts.KeyPrefix
+	opts.SentinelAddrs = redisOpts.SentinelAddrs
+	opts.SentinelMasterName = redisOpts.SentinelMasterName
+	opts.SentinelPassword = redisOpts.SentinelPassword
+	opts.ClusterAddrs = redisOpts.ClusterAddrs
+	return opts
+}
+
+// addRedisOptionsFlags adds the flags for the Redis key prefix, connection mode, connection pool and read retries
+func addRedisOptionsFlags(cmd *cobra.Command) {
+	addRedisConnectionFlags(cmd)
+	cmd.Flags().IntVar(&redisOpts.PoolSize, "redis-pool-size", defaultRedisOpts.PoolSize, "redis connection pool size (0: go-redis default of 10 per CPU)")
+	cmd.Flags().IntVar(&redisOpts.MinIdleConns, "redis-min-idle-conns", defaultRedisOpts.MinIdleConns, "minimum number of idle redis connections")
+	cmd.Flags().DurationVar(&redisOpts.DialTimeout, "redis-dial-timeout", defaultRedisOpts.DialTimeout, "redis dial timeout (0: go-redis default of 5s)")
+	cmd.Flags().DurationVar(&redisOpts.ReadTimeout, "redis-read-timeout", defaultRedisOpts.ReadTimeout, "redis read timeout (0: go-redis default of 3s)")
+	cmd.Flags().DurationVar(&redisOpts.WriteTimeout, "redis-write-timeout", defaultRedisOpts.WriteTimeout, "redis write timeout (0: go-redis default of 3s)")
+	cmd.Flags().DurationVar(&redisOpts.PoolTimeout, "redis-pool-timeout", defaultRedisOpts.PoolTimeout, "how long to wait for a free redis connection (0: go-redis default of read timeout + 1s)")
+	cmd.Flags().IntVar(&redisOpts.MaxRetries, "redis-max-retries", defaultRedisOpts.MaxRetries, "retries of failed redis commands by go-redis (0: go-redis default of 3, -1: no retries)")
+	cmd.Flags().IntVar(&redisOpts.ReadRetries, "redis-read-retries", defaultRedisOpts.ReadRetries, "retries of bid and payload reads on transient redis errors, instead of the go-redis retries (0: go-redis retries)")
+	cmd.Flags().DurationVar(&redisOpts.ReadRetryBackoff, "redis-read-retry-backoff", defaultRedisOpts.ReadRetryBackoff, "initial backoff between read retries, doubled on each attempt")
+}
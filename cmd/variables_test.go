@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testGenesisSource struct {
+	genesisValidatorsRoot string
+}
+
+func (s *testGenesisSource) GetGenesis() (*beaconclient.GetGenesisResponse, error) {
+	return &beaconclient.GetGenesisResponse{Data: beaconclient.GetGenesisResponseData{GenesisValidatorsRoot: s.genesisValidatorsRoot}}, nil //nolint:exhaustruct
+}
+
+func TestGetNetworkDetailsWithBeacon(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	config := "CONFIG_NAME: devnet\nGENESIS_FORK_VERSION: 0x10000038\nBELLATRIX_FORK_VERSION: 0x30000038\nCAPELLA_FORK_VERSION: 0x40000038\n"
+	require.NoError(t, os.WriteFile(configFile, []byte(config), 0o600))
+
+	prevNetwork, prevConfigFile, prevRoot := network, networkConfigFile, genesisValidatorsRoot
+	t.Cleanup(func() {
+		network, networkConfigFile, genesisValidatorsRoot = prevNetwork, prevConfigFile, prevRoot
+	})
+	network, networkConfigFile, genesisValidatorsRoot = "", configFile, ""
+
+	// without a beacon node, the error says how to configure the root
+	_, err := getNetworkDetails()
+	require.ErrorIs(t, err, common.ErrMissingGenesisValidatorsRoot)
+	require.Contains(t, err.Error(), "--genesis-validators-root")
+
+	root := "0x53a92d8f2bb1d85f62d16a156e6ebcd1bcaba652d0900b2c2f387826f3481f6f"
+	networkInfo, err := getNetworkDetailsWithBeacon(common.TestLog, &testGenesisSource{genesisValidatorsRoot: root})
+	require.NoError(t, err)
+	require.Equal(t, root, networkInfo.GenesisValidatorsRootHex)
+	require.Equal(t, "devnet", networkInfo.Name)
+	require.Equal(t, "0x40000038", networkInfo.CapellaForkVersionHex)
+}
@@ -35,11 +35,12 @@ func init() {
 
 	websiteCmd.Flags().StringVar(&websiteListenAddr, "listen-addr", websiteDefaultListenAddr, "listen address for webserver")
 	websiteCmd.Flags().StringVar(&redisURI, "redis-uri", defaultRedisURI, "redis uri")
+	addRedisConnectionFlags(websiteCmd)
 	websiteCmd.Flags().StringVar(&redisReadonlyURI, "redis-readonly-uri", defaultRedisReadonlyURI, "redis readonly uri")
 	websiteCmd.Flags().StringVar(&postgresDSN, "db", defaultPostgresDSN, "PostgreSQL DSN")
 	websiteCmd.Flags().StringVar(&websitePubkeyOverride, "pubkey-override", os.Getenv("PUBKEY_OVERRIDE"), "override for public key")
 
-	websiteCmd.Flags().StringVar(&network, "network", defaultNetwork, "Which network to use")
+	addNetworkFlags(websiteCmd)
 	websiteCmd.Flags().BoolVar(&websiteShowConfigDetails, "show-config-details", websiteDefaultShowConfigDetails, "show config details")
 	websiteCmd.Flags().StringVar(&websiteLinkBeaconchain, "link-beaconchain", websiteDefaultLinkBeaconchain, "url for beaconcha.in")
 	websiteCmd.Flags().StringVar(&websiteLinkEtherscan, "link-etherscan", websiteDefaultLinkEtherscan, "url for etherscan")
@@ -58,7 +59,7 @@ var websiteCmd = &cobra.Command{
 		})
 		log.Infof("boost-relay %s", Version)
 
-		networkInfo, err := common.NewEthNetworkDetails(network)
+		networkInfo, err := getNetworkDetails()
 		if err != nil {
 			log.WithError(err).Fatalf("error getting network details")
 		}
@@ -72,7 +73,7 @@ var websiteCmd = &cobra.Command{
 		} else {
 			log.Infof("Connecting to Redis at %s / readonly: %s ...", redisURI, redisReadonlyURI)
 		}
-		redis, err := datastore.NewRedisCache(networkInfo.Name, redisURI, redisReadonlyURI)
+		redis, err := datastore.NewRedisCacheWithOptions(networkInfo.Name, redisURI, redisReadonlyURI, redisConnectionOpts())
 		if err != nil {
 			log.WithError(err).Fatalf("Failed to connect to Redis at %s", redisURI)
 		}
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+)
+
+// FarFutureEpoch is the epoch of forks which aren't scheduled (yet)
+const FarFutureEpoch = uint64(math.MaxUint64)
+
+var ErrEmptyForkSchedule = errors.New("fork schedule has no forks")
+
+// Fork is a hard fork of the network: its version, the epoch from which it is active, and the beacon proposer signing
+// domain of the version
+type Fork struct {
+	Name    string
+	Version string
+	Epoch   uint64
+
+	DomainBeaconProposer boostTypes.Domain
+}
+
+// ForkSchedule picks the signing domain of a slot by the fork active in its epoch. Forks are added from the network
+// preset or config and from the beacon node's fork schedule, so that a new fork needs no code changes for its domain.
+type ForkSchedule struct {
+	genesisValidatorsRoot string
+	forks                 []*Fork // ordered by epoch
+}
+
+// NewForkSchedule computes the proposer domains of the forks
+func NewForkSchedule(genesisValidatorsRoot string, forks []Fork) (*ForkSchedule, error) {
+	if len(forks) == 0 {
+		return nil, ErrEmptyForkSchedule
+	}
+	s := &ForkSchedule{genesisValidatorsRoot: genesisValidatorsRoot}
+	for _, fork := range forks {
+		if err := s.add(fork.Name, fork.Version, fork.Epoch); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *ForkSchedule) add(name, version string, epoch uint64) error {
+	domain, err := ComputeDomain(boostTypes.DomainTypeBeaconProposer, version, s.genesisValidatorsRoot)
+	if err != nil {
+		return fmt.Errorf("fork %s (%s): %w", name, version, err)
+	}
+	s.forks = append(s.forks, &Fork{Name: name, Version: version, Epoch: epoch, DomainBeaconProposer: domain})
+	sort.SliceStable(s.forks, func(i, j int) bool { return s.forks[i].Epoch < s.forks[j].Epoch })
+	return nil
+}
+
+// SetForkEpoch sets the epoch of the fork with the version, or adds the fork if the version is unknown
+func (s *ForkSchedule) SetForkEpoch(version string, epoch uint64) error {
+	for _, fork := range s.forks {
+		if strings.EqualFold(fork.Version, version) {
+			fork.Epoch = epoch
+			sort.SliceStable(s.forks, func(i, j int) bool { return s.forks[i].Epoch < s.forks[j].Epoch })
+			return nil
+		}
+	}
+	return s.add(version, version, epoch)
+}
+
+// ForkAtEpoch returns the latest fork active at the epoch, or the first fork for epochs before all of them
+func (s *ForkSchedule) ForkAtEpoch(epoch uint64) Fork {
+	fork := s.forks[0]
+	for _, f := range s.forks[1:] {
+		if f.Epoch > epoch || f.Epoch == FarFutureEpoch {
+			break
+		}
+		fork = f
+	}
+	return *fork
+}
+
+// ProposerDomain returns the beacon proposer signing domain for blocks of the slot
+func (s *ForkSchedule) ProposerDomain(slot uint64) boostTypes.Domain {
+	return s.ForkAtEpoch(slot / SlotsPerEpoch).DomainBeaconProposer
+}
+
+func (s *ForkSchedule) String() string {
+	forks := make([]string, len(s.forks))
+	for i, fork := range s.forks {
+		epoch := "unscheduled"
+		if fork.Epoch != FarFutureEpoch {
+			epoch = fmt.Sprint(fork.Epoch)
+		}
+		forks[i] = fmt.Sprintf("%s=%s@%s", fork.Name, fork.Version, epoch)
+	}
+	return strings.Join(forks, ", ")
+}
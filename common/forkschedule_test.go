@@ -0,0 +1,69 @@
+package common
+
+import (
+	"testing"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkSchedule(t *testing.T) {
+	mainnet, err := NewEthNetworkDetails(EthNetworkMainnet)
+	require.NoError(t, err)
+	schedule, err := mainnet.ForkSchedule()
+	require.NoError(t, err)
+
+	capellaSlot := mainnet.ForkEpochs[ForkVersionStringCapella] * SlotsPerEpoch
+	denebSlot := mainnet.ForkEpochs[ForkVersionStringDeneb] * SlotsPerEpoch
+	require.Equal(t, mainnet.DomainBeaconProposerBellatrix, schedule.ProposerDomain(0))
+	require.Equal(t, mainnet.DomainBeaconProposerBellatrix, schedule.ProposerDomain(capellaSlot-1))
+	require.Equal(t, mainnet.DomainBeaconProposerCapella, schedule.ProposerDomain(capellaSlot))
+	require.Equal(t, mainnet.DomainBeaconProposerCapella, schedule.ProposerDomain(denebSlot-1))
+	require.Equal(t, mainnet.DomainBeaconProposerDeneb, schedule.ProposerDomain(denebSlot))
+
+	// The beacon node's epochs override the preset
+	require.NoError(t, schedule.SetForkEpoch(mainnet.CapellaForkVersionHex, 1))
+	require.Equal(t, mainnet.DomainBeaconProposerCapella, schedule.ProposerDomain(SlotsPerEpoch))
+
+	// Unknown versions are added, and unscheduled forks are never active
+	require.NoError(t, schedule.SetForkEpoch("0x05000000", FarFutureEpoch))
+	require.Equal(t, ForkVersionStringDeneb, schedule.ForkAtEpoch(FarFutureEpoch-1).Name)
+	require.NoError(t, schedule.SetForkEpoch("0x05000000", mainnet.ForkEpochs[ForkVersionStringDeneb]+10))
+	fork := schedule.ForkAtEpoch(mainnet.ForkEpochs[ForkVersionStringDeneb] + 10)
+	require.Equal(t, "0x05000000", fork.Version)
+	expectedDomain, err := ComputeDomain(boostTypes.DomainTypeBeaconProposer, "0x05000000", mainnet.GenesisValidatorsRootHex)
+	require.NoError(t, err)
+	require.Equal(t, expectedDomain, fork.DomainBeaconProposer)
+
+	_, err = NewForkSchedule(mainnet.GenesisValidatorsRootHex, nil)
+	require.ErrorIs(t, err, ErrEmptyForkSchedule)
+}
+
+func TestForkScheduleFromNetworkConfig(t *testing.T) {
+	cfg, err := LoadNetworkConfig(writeNetworkConfig(t, "config.yaml", `
+CONFIG_NAME: devnet
+GENESIS_FORK_VERSION: 0x10000038
+GENESIS_VALIDATORS_ROOT: 0x53a92d8f2bb1d85f62d16a156e6ebcd1bcaba652d0900b2c2f387826f3481f6f
+BELLATRIX_FORK_VERSION: 0x30000038
+BELLATRIX_FORK_EPOCH: 0
+CAPELLA_FORK_VERSION: 0x40000038
+CAPELLA_FORK_EPOCH: 10
+DENEB_FORK_VERSION: 0x50000038
+DENEB_FORK_EPOCH: 20
+ELECTRA_FORK_VERSION: 0x60000038
+ELECTRA_FORK_EPOCH: 30
+`))
+	require.NoError(t, err)
+	require.Equal(t, "0x60000038", cfg.ForkVersions["electra"])
+	require.Equal(t, uint64(30), cfg.ForkEpochs["electra"])
+
+	networkDetails, err := NewEthNetworkDetailsWithConfig("", cfg)
+	require.NoError(t, err)
+	schedule, err := networkDetails.ForkSchedule()
+	require.NoError(t, err)
+	require.Equal(t, ForkVersionStringBellatrix, schedule.ForkAtEpoch(9).Name)
+	require.Equal(t, ForkVersionStringCapella, schedule.ForkAtEpoch(10).Name)
+	require.Equal(t, ForkVersionStringDeneb, schedule.ForkAtEpoch(29).Name)
+	require.Equal(t, "electra", schedule.ForkAtEpoch(30).Name)
+	require.Equal(t, networkDetails.DomainBeaconProposerDeneb, schedule.ProposerDomain(20*SlotsPerEpoch))
+}
@@ -0,0 +1,187 @@
+package common
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/flashbots/go-boost-utils/bls"
+)
+
+var (
+	ErrNoSecretKey          = errors.New("no secret key specified")
+	ErrUnknownKeyProvider   = errors.New("unknown key provider")
+	ErrKeyProviderConfig    = errors.New("key provider is not configured")
+	ErrVaultSecretKeyAbsent = errors.New("secret key field not found in vault secret")
+
+	keyProviderTimeout = 10 * time.Second
+)
+
+// KeyProvider fetches the BLS secret key used for signing bids. It is only called at startup, signing itself is
+// unaffected by where the key came from.
+type KeyProvider interface {
+	SecretKey(ctx context.Context) (*bls.SecretKey, error)
+}
+
+// LocalKeyProvider reads the secret key from a file or a hex string, with the file taking precedence
+type LocalKeyProvider struct {
+	Hex  string
+	File string
+}
+
+func (p *LocalKeyProvider) SecretKey(ctx context.Context) (*bls.SecretKey, error) {
+	switch {
+	case p.File != "":
+		return SecretKeyFromFile(p.File)
+	case p.Hex != "":
+		return SecretKeyFromHex(p.Hex)
+	default:
+		return nil, ErrNoSecretKey
+	}
+}
+
+// VaultKeyProvider reads the hex-encoded secret key from a field of a HashiCorp Vault secret, for both the KV v1 and
+// v2 secrets engines (for v2 the path includes "data/", e.g. "secret/data/relay")
+type VaultKeyProvider struct {
+	Addr  string
+	Token string
+	Path  string
+	Field string
+}
+
+func (p *VaultKeyProvider) SecretKey(ctx context.Context) (*bls.SecretKey, error) {
+	if p.Addr == "" || p.Token == "" || p.Path == "" {
+		return nil, fmt.Errorf("%w: vault needs VAULT_ADDR, VAULT_TOKEN and a key path", ErrKeyProviderConfig)
+	}
+
+	uri := strings.TrimSuffix(p.Addr, "/") + "/v1/" + strings.TrimPrefix(p.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+
+	body, err := doKeyProviderRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	defer zeroBytes(body)
+
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	fields := secret.Data
+	if nested, ok := secret.Data["data"]; ok { // KV v2 wraps the fields in another data object
+		fields = nil
+		if err := json.Unmarshal(nested, &fields); err != nil {
+			return nil, fmt.Errorf("vault: %w", err)
+		}
+	}
+
+	var skHex string
+	if raw, ok := fields[p.Field]; !ok || json.Unmarshal(raw, &skHex) != nil {
+		return nil, fmt.Errorf("%w: %s", ErrVaultSecretKeyAbsent, p.Field)
+	}
+	return SecretKeyFromHex(skHex)
+}
+
+// KMSKeyProvider decrypts the secret key with AWS KMS, from a file with the base64-encoded ciphertext (the output of
+// `aws kms encrypt`). The plaintext is either the 32 raw bytes of the key or its hex encoding. The credentials come
+// from the default credential chain of the AWS SDK (environment, shared config and credentials files, web identity,
+// ECS and EC2 instance roles).
+type KMSKeyProvider struct {
+	Region         string // defaults to the region of the AWS config
+	Endpoint       string // defaults to the KMS endpoint of the region
+	CiphertextFile string
+}
+
+func (p *KMSKeyProvider) SecretKey(ctx context.Context) (*bls.SecretKey, error) {
+	if p.CiphertextFile == "" {
+		return nil, fmt.Errorf("%w: kms needs a ciphertext file", ErrKeyProviderConfig)
+	}
+
+	ciphertextBase64, err := os.ReadFile(p.CiphertextFile)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(ciphertextBase64)))
+	if err != nil {
+		return nil, fmt.Errorf("kms: invalid ciphertext file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, keyProviderTimeout)
+	defer cancel()
+
+	loadOpts := []func(*awsconfig.LoadOptions) error{}
+	if p.Region != "" {
+		loadOpts = append(loadOpts, awsconfig.WithRegion(p.Region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("kms: %w", err)
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("%w: kms needs AWS_REGION or a region in the AWS config", ErrKeyProviderConfig)
+	}
+	client := kms.NewFromConfig(cfg, func(o *kms.Options) {
+		if p.Endpoint != "" {
+			o.BaseEndpoint = aws.String(p.Endpoint)
+		}
+	})
+
+	resp, err := client.Decrypt(ctx, &kms.DecryptInput{CiphertextBlob: ciphertext}) //nolint:exhaustruct
+	if err != nil {
+		return nil, fmt.Errorf("kms: %w", err)
+	}
+	defer zeroBytes(resp.Plaintext)
+	if len(resp.Plaintext) == 32 {
+		return bls.SecretKeyFromBytes(resp.Plaintext)
+	}
+	return SecretKeyFromHex(string(resp.Plaintext))
+}
+
+func doKeyProviderRequest(req *http.Request) ([]byte, error) {
+	client := &http.Client{Timeout: keyProviderTimeout} //nolint:exhaustruct
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		zeroBytes(body)
+		return nil, fmt.Errorf("%w: %d", ErrHTTPErrorResponse, resp.StatusCode)
+	}
+	return body, nil
+}
+
+// NewKeyProvider returns the key provider of the given name: "local", "vault" or "kms"
+func NewKeyProvider(name string, local *LocalKeyProvider, vault *VaultKeyProvider, kms *KMSKeyProvider) (KeyProvider, error) {
+	switch name {
+	case "", "local":
+		return local, nil
+	case "vault":
+		return vault, nil
+	case "kms":
+		return kms, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyProvider, name)
+	}
+}
@@ -0,0 +1,105 @@
+package common
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyProviderSkHex = "0x607a11b45a7219cc61a3d9c5fd08c7eebd602a6a19a977f8d3771d5711a550f2"
+
+func TestLocalKeyProvider(t *testing.T) {
+	sk, err := (&LocalKeyProvider{Hex: testKeyProviderSkHex}).SecretKey(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, testKeyProviderSkHex, hexutil.Encode(bls.SecretKeyToBytes(sk)))
+
+	_, err = (&LocalKeyProvider{}).SecretKey(context.Background())
+	require.ErrorIs(t, err, ErrNoSecretKey)
+
+	_, err = NewKeyProvider("hsm", nil, nil, nil)
+	require.ErrorIs(t, err, ErrUnknownKeyProvider)
+}
+
+func TestVaultKeyProvider(t *testing.T) {
+	secrets := map[string]string{
+		"/v1/kv/relay":          `{"data":{"secret_key":"` + testKeyProviderSkHex + `"}}`,
+		"/v1/secret/data/relay": `{"data":{"data":{"secret_key":"` + testKeyProviderSkHex + `"},"metadata":{}}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := secrets[r.URL.Path]
+		if r.Header.Get("X-Vault-Token") != "token" || !ok {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(secret))
+	}))
+	defer srv.Close()
+
+	for _, path := range []string{"kv/relay", "secret/data/relay"} {
+		p := &VaultKeyProvider{Addr: srv.URL, Token: "token", Path: path, Field: "secret_key"}
+		sk, err := p.SecretKey(context.Background())
+		require.NoError(t, err, path)
+		require.Equal(t, testKeyProviderSkHex, hexutil.Encode(bls.SecretKeyToBytes(sk)))
+	}
+
+	_, err := (&VaultKeyProvider{Addr: srv.URL, Token: "token", Path: "kv/relay", Field: "key"}).SecretKey(context.Background())
+	require.ErrorIs(t, err, ErrVaultSecretKeyAbsent)
+
+	_, err = (&VaultKeyProvider{Addr: srv.URL, Token: "wrong", Path: "kv/relay", Field: "secret_key"}).SecretKey(context.Background())
+	require.ErrorIs(t, err, ErrHTTPErrorResponse)
+
+	_, err = (&VaultKeyProvider{Path: "kv/relay"}).SecretKey(context.Background())
+	require.ErrorIs(t, err, ErrKeyProviderConfig)
+}
+
+func TestKMSKeyProvider(t *testing.T) {
+	skBytes, err := hexutil.Decode(testKeyProviderSkHex)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "TrentService.Decrypt", r.Header.Get("X-Amz-Target"))
+		require.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		auth := r.Header.Get("Authorization")
+		require.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"), auth)
+		require.Contains(t, auth, "/eu-west-1/kms/aws4_request, SignedHeaders=")
+
+		var req struct {
+			CiphertextBlob string
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "Y2lwaGVydGV4dA==", req.CiphertextBlob)
+		_ = json.NewEncoder(w).Encode(map[string]string{"Plaintext": base64.StdEncoding.EncodeToString(skBytes)})
+	}))
+	defer srv.Close()
+
+	fn := filepath.Join(t.TempDir(), "ciphertext")
+	require.NoError(t, os.WriteFile(fn, []byte("Y2lwaGVydGV4dA==\n"), 0o600))
+
+	// Credentials from the environment, through the credential chain of the AWS SDK
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	p := &KMSKeyProvider{
+		Region:         "eu-west-1",
+		Endpoint:       srv.URL,
+		CiphertextFile: fn,
+	}
+	sk, err := p.SecretKey(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, testKeyProviderSkHex, hexutil.Encode(bls.SecretKeyToBytes(sk)))
+
+	_, err = (&KMSKeyProvider{Region: "eu-west-1"}).SecretKey(context.Background())
+	require.ErrorIs(t, err, ErrKeyProviderConfig)
+}
@@ -0,0 +1,184 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidNetworkConfig = errors.New("invalid network config")
+
+// NetworkConfig describes a network in a YAML or JSON file, i.e. for a devnet. The keys are the ones of the consensus
+// spec config, so that a devnet's config.yaml can be used as is (with the genesis validators root added). Fields which
+// are not set keep the values of the network preset.
+type NetworkConfig struct {
+	Name                  string `yaml:"CONFIG_NAME"`
+	GenesisForkVersion    string `yaml:"GENESIS_FORK_VERSION"`
+	GenesisValidatorsRoot string `yaml:"GENESIS_VALIDATORS_ROOT"`
+	GenesisTime           uint64 `yaml:"GENESIS_TIME"`
+	SecondsPerSlot        uint64 `yaml:"SECONDS_PER_SLOT"`
+	BellatrixForkVersion  string `yaml:"BELLATRIX_FORK_VERSION"`
+	CapellaForkVersion    string `yaml:"CAPELLA_FORK_VERSION"`
+	DenebForkVersion      string `yaml:"DENEB_FORK_VERSION"`
+
+	// Versions and epochs of all forks from the <NAME>_FORK_VERSION and <NAME>_FORK_EPOCH keys, by lowercase name
+	ForkVersions map[string]string `yaml:"-"`
+	ForkEpochs   map[string]uint64 `yaml:"-"`
+}
+
+// LoadNetworkConfig reads a network config from a YAML or JSON file
+func LoadNetworkConfig(path string) (*NetworkConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(NetworkConfig)
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidNetworkConfig, path, err)
+	}
+
+	// Collect the forks generically, so that configs with later forks need no new fields
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidNetworkConfig, path, err)
+	}
+	values := make(map[string]string, len(nodes))
+	for key, node := range nodes {
+		values[key] = node.Value
+	}
+	if err := cfg.setForks(values); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidNetworkConfig, path, err)
+	}
+	return cfg, nil
+}
+
+// NetworkConfigFromSpec returns the network config of a beacon node's spec (/eth/v1/config/spec), which has the keys of
+// the config files. The genesis validators root and time are not part of the spec.
+func NetworkConfigFromSpec(spec map[string]string) (*NetworkConfig, error) {
+	cfg := &NetworkConfig{ //nolint:exhaustruct
+		Name:                 spec["CONFIG_NAME"],
+		GenesisForkVersion:   spec["GENESIS_FORK_VERSION"],
+		BellatrixForkVersion: spec["BELLATRIX_FORK_VERSION"],
+		CapellaForkVersion:   spec["CAPELLA_FORK_VERSION"],
+		DenebForkVersion:     spec["DENEB_FORK_VERSION"],
+	}
+	if secondsPerSlot, found := spec["SECONDS_PER_SLOT"]; found {
+		var err error
+		if cfg.SecondsPerSlot, err = strconv.ParseUint(secondsPerSlot, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: SECONDS_PER_SLOT: %w", ErrInvalidNetworkConfig, err)
+		}
+	}
+	if err := cfg.setForks(spec); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidNetworkConfig, err)
+	}
+	return cfg, nil
+}
+
+// setForks sets the fork versions and epochs from the <NAME>_FORK_VERSION and <NAME>_FORK_EPOCH keys
+func (cfg *NetworkConfig) setForks(values map[string]string) error {
+	cfg.ForkVersions = make(map[string]string)
+	cfg.ForkEpochs = make(map[string]uint64)
+	for key, value := range values {
+		if name, found := strings.CutSuffix(key, "_FORK_VERSION"); found {
+			cfg.ForkVersions[strings.ToLower(name)] = value
+		} else if name, found := strings.CutSuffix(key, "_FORK_EPOCH"); found {
+			epoch, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			cfg.ForkEpochs[strings.ToLower(name)] = epoch
+		}
+	}
+	return nil
+}
+
+// NewEthNetworkDetailsWithConfig returns the details of the network, with the values of the config overriding the
+// preset. Other network names than the presets are allowed (i.e. the name of a devnet), without preset values.
+func NewEthNetworkDetailsWithConfig(networkName string, cfg *NetworkConfig) (*EthNetworkDetails, error) {
+	if networkName == "" {
+		networkName = cfg.Name
+	}
+	if networkName == "" {
+		networkName = EthNetworkCustom
+	}
+
+	ret, err := newEthNetworkPreset(networkName)
+	if errors.Is(err, ErrUnknownNetwork) {
+		ret = &EthNetworkDetails{Name: networkName} //nolint:exhaustruct
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := ret.ApplyNetworkConfig(cfg); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ApplyNetworkConfig overrides the details with the values which are set in the config, and computes the domains again
+func (e *EthNetworkDetails) ApplyNetworkConfig(cfg *NetworkConfig) error {
+	override := func(value *string, configValue string) {
+		if configValue != "" {
+			*value = configValue
+		}
+	}
+	override(&e.GenesisForkVersionHex, cfg.GenesisForkVersion)
+	override(&e.GenesisValidatorsRootHex, cfg.GenesisValidatorsRoot)
+	override(&e.BellatrixForkVersionHex, cfg.BellatrixForkVersion)
+	override(&e.CapellaForkVersionHex, cfg.CapellaForkVersion)
+	override(&e.DenebForkVersionHex, cfg.DenebForkVersion)
+	if cfg.GenesisTime != 0 {
+		e.GenesisTime = cfg.GenesisTime
+	}
+
+	if e.ForkEpochs == nil {
+		e.ForkEpochs = make(map[string]uint64)
+	}
+	for name, epoch := range cfg.ForkEpochs {
+		e.ForkEpochs[name] = epoch
+	}
+	names := make([]string, 0, len(cfg.ForkVersions))
+	for name := range cfg.ForkVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch name {
+		case "genesis", "altair", ForkVersionStringBellatrix, ForkVersionStringCapella, ForkVersionStringDeneb:
+			continue
+		}
+		epoch, found := cfg.ForkEpochs[name]
+		if !found {
+			epoch = FarFutureEpoch
+		}
+		fork := Fork{Name: name, Version: cfg.ForkVersions[name], Epoch: epoch} //nolint:exhaustruct
+		replaced := false
+		for i := range e.LaterForks {
+			if e.LaterForks[i].Name == name {
+				e.LaterForks[i], replaced = fork, true
+			}
+		}
+		if !replaced {
+			e.LaterForks = append(e.LaterForks, fork)
+		}
+	}
+
+	if err := e.computeDomains(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidNetworkConfig, err)
+	}
+	return nil
+}
+
+// SetSecondsPerSlot overrides the slot duration (SEC_PER_SLOT), i.e. from a network config. It needs to be called
+// before the services start.
+func SetSecondsPerSlot(secondsPerSlot uint64) {
+	SecondsPerSlot = secondsPerSlot
+	DurationPerSlot = time.Duration(SecondsPerSlot) * time.Second
+	DurationPerEpoch = DurationPerSlot * time.Duration(SlotsPerEpoch)
+}
@@ -0,0 +1,117 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/stretchr/testify/require"
+)
+
+func writeNetworkConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadNetworkConfig(t *testing.T) {
+	yamlPath := writeNetworkConfig(t, "config.yaml", `
+CONFIG_NAME: devnet
+PRESET_BASE: mainnet
+GENESIS_FORK_VERSION: 0x10000038
+GENESIS_VALIDATORS_ROOT: 0x53a92d8f2bb1d85f62d16a156e6ebcd1bcaba652d0900b2c2f387826f3481f6f
+GENESIS_TIME: 1606824023
+SECONDS_PER_SLOT: 6
+BELLATRIX_FORK_VERSION: 0x30000038
+CAPELLA_FORK_VERSION: 0x40000038
+`)
+	cfg, err := LoadNetworkConfig(yamlPath)
+	require.NoError(t, err)
+	require.Equal(t, "devnet", cfg.Name)
+	require.Equal(t, "0x10000038", cfg.GenesisForkVersion)
+	require.Equal(t, uint64(1606824023), cfg.GenesisTime)
+	require.Equal(t, uint64(6), cfg.SecondsPerSlot)
+	require.Equal(t, "0x40000038", cfg.CapellaForkVersion)
+	require.Empty(t, cfg.DenebForkVersion)
+
+	jsonPath := writeNetworkConfig(t, "config.json", `{"GENESIS_FORK_VERSION": "0x10000038", "GENESIS_TIME": 1606824023}`)
+	cfg, err = LoadNetworkConfig(jsonPath)
+	require.NoError(t, err)
+	require.Equal(t, "0x10000038", cfg.GenesisForkVersion)
+	require.Equal(t, uint64(1606824023), cfg.GenesisTime)
+
+	_, err = LoadNetworkConfig(writeNetworkConfig(t, "invalid.yaml", "GENESIS_TIME: [1"))
+	require.ErrorIs(t, err, ErrInvalidNetworkConfig)
+}
+
+func TestNewEthNetworkDetailsWithConfig(t *testing.T) {
+	mainnetDetails, err := NewEthNetworkDetails(EthNetworkMainnet)
+	require.NoError(t, err)
+
+	// The config supplements the preset
+	networkDetails, err := NewEthNetworkDetailsWithConfig(EthNetworkMainnet, &NetworkConfig{GenesisTime: 1606824023}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Equal(t, uint64(1606824023), networkDetails.GenesisTime)
+	require.Equal(t, mainnetDetails.DomainBeaconProposerCapella, networkDetails.DomainBeaconProposerCapella)
+
+	// and overrides it
+	networkDetails, err = NewEthNetworkDetailsWithConfig(EthNetworkMainnet, &NetworkConfig{CapellaForkVersion: CapellaForkVersionGoerli}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Equal(t, CapellaForkVersionGoerli, networkDetails.CapellaForkVersionHex)
+	require.Equal(t, mainnetDetails.DomainBeaconProposerBellatrix, networkDetails.DomainBeaconProposerBellatrix)
+	require.NotEqual(t, mainnetDetails.DomainBeaconProposerCapella, networkDetails.DomainBeaconProposerCapella)
+
+	// Networks without a preset take the name of the config
+	cfg := &NetworkConfig{ //nolint:exhaustruct
+		Name:                  "devnet",
+		GenesisForkVersion:    boostTypes.GenesisForkVersionGoerli,
+		GenesisValidatorsRoot: boostTypes.GenesisValidatorsRootGoerli,
+		BellatrixForkVersion:  boostTypes.BellatrixForkVersionGoerli,
+		CapellaForkVersion:    CapellaForkVersionGoerli,
+	}
+	networkDetails, err = NewEthNetworkDetailsWithConfig("", cfg)
+	require.NoError(t, err)
+	require.Equal(t, "devnet", networkDetails.Name)
+	goerliDetails, err := NewEthNetworkDetails(EthNetworkGoerli)
+	require.NoError(t, err)
+	require.Equal(t, goerliDetails.DomainBuilder, networkDetails.DomainBuilder)
+	require.Equal(t, goerliDetails.DomainBeaconProposerCapella, networkDetails.DomainBeaconProposerCapella)
+
+	// Fork versions are required
+	_, err = NewEthNetworkDetailsWithConfig("devnet", &NetworkConfig{}) //nolint:exhaustruct
+	require.ErrorIs(t, err, ErrInvalidNetworkConfig)
+}
+
+func TestNetworkConfigFromSpec(t *testing.T) {
+	cfg, err := NetworkConfigFromSpec(map[string]string{
+		"CONFIG_NAME":              "goerli",
+		"GENESIS_FORK_VERSION":     boostTypes.GenesisForkVersionGoerli,
+		"CAPELLA_FORK_VERSION":     CapellaForkVersionGoerli,
+		"CAPELLA_FORK_EPOCH":       "162304",
+		"ELECTRA_FORK_VERSION":     "0x05001020",
+		"ELECTRA_FORK_EPOCH":       "18446744073709551615",
+		"SECONDS_PER_SLOT":         "12",
+		"DEPOSIT_CONTRACT_ADDRESS": "0xff50ed3d0ec03ac01d4c79aad74928bff48a7b2b",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "goerli", cfg.Name)
+	require.Equal(t, uint64(12), cfg.SecondsPerSlot)
+	require.Equal(t, FarFutureEpoch, cfg.ForkEpochs["electra"])
+
+	_, err = NetworkConfigFromSpec(map[string]string{"CAPELLA_FORK_EPOCH": "soon"})
+	require.ErrorIs(t, err, ErrInvalidNetworkConfig)
+
+	// Applying the config overrides the values it has, also twice
+	networkDetails, err := NewEthNetworkDetails(EthNetworkMainnet)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, networkDetails.ApplyNetworkConfig(cfg))
+	}
+	goerliDetails, err := NewEthNetworkDetails(EthNetworkGoerli)
+	require.NoError(t, err)
+	require.Equal(t, goerliDetails.DomainBuilder, networkDetails.DomainBuilder)
+	require.Equal(t, boostTypes.BellatrixForkVersionMainnet, networkDetails.BellatrixForkVersionHex)
+	require.Len(t, networkDetails.LaterForks, 1)
+}
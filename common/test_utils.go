@@ -10,8 +10,10 @@ import (
 	"github.com/attestantio/go-builder-client/api/capella"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/flashbots/go-boost-utils/bls"
 	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/holiman/uint256"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 )
@@ -79,6 +81,40 @@ func TestBuilderSubmitBlockRequest(sk *bls.SecretKey, bid *BidTraceV2) BuilderSu
 	}
 }
 
+// CapellaToDenebSubmitBlockRequest turns a capella block submission into a deneb one with numBlobs (empty) blobs
+func CapellaToDenebSubmitBlockRequest(req *capella.SubmitBlockRequest, numBlobs int) *DenebSubmitBlockRequest {
+	p := req.ExecutionPayload
+	bundle := &BlobsBundle{
+		Commitments: make([]deneb.KzgCommitment, numBlobs),
+		Proofs:      make([]deneb.KzgProof, numBlobs),
+		Blobs:       make([]deneb.Blob, numBlobs),
+	}
+	return &DenebSubmitBlockRequest{
+		Message: req.Message,
+		ExecutionPayload: &deneb.ExecutionPayload{
+			ParentHash:    p.ParentHash,
+			FeeRecipient:  p.FeeRecipient,
+			StateRoot:     p.StateRoot,
+			ReceiptsRoot:  p.ReceiptsRoot,
+			LogsBloom:     p.LogsBloom,
+			PrevRandao:    p.PrevRandao,
+			BlockNumber:   p.BlockNumber,
+			GasLimit:      p.GasLimit,
+			GasUsed:       p.GasUsed,
+			Timestamp:     p.Timestamp,
+			ExtraData:     p.ExtraData,
+			BaseFeePerGas: new(uint256.Int).SetBytes(reverse(p.BaseFeePerGas[:])), // little endian in capella
+			BlockHash:     p.BlockHash,
+			Transactions:  p.Transactions,
+			Withdrawals:   p.Withdrawals,
+			DataGasUsed:   uint64(numBlobs) * 131072,
+			ExcessDataGas: 0,
+		},
+		BlobsBundle: bundle,
+		Signature:   req.Signature,
+	}
+}
+
 func LoadGzippedBytes(t *testing.T, filename string) []byte {
 	t.Helper()
 	fi, err := os.Open(filename)
@@ -12,6 +12,7 @@ import (
 	apiv1 "github.com/attestantio/go-builder-client/api/v1"
 	"github.com/attestantio/go-builder-client/spec"
 	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
 	consensusspec "github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
@@ -35,6 +36,10 @@ var (
 	CapellaForkVersionGoerli  = "0x03001020"
 	CapellaForkVersionMainnet = "0x03000000"
 
+	DenebForkVersionSepolia = "0x90000073"
+	DenebForkVersionGoerli  = "0x04001020"
+	DenebForkVersionMainnet = "0x04000000"
+
 	// Zhejiang details
 	GenesisForkVersionZhejiang    = "0x00000069"
 	GenesisValidatorsRootZhejiang = "0x53a92d8f2bb1d85f62d16a156e6ebcd1bcaba652d0900b2c2f387826f3481f6f"
@@ -44,6 +49,10 @@ var (
 	ForkVersionStringBellatrix = "bellatrix"
 	ForkVersionStringCapella   = "capella"
 	ForkVersionStringDeneb     = "deneb"
+
+	forkEpochsMainnet = map[string]uint64{ForkVersionStringBellatrix: 144896, ForkVersionStringCapella: 194048, ForkVersionStringDeneb: 269568}
+	forkEpochsGoerli  = map[string]uint64{ForkVersionStringBellatrix: 112260, ForkVersionStringCapella: 162304, ForkVersionStringDeneb: 231680}
+	forkEpochsSepolia = map[string]uint64{ForkVersionStringBellatrix: 100, ForkVersionStringCapella: 56832, ForkVersionStringDeneb: 132608}
 )
 
 type EthNetworkDetails struct {
@@ -52,86 +61,154 @@ type EthNetworkDetails struct {
 	GenesisValidatorsRootHex string
 	BellatrixForkVersionHex  string
 	CapellaForkVersionHex    string
+	DenebForkVersionHex      string
+
+	// Genesis time from the network config file, to check the beacon node's (0 if not configured)
+	GenesisTime uint64
+
+	// Activation epochs of the forks by name, i.e. "capella" (missing: not known before the beacon node's fork
+	// schedule), and the forks after deneb from the network config
+	ForkEpochs map[string]uint64
+	LaterForks []Fork
 
 	DomainBuilder                 boostTypes.Domain
 	DomainBeaconProposerBellatrix boostTypes.Domain
 	DomainBeaconProposerCapella   boostTypes.Domain
+	DomainBeaconProposerDeneb     boostTypes.Domain
 }
 
 func NewEthNetworkDetails(networkName string) (ret *EthNetworkDetails, err error) {
-	var genesisForkVersion string
-	var genesisValidatorsRoot string
-	var bellatrixForkVersion string
-	var capellaForkVersion string
-	var domainBuilder boostTypes.Domain
-	var domainBeaconProposerBellatrix boostTypes.Domain
-	var domainBeaconProposerCapella boostTypes.Domain
+	ret, err = newEthNetworkPreset(networkName)
+	if err != nil {
+		return nil, err
+	}
+	if err := ret.computeDomains(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// newEthNetworkPreset returns the fork versions and genesis validators root of the network, without the domains
+func newEthNetworkPreset(networkName string) (*EthNetworkDetails, error) {
+	ret := &EthNetworkDetails{Name: networkName} //nolint:exhaustruct
 
 	switch networkName {
 	case EthNetworkRopsten:
-		genesisForkVersion = boostTypes.GenesisForkVersionRopsten
-		genesisValidatorsRoot = boostTypes.GenesisValidatorsRootRopsten
-		bellatrixForkVersion = boostTypes.BellatrixForkVersionRopsten
-		capellaForkVersion = CapellaForkVersionRopsten
+		ret.GenesisForkVersionHex = boostTypes.GenesisForkVersionRopsten
+		ret.GenesisValidatorsRootHex = boostTypes.GenesisValidatorsRootRopsten
+		ret.BellatrixForkVersionHex = boostTypes.BellatrixForkVersionRopsten
+		ret.CapellaForkVersionHex = CapellaForkVersionRopsten
 	case EthNetworkSepolia:
-		genesisForkVersion = boostTypes.GenesisForkVersionSepolia
-		genesisValidatorsRoot = boostTypes.GenesisValidatorsRootSepolia
-		bellatrixForkVersion = boostTypes.BellatrixForkVersionSepolia
-		capellaForkVersion = CapellaForkVersionSepolia
+		ret.GenesisForkVersionHex = boostTypes.GenesisForkVersionSepolia
+		ret.GenesisValidatorsRootHex = boostTypes.GenesisValidatorsRootSepolia
+		ret.BellatrixForkVersionHex = boostTypes.BellatrixForkVersionSepolia
+		ret.CapellaForkVersionHex = CapellaForkVersionSepolia
+		ret.DenebForkVersionHex = DenebForkVersionSepolia
+		ret.ForkEpochs = copyForkEpochs(forkEpochsSepolia)
 	case EthNetworkGoerli:
-		genesisForkVersion = boostTypes.GenesisForkVersionGoerli
-		genesisValidatorsRoot = boostTypes.GenesisValidatorsRootGoerli
-		bellatrixForkVersion = boostTypes.BellatrixForkVersionGoerli
-		capellaForkVersion = CapellaForkVersionGoerli
+		ret.GenesisForkVersionHex = boostTypes.GenesisForkVersionGoerli
+		ret.GenesisValidatorsRootHex = boostTypes.GenesisValidatorsRootGoerli
+		ret.BellatrixForkVersionHex = boostTypes.BellatrixForkVersionGoerli
+		ret.CapellaForkVersionHex = CapellaForkVersionGoerli
+		ret.DenebForkVersionHex = DenebForkVersionGoerli
+		ret.ForkEpochs = copyForkEpochs(forkEpochsGoerli)
 	case EthNetworkMainnet:
-		genesisForkVersion = boostTypes.GenesisForkVersionMainnet
-		genesisValidatorsRoot = boostTypes.GenesisValidatorsRootMainnet
-		bellatrixForkVersion = boostTypes.BellatrixForkVersionMainnet
-		capellaForkVersion = CapellaForkVersionMainnet
+		ret.GenesisForkVersionHex = boostTypes.GenesisForkVersionMainnet
+		ret.GenesisValidatorsRootHex = boostTypes.GenesisValidatorsRootMainnet
+		ret.BellatrixForkVersionHex = boostTypes.BellatrixForkVersionMainnet
+		ret.CapellaForkVersionHex = CapellaForkVersionMainnet
+		ret.DenebForkVersionHex = DenebForkVersionMainnet
+		ret.ForkEpochs = copyForkEpochs(forkEpochsMainnet)
 	case EthNetworkZhejiang:
-		genesisForkVersion = GenesisForkVersionZhejiang
-		genesisValidatorsRoot = GenesisValidatorsRootZhejiang
-		bellatrixForkVersion = BellatrixForkVersionZhejiang
-		capellaForkVersion = CapellaForkVersionZhejiang
+		ret.GenesisForkVersionHex = GenesisForkVersionZhejiang
+		ret.GenesisValidatorsRootHex = GenesisValidatorsRootZhejiang
+		ret.BellatrixForkVersionHex = BellatrixForkVersionZhejiang
+		ret.CapellaForkVersionHex = CapellaForkVersionZhejiang
 	case EthNetworkCustom:
-		genesisForkVersion = os.Getenv("GENESIS_FORK_VERSION")
-		genesisValidatorsRoot = os.Getenv("GENESIS_VALIDATORS_ROOT")
-		bellatrixForkVersion = os.Getenv("BELLATRIX_FORK_VERSION")
-		capellaForkVersion = os.Getenv("CAPELLA_FORK_VERSION")
+		ret.GenesisForkVersionHex = os.Getenv("GENESIS_FORK_VERSION")
+		ret.GenesisValidatorsRootHex = os.Getenv("GENESIS_VALIDATORS_ROOT")
+		ret.BellatrixForkVersionHex = os.Getenv("BELLATRIX_FORK_VERSION")
+		ret.CapellaForkVersionHex = os.Getenv("CAPELLA_FORK_VERSION")
+		ret.DenebForkVersionHex = os.Getenv("DENEB_FORK_VERSION")
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrUnknownNetwork, networkName)
 	}
+	if ret.ForkEpochs == nil {
+		ret.ForkEpochs = make(map[string]uint64)
+	}
+	return ret, nil
+}
 
-	domainBuilder, err = ComputeDomain(boostTypes.DomainTypeAppBuilder, genesisForkVersion, boostTypes.Root{}.String())
+func copyForkEpochs(epochs map[string]uint64) map[string]uint64 {
+	ret := make(map[string]uint64, len(epochs))
+	for name, epoch := range epochs {
+		ret[name] = epoch
+	}
+	return ret
+}
+
+// ForkSchedule returns the schedule of the forks since bellatrix, with the epochs known from the preset or config
+func (e *EthNetworkDetails) ForkSchedule() (*ForkSchedule, error) {
+	forks := []Fork{}
+	addFork := func(name, version string) {
+		if version == "" {
+			return
+		}
+		epoch, found := e.ForkEpochs[name]
+		if !found {
+			epoch = FarFutureEpoch
+		}
+		forks = append(forks, Fork{Name: name, Version: version, Epoch: epoch}) //nolint:exhaustruct
+	}
+	addFork(ForkVersionStringBellatrix, e.BellatrixForkVersionHex)
+	addFork(ForkVersionStringCapella, e.CapellaForkVersionHex)
+	addFork(ForkVersionStringDeneb, e.DenebForkVersionHex)
+	forks = append(forks, e.LaterForks...)
+	return NewForkSchedule(e.GenesisValidatorsRootHex, forks)
+}
+
+// computeDomains computes the builder and proposer signing domains from the fork versions and genesis validators root
+func (e *EthNetworkDetails) computeDomains() (err error) {
+	e.DomainBuilder, err = ComputeDomain(boostTypes.DomainTypeAppBuilder, e.GenesisForkVersionHex, boostTypes.Root{}.String())
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	domainBeaconProposerBellatrix, err = ComputeDomain(boostTypes.DomainTypeBeaconProposer, bellatrixForkVersion, genesisValidatorsRoot)
+	e.DomainBeaconProposerBellatrix, err = ComputeDomain(boostTypes.DomainTypeBeaconProposer, e.BellatrixForkVersionHex, e.GenesisValidatorsRootHex)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	domainBeaconProposerCapella, err = ComputeDomain(boostTypes.DomainTypeBeaconProposer, capellaForkVersion, genesisValidatorsRoot)
+	e.DomainBeaconProposerCapella, err = ComputeDomain(boostTypes.DomainTypeBeaconProposer, e.CapellaForkVersionHex, e.GenesisValidatorsRootHex)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &EthNetworkDetails{
-		Name:                          networkName,
-		GenesisForkVersionHex:         genesisForkVersion,
-		GenesisValidatorsRootHex:      genesisValidatorsRoot,
-		BellatrixForkVersionHex:       bellatrixForkVersion,
-		CapellaForkVersionHex:         capellaForkVersion,
-		DomainBuilder:                 domainBuilder,
-		DomainBeaconProposerBellatrix: domainBeaconProposerBellatrix,
-		DomainBeaconProposerCapella:   domainBeaconProposerCapella,
-	}, nil
+	// Not all networks have a Deneb fork (yet)
+	e.DomainBeaconProposerDeneb = boostTypes.Domain{}
+	if e.DenebForkVersionHex != "" {
+		e.DomainBeaconProposerDeneb, err = ComputeDomain(boostTypes.DomainTypeBeaconProposer, e.DenebForkVersionHex, e.GenesisValidatorsRootHex)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetCapellaForkVersion overrides the Capella fork version of the network, and recomputes the Capella proposer domain
+func (e *EthNetworkDetails) SetCapellaForkVersion(capellaForkVersion string) (err error) {
+	domain, err := ComputeDomain(boostTypes.DomainTypeBeaconProposer, capellaForkVersion, e.GenesisValidatorsRootHex)
+	if err != nil {
+		return err
+	}
+	e.CapellaForkVersionHex = capellaForkVersion
+	e.DomainBeaconProposerCapella = domain
+	return nil
 }
 
 func (e *EthNetworkDetails) String() string {
-	return fmt.Sprintf("EthNetworkDetails{Name: %s, GenesisForkVersionHex: %s, GenesisValidatorsRootHex: %s, BellatrixForkVersionHex: %s, CapellaForkVersionHex: %s, DomainBuilder: %x, DomainBeaconProposerBellatrix: %x, DomainBeaconProposerCapella: %x}",
-		e.Name, e.GenesisForkVersionHex, e.GenesisValidatorsRootHex, e.BellatrixForkVersionHex, e.CapellaForkVersionHex, e.DomainBuilder, e.DomainBeaconProposerBellatrix, e.DomainBeaconProposerCapella)
+	return fmt.Sprintf("EthNetworkDetails{Name: %s, GenesisForkVersionHex: %s, GenesisValidatorsRootHex: %s, BellatrixForkVersionHex: %s, CapellaForkVersionHex: %s, DenebForkVersionHex: %s, DomainBuilder: %x, DomainBeaconProposerBellatrix: %x, DomainBeaconProposerCapella: %x, DomainBeaconProposerDeneb: %x}",
+		e.Name, e.GenesisForkVersionHex, e.GenesisValidatorsRootHex, e.BellatrixForkVersionHex, e.CapellaForkVersionHex, e.DenebForkVersionHex, e.DomainBuilder, e.DomainBeaconProposerBellatrix, e.DomainBeaconProposerCapella, e.DomainBeaconProposerDeneb)
 }
 
 type BuilderGetValidatorsResponseEntry struct {
@@ -236,6 +313,17 @@ type BidTraceV2WithTimestampJSON struct {
 	OptimisticSubmission bool  `json:"optimistic_submission"`
 }
 
+// BidHistoryEntry is a bid in the bid history of a slot, which keeps the bids received in the slot in order
+type BidHistoryEntry struct {
+	Slot           uint64 `json:"slot,string"`
+	ParentHash     string `json:"parent_hash"`
+	BlockHash      string `json:"block_hash"`
+	BuilderPubkey  string `json:"builder_pubkey"`
+	ProposerPubkey string `json:"proposer_pubkey"`
+	Value          string `json:"value"`
+	TimestampMs    int64  `json:"timestamp_ms,string"`
+}
+
 func (b *BidTraceV2WithTimestampJSON) CSVHeader() []string {
 	return []string{
 		"slot",
@@ -277,9 +365,13 @@ func (b *BidTraceV2WithTimestampJSON) ToCSVRecord() []string {
 type SignedBlindedBeaconBlock struct {
 	Bellatrix *boostTypes.SignedBlindedBeaconBlock
 	Capella   *apiv1capella.SignedBlindedBeaconBlock
+	Deneb     *apiv1deneb.SignedBlindedBeaconBlock
 }
 
 func (s *SignedBlindedBeaconBlock) MarshalJSON() ([]byte, error) {
+	if s.Deneb != nil {
+		return json.Marshal(s.Deneb)
+	}
 	if s.Capella != nil {
 		return json.Marshal(s.Capella)
 	}
@@ -290,6 +382,9 @@ func (s *SignedBlindedBeaconBlock) MarshalJSON() ([]byte, error) {
 }
 
 func (s *SignedBlindedBeaconBlock) Slot() uint64 {
+	if s.Deneb != nil {
+		return uint64(s.Deneb.Message.Slot)
+	}
 	if s.Capella != nil {
 		return uint64(s.Capella.Message.Slot)
 	}
@@ -300,6 +395,9 @@ func (s *SignedBlindedBeaconBlock) Slot() uint64 {
 }
 
 func (s *SignedBlindedBeaconBlock) BlockHash() string {
+	if s.Deneb != nil {
+		return s.Deneb.Message.Body.ExecutionPayloadHeader.BlockHash.String()
+	}
 	if s.Capella != nil {
 		return s.Capella.Message.Body.ExecutionPayloadHeader.BlockHash.String()
 	}
@@ -310,6 +408,9 @@ func (s *SignedBlindedBeaconBlock) BlockHash() string {
 }
 
 func (s *SignedBlindedBeaconBlock) BlockNumber() uint64 {
+	if s.Deneb != nil {
+		return s.Deneb.Message.Body.ExecutionPayloadHeader.BlockNumber
+	}
 	if s.Capella != nil {
 		return s.Capella.Message.Body.ExecutionPayloadHeader.BlockNumber
 	}
@@ -320,6 +421,9 @@ func (s *SignedBlindedBeaconBlock) BlockNumber() uint64 {
 }
 
 func (s *SignedBlindedBeaconBlock) ProposerIndex() uint64 {
+	if s.Deneb != nil {
+		return uint64(s.Deneb.Message.ProposerIndex)
+	}
 	if s.Capella != nil {
 		return uint64(s.Capella.Message.ProposerIndex)
 	}
@@ -330,6 +434,9 @@ func (s *SignedBlindedBeaconBlock) ProposerIndex() uint64 {
 }
 
 func (s *SignedBlindedBeaconBlock) Signature() []byte {
+	if s.Deneb != nil {
+		return s.Deneb.Signature[:]
+	}
 	if s.Capella != nil {
 		return s.Capella.Signature[:]
 	}
@@ -341,6 +448,9 @@ func (s *SignedBlindedBeaconBlock) Signature() []byte {
 
 //nolint:nolintlint,ireturn
 func (s *SignedBlindedBeaconBlock) Message() boostTypes.HashTreeRoot {
+	if s.Deneb != nil {
+		return s.Deneb.Message
+	}
 	if s.Capella != nil {
 		return s.Capella.Message
 	}
@@ -353,9 +463,13 @@ func (s *SignedBlindedBeaconBlock) Message() boostTypes.HashTreeRoot {
 type SignedBeaconBlock struct {
 	Bellatrix *boostTypes.SignedBeaconBlock
 	Capella   *consensuscapella.SignedBeaconBlock
+	Deneb     *DenebSignedBlockContents
 }
 
 func (s *SignedBeaconBlock) MarshalJSON() ([]byte, error) {
+	if s.Deneb != nil {
+		return json.Marshal(s.Deneb)
+	}
 	if s.Capella != nil {
 		return json.Marshal(s.Capella)
 	}
@@ -366,6 +480,9 @@ func (s *SignedBeaconBlock) MarshalJSON() ([]byte, error) {
 }
 
 func (s *SignedBeaconBlock) Slot() uint64 {
+	if s.Deneb != nil {
+		return uint64(s.Deneb.SignedBlock.Message.Slot)
+	}
 	if s.Capella != nil {
 		return uint64(s.Capella.Message.Slot)
 	}
@@ -376,6 +493,9 @@ func (s *SignedBeaconBlock) Slot() uint64 {
 }
 
 func (s *SignedBeaconBlock) BlockHash() string {
+	if s.Deneb != nil {
+		return s.Deneb.SignedBlock.Message.Body.ExecutionPayload.BlockHash.String()
+	}
 	if s.Capella != nil {
 		return s.Capella.Message.Body.ExecutionPayload.BlockHash.String()
 	}
@@ -388,9 +508,13 @@ func (s *SignedBeaconBlock) BlockHash() string {
 type VersionedExecutionPayload struct {
 	Bellatrix *boostTypes.GetPayloadResponse
 	Capella   *api.VersionedExecutionPayload
+	Deneb     *DenebExecutionPayloadAndBlobsBundle
 }
 
 func (e *VersionedExecutionPayload) MarshalJSON() ([]byte, error) {
+	if e.Deneb != nil {
+		return marshalVersioned(ForkVersionStringDeneb, e.Deneb)
+	}
 	if e.Capella != nil {
 		return json.Marshal(e.Capella)
 	}
@@ -402,6 +526,15 @@ func (e *VersionedExecutionPayload) MarshalJSON() ([]byte, error) {
 }
 
 func (e *VersionedExecutionPayload) UnmarshalJSON(data []byte) error {
+	deneb := new(DenebExecutionPayloadAndBlobsBundle)
+	if isDeneb, err := unmarshalDeneb(data, deneb); isDeneb {
+		if err != nil {
+			return err
+		}
+		e.Deneb = deneb
+		return nil
+	}
+
 	capella := new(api.VersionedExecutionPayload)
 	err := json.Unmarshal(data, capella)
 	if err == nil && capella.Capella != nil {
@@ -418,6 +551,9 @@ func (e *VersionedExecutionPayload) UnmarshalJSON(data []byte) error {
 }
 
 func (e *VersionedExecutionPayload) NumTx() int {
+	if e.Deneb != nil {
+		return len(e.Deneb.ExecutionPayload.Transactions)
+	}
 	if e.Capella != nil {
 		return len(e.Capella.Capella.Transactions)
 	}
@@ -427,12 +563,29 @@ func (e *VersionedExecutionPayload) NumTx() int {
 	return 0
 }
 
+func (e *VersionedExecutionPayload) FeeRecipient() string {
+	if e.Deneb != nil {
+		return e.Deneb.ExecutionPayload.FeeRecipient.String()
+	}
+	if e.Capella != nil {
+		return e.Capella.Capella.FeeRecipient.String()
+	}
+	if e.Bellatrix != nil {
+		return e.Bellatrix.Data.FeeRecipient.String()
+	}
+	return ""
+}
+
 type BuilderSubmitBlockRequest struct {
 	Bellatrix *boostTypes.BuilderSubmitBlockRequest
 	Capella   *capella.SubmitBlockRequest
+	Deneb     *DenebSubmitBlockRequest
 }
 
 func (b *BuilderSubmitBlockRequest) MarshalJSON() ([]byte, error) {
+	if b.Deneb != nil {
+		return json.Marshal(b.Deneb)
+	}
 	if b.Capella != nil {
 		return json.Marshal(b.Capella)
 	}
@@ -443,8 +596,15 @@ func (b *BuilderSubmitBlockRequest) MarshalJSON() ([]byte, error) {
 }
 
 func (b *BuilderSubmitBlockRequest) UnmarshalJSON(data []byte) error {
+	// Deneb first: a capella payload ignores the additional fields, but the deneb payload requires them
+	deneb := new(DenebSubmitBlockRequest)
+	err := json.Unmarshal(data, deneb)
+	if err == nil {
+		b.Deneb = deneb
+		return nil
+	}
 	capella := new(capella.SubmitBlockRequest)
-	err := json.Unmarshal(data, capella)
+	err = json.Unmarshal(data, capella)
 	if err == nil {
 		b.Capella = capella
 		return nil
@@ -459,6 +619,9 @@ func (b *BuilderSubmitBlockRequest) UnmarshalJSON(data []byte) error {
 }
 
 func (b *BuilderSubmitBlockRequest) HasExecutionPayload() bool {
+	if b.Deneb != nil {
+		return b.Deneb.ExecutionPayload != nil
+	}
 	if b.Capella != nil {
 		return b.Capella.ExecutionPayload != nil
 	}
@@ -469,6 +632,17 @@ func (b *BuilderSubmitBlockRequest) HasExecutionPayload() bool {
 }
 
 func (b *BuilderSubmitBlockRequest) ExecutionPayloadResponse() (*GetPayloadResponse, error) {
+	if b.Deneb != nil {
+		return &GetPayloadResponse{
+			Bellatrix: nil,
+			Capella:   nil,
+			Deneb: &DenebExecutionPayloadAndBlobsBundle{
+				ExecutionPayload: b.Deneb.ExecutionPayload,
+				BlobsBundle:      b.Deneb.BlobsBundle,
+			},
+		}, nil
+	}
+
 	if b.Bellatrix != nil {
 		return &GetPayloadResponse{
 			Bellatrix: &boostTypes.GetPayloadResponse{
@@ -494,6 +668,9 @@ func (b *BuilderSubmitBlockRequest) ExecutionPayloadResponse() (*GetPayloadRespo
 }
 
 func (b *BuilderSubmitBlockRequest) Slot() uint64 {
+	if b.Deneb != nil {
+		return b.Deneb.Message.Slot
+	}
 	if b.Capella != nil {
 		return b.Capella.Message.Slot
 	}
@@ -504,6 +681,9 @@ func (b *BuilderSubmitBlockRequest) Slot() uint64 {
 }
 
 func (b *BuilderSubmitBlockRequest) BlockHash() string {
+	if b.Deneb != nil {
+		return b.Deneb.Message.BlockHash.String()
+	}
 	if b.Capella != nil {
 		return b.Capella.Message.BlockHash.String()
 	}
@@ -514,6 +694,9 @@ func (b *BuilderSubmitBlockRequest) BlockHash() string {
 }
 
 func (b *BuilderSubmitBlockRequest) ExecutionPayloadBlockHash() string {
+	if b.Deneb != nil {
+		return b.Deneb.ExecutionPayload.BlockHash.String()
+	}
 	if b.Capella != nil {
 		return b.Capella.ExecutionPayload.BlockHash.String()
 	}
@@ -524,6 +707,9 @@ func (b *BuilderSubmitBlockRequest) ExecutionPayloadBlockHash() string {
 }
 
 func (b *BuilderSubmitBlockRequest) BuilderPubkey() phase0.BLSPubKey {
+	if b.Deneb != nil {
+		return b.Deneb.Message.BuilderPubkey
+	}
 	if b.Capella != nil {
 		return b.Capella.Message.BuilderPubkey
 	}
@@ -534,6 +720,9 @@ func (b *BuilderSubmitBlockRequest) BuilderPubkey() phase0.BLSPubKey {
 }
 
 func (b *BuilderSubmitBlockRequest) ProposerFeeRecipient() string {
+	if b.Deneb != nil {
+		return b.Deneb.Message.ProposerFeeRecipient.String()
+	}
 	if b.Capella != nil {
 		return b.Capella.Message.ProposerFeeRecipient.String()
 	}
@@ -544,6 +733,9 @@ func (b *BuilderSubmitBlockRequest) ProposerFeeRecipient() string {
 }
 
 func (b *BuilderSubmitBlockRequest) Timestamp() uint64 {
+	if b.Deneb != nil {
+		return b.Deneb.ExecutionPayload.Timestamp
+	}
 	if b.Capella != nil {
 		return b.Capella.ExecutionPayload.Timestamp
 	}
@@ -554,6 +746,9 @@ func (b *BuilderSubmitBlockRequest) Timestamp() uint64 {
 }
 
 func (b *BuilderSubmitBlockRequest) ProposerPubkey() string {
+	if b.Deneb != nil {
+		return b.Deneb.Message.ProposerPubkey.String()
+	}
 	if b.Capella != nil {
 		return b.Capella.Message.ProposerPubkey.String()
 	}
@@ -564,6 +759,9 @@ func (b *BuilderSubmitBlockRequest) ProposerPubkey() string {
 }
 
 func (b *BuilderSubmitBlockRequest) ParentHash() string {
+	if b.Deneb != nil {
+		return b.Deneb.Message.ParentHash.String()
+	}
 	if b.Capella != nil {
 		return b.Capella.Message.ParentHash.String()
 	}
@@ -574,6 +772,9 @@ func (b *BuilderSubmitBlockRequest) ParentHash() string {
 }
 
 func (b *BuilderSubmitBlockRequest) ExecutionPayloadParentHash() string {
+	if b.Deneb != nil {
+		return b.Deneb.ExecutionPayload.ParentHash.String()
+	}
 	if b.Capella != nil {
 		return b.Capella.ExecutionPayload.ParentHash.String()
 	}
@@ -584,6 +785,9 @@ func (b *BuilderSubmitBlockRequest) ExecutionPayloadParentHash() string {
 }
 
 func (b *BuilderSubmitBlockRequest) Value() *big.Int {
+	if b.Deneb != nil {
+		return b.Deneb.Message.Value.ToBig()
+	}
 	if b.Capella != nil {
 		return b.Capella.Message.Value.ToBig()
 	}
@@ -594,6 +798,9 @@ func (b *BuilderSubmitBlockRequest) Value() *big.Int {
 }
 
 func (b *BuilderSubmitBlockRequest) NumTx() int {
+	if b.Deneb != nil {
+		return len(b.Deneb.ExecutionPayload.Transactions)
+	}
 	if b.Capella != nil {
 		return len(b.Capella.ExecutionPayload.Transactions)
 	}
@@ -604,6 +811,9 @@ func (b *BuilderSubmitBlockRequest) NumTx() int {
 }
 
 func (b *BuilderSubmitBlockRequest) BlockNumber() uint64 {
+	if b.Deneb != nil {
+		return b.Deneb.ExecutionPayload.BlockNumber
+	}
 	if b.Capella != nil {
 		return b.Capella.ExecutionPayload.BlockNumber
 	}
@@ -614,6 +824,9 @@ func (b *BuilderSubmitBlockRequest) BlockNumber() uint64 {
 }
 
 func (b *BuilderSubmitBlockRequest) GasUsed() uint64 {
+	if b.Deneb != nil {
+		return b.Deneb.ExecutionPayload.GasUsed
+	}
 	if b.Capella != nil {
 		return b.Capella.ExecutionPayload.GasUsed
 	}
@@ -624,6 +837,9 @@ func (b *BuilderSubmitBlockRequest) GasUsed() uint64 {
 }
 
 func (b *BuilderSubmitBlockRequest) GasLimit() uint64 {
+	if b.Deneb != nil {
+		return b.Deneb.ExecutionPayload.GasLimit
+	}
 	if b.Capella != nil {
 		return b.Capella.ExecutionPayload.GasLimit
 	}
@@ -634,6 +850,9 @@ func (b *BuilderSubmitBlockRequest) GasLimit() uint64 {
 }
 
 func (b *BuilderSubmitBlockRequest) Signature() phase0.BLSSignature {
+	if b.Deneb != nil {
+		return b.Deneb.Signature
+	}
 	if b.Capella != nil {
 		return b.Capella.Signature
 	}
@@ -644,6 +863,9 @@ func (b *BuilderSubmitBlockRequest) Signature() phase0.BLSSignature {
 }
 
 func (b *BuilderSubmitBlockRequest) Random() string {
+	if b.Deneb != nil {
+		return fmt.Sprintf("%#x", b.Deneb.ExecutionPayload.PrevRandao)
+	}
 	if b.Capella != nil {
 		return fmt.Sprintf("%#x", b.Capella.ExecutionPayload.PrevRandao)
 	}
@@ -654,6 +876,9 @@ func (b *BuilderSubmitBlockRequest) Random() string {
 }
 
 func (b *BuilderSubmitBlockRequest) Message() *apiv1.BidTrace {
+	if b.Deneb != nil {
+		return b.Deneb.Message
+	}
 	if b.Capella != nil {
 		return b.Capella.Message
 	}
@@ -683,9 +908,19 @@ func BoostBidToBidTrace(bidTrace *boostTypes.BidTrace) *apiv1.BidTrace {
 type GetPayloadResponse struct {
 	Bellatrix *boostTypes.GetPayloadResponse
 	Capella   *api.VersionedExecutionPayload
+	Deneb     *DenebExecutionPayloadAndBlobsBundle
 }
 
 func (p *GetPayloadResponse) UnmarshalJSON(data []byte) error {
+	deneb := new(DenebExecutionPayloadAndBlobsBundle)
+	if isDeneb, err := unmarshalDeneb(data, deneb); isDeneb {
+		if err != nil {
+			return err
+		}
+		p.Deneb = deneb
+		return nil
+	}
+
 	capella := new(api.VersionedExecutionPayload)
 	err := json.Unmarshal(data, capella)
 	if err == nil && capella.Capella != nil {
@@ -702,6 +937,9 @@ func (p *GetPayloadResponse) UnmarshalJSON(data []byte) error {
 }
 
 func (p *GetPayloadResponse) MarshalJSON() ([]byte, error) {
+	if p.Deneb != nil {
+		return marshalVersioned(ForkVersionStringDeneb, p.Deneb)
+	}
 	if p.Bellatrix != nil {
 		return json.Marshal(p.Bellatrix)
 	}
@@ -714,9 +952,19 @@ func (p *GetPayloadResponse) MarshalJSON() ([]byte, error) {
 type GetHeaderResponse struct {
 	Bellatrix *boostTypes.GetHeaderResponse
 	Capella   *spec.VersionedSignedBuilderBid
+	Deneb     *DenebSignedBuilderBid
 }
 
 func (p *GetHeaderResponse) UnmarshalJSON(data []byte) error {
+	deneb := new(DenebSignedBuilderBid)
+	if isDeneb, err := unmarshalDeneb(data, deneb); isDeneb {
+		if err != nil {
+			return err
+		}
+		p.Deneb = deneb
+		return nil
+	}
+
 	capella := new(spec.VersionedSignedBuilderBid)
 	err := json.Unmarshal(data, capella)
 	if err == nil && capella.Capella != nil {
@@ -733,6 +981,9 @@ func (p *GetHeaderResponse) UnmarshalJSON(data []byte) error {
 }
 
 func (p *GetHeaderResponse) MarshalJSON() ([]byte, error) {
+	if p.Deneb != nil {
+		return marshalVersioned(ForkVersionStringDeneb, p.Deneb)
+	}
 	if p.Capella != nil {
 		return json.Marshal(p.Capella)
 	}
@@ -743,6 +994,9 @@ func (p *GetHeaderResponse) MarshalJSON() ([]byte, error) {
 }
 
 func (p *GetHeaderResponse) Value() *big.Int {
+	if p.Deneb != nil {
+		return p.Deneb.Message.Value.ToBig()
+	}
 	if p.Capella != nil {
 		return p.Capella.Capella.Message.Value.ToBig()
 	}
@@ -753,6 +1007,9 @@ func (p *GetHeaderResponse) Value() *big.Int {
 }
 
 func (p *GetHeaderResponse) BlockHash() phase0.Hash32 {
+	if p.Deneb != nil {
+		return p.Deneb.Message.Header.BlockHash
+	}
 	if p.Capella != nil {
 		return p.Capella.Capella.Message.Header.BlockHash
 	}
@@ -766,6 +1023,9 @@ func (p *GetHeaderResponse) Empty() bool {
 	if p == nil {
 		return true
 	}
+	if p.Deneb != nil {
+		return p.Deneb.Message == nil
+	}
 	if p.Capella != nil {
 		return p.Capella.Capella == nil || p.Capella.Capella.Message == nil
 	}
@@ -776,6 +1036,9 @@ func (p *GetHeaderResponse) Empty() bool {
 }
 
 func (b *BuilderSubmitBlockRequest) Withdrawals() []*consensuscapella.Withdrawal {
+	if b.Deneb != nil {
+		return b.Deneb.ExecutionPayload.Withdrawals
+	}
 	if b.Capella != nil {
 		return b.Capella.ExecutionPayload.Withdrawals
 	}
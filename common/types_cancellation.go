@@ -0,0 +1,54 @@
+package common
+
+import (
+	ssz "github.com/ferranbt/fastssz"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+)
+
+// BuilderBidCancellation asks the relay to stop serving the builder's latest bid for a slot, parentHash and proposer
+type BuilderBidCancellation struct {
+	Slot           uint64               `json:"slot,string"`
+	ParentHash     boostTypes.Hash      `json:"parent_hash"`
+	ProposerPubkey boostTypes.PublicKey `json:"proposer_pubkey"`
+	BuilderPubkey  boostTypes.PublicKey `json:"builder_pubkey"`
+	BlockHash      boostTypes.Hash      `json:"block_hash"`
+}
+
+// SignedBuilderBidCancellation is signed by the builder, with the builder domain
+type SignedBuilderBidCancellation struct {
+	Message   *BuilderBidCancellation `json:"message"`
+	Signature boostTypes.Signature    `json:"signature"`
+}
+
+// HashTreeRoot ssz hashes the BuilderBidCancellation object, to sign it
+func (c *BuilderBidCancellation) HashTreeRoot() ([32]byte, error) {
+	return ssz.HashWithDefaultHasher(c)
+}
+
+// HashTreeRootWith ssz hashes the BuilderBidCancellation object with a hasher
+func (c *BuilderBidCancellation) HashTreeRootWith(hh ssz.HashWalker) (err error) {
+	indx := hh.Index()
+
+	// Field (0) 'Slot'
+	hh.PutUint64(c.Slot)
+
+	// Field (1) 'ParentHash'
+	hh.PutBytes(c.ParentHash[:])
+
+	// Field (2) 'ProposerPubkey'
+	hh.PutBytes(c.ProposerPubkey[:])
+
+	// Field (3) 'BuilderPubkey'
+	hh.PutBytes(c.BuilderPubkey[:])
+
+	// Field (4) 'BlockHash'
+	hh.PutBytes(c.BlockHash[:])
+
+	hh.Merkleize(indx)
+	return nil
+}
+
+// GetTree ssz hashes the BuilderBidCancellation object
+func (c *BuilderBidCancellation) GetTree() (*ssz.Node, error) {
+	return ssz.ProofTree(c)
+}
@@ -0,0 +1,331 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	apiv1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ssz "github.com/ferranbt/fastssz"
+	"github.com/holiman/uint256"
+)
+
+// Deneb types of the builder API (https://github.com/ethereum/builder-specs), which go-builder-client doesn't have yet
+
+// MaxBlobsPerBlock is MAX_BLOBS_PER_BLOCK of the Deneb consensus spec
+const MaxBlobsPerBlock = 6
+
+var (
+	ErrMissingField        = errors.New("missing field")
+	ErrInvalidFieldLength  = errors.New("incorrect length")
+	ErrBlobsBundleMismatch = errors.New("blobs bundle needs one commitment and one proof per blob")
+	ErrTooManyBlobs        = errors.New("too many blobs")
+	ErrInvalidValue        = errors.New("invalid value")
+)
+
+// BlobsBundle holds the blobs of a Deneb execution payload, with their KZG commitments and proofs
+type BlobsBundle struct {
+	Commitments []deneb.KzgCommitment
+	Proofs      []deneb.KzgProof
+	Blobs       []deneb.Blob
+}
+
+type blobsBundleJSON struct {
+	Commitments []hexutil.Bytes `json:"commitments"`
+	Proofs      []hexutil.Bytes `json:"proofs"`
+	Blobs       []hexutil.Bytes `json:"blobs"`
+}
+
+func (b *BlobsBundle) MarshalJSON() ([]byte, error) {
+	data := blobsBundleJSON{
+		Commitments: make([]hexutil.Bytes, len(b.Commitments)),
+		Proofs:      make([]hexutil.Bytes, len(b.Proofs)),
+		Blobs:       make([]hexutil.Bytes, len(b.Blobs)),
+	}
+	for i := range b.Commitments {
+		data.Commitments[i] = b.Commitments[i][:]
+	}
+	for i := range b.Proofs {
+		data.Proofs[i] = b.Proofs[i][:]
+	}
+	for i := range b.Blobs {
+		data.Blobs[i] = b.Blobs[i][:]
+	}
+	return json.Marshal(data)
+}
+
+func (b *BlobsBundle) UnmarshalJSON(input []byte) error {
+	var data blobsBundleJSON
+	if err := json.Unmarshal(input, &data); err != nil {
+		return err
+	}
+	if data.Commitments == nil || data.Proofs == nil || data.Blobs == nil {
+		return fmt.Errorf("%w: blobs bundle commitments, proofs or blobs", ErrMissingField)
+	}
+
+	b.Commitments = make([]deneb.KzgCommitment, len(data.Commitments))
+	for i := range data.Commitments {
+		if err := copyFixedLength(b.Commitments[i][:], data.Commitments[i], "commitment"); err != nil {
+			return err
+		}
+	}
+	b.Proofs = make([]deneb.KzgProof, len(data.Proofs))
+	for i := range data.Proofs {
+		if err := copyFixedLength(b.Proofs[i][:], data.Proofs[i], "proof"); err != nil {
+			return err
+		}
+	}
+	b.Blobs = make([]deneb.Blob, len(data.Blobs))
+	for i := range data.Blobs {
+		if err := copyFixedLength(b.Blobs[i][:], data.Blobs[i], "blob"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that there is one commitment and one proof per blob, and not more blobs than fit into a block
+func (b *BlobsBundle) Validate() error {
+	if len(b.Commitments) != len(b.Blobs) || len(b.Proofs) != len(b.Blobs) {
+		return fmt.Errorf("%w: %d commitments, %d proofs, %d blobs", ErrBlobsBundleMismatch, len(b.Commitments), len(b.Proofs), len(b.Blobs))
+	}
+	if len(b.Blobs) > MaxBlobsPerBlock {
+		return fmt.Errorf("%w: %d, maximum is %d", ErrTooManyBlobs, len(b.Blobs), MaxBlobsPerBlock)
+	}
+	return nil
+}
+
+func copyFixedLength(dst, src []byte, name string) error {
+	if len(src) != len(dst) {
+		return fmt.Errorf("%w for %s: %d, expected %d", ErrInvalidFieldLength, name, len(src), len(dst))
+	}
+	copy(dst, src)
+	return nil
+}
+
+// DenebSubmitBlockRequest is a block submission of a builder for a Deneb slot
+type DenebSubmitBlockRequest struct {
+	Message          *apiv1.BidTrace
+	ExecutionPayload *deneb.ExecutionPayload
+	BlobsBundle      *BlobsBundle
+	Signature        phase0.BLSSignature
+}
+
+type denebSubmitBlockRequestJSON struct {
+	Message          *apiv1.BidTrace         `json:"message"`
+	ExecutionPayload *deneb.ExecutionPayload `json:"execution_payload"`
+	BlobsBundle      *BlobsBundle            `json:"blobs_bundle"`
+	Signature        hexutil.Bytes           `json:"signature"`
+}
+
+func (s *DenebSubmitBlockRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&denebSubmitBlockRequestJSON{
+		Message:          s.Message,
+		ExecutionPayload: s.ExecutionPayload,
+		BlobsBundle:      s.BlobsBundle,
+		Signature:        s.Signature[:],
+	})
+}
+
+func (s *DenebSubmitBlockRequest) UnmarshalJSON(input []byte) error {
+	var data denebSubmitBlockRequestJSON
+	if err := json.Unmarshal(input, &data); err != nil {
+		return err
+	}
+	if data.Message == nil || data.ExecutionPayload == nil || data.BlobsBundle == nil {
+		return fmt.Errorf("%w: message, execution payload or blobs bundle", ErrMissingField)
+	}
+	s.Message = data.Message
+	s.ExecutionPayload = data.ExecutionPayload
+	s.BlobsBundle = data.BlobsBundle
+	return copyFixedLength(s.Signature[:], data.Signature, "signature")
+}
+
+// DenebBuilderBid is the bid served in getHeader for a Deneb slot
+type DenebBuilderBid struct {
+	Header             *deneb.ExecutionPayloadHeader
+	BlobKzgCommitments []deneb.KzgCommitment
+	Value              *uint256.Int
+	Pubkey             phase0.BLSPubKey
+}
+
+type denebBuilderBidJSON struct {
+	Header             *deneb.ExecutionPayloadHeader `json:"header"`
+	BlobKzgCommitments []hexutil.Bytes               `json:"blob_kzg_commitments"`
+	Value              string                        `json:"value"`
+	Pubkey             hexutil.Bytes                 `json:"pubkey"`
+}
+
+func (b *DenebBuilderBid) MarshalJSON() ([]byte, error) {
+	commitments := make([]hexutil.Bytes, len(b.BlobKzgCommitments))
+	for i := range b.BlobKzgCommitments {
+		commitments[i] = b.BlobKzgCommitments[i][:]
+	}
+	return json.Marshal(&denebBuilderBidJSON{
+		Header:             b.Header,
+		BlobKzgCommitments: commitments,
+		Value:              b.Value.Dec(),
+		Pubkey:             b.Pubkey[:],
+	})
+}
+
+func (b *DenebBuilderBid) UnmarshalJSON(input []byte) error {
+	var data denebBuilderBidJSON
+	if err := json.Unmarshal(input, &data); err != nil {
+		return err
+	}
+	if data.Header == nil || data.BlobKzgCommitments == nil || data.Value == "" {
+		return fmt.Errorf("%w: header, blob kzg commitments or value", ErrMissingField)
+	}
+	b.Header = data.Header
+
+	b.BlobKzgCommitments = make([]deneb.KzgCommitment, len(data.BlobKzgCommitments))
+	for i := range data.BlobKzgCommitments {
+		if err := copyFixedLength(b.BlobKzgCommitments[i][:], data.BlobKzgCommitments[i], "commitment"); err != nil {
+			return err
+		}
+	}
+
+	value, ok := new(big.Int).SetString(data.Value, 10)
+	if !ok || value.Sign() < 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidValue, data.Value)
+	}
+	var overflow bool
+	b.Value, overflow = uint256.FromBig(value)
+	if overflow {
+		return fmt.Errorf("%w: %s", ErrInvalidValue, data.Value)
+	}
+	return copyFixedLength(b.Pubkey[:], data.Pubkey, "pubkey")
+}
+
+// HashTreeRoot ssz hashes the DenebBuilderBid object, to sign it
+func (b *DenebBuilderBid) HashTreeRoot() ([32]byte, error) {
+	return ssz.HashWithDefaultHasher(b)
+}
+
+// HashTreeRootWith ssz hashes the DenebBuilderBid object with a hasher
+func (b *DenebBuilderBid) HashTreeRootWith(hh ssz.HashWalker) (err error) {
+	indx := hh.Index()
+
+	// Field (0) 'Header'
+	if err = b.Header.HashTreeRootWith(hh); err != nil {
+		return err
+	}
+
+	// Field (1) 'BlobKzgCommitments'
+	if size := len(b.BlobKzgCommitments); size > 4096 {
+		return ssz.ErrListTooBigFn("DenebBuilderBid.BlobKzgCommitments", size, 4096)
+	}
+	subIndx := hh.Index()
+	for _, commitment := range b.BlobKzgCommitments {
+		hh.PutBytes(commitment[:])
+	}
+	hh.MerkleizeWithMixin(subIndx, uint64(len(b.BlobKzgCommitments)), 4096)
+
+	// Field (2) 'Value' (little endian)
+	value := b.Value.Bytes32()
+	for i, j := 0, 31; i < j; i, j = i+1, j-1 {
+		value[i], value[j] = value[j], value[i]
+	}
+	hh.PutBytes(value[:])
+
+	// Field (3) 'Pubkey'
+	hh.PutBytes(b.Pubkey[:])
+
+	hh.Merkleize(indx)
+	return nil
+}
+
+// GetTree ssz hashes the DenebBuilderBid object
+func (b *DenebBuilderBid) GetTree() (*ssz.Node, error) {
+	return ssz.ProofTree(b)
+}
+
+type DenebSignedBuilderBid struct {
+	Message   *DenebBuilderBid
+	Signature phase0.BLSSignature
+}
+
+type denebSignedBuilderBidJSON struct {
+	Message   *DenebBuilderBid `json:"message"`
+	Signature hexutil.Bytes    `json:"signature"`
+}
+
+func (s *DenebSignedBuilderBid) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&denebSignedBuilderBidJSON{
+		Message:   s.Message,
+		Signature: s.Signature[:],
+	})
+}
+
+func (s *DenebSignedBuilderBid) UnmarshalJSON(input []byte) error {
+	var data denebSignedBuilderBidJSON
+	if err := json.Unmarshal(input, &data); err != nil {
+		return err
+	}
+	if data.Message == nil {
+		return fmt.Errorf("%w: message", ErrMissingField)
+	}
+	s.Message = data.Message
+	return copyFixedLength(s.Signature[:], data.Signature, "signature")
+}
+
+// DenebExecutionPayloadAndBlobsBundle is the getPayload response for a Deneb slot
+type DenebExecutionPayloadAndBlobsBundle struct {
+	ExecutionPayload *deneb.ExecutionPayload `json:"execution_payload"`
+	BlobsBundle      *BlobsBundle            `json:"blobs_bundle"`
+}
+
+// DenebSignedBlockContents is the Deneb block with its blobs, as published to the beacon node
+type DenebSignedBlockContents struct {
+	SignedBlock *deneb.SignedBeaconBlock
+	KzgProofs   []deneb.KzgProof
+	Blobs       []deneb.Blob
+}
+
+func (c *DenebSignedBlockContents) MarshalJSON() ([]byte, error) {
+	proofs := make([]hexutil.Bytes, len(c.KzgProofs))
+	for i := range c.KzgProofs {
+		proofs[i] = c.KzgProofs[i][:]
+	}
+	blobs := make([]hexutil.Bytes, len(c.Blobs))
+	for i := range c.Blobs {
+		blobs[i] = c.Blobs[i][:]
+	}
+	return json.Marshal(&struct {
+		SignedBlock *deneb.SignedBeaconBlock `json:"signed_block"`
+		KzgProofs   []hexutil.Bytes          `json:"kzg_proofs"`
+		Blobs       []hexutil.Bytes          `json:"blobs"`
+	}{
+		SignedBlock: c.SignedBlock,
+		KzgProofs:   proofs,
+		Blobs:       blobs,
+	})
+}
+
+// versionedJSON is the {"version": ..., "data": ...} envelope of the builder API responses
+type versionedJSON struct {
+	Version string          `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func marshalVersioned(version string, data any) ([]byte, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&versionedJSON{Version: version, Data: b})
+}
+
+// unmarshalDeneb decodes the data of a versioned response if its version is deneb, and returns false otherwise
+func unmarshalDeneb(input []byte, data any) (bool, error) {
+	var versioned versionedJSON
+	if err := json.Unmarshal(input, &versioned); err != nil || versioned.Version != ForkVersionStringDeneb {
+		return false, nil //nolint:nilerr
+	}
+	return true, json.Unmarshal(versioned.Data, data)
+}
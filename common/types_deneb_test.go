@@ -0,0 +1,104 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/attestantio/go-builder-client/api/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/stretchr/testify/require"
+)
+
+func loadDenebSubmitBlockRequest(t *testing.T, numBlobs int) *DenebSubmitBlockRequest {
+	t.Helper()
+	capellaReq := new(capella.SubmitBlockRequest)
+	LoadGzippedJSON(t, "../testdata/submitBlockPayloadCapella_Goerli.json.gz", capellaReq)
+	return CapellaToDenebSubmitBlockRequest(capellaReq, numBlobs)
+}
+
+func TestDenebSubmitBlockRequestJSON(t *testing.T) {
+	// A capella submission is not mistaken for a deneb one
+	req := new(BuilderSubmitBlockRequest)
+	LoadGzippedJSON(t, "../testdata/submitBlockPayloadCapella_Goerli.json.gz", req)
+	require.NotNil(t, req.Capella)
+	require.Nil(t, req.Deneb)
+
+	denebReq := loadDenebSubmitBlockRequest(t, 2)
+	denebReq.BlobsBundle.Commitments[1][0] = 0x01
+	denebReq.BlobsBundle.Blobs[1][100] = 0x02
+	b, err := json.Marshal(denebReq)
+	require.NoError(t, err)
+
+	req = new(BuilderSubmitBlockRequest)
+	require.NoError(t, json.Unmarshal(b, req))
+	require.Nil(t, req.Capella)
+	require.Equal(t, denebReq, req.Deneb)
+	require.Equal(t, denebReq.Message.Slot, req.Slot())
+	require.Equal(t, denebReq.ExecutionPayload.BlockHash.String(), req.ExecutionPayloadBlockHash())
+
+	// The blobs bundle is required
+	b, err = json.Marshal(&DenebSubmitBlockRequest{Message: denebReq.Message, ExecutionPayload: denebReq.ExecutionPayload, Signature: denebReq.Signature}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.ErrorIs(t, json.Unmarshal(b, new(DenebSubmitBlockRequest)), ErrMissingField)
+}
+
+func TestBlobsBundleValidate(t *testing.T) {
+	bundle := &BlobsBundle{
+		Commitments: make([]deneb.KzgCommitment, 2),
+		Proofs:      make([]deneb.KzgProof, 2),
+		Blobs:       make([]deneb.Blob, 2),
+	}
+	require.NoError(t, bundle.Validate())
+
+	bundle.Proofs = bundle.Proofs[:1]
+	require.ErrorIs(t, bundle.Validate(), ErrBlobsBundleMismatch)
+
+	bundle = &BlobsBundle{
+		Commitments: make([]deneb.KzgCommitment, MaxBlobsPerBlock+1),
+		Proofs:      make([]deneb.KzgProof, MaxBlobsPerBlock+1),
+		Blobs:       make([]deneb.Blob, MaxBlobsPerBlock+1),
+	}
+	require.ErrorIs(t, bundle.Validate(), ErrTooManyBlobs)
+}
+
+func TestDenebGetHeaderAndPayloadResponse(t *testing.T) {
+	sk, blsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkey, err := boostTypes.BlsPublicKeyToPublicKey(blsPubkey)
+	require.NoError(t, err)
+
+	denebReq := loadDenebSubmitBlockRequest(t, 1)
+	denebReq.BlobsBundle.Commitments[0][0] = 0x01
+	req := &BuilderSubmitBlockRequest{Deneb: denebReq} //nolint:exhaustruct
+
+	// The bid is signed, and commits to the blobs
+	getHeaderResp, err := BuildGetHeaderResponse(req, sk, &pubkey, boostTypes.DomainBuilder)
+	require.NoError(t, err)
+	b, err := json.Marshal(getHeaderResp)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"version":"deneb"`)
+
+	decodedHeaderResp := new(GetHeaderResponse)
+	require.NoError(t, json.Unmarshal(b, decodedHeaderResp))
+	require.NotNil(t, decodedHeaderResp.Deneb)
+	require.Equal(t, req.Value(), decodedHeaderResp.Value())
+	require.Equal(t, denebReq.ExecutionPayload.BlockHash, decodedHeaderResp.BlockHash())
+	bid := decodedHeaderResp.Deneb.Message
+	require.Equal(t, denebReq.BlobsBundle.Commitments, bid.BlobKzgCommitments)
+	ok, err := boostTypes.VerifySignature(bid, boostTypes.DomainBuilder, pubkey[:], decodedHeaderResp.Deneb.Signature[:])
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The payload is delivered with its blobs
+	getPayloadResp, err := BuildGetPayloadResponse(req)
+	require.NoError(t, err)
+	b, err = json.Marshal(getPayloadResp)
+	require.NoError(t, err)
+	decodedPayloadResp := new(VersionedExecutionPayload)
+	require.NoError(t, json.Unmarshal(b, decodedPayloadResp))
+	require.NotNil(t, decodedPayloadResp.Deneb)
+	require.Equal(t, denebReq.BlobsBundle, decodedPayloadResp.Deneb.BlobsBundle)
+	require.Equal(t, req.NumTx(), decodedPayloadResp.NumTx())
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/attestantio/go-builder-client/spec"
 	consensusspec "github.com/attestantio/go-eth2-client/spec"
 	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	utilbellatrix "github.com/attestantio/go-eth2-client/util/bellatrix"
 	utilcapella "github.com/attestantio/go-eth2-client/util/capella"
@@ -68,6 +69,18 @@ func BuildGetHeaderResponse(payload *BuilderSubmitBlockRequest, sk *bls.SecretKe
 			Bellatrix: nil,
 		}, nil
 	}
+
+	if payload.Deneb != nil {
+		signedBuilderBid, err := DenebBuilderSubmitBlockRequestToSignedBuilderBid(payload.Deneb, sk, (*phase0.BLSPubKey)(pubkey), domain)
+		if err != nil {
+			return nil, err
+		}
+		return &GetHeaderResponse{
+			Deneb:     signedBuilderBid,
+			Capella:   nil,
+			Bellatrix: nil,
+		}, nil
+	}
 	return nil, ErrEmptyPayload
 }
 
@@ -93,6 +106,17 @@ func BuildGetPayloadResponse(payload *BuilderSubmitBlockRequest) (*GetPayloadRes
 		}, nil
 	}
 
+	if payload.Deneb != nil {
+		return &GetPayloadResponse{
+			Deneb: &DenebExecutionPayloadAndBlobsBundle{
+				ExecutionPayload: payload.Deneb.ExecutionPayload,
+				BlobsBundle:      payload.Deneb.BlobsBundle,
+			},
+			Capella:   nil,
+			Bellatrix: nil,
+		}, nil
+	}
+
 	return nil, ErrEmptyPayload
 }
 
@@ -178,11 +202,102 @@ func CapellaPayloadToPayloadHeader(p *consensuscapella.ExecutionPayload) (*conse
 	}, nil
 }
 
+func DenebBuilderSubmitBlockRequestToSignedBuilderBid(req *DenebSubmitBlockRequest, sk *bls.SecretKey, pubkey *phase0.BLSPubKey, domain boostTypes.Domain) (*DenebSignedBuilderBid, error) {
+	header, err := DenebPayloadToPayloadHeader(req.ExecutionPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	builderBid := DenebBuilderBid{
+		Header:             header,
+		BlobKzgCommitments: req.BlobsBundle.Commitments,
+		Value:              req.Message.Value,
+		Pubkey:             *pubkey,
+	}
+
+	sig, err := boostTypes.SignMessage(&builderBid, domain, sk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DenebSignedBuilderBid{
+		Message:   &builderBid,
+		Signature: phase0.BLSSignature(sig),
+	}, nil
+}
+
+func DenebPayloadToPayloadHeader(p *deneb.ExecutionPayload) (*deneb.ExecutionPayloadHeader, error) {
+	if p == nil {
+		return nil, ErrEmptyPayload
+	}
+
+	transactions := utilbellatrix.ExecutionPayloadTransactions{Transactions: p.Transactions}
+	transactionsRoot, err := transactions.HashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawals := utilcapella.ExecutionPayloadWithdrawals{Withdrawals: p.Withdrawals}
+	withdrawalsRoot, err := withdrawals.HashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &deneb.ExecutionPayloadHeader{
+		ParentHash:       p.ParentHash,
+		FeeRecipient:     p.FeeRecipient,
+		StateRoot:        p.StateRoot,
+		ReceiptsRoot:     p.ReceiptsRoot,
+		LogsBloom:        p.LogsBloom,
+		PrevRandao:       p.PrevRandao,
+		BlockNumber:      p.BlockNumber,
+		GasLimit:         p.GasLimit,
+		GasUsed:          p.GasUsed,
+		Timestamp:        p.Timestamp,
+		ExtraData:        p.ExtraData,
+		BaseFeePerGas:    p.BaseFeePerGas,
+		BlockHash:        p.BlockHash,
+		TransactionsRoot: transactionsRoot,
+		WithdrawalsRoot:  withdrawalsRoot,
+		DataGasUsed:      p.DataGasUsed,
+		ExcessDataGas:    p.ExcessDataGas,
+	}, nil
+}
+
 func SignedBlindedBeaconBlockToBeaconBlock(signedBlindedBeaconBlock *SignedBlindedBeaconBlock, executionPayload *VersionedExecutionPayload) *SignedBeaconBlock {
 	var signedBeaconBlock SignedBeaconBlock
+	denebBlindedBlock := signedBlindedBeaconBlock.Deneb
 	capellaBlindedBlock := signedBlindedBeaconBlock.Capella
 	bellatrixBlindedBlock := signedBlindedBeaconBlock.Bellatrix
-	if capellaBlindedBlock != nil {
+	if denebBlindedBlock != nil {
+		signedBeaconBlock.Deneb = &DenebSignedBlockContents{
+			SignedBlock: &deneb.SignedBeaconBlock{
+				Signature: denebBlindedBlock.Signature,
+				Message: &deneb.BeaconBlock{
+					Slot:          denebBlindedBlock.Message.Slot,
+					ProposerIndex: denebBlindedBlock.Message.ProposerIndex,
+					ParentRoot:    denebBlindedBlock.Message.ParentRoot,
+					StateRoot:     denebBlindedBlock.Message.StateRoot,
+					Body: &deneb.BeaconBlockBody{
+						BLSToExecutionChanges: denebBlindedBlock.Message.Body.BLSToExecutionChanges,
+						RANDAOReveal:          denebBlindedBlock.Message.Body.RANDAOReveal,
+						ETH1Data:              denebBlindedBlock.Message.Body.ETH1Data,
+						Graffiti:              denebBlindedBlock.Message.Body.Graffiti,
+						ProposerSlashings:     denebBlindedBlock.Message.Body.ProposerSlashings,
+						AttesterSlashings:     denebBlindedBlock.Message.Body.AttesterSlashings,
+						Attestations:          denebBlindedBlock.Message.Body.Attestations,
+						Deposits:              denebBlindedBlock.Message.Body.Deposits,
+						VoluntaryExits:        denebBlindedBlock.Message.Body.VoluntaryExits,
+						SyncAggregate:         denebBlindedBlock.Message.Body.SyncAggregate,
+						ExecutionPayload:      executionPayload.Deneb.ExecutionPayload,
+						BlobKzgCommitments:    denebBlindedBlock.Message.Body.BlobKzgCommitments,
+					},
+				},
+			},
+			KzgProofs: executionPayload.Deneb.BlobsBundle.Proofs,
+			Blobs:     executionPayload.Deneb.BlobsBundle.Blobs,
+		}
+	} else if capellaBlindedBlock != nil {
 		signedBeaconBlock.Capella = &consensuscapella.SignedBeaconBlock{
 			Signature: capellaBlindedBlock.Signature,
 			Message: &consensuscapella.BeaconBlock{
@@ -233,7 +348,8 @@ func SignedBlindedBeaconBlockToBeaconBlock(signedBlindedBeaconBlock *SignedBlind
 
 type BuilderBlockValidationRequest struct {
 	BuilderSubmitBlockRequest
-	RegisteredGasLimit uint64 `json:"registered_gas_limit,string"`
+	RegisteredGasLimit    uint64 `json:"registered_gas_limit,string"`
+	ParentBeaconBlockRoot string `json:"parent_beacon_block_root,omitempty"` // deneb only
 }
 
 func (r *BuilderBlockValidationRequest) MarshalJSON() ([]byte, error) {
@@ -242,9 +358,11 @@ func (r *BuilderBlockValidationRequest) MarshalJSON() ([]byte, error) {
 		return nil, err
 	}
 	gasLimit, err := json.Marshal(&struct {
-		RegisteredGasLimit uint64 `json:"registered_gas_limit,string"`
+		RegisteredGasLimit    uint64 `json:"registered_gas_limit,string"`
+		ParentBeaconBlockRoot string `json:"parent_beacon_block_root,omitempty"`
 	}{
-		RegisteredGasLimit: r.RegisteredGasLimit,
+		RegisteredGasLimit:    r.RegisteredGasLimit,
+		ParentBeaconBlockRoot: r.ParentBeaconBlockRoot,
 	})
 	if err != nil {
 		return nil, err
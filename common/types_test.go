@@ -37,3 +37,23 @@ func TestDataVersion(t *testing.T) {
 	require.Equal(t, ForkVersionStringCapella, consensusspec.DataVersionCapella.String())
 	require.Equal(t, ForkVersionStringDeneb, consensusspec.DataVersionDeneb.String())
 }
+
+func TestSetCapellaForkVersion(t *testing.T) {
+	networkDetails, err := NewEthNetworkDetails(EthNetworkGoerli)
+	require.NoError(t, err)
+
+	mainnetDetails, err := NewEthNetworkDetails(EthNetworkMainnet)
+	require.NoError(t, err)
+
+	// same fork version recomputes the same domain
+	domain := networkDetails.DomainBeaconProposerCapella
+	require.NoError(t, networkDetails.SetCapellaForkVersion(CapellaForkVersionGoerli))
+	require.Equal(t, domain, networkDetails.DomainBeaconProposerCapella)
+
+	require.NoError(t, networkDetails.SetCapellaForkVersion(CapellaForkVersionMainnet))
+	require.Equal(t, CapellaForkVersionMainnet, networkDetails.CapellaForkVersionHex)
+	require.NotEqual(t, domain, networkDetails.DomainBeaconProposerCapella)
+	require.NotEqual(t, mainnetDetails.DomainBeaconProposerCapella, networkDetails.DomainBeaconProposerCapella) // different genesis validators root
+
+	require.Error(t, networkDetails.SetCapellaForkVersion("0x1234"))
+}
@@ -27,11 +27,27 @@ import (
 )
 
 var (
-	ErrInvalidForkVersion = errors.New("invalid fork version")
-	ErrHTTPErrorResponse  = errors.New("got an HTTP error response")
-	ErrIncorrectLength    = errors.New("incorrect length")
+	ErrInvalidForkVersion           = errors.New("invalid fork version")
+	ErrMissingGenesisValidatorsRoot = errors.New("missing genesis validators root")
+	ErrInvalidGenesisValidatorsRoot = errors.New("invalid genesis validators root")
+	ErrHTTPErrorResponse            = errors.New("got an HTTP error response")
+	ErrIncorrectLength              = errors.New("incorrect length")
 )
 
+// ValidateBLSPublicKey checks that a compressed BLS public key is a point on the curve and in the G1 subgroup, and not
+// the point at infinity (which verifies the infinity signature for any message)
+func ValidateBLSPublicKey(pubkey []byte) error {
+	pk, err := bls.PublicKeyFromBytes(pubkey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPubkey, err)
+	} else if pk.IsInfinity() {
+		return fmt.Errorf("%w: point at infinity", ErrInvalidPubkey)
+	} else if !pk.IsOnCurve() || !pk.IsInSubGroup() {
+		return fmt.Errorf("%w: not in the G1 subgroup", ErrInvalidPubkey)
+	}
+	return nil
+}
+
 // SlotPos returns the slot's position in the epoch (1-based, i.e. 1..32)
 func SlotPos(slot uint64) uint64 {
 	return (slot % SlotsPerEpoch) + 1
@@ -72,8 +88,24 @@ func makeRequest(ctx context.Context, client http.Client, method, url string, pa
 	return resp, nil
 }
 
-// ComputeDomain computes the signing domain
+// ValidateGenesisValidatorsRoot checks that the genesis validators root is 32 bytes of hex
+func ValidateGenesisValidatorsRoot(genesisValidatorsRootHex string) error {
+	if genesisValidatorsRootHex == "" {
+		return ErrMissingGenesisValidatorsRoot
+	}
+	rootBytes, err := hexutil.Decode(genesisValidatorsRootHex)
+	if err != nil || len(rootBytes) != 32 {
+		return fmt.Errorf("%w: %s", ErrInvalidGenesisValidatorsRoot, genesisValidatorsRootHex)
+	}
+	return nil
+}
+
+// ComputeDomain computes the signing domain. The genesis validators root must be set, instead of silently computing the
+// domain of a zero root (the builder domain uses the zero root explicitly).
 func ComputeDomain(domainType types.DomainType, forkVersionHex, genesisValidatorsRootHex string) (domain types.Domain, err error) {
+	if err := ValidateGenesisValidatorsRoot(genesisValidatorsRootHex); err != nil {
+		return domain, err
+	}
 	genesisValidatorsRoot := types.Root(ethcommon.HexToHash(genesisValidatorsRootHex))
 	forkVersionBytes, err := hexutil.Decode(forkVersionHex)
 	if err != nil || len(forkVersionBytes) != 4 {
@@ -170,6 +202,36 @@ func StrToPhase0Hash(s string) (ret phase0.Hash32, err error) {
 	return ret, nil
 }
 
+// SecretKeyFromHex decodes and validates a hex-encoded BLS secret key (with or without 0x prefix)
+func SecretKeyFromHex(s string) (*bls.SecretKey, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "0x") {
+		s = "0x" + s
+	}
+	skBytes, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(skBytes)
+	return bls.SecretKeyFromBytes(skBytes)
+}
+
+// SecretKeyFromFile reads a hex-encoded BLS secret key from a file, and zeroes the file contents after decoding
+func SecretKeyFromFile(path string) (*bls.SecretKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(content)
+	return SecretKeyFromHex(string(content))
+}
+
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
 type CreateTestBlockSubmissionOpts struct {
 	relaySk bls.SecretKey
 	relayPk types.PublicKey
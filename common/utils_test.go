@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/go-boost-utils/bls"
 	boostTypes "github.com/flashbots/go-boost-utils/types"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +29,51 @@ func TestMakePostRequest(t *testing.T) {
 	}
 }
 
+func TestValidateBLSPublicKey(t *testing.T) {
+	_, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	require.NoError(t, ValidateBLSPublicKey(bls.PublicKeyToBytes(pk)))
+
+	invalid := map[string]string{
+		"point at infinity": "0xc00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"not on the curve":  "0x800000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001",
+		"not in subgroup":   "0x800000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004",
+		"uncompressed flag": "0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004",
+		"zero":              "0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	}
+	for name, pubkey := range invalid {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, ValidateBLSPublicKey(hexutil.MustDecode(pubkey)), ErrInvalidPubkey)
+		})
+	}
+	require.ErrorIs(t, ValidateBLSPublicKey([]byte{0xc0}), ErrInvalidPubkey)
+}
+
+func TestGenesisValidatorsRoot(t *testing.T) {
+	require.NoError(t, ValidateGenesisValidatorsRoot(boostTypes.GenesisValidatorsRootMainnet))
+	require.ErrorIs(t, ValidateGenesisValidatorsRoot(""), ErrMissingGenesisValidatorsRoot)
+	require.ErrorIs(t, ValidateGenesisValidatorsRoot("0x1234"), ErrInvalidGenesisValidatorsRoot)
+	require.ErrorIs(t, ValidateGenesisValidatorsRoot(strings.TrimPrefix(boostTypes.GenesisValidatorsRootMainnet, "0x")), ErrInvalidGenesisValidatorsRoot)
+
+	_, err := ComputeDomain(boostTypes.DomainTypeBeaconProposer, CapellaForkVersionMainnet, "")
+	require.ErrorIs(t, err, ErrMissingGenesisValidatorsRoot)
+
+	// the proposer domains of a network depend on its genesis validators root
+	t.Setenv("GENESIS_FORK_VERSION", boostTypes.GenesisForkVersionMainnet)
+	t.Setenv("BELLATRIX_FORK_VERSION", boostTypes.BellatrixForkVersionMainnet)
+	t.Setenv("CAPELLA_FORK_VERSION", CapellaForkVersionMainnet)
+	t.Setenv("GENESIS_VALIDATORS_ROOT", "")
+	_, err = NewEthNetworkDetails(EthNetworkCustom)
+	require.ErrorIs(t, err, ErrMissingGenesisValidatorsRoot)
+
+	mainnet, err := NewEthNetworkDetails(EthNetworkMainnet)
+	require.NoError(t, err)
+	custom, err := NewEthNetworkDetailsWithConfig(EthNetworkCustom, &NetworkConfig{GenesisValidatorsRoot: GenesisValidatorsRootZhejiang}) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Equal(t, mainnet.DomainBuilder, custom.DomainBuilder)
+	require.NotEqual(t, mainnet.DomainBeaconProposerCapella, custom.DomainBeaconProposerCapella)
+}
+
 func TestGetMevBoostVersionFromUserAgent(t *testing.T) {
 	tests := []struct {
 		ua      string
@@ -98,3 +147,24 @@ func TestGetEnvStrSlice(t *testing.T) {
 	require.Equal(t, "str2", r[1])
 	os.Unsetenv(testEnvVar)
 }
+
+func TestSecretKeyFromHex(t *testing.T) {
+	skHex := "0x607a11b45a7219cc61a3d9c5fd08c7eebd602a6a19a977f8d3771d5711a550f2"
+	sk, err := SecretKeyFromHex(skHex)
+	require.NoError(t, err)
+	require.Equal(t, skHex, hexutil.Encode(bls.SecretKeyToBytes(sk)))
+
+	// without prefix and with whitespace (i.e. trailing newline in a file)
+	sk, err = SecretKeyFromHex(strings.TrimPrefix(skHex, "0x") + "\n")
+	require.NoError(t, err)
+	require.Equal(t, skHex, hexutil.Encode(bls.SecretKeyToBytes(sk)))
+
+	_, err = SecretKeyFromHex("0x1234")
+	require.Error(t, err)
+
+	fn := filepath.Join(t.TempDir(), "secret-key")
+	require.NoError(t, os.WriteFile(fn, []byte(skHex+"\n"), 0o600))
+	sk, err = SecretKeyFromFile(fn)
+	require.NoError(t, err)
+	require.Equal(t, skHex, hexutil.Encode(bls.SecretKeyToBytes(sk)))
+}
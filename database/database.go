@@ -20,6 +20,7 @@ import (
 
 type IDatabaseService interface {
 	NumRegisteredValidators() (count uint64, err error)
+	GetValidatorRegistrationCountsByDay() ([]*ValidatorRegistrationCountEntry, error)
 	SaveValidatorRegistration(entry ValidatorRegistrationEntry) error
 	GetLatestValidatorRegistrations(timestampOnly bool) ([]*ValidatorRegistrationEntry, error)
 	GetValidatorRegistration(pubkey string) (*ValidatorRegistrationEntry, error)
@@ -38,6 +39,7 @@ type IDatabaseService interface {
 	GetNumDeliveredPayloads() (uint64, error)
 	GetRecentDeliveredPayloads(filters GetPayloadsFilters) ([]*DeliveredPayloadEntry, error)
 	GetDeliveredPayloads(idFirst, idLast uint64) (entries []*DeliveredPayloadEntry, err error)
+	GetDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (entries []*DeliveredPayloadEntry, err error)
 
 	GetBlockBuilders() ([]*BlockBuilderEntry, error)
 	GetBlockBuilderByPubkey(pubkey string) (*BlockBuilderEntry, error)
@@ -45,6 +47,7 @@ type IDatabaseService interface {
 	SetBlockBuilderIDStatusIsOptimistic(pubkey string, isOptimistic bool) error
 	SetBlockBuilderCollateral(pubkey, builderID, collateral string) error
 	UpsertBlockBuilderEntryAfterSubmission(lastSubmission *BuilderBlockSubmissionEntry, isError bool) error
+	IncBlockBuilderStatsAfterGetHeader(builderPubkey string) error
 	IncBlockBuilderStatsAfterGetPayload(builderPubkey string) error
 
 	InsertBuilderDemotion(submitBlockRequest *common.BuilderSubmitBlockRequest, simError error) error
@@ -53,6 +56,15 @@ type IDatabaseService interface {
 
 	GetTooLateGetPayload(slot uint64) (entries []*TooLateGetPayloadEntry, err error)
 	InsertTooLateGetPayload(slot uint64, proposerPubkey, blockHash string, slotStart, requestTime, decodeTime, msIntoSlot uint64) error
+
+	InsertAuditLogEntry(entry *AuditLogEntry) error
+	GetAuditLogEntries(slotFrom, slotTo uint64) (entries []*AuditLogEntry, err error)
+
+	InsertGetPayloadFailure(entry *GetPayloadFailureEntry) error
+	GetGetPayloadFailures(slotFrom, slotTo uint64) (entries []*GetPayloadFailureEntry, err error)
+
+	InsertBidDecision(entry *BidDecisionEntry) error
+	GetBidDecisions(slot uint64) (entries []*BidDecisionEntry, err error)
 }
 
 type DatabaseService struct {
@@ -118,6 +130,17 @@ func (s *DatabaseService) NumRegisteredValidators() (count uint64, err error) {
 	return count, err
 }
 
+// GetValidatorRegistrationCountsByDay returns the number of unique registered validators at the end of each day, by the
+// day of their first registration
+func (s *DatabaseService) GetValidatorRegistrationCountsByDay() (entries []*ValidatorRegistrationCountEntry, err error) {
+	query := `SELECT day, SUM(COUNT(*)) OVER (ORDER BY day) AS num_validators
+		FROM (SELECT date_trunc('day', MIN(inserted_at)) AS day FROM ` + vars.TableValidatorRegistration + ` GROUP BY pubkey) AS first_registrations
+		GROUP BY day
+		ORDER BY day ASC;`
+	err = s.DB.Select(&entries, query)
+	return entries, err
+}
+
 func (s *DatabaseService) NumValidatorRegistrationRows() (count uint64, err error) {
 	query := `SELECT COUNT(*) FROM ` + vars.TableValidatorRegistration + `;`
 	row := s.DB.QueryRow(query)
@@ -379,6 +402,17 @@ func (s *DatabaseService) GetDeliveredPayloads(idFirst, idLast uint64) (entries
 	return entries, err
 }
 
+// GetDeliveredPayloadsBySlots returns the delivered payloads of the slot range (inclusive), ordered by slot
+func (s *DatabaseService) GetDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (entries []*DeliveredPayloadEntry, err error) {
+	query := `SELECT id, inserted_at, signed_at, slot, epoch, builder_pubkey, proposer_pubkey, proposer_fee_recipient, parent_hash, block_hash, block_number, num_tx, value, gas_used, gas_limit, publish_ms
+	FROM ` + vars.TableDeliveredPayload + `
+	WHERE slot >= $1 AND slot <= $2
+	ORDER BY slot ASC, id ASC`
+
+	err = s.DB.Select(&entries, query, slotFrom, slotTo)
+	return entries, err
+}
+
 func (s *DatabaseService) GetNumDeliveredPayloads() (uint64, error) {
 	var count uint64
 	err := s.DB.QueryRow("SELECT COUNT(*) FROM " + vars.TableDeliveredPayload).Scan(&count)
@@ -478,14 +512,14 @@ func (s *DatabaseService) UpsertBlockBuilderEntryAfterSubmission(lastSubmission
 }
 
 func (s *DatabaseService) GetBlockBuilders() ([]*BlockBuilderEntry, error) {
-	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` ORDER BY id ASC;`
+	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getheader, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` ORDER BY id ASC;`
 	entries := []*BlockBuilderEntry{}
 	err := s.DB.Select(&entries, query)
 	return entries, err
 }
 
 func (s *DatabaseService) GetBlockBuilderByPubkey(pubkey string) (*BlockBuilderEntry, error) {
-	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` WHERE builder_pubkey=$1;`
+	query := `SELECT id, inserted_at, builder_pubkey, description, is_high_prio, is_blacklisted, is_optimistic, collateral, builder_id, last_submission_id, last_submission_slot, num_submissions_total, num_submissions_simerror, num_sent_getheader, num_sent_getpayload FROM ` + vars.TableBlockBuilder + ` WHERE builder_pubkey=$1;`
 	entry := &BlockBuilderEntry{}
 	err := s.DB.Get(entry, query, pubkey)
 	return entry, err
@@ -516,6 +550,14 @@ func (s *DatabaseService) SetBlockBuilderCollateral(pubkey, builderID, collatera
 	return err
 }
 
+func (s *DatabaseService) IncBlockBuilderStatsAfterGetHeader(builderPubkey string) error {
+	query := `UPDATE ` + vars.TableBlockBuilder + `
+		SET num_sent_getheader=num_sent_getheader+1
+		WHERE builder_pubkey=$1;`
+	_, err := s.DB.Exec(query, builderPubkey)
+	return err
+}
+
 func (s *DatabaseService) IncBlockBuilderStatsAfterGetPayload(builderPubkey string) error {
 	query := `UPDATE ` + vars.TableBlockBuilder + `
 		SET num_sent_getpayload=num_sent_getpayload+1
@@ -618,3 +660,60 @@ func (s *DatabaseService) InsertTooLateGetPayload(slot uint64, proposerPubkey, b
 	_, err := s.DB.NamedExec(query, entry)
 	return err
 }
+
+func (s *DatabaseService) InsertAuditLogEntry(entry *AuditLogEntry) error {
+	query := `INSERT INTO ` + vars.TableAuditLog + `
+		(slot, action, proposer_pubkey, builder_pubkey, value, block_hash, event_at) VALUES
+		(:slot, :action, :proposer_pubkey, :builder_pubkey, :value, :block_hash, :event_at)`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+// GetAuditLogEntries returns the audit log for the slot range (inclusive), in the order of the events
+func (s *DatabaseService) GetAuditLogEntries(slotFrom, slotTo uint64) (entries []*AuditLogEntry, err error) {
+	query := `SELECT id, inserted_at, slot, action, proposer_pubkey, builder_pubkey, value, block_hash, event_at
+	FROM ` + vars.TableAuditLog + `
+	WHERE slot >= $1 AND slot <= $2
+	ORDER BY slot ASC, event_at ASC, id ASC`
+	err = s.DB.Select(&entries, query, slotFrom, slotTo)
+	return entries, err
+}
+
+func (s *DatabaseService) InsertGetPayloadFailure(entry *GetPayloadFailureEntry) error {
+	query := `INSERT INTO ` + vars.TableGetPayloadFailure + `
+		(slot, proposer_pubkey, block_hash, reason, error, request_id, user_agent, ip, ms_into_slot, failed_at) VALUES
+		(:slot, :proposer_pubkey, :block_hash, :reason, :error, :request_id, :user_agent, :ip, :ms_into_slot, :failed_at)`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+// GetGetPayloadFailures returns the failed getPayload requests of the slot range (inclusive), in the order of the failures
+func (s *DatabaseService) GetGetPayloadFailures(slotFrom, slotTo uint64) (entries []*GetPayloadFailureEntry, err error) {
+	query := `SELECT id, inserted_at, slot, proposer_pubkey, block_hash, reason, error, request_id, user_agent, ip, ms_into_slot, failed_at
+	FROM ` + vars.TableGetPayloadFailure + `
+	WHERE slot >= $1 AND slot <= $2
+	ORDER BY slot ASC, failed_at ASC, id ASC`
+	err = s.DB.Select(&entries, query, slotFrom, slotTo)
+	return entries, err
+}
+
+// InsertBidDecision saves a bid getHeader served. getHeader requests aren't authenticated, so every served header is
+// kept, once per slot, proposer and block hash with the time it was first served.
+func (s *DatabaseService) InsertBidDecision(entry *BidDecisionEntry) error {
+	query := `INSERT INTO ` + vars.TableBidDecision + `
+		(slot, proposer_pubkey, parent_hash, builder_pubkey, value, block_hash, decided_at) VALUES
+		(:slot, :proposer_pubkey, :parent_hash, :builder_pubkey, :value, :block_hash, :decided_at)
+		ON CONFLICT (slot, proposer_pubkey, block_hash) DO NOTHING`
+	_, err := s.DB.NamedExec(query, entry)
+	return err
+}
+
+// GetBidDecisions returns the bid decisions of the slot, in the order of the decisions
+func (s *DatabaseService) GetBidDecisions(slot uint64) (entries []*BidDecisionEntry, err error) {
+	query := `SELECT id, inserted_at, slot, proposer_pubkey, parent_hash, builder_pubkey, value, block_hash, decided_at
+	FROM ` + vars.TableBidDecision + `
+	WHERE slot = $1
+	ORDER BY decided_at ASC, id ASC`
+	err = s.DB.Select(&entries, query, slot)
+	return entries, err
+}
@@ -195,6 +195,30 @@ func TestSaveValidatorRegistration(t *testing.T) {
 	require.Equal(t, uint64(3), cnt)
 }
 
+func TestGetValidatorRegistrationCountsByDay(t *testing.T) {
+	db := resetDatabase(t)
+	pk1 := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+	pk2 := "0x9996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+
+	reg1 := createValidatorRegistration(pk1)
+	require.NoError(t, db.SaveValidatorRegistration(reg1))
+	reg1.Timestamp++
+	reg1.GasLimit++
+	require.NoError(t, db.SaveValidatorRegistration(reg1))
+	require.NoError(t, db.SaveValidatorRegistration(createValidatorRegistration(pk2)))
+
+	// pk1 registered first two days ago
+	_, err := db.DB.Exec(`UPDATE `+vars.TableValidatorRegistration+` SET inserted_at = inserted_at - interval '2 days' WHERE pubkey=$1 AND timestamp=$2`, pk1, reg1.Timestamp-1)
+	require.NoError(t, err)
+
+	entries, err := db.GetValidatorRegistrationCountsByDay()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, uint64(1), entries[0].NumValidators)
+	require.Equal(t, uint64(2), entries[1].NumValidators)
+	require.True(t, entries[0].Day.Before(entries[1].Day))
+}
+
 func TestMigrations(t *testing.T) {
 	db := resetDatabase(t)
 	query := `SELECT COUNT(*) FROM ` + vars.TableMigrations + `;`
@@ -288,6 +312,24 @@ func TestSetBlockBuilderCollateral(t *testing.T) {
 	require.Equal(t, collateralStr, builder.Collateral)
 }
 
+func TestIncBlockBuilderStats(t *testing.T) {
+	db := resetDatabase(t)
+	pubkey := insertTestBuilder(t, db)
+
+	err := db.IncBlockBuilderStatsAfterGetHeader(pubkey)
+	require.NoError(t, err)
+	err = db.IncBlockBuilderStatsAfterGetHeader(pubkey)
+	require.NoError(t, err)
+	err = db.IncBlockBuilderStatsAfterGetPayload(pubkey)
+	require.NoError(t, err)
+
+	builder, err := db.GetBlockBuilderByPubkey(pubkey)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), builder.NumSubmissionsTotal)
+	require.Equal(t, uint64(2), builder.NumSentGetHeader)
+	require.Equal(t, uint64(1), builder.NumSentGetPayload)
+}
+
 func TestInsertBuilderDemotion(t *testing.T) {
 	db := resetDatabase(t)
 	pk, sk := getTestKeyPair(t)
@@ -440,3 +482,117 @@ func TestUpsertTooLateGetPayload(t *testing.T) {
 	entry = entries[1]
 	require.Equal(t, hash2, entry.BlockHash)
 }
+
+func TestAuditLog(t *testing.T) {
+	db := resetDatabase(t)
+	pk := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+	hash := "0x00bb8996515293fcd87ca09b5c6ffe5c17f043c600bb8996515293fcd8012343"
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	for i, slot := range []uint64{100, 101, 101, 102} {
+		action := AuditActionGetHeader
+		if i == 2 {
+			action = AuditActionGetPayload
+		}
+		err := db.InsertAuditLogEntry(&AuditLogEntry{
+			Slot:           slot,
+			Action:         action,
+			ProposerPubkey: pk,
+			BuilderPubkey:  pk,
+			Value:          "1000",
+			BlockHash:      hash,
+			EventAt:        now.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	entries, err := db.GetAuditLogEntries(101, 102)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, AuditActionGetHeader, entries[0].Action)
+	require.Equal(t, AuditActionGetPayload, entries[1].Action)
+	require.Equal(t, uint64(102), entries[2].Slot)
+	require.Equal(t, "1000", entries[1].Value)
+}
+
+func TestGetPayloadFailures(t *testing.T) {
+	db := resetDatabase(t)
+	pk := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+	hash := "0x00bb8996515293fcd87ca09b5c6ffe5c17f043c600bb8996515293fcd8012343"
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	for i, slot := range []uint64{100, 101, 102} {
+		err := db.InsertGetPayloadFailure(&GetPayloadFailureEntry{
+			Slot:           slot,
+			ProposerPubkey: pk,
+			BlockHash:      hash,
+			Reason:         GetPayloadFailureSubmissionFound,
+			Error:          "execution payload not found",
+			RequestID:      "abc",
+			UserAgent:      "mev-boost/v1.6.0",
+			IP:             "127.0.0.1",
+			MsIntoSlot:     int64(1000 + i),
+			FailedAt:       now.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	entries, err := db.GetGetPayloadFailures(101, 102)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, uint64(101), entries[0].Slot)
+	require.Equal(t, GetPayloadFailureSubmissionFound, entries[0].Reason)
+	require.Equal(t, int64(1001), entries[0].MsIntoSlot)
+	require.Equal(t, "mev-boost/v1.6.0", entries[1].UserAgent)
+}
+
+func TestBidDecisions(t *testing.T) {
+	db := resetDatabase(t)
+	pk1 := "0x8996515293fcd87ca09b5c6ffe5c17f043c6a1a3639cc9494a82ec8eb50a9b55c34b47675e573be40d9be308b1ca2908"
+	pk2 := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	hash1 := "0x00bb8996515293fcd87ca09b5c6ffe5c17f043c600bb8996515293fcd8012343"
+	hash2 := "0x00bb8996515293fcd87ca09b5c6ffe5c17f043c600bb8996515293fcd8012344"
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	entry := &BidDecisionEntry{
+		Slot:           100,
+		ProposerPubkey: pk1,
+		ParentHash:     hash1,
+		BuilderPubkey:  pk2,
+		Value:          "1000",
+		BlockHash:      hash1,
+		DecidedAt:      now,
+	}
+	require.NoError(t, db.InsertBidDecision(entry))
+
+	// Serving the same header again keeps the time it was first served
+	again := *entry
+	again.DecidedAt = now.Add(time.Second)
+	require.NoError(t, db.InsertBidDecision(&again))
+
+	// Other headers for the slot and proposer are recorded as well
+	later := *entry
+	later.BlockHash = hash2
+	later.Value = "2000"
+	later.DecidedAt = now.Add(2 * time.Second)
+	require.NoError(t, db.InsertBidDecision(&later))
+
+	other := later
+	other.ProposerPubkey = pk2
+	other.DecidedAt = now.Add(3 * time.Second)
+	require.NoError(t, db.InsertBidDecision(&other))
+
+	entries, err := db.GetBidDecisions(100)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, hash1, entries[0].BlockHash)
+	require.Equal(t, "1000", entries[0].Value)
+	require.Equal(t, now, entries[0].DecidedAt)
+	require.Equal(t, hash2, entries[1].BlockHash)
+	require.Equal(t, pk1, entries[1].ProposerPubkey)
+	require.Equal(t, pk2, entries[2].ProposerPubkey)
+
+	entries, err = db.GetBidDecisions(101)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
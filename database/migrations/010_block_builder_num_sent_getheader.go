@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+// Migration010BlockBuilderAddNumSentGetHeader adds a counter for the bids of a builder that were served
+// through getHeader, next to the existing submission and getPayload counters.
+var Migration010BlockBuilderAddNumSentGetHeader = &migrate.Migration{
+	Id: "010-block-builder-add-num-sent-getheader",
+	Up: []string{`
+		ALTER TABLE ` + vars.TableBlockBuilder + ` ADD num_sent_getheader bigint NOT NULL DEFAULT 0;
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+var Migration011AuditLog = &migrate.Migration{
+	Id: "011-audit-log",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableAuditLog + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			slot   bigint NOT NULL,
+			action varchar(32) NOT NULL,
+
+			proposer_pubkey varchar(98) NOT NULL,
+			builder_pubkey  varchar(98) NOT NULL,
+			value           NUMERIC(48, 0) NOT NULL,
+			block_hash      varchar(66) NOT NULL,
+			event_at        timestamp NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS ` + vars.TableAuditLog + `_slot_idx ON ` + vars.TableAuditLog + `(slot);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
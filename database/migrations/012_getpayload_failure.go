@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+var Migration012GetPayloadFailure = &migrate.Migration{
+	Id: "012-getpayload-failure",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableGetPayloadFailure + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			slot            bigint NOT NULL,
+			proposer_pubkey varchar(98) NOT NULL,
+			block_hash      varchar(66) NOT NULL,
+
+			reason      varchar(64) NOT NULL,
+			error       text NOT NULL,
+			request_id  varchar(64) NOT NULL,
+			user_agent  text NOT NULL,
+			ip          varchar(64) NOT NULL,
+			ms_into_slot bigint NOT NULL,
+			failed_at   timestamp NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS ` + vars.TableGetPayloadFailure + `_slot_idx ON ` + vars.TableGetPayloadFailure + `(slot);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
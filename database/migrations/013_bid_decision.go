@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/flashbots/mev-boost-relay/database/vars"
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+var Migration013BidDecision = &migrate.Migration{
+	Id: "013-bid-decision",
+	Up: []string{`
+		CREATE TABLE IF NOT EXISTS ` + vars.TableBidDecision + ` (
+			id          bigint GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			inserted_at timestamp NOT NULL default current_timestamp,
+
+			slot            bigint NOT NULL,
+			proposer_pubkey varchar(98) NOT NULL,
+			parent_hash     varchar(66) NOT NULL,
+
+			builder_pubkey varchar(98) NOT NULL,
+			value          NUMERIC(48, 0) NOT NULL,
+			block_hash     varchar(66) NOT NULL,
+			decided_at     timestamp NOT NULL,
+
+			UNIQUE (slot, proposer_pubkey, block_hash)
+		);
+	`},
+	Down: []string{},
+
+	DisableTransactionUp:   true,
+	DisableTransactionDown: true,
+}
@@ -16,5 +16,9 @@ var Migrations = migrate.MemoryMigrationSource{
 		Migration007BuilderSubmissionWasSimulated,
 		Migration008Optimistic,
 		Migration009BlockBuilderRemoveReference,
+		Migration010BlockBuilderAddNumSentGetHeader,
+		Migration011AuditLog,
+		Migration012GetPayloadFailure,
+		Migration013BidDecision,
 	},
 }
@@ -20,6 +20,10 @@ func (db MockDB) NumRegisteredValidators() (count uint64, err error) {
 	return 0, nil
 }
 
+func (db MockDB) GetValidatorRegistrationCountsByDay() ([]*ValidatorRegistrationCountEntry, error) {
+	return nil, nil
+}
+
 func (db MockDB) SaveValidatorRegistration(entry ValidatorRegistrationEntry) error {
 	return nil
 }
@@ -73,6 +77,10 @@ func (db MockDB) GetDeliveredPayloads(idFirst, idLast uint64) (entries []*Delive
 	return nil, nil
 }
 
+func (db MockDB) GetDeliveredPayloadsBySlots(slotFrom, slotTo uint64) (entries []*DeliveredPayloadEntry, err error) {
+	return nil, nil
+}
+
 func (db MockDB) GetNumDeliveredPayloads() (uint64, error) {
 	return 0, nil
 }
@@ -145,11 +153,17 @@ func (db MockDB) SetBlockBuilderCollateral(pubkey, builderID, collateral string)
 	return nil
 }
 
-func (db MockDB) IncBlockBuilderStatsAfterGetHeader(slot uint64, blockhash string) error {
+func (db MockDB) IncBlockBuilderStatsAfterGetHeader(builderPubkey string) error {
+	if builder, ok := db.Builders[builderPubkey]; ok {
+		builder.NumSentGetHeader++
+	}
 	return nil
 }
 
 func (db MockDB) IncBlockBuilderStatsAfterGetPayload(builderPubkey string) error {
+	if builder, ok := db.Builders[builderPubkey]; ok {
+		builder.NumSentGetPayload++
+	}
 	return nil
 }
 
@@ -191,3 +205,27 @@ func (db MockDB) GetTooLateGetPayload(slot uint64) (entries []*TooLateGetPayload
 func (db MockDB) InsertTooLateGetPayload(slot uint64, proposerPubkey, blockHash string, slotStart, requestTime, decodeTime, msIntoSlot uint64) error {
 	return nil
 }
+
+func (db MockDB) InsertAuditLogEntry(entry *AuditLogEntry) error {
+	return nil
+}
+
+func (db MockDB) GetAuditLogEntries(slotFrom, slotTo uint64) (entries []*AuditLogEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) InsertGetPayloadFailure(entry *GetPayloadFailureEntry) error {
+	return nil
+}
+
+func (db MockDB) GetGetPayloadFailures(slotFrom, slotTo uint64) (entries []*GetPayloadFailureEntry, err error) {
+	return nil, nil
+}
+
+func (db MockDB) InsertBidDecision(entry *BidDecisionEntry) error {
+	return nil
+}
+
+func (db MockDB) GetBidDecisions(slot uint64) (entries []*BidDecisionEntry, err error) {
+	return nil, nil
+}
@@ -60,6 +60,12 @@ type ValidatorRegistrationEntry struct {
 	Signature    string `db:"signature"`
 }
 
+// ValidatorRegistrationCountEntry is the number of unique registered validators at the end of a day
+type ValidatorRegistrationCountEntry struct {
+	Day           time.Time `db:"day"`
+	NumValidators uint64    `db:"num_validators"`
+}
+
 func (reg ValidatorRegistrationEntry) ToSignedValidatorRegistration() (*types.SignedValidatorRegistration, error) {
 	pubkey, err := types.HexToPubkey(reg.Pubkey)
 	if err != nil {
@@ -215,6 +221,7 @@ type BlockBuilderEntry struct {
 	NumSubmissionsTotal    uint64 `db:"num_submissions_total"    json:"num_submissions_total"`
 	NumSubmissionsSimError uint64 `db:"num_submissions_simerror" json:"num_submissions_simerror"`
 
+	NumSentGetHeader  uint64 `db:"num_sent_getheader"  json:"num_sent_getheader"`
 	NumSentGetPayload uint64 `db:"num_sent_getpayload" json:"num_sent_getpayload"`
 }
 
@@ -255,3 +262,63 @@ type TooLateGetPayloadEntry struct {
 	BlockHash      string `db:"block_hash"`
 	MsIntoSlot     uint64 `db:"ms_into_slot"`
 }
+
+const (
+	AuditActionGetHeader  = "get_header"
+	AuditActionGetPayload = "get_payload"
+)
+
+// AuditLogEntry is a bid served on getHeader, or a payload delivered on getPayload
+type AuditLogEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	Slot   uint64 `db:"slot"`
+	Action string `db:"action"`
+
+	ProposerPubkey string    `db:"proposer_pubkey"`
+	BuilderPubkey  string    `db:"builder_pubkey"`
+	Value          string    `db:"value"`
+	BlockHash      string    `db:"block_hash"`
+	EventAt        time.Time `db:"event_at"`
+}
+
+const (
+	GetPayloadFailureNeverSubmitted  = "never_submitted"
+	GetPayloadFailureSubmissionFound = "submission_found"
+	GetPayloadFailureError           = "error"
+)
+
+// GetPayloadFailureEntry is a getPayload request for which the execution payload could not be found, with the request
+// context for the investigation
+type GetPayloadFailureEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	Slot           uint64 `db:"slot"`
+	ProposerPubkey string `db:"proposer_pubkey"`
+	BlockHash      string `db:"block_hash"`
+
+	Reason     string    `db:"reason"`
+	Error      string    `db:"error"`
+	RequestID  string    `db:"request_id"`
+	UserAgent  string    `db:"user_agent"`
+	IP         string    `db:"ip"`
+	MsIntoSlot int64     `db:"ms_into_slot"`
+	FailedAt   time.Time `db:"failed_at"`
+}
+
+// BidDecisionEntry is the bid getHeader committed to serve to the proposer of a slot
+type BidDecisionEntry struct {
+	ID         int64     `db:"id"`
+	InsertedAt time.Time `db:"inserted_at"`
+
+	Slot           uint64 `db:"slot"`
+	ProposerPubkey string `db:"proposer_pubkey"`
+	ParentHash     string `db:"parent_hash"`
+
+	BuilderPubkey string    `db:"builder_pubkey"` // empty for bids of peer relays
+	Value         string    `db:"value"`
+	BlockHash     string    `db:"block_hash"`
+	DecidedAt     time.Time `db:"decided_at"`
+}
@@ -31,6 +31,16 @@ func PayloadToExecPayloadEntry(payload *common.BuilderSubmitBlockRequest) (*Exec
 		}
 		version = common.ForkVersionStringCapella
 	}
+	if payload.Deneb != nil {
+		_payload, err = json.Marshal(&common.DenebExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: payload.Deneb.ExecutionPayload,
+			BlobsBundle:      payload.Deneb.BlobsBundle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		version = common.ForkVersionStringDeneb
+	}
 	return &ExecutionPayloadEntry{
 		Slot:           payload.Slot(),
 		ProposerPubkey: payload.ProposerPubkey(),
@@ -86,7 +96,16 @@ func BuilderSubmissionEntryToBidTraceV2WithTimestampJSON(payload *BuilderBlockSu
 func ExecutionPayloadEntryToExecutionPayload(executionPayloadEntry *ExecutionPayloadEntry) (payload *common.VersionedExecutionPayload, err error) {
 	payloadVersion := executionPayloadEntry.Version
 	if payloadVersion == common.ForkVersionStringDeneb {
-		return nil, ErrUnsupportedExecutionPayload
+		deneb := new(common.DenebExecutionPayloadAndBlobsBundle)
+		err = json.Unmarshal([]byte(executionPayloadEntry.Payload), deneb)
+		if err != nil {
+			return nil, err
+		}
+		return &common.VersionedExecutionPayload{
+			Deneb:     deneb,
+			Capella:   nil,
+			Bellatrix: nil,
+		}, nil
 	} else if payloadVersion == common.ForkVersionStringCapella {
 		executionPayload := new(capella.ExecutionPayload)
 		err = json.Unmarshal([]byte(executionPayloadEntry.Payload), executionPayload)
@@ -15,4 +15,7 @@ var (
 	TableBuilderDemotions       = tableBase + "_builder_demotions"
 	TableBlockedValidator       = tableBase + "_blocked_validator"
 	TableTooLateGetPayload      = tableBase + "_too_late_get_payload"
+	TableAuditLog               = tableBase + "_audit_log"
+	TableGetPayloadFailure      = tableBase + "_getpayload_failure"
+	TableBidDecision            = tableBase + "_bid_decision"
 )
@@ -43,6 +43,7 @@ type Datastore struct {
 
 	knownValidatorsByPubkey   map[types.PubkeyHex]uint64
 	knownValidatorsByIndex    map[uint64]types.PubkeyHex
+	knownValidatorsStatus     map[types.PubkeyHex]string
 	knownValidatorsLock       sync.RWMutex
 	knownValidatorsIsUpdating uberatomic.Bool
 	knownValidatorsLastSlot   uberatomic.Uint64
@@ -56,6 +57,7 @@ func NewDatastore(log *logrus.Entry, redisCache *RedisCache, memcached *Memcache
 		redis:                   redisCache,
 		knownValidatorsByPubkey: make(map[types.PubkeyHex]uint64),
 		knownValidatorsByIndex:  make(map[uint64]types.PubkeyHex),
+		knownValidatorsStatus:   make(map[types.PubkeyHex]string),
 	}
 
 	return ds, err
@@ -131,16 +133,26 @@ func (ds *Datastore) RefreshKnownValidators(beaconClient beaconclient.IMultiBeac
 
 	knownValidatorsByPubkey := make(map[types.PubkeyHex]uint64)
 	knownValidatorsByIndex := make(map[uint64]types.PubkeyHex)
+	knownValidatorsStatus := make(map[types.PubkeyHex]string)
+	statuses := make(map[string]string) // to share the few distinct status strings between all validators
 
 	for _, valEntry := range validators.Data {
 		pk := types.NewPubkeyHex(valEntry.Validator.Pubkey)
 		knownValidatorsByPubkey[pk] = valEntry.Index
 		knownValidatorsByIndex[valEntry.Index] = pk
+
+		status, found := statuses[valEntry.Status]
+		if !found {
+			status = valEntry.Status
+			statuses[status] = status
+		}
+		knownValidatorsStatus[pk] = status
 	}
 
 	ds.knownValidatorsLock.Lock()
 	ds.knownValidatorsByPubkey = knownValidatorsByPubkey
 	ds.knownValidatorsByIndex = knownValidatorsByIndex
+	ds.knownValidatorsStatus = knownValidatorsStatus
 	ds.knownValidatorsLock.Unlock()
 
 	log.Infof("known validators updated")
@@ -160,6 +172,14 @@ func (ds *Datastore) GetKnownValidatorPubkeyByIndex(index uint64) (types.PubkeyH
 	return pk, found
 }
 
+// GetKnownValidatorStatus returns the status of a known validator as of the last refresh, i.e. "active_ongoing"
+func (ds *Datastore) GetKnownValidatorStatus(pubkeyHex types.PubkeyHex) (string, bool) {
+	ds.knownValidatorsLock.RLock()
+	defer ds.knownValidatorsLock.RUnlock()
+	status, found := ds.knownValidatorsStatus[pubkeyHex]
+	return status, found
+}
+
 func (ds *Datastore) NumKnownValidators() int {
 	ds.knownValidatorsLock.RLock()
 	defer ds.knownValidatorsLock.RUnlock()
@@ -195,6 +215,9 @@ func (ds *Datastore) GetGetPayloadResponse(slot uint64, proposerPubkey, blockHas
 
 	// 1. try to get from Redis
 	resp, err := ds.redis.GetExecutionPayloadCapella(slot, _proposerPubkey, _blockHash)
+	if errors.Is(err, redis.Nil) {
+		resp, err = ds.redis.GetExecutionPayloadDeneb(slot, _proposerPubkey, _blockHash)
+	}
 	if errors.Is(err, redis.Nil) {
 		ds.log.WithError(err).Warn("execution payload not found in redis")
 	} else if err != nil {
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"net"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -17,12 +20,15 @@ import (
 	"github.com/flashbots/go-utils/cli"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/go-redis/redis/v9"
+	"github.com/sirupsen/logrus"
 )
 
 var (
-	redisPrefix = "boost-relay"
+	// all keys are namespaced as <key prefix>/<network>:<key>
+	defaultRedisKeyPrefix = "boost-relay"
 
-	expiryBidCache = 45 * time.Second
+	// bids, payloads and bid traces expire this long after they were written, i.e. a few slots after the target slot
+	expiryBidCache = time.Duration(cli.GetEnvInt("REDIS_BID_EXPIRY_SEC", 45)) * time.Second
 
 	RedisConfigFieldPubkey         = "pubkey"
 	RedisStatsFieldLatestSlot      = "latest-slot"
@@ -31,6 +37,18 @@ var (
 	ErrFailedUpdatingTopBidNoBids            = errors.New("failed to update top bid because no bids were found")
 	ErrAnotherPayloadAlreadyDeliveredForSlot = errors.New("another payload block hash for slot was already delivered")
 	ErrPastSlotAlreadyDelivered              = errors.New("payload for past slot was already delivered")
+	ErrRedisSentinelAndCluster               = errors.New("redis sentinel and cluster mode can't be used together")
+	ErrRedisNoSentinelMaster                 = errors.New("redis sentinel mode needs the master name")
+
+	// sets the registration timestamp field (ARGV[1]) to ARGV[2], unless the known one is the same or later
+	setRegistrationTimestampIfNewerScript = redis.NewScript(`
+local known = tonumber(redis.call('HGET', KEYS[1], ARGV[1]))
+if known and known >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return 1
+`)
 
 	// Docs about redis settings: https://redis.io/docs/reference/clients/
 	redisConnectionPoolSize = cli.GetEnvInt("REDIS_CONNECTION_POOL_SIZE", 0) // 0 means use default (10 per CPU)
@@ -38,13 +56,101 @@ var (
 	redisReadTimeoutSec     = cli.GetEnvInt("REDIS_READ_TIMEOUT_SEC", 0)     // 0 means use default (3 sec)
 	redisPoolTimeoutSec     = cli.GetEnvInt("REDIS_POOL_TIMEOUT_SEC", 0)     // 0 means use default (ReadTimeout + 1 sec)
 	redisWriteTimeoutSec    = cli.GetEnvInt("REDIS_WRITE_TIMEOUT_SEC", 0)    // 0 means use default (3 seconds)
+	redisDialTimeoutSec     = cli.GetEnvInt("REDIS_DIAL_TIMEOUT_SEC", 0)     // 0 means use default (5 seconds)
+	redisMaxRetries         = cli.GetEnvInt("REDIS_MAX_RETRIES", 0)          // 0 means use default (3 retries), -1 disables retries
+	redisReadRetries        = cli.GetEnvInt("REDIS_READ_RETRIES", 2)         // retries of transient errors on reads, instead of MaxRetries
+	redisReadRetryBackoffMs = cli.GetEnvInt("REDIS_READ_RETRY_BACKOFF_MS", 10)
+	redisKeyPrefix          = common.GetEnv("REDIS_PREFIX", defaultRedisKeyPrefix)
+	redisSentinelAddrs      = common.GetSliceEnv("REDIS_SENTINEL", nil)
+	redisSentinelMasterName = common.GetEnv("REDIS_SENTINEL_MASTER", "")
+	redisSentinelPassword   = os.Getenv("REDIS_SENTINEL_PASSWORD")
+	redisClusterAddrs       = common.GetSliceEnv("REDIS_CLUSTER", nil)
 )
 
+// RedisOptions configure the connection pool of the Redis clients. Zero values keep the go-redis defaults.
+type RedisOptions struct {
+	PoolSize     int
+	MinIdleConns int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PoolTimeout  time.Duration
+	MaxRetries   int
+
+	// ReadRetries is how often reads are retried on transient errors, with exponential backoff starting at ReadRetryBackoff.
+	// The retried reads use a separate client without the go-redis retries (MaxRetries).
+	ReadRetries      int
+	ReadRetryBackoff time.Duration
+
+	// Log is used to report exhausted read retries
+	Log *logrus.Entry
+
+	// KeyPrefix namespaces all keys, so that relays of different networks or deployments can share a Redis (empty: the
+	// default of "boost-relay")
+	KeyPrefix string
+
+	// SentinelAddrs enables sentinel mode: the primary of SentinelMasterName is looked up from the sentinels, and the
+	// client reconnects to the new primary after a failover. The Redis URI only provides the credentials, DB and TLS.
+	SentinelAddrs      []string
+	SentinelMasterName string
+	SentinelPassword   string
+
+	// ClusterAddrs enables cluster mode, with these seed nodes. The Redis URI only provides the credentials and TLS.
+	ClusterAddrs []string
+}
+
+func (opts RedisOptions) clusterMode() bool {
+	return len(opts.ClusterAddrs) > 0
+}
+
+// singleNode returns the options without sentinel and cluster mode
+func (opts RedisOptions) singleNode() RedisOptions {
+	opts.SentinelAddrs = nil
+	opts.SentinelMasterName = ""
+	opts.SentinelPassword = ""
+	opts.ClusterAddrs = nil
+	return opts
+}
+
+// withoutRetries returns the options with the go-redis retries disabled
+func (opts RedisOptions) withoutRetries() RedisOptions {
+	opts.MaxRetries = -1
+	return opts
+}
+
+// DefaultRedisOptions returns the options set through the REDIS_* environment variables
+func DefaultRedisOptions() RedisOptions {
+	return RedisOptions{
+		PoolSize:         redisConnectionPoolSize,
+		MinIdleConns:     redisMinIdleConnections,
+		DialTimeout:      time.Duration(redisDialTimeoutSec) * time.Second,
+		ReadTimeout:      time.Duration(redisReadTimeoutSec) * time.Second,
+		WriteTimeout:     time.Duration(redisWriteTimeoutSec) * time.Second,
+		PoolTimeout:      time.Duration(redisPoolTimeoutSec) * time.Second,
+		MaxRetries:       redisMaxRetries,
+		ReadRetries:      redisReadRetries,
+		ReadRetryBackoff: time.Duration(redisReadRetryBackoffMs) * time.Millisecond,
+		Log:              nil,
+		KeyPrefix:        redisKeyPrefix,
+
+		SentinelAddrs:      redisSentinelAddrs,
+		SentinelMasterName: redisSentinelMasterName,
+		SentinelPassword:   redisSentinelPassword,
+		ClusterAddrs:       redisClusterAddrs,
+	}
+}
+
 func PubkeyHexToLowerStr(pk boostTypes.PubkeyHex) string {
 	return strings.ToLower(string(pk))
 }
 
-func connectRedis(redisURI string) (*redis.Client, error) {
+func connectRedis(redisURI string, opts RedisOptions) (redis.UniversalClient, error) { //nolint:ireturn
+	if len(opts.SentinelAddrs) > 0 && opts.clusterMode() {
+		return nil, ErrRedisSentinelAndCluster
+	} else if len(opts.SentinelAddrs) > 0 && opts.SentinelMasterName == "" {
+		return nil, ErrRedisNoSentinelMaster
+	}
+
 	// Handle both URIs and full URLs, assume unencrypted connections
 	if !strings.HasPrefix(redisURI, "redis://") && !strings.HasPrefix(redisURI, "rediss://") {
 		redisURI = "redis://" + redisURI
@@ -55,23 +161,37 @@ func connectRedis(redisURI string) (*redis.Client, error) {
 		return nil, err
 	}
 
-	if redisConnectionPoolSize > 0 {
-		redisOpts.PoolSize = redisConnectionPoolSize
+	if opts.PoolSize > 0 {
+		redisOpts.PoolSize = opts.PoolSize
 	}
-	if redisMinIdleConnections > 0 {
-		redisOpts.MinIdleConns = redisMinIdleConnections
+	if opts.MinIdleConns > 0 {
+		redisOpts.MinIdleConns = opts.MinIdleConns
 	}
-	if redisReadTimeoutSec > 0 {
-		redisOpts.ReadTimeout = time.Duration(redisReadTimeoutSec) * time.Second
+	if opts.DialTimeout > 0 {
+		redisOpts.DialTimeout = opts.DialTimeout
 	}
-	if redisPoolTimeoutSec > 0 {
-		redisOpts.PoolTimeout = time.Duration(redisPoolTimeoutSec) * time.Second
+	if opts.ReadTimeout > 0 {
+		redisOpts.ReadTimeout = opts.ReadTimeout
 	}
-	if redisWriteTimeoutSec > 0 {
-		redisOpts.WriteTimeout = time.Duration(redisWriteTimeoutSec) * time.Second
+	if opts.PoolTimeout > 0 {
+		redisOpts.PoolTimeout = opts.PoolTimeout
+	}
+	if opts.WriteTimeout > 0 {
+		redisOpts.WriteTimeout = opts.WriteTimeout
+	}
+	if opts.MaxRetries != 0 {
+		redisOpts.MaxRetries = opts.MaxRetries
 	}
 
-	redisClient := redis.NewClient(redisOpts)
+	var redisClient redis.UniversalClient
+	switch {
+	case len(opts.SentinelAddrs) > 0:
+		redisClient = redis.NewFailoverClient(failoverOptions(redisOpts, opts))
+	case opts.clusterMode():
+		redisClient = redis.NewClusterClient(clusterOptions(redisOpts, opts))
+	default:
+		redisClient = redis.NewClient(redisOpts)
+	}
 	if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
 		// unable to connect to redis
 		return nil, err
@@ -79,13 +199,58 @@ func connectRedis(redisURI string) (*redis.Client, error) {
 	return redisClient, nil
 }
 
+// failoverOptions returns the options of a sentinel-backed client, with the connection settings of the Redis URI
+func failoverOptions(redisOpts *redis.Options, opts RedisOptions) *redis.FailoverOptions {
+	return &redis.FailoverOptions{ //nolint:exhaustruct
+		MasterName:       opts.SentinelMasterName,
+		SentinelAddrs:    opts.SentinelAddrs,
+		SentinelPassword: opts.SentinelPassword,
+
+		Username:     redisOpts.Username,
+		Password:     redisOpts.Password,
+		DB:           redisOpts.DB,
+		TLSConfig:    redisOpts.TLSConfig,
+		PoolSize:     redisOpts.PoolSize,
+		MinIdleConns: redisOpts.MinIdleConns,
+		DialTimeout:  redisOpts.DialTimeout,
+		ReadTimeout:  redisOpts.ReadTimeout,
+		WriteTimeout: redisOpts.WriteTimeout,
+		PoolTimeout:  redisOpts.PoolTimeout,
+		MaxRetries:   redisOpts.MaxRetries,
+	}
+}
+
+// clusterOptions returns the options of a cluster client, with the connection settings of the Redis URI
+func clusterOptions(redisOpts *redis.Options, opts RedisOptions) *redis.ClusterOptions {
+	return &redis.ClusterOptions{ //nolint:exhaustruct
+		Addrs: opts.ClusterAddrs,
+
+		Username:     redisOpts.Username,
+		Password:     redisOpts.Password,
+		TLSConfig:    redisOpts.TLSConfig,
+		PoolSize:     redisOpts.PoolSize,
+		MinIdleConns: redisOpts.MinIdleConns,
+		DialTimeout:  redisOpts.DialTimeout,
+		ReadTimeout:  redisOpts.ReadTimeout,
+		WriteTimeout: redisOpts.WriteTimeout,
+		PoolTimeout:  redisOpts.PoolTimeout,
+		MaxRetries:   redisOpts.MaxRetries,
+	}
+}
+
 type RedisCache struct {
-	client         *redis.Client
-	readonlyClient *redis.Client
+	client         redis.UniversalClient
+	readClient     redis.UniversalClient // for the reads retried by withReadRetries, so the retries don't stack on MaxRetries
+	readonlyClient redis.UniversalClient
+
+	readRetries      int
+	readRetryBackoff time.Duration
+	log              *logrus.Entry
 
 	// prefixes (keys generated with a function)
 	prefixGetHeaderResponse           string
 	prefixExecPayloadCapella          string
+	prefixExecPayloadDeneb            string
 	prefixBidTrace                    string
 	prefixBlockBuilderLatestBids      string // latest bid for a given slot
 	prefixBlockBuilderLatestBidsValue string // value of latest bid for a given slot
@@ -93,6 +258,8 @@ type RedisCache struct {
 	prefixTopBidValue                 string
 	prefixFloorBid                    string
 	prefixFloorBidValue               string
+	prefixBidHistory                  string
+	prefixBuilderOutcomes             string
 
 	// keys
 	keyValidatorRegistrationTimestamp string
@@ -101,47 +268,89 @@ type RedisCache struct {
 	keyStats              string
 	keyProposerDuties     string
 	keyBlockBuilderStatus string
+	keyBuilderReputation  string
 	keyLastSlotDelivered  string
 	keyLastHashDelivered  string
+	keyPruneLock          string
 }
 
 func NewRedisCache(prefix, redisURI, readonlyURI string) (*RedisCache, error) {
-	client, err := connectRedis(redisURI)
+	return NewRedisCacheWithOptions(prefix, redisURI, readonlyURI, DefaultRedisOptions())
+}
+
+func NewRedisCacheWithOptions(prefix, redisURI, readonlyURI string, opts RedisOptions) (*RedisCache, error) {
+	client, err := connectRedis(redisURI, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	roClient := client
+	readOpts := opts
+	readClient := client
+	if opts.ReadRetries > 0 {
+		readOpts = opts.withoutRetries()
+		readClient, err = connectRedis(redisURI, readOpts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	roClient := readClient
 	if readonlyURI != "" {
-		roClient, err = connectRedis(readonlyURI)
+		roClient, err = connectRedis(readonlyURI, readOpts.singleNode())
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	log := opts.Log
+	if log == nil {
+		log = logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	redisPrefix := opts.KeyPrefix
+	if redisPrefix == "" {
+		redisPrefix = defaultRedisKeyPrefix
+	}
+	namespace := fmt.Sprintf("%s/%s", redisPrefix, prefix)
+	if opts.clusterMode() {
+		// A hash tag puts all keys into the same hash slot, as transactions and multi-key commands need their keys in one
+		// slot. Cluster mode gives failover, not sharding.
+		namespace = "{" + namespace + "}"
+	}
+
 	return &RedisCache{
 		client:         client,
+		readClient:     readClient,
 		readonlyClient: roClient,
 
-		prefixGetHeaderResponse:  fmt.Sprintf("%s/%s:cache-gethead-response", redisPrefix, prefix),
-		prefixExecPayloadCapella: fmt.Sprintf("%s/%s:cache-execpayload-capella", redisPrefix, prefix),
-		prefixBidTrace:           fmt.Sprintf("%s/%s:cache-bid-trace", redisPrefix, prefix),
-
-		prefixBlockBuilderLatestBids:      fmt.Sprintf("%s/%s:block-builder-latest-bid", redisPrefix, prefix),       // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
-		prefixBlockBuilderLatestBidsValue: fmt.Sprintf("%s/%s:block-builder-latest-bid-value", redisPrefix, prefix), // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
-		prefixBlockBuilderLatestBidsTime:  fmt.Sprintf("%s/%s:block-builder-latest-bid-time", redisPrefix, prefix),  // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
-		prefixTopBidValue:                 fmt.Sprintf("%s/%s:top-bid-value", redisPrefix, prefix),                  // prefix:slot_parentHash_proposerPubkey
-		prefixFloorBid:                    fmt.Sprintf("%s/%s:bid-floor", redisPrefix, prefix),                      // prefix:slot_parentHash_proposerPubkey
-		prefixFloorBidValue:               fmt.Sprintf("%s/%s:bid-floor-value", redisPrefix, prefix),                // prefix:slot_parentHash_proposerPubkey
-
-		keyValidatorRegistrationTimestamp: fmt.Sprintf("%s/%s:validator-registration-timestamp", redisPrefix, prefix),
-		keyRelayConfig:                    fmt.Sprintf("%s/%s:relay-config", redisPrefix, prefix),
-
-		keyStats:              fmt.Sprintf("%s/%s:stats", redisPrefix, prefix),
-		keyProposerDuties:     fmt.Sprintf("%s/%s:proposer-duties", redisPrefix, prefix),
-		keyBlockBuilderStatus: fmt.Sprintf("%s/%s:block-builder-status", redisPrefix, prefix),
-		keyLastSlotDelivered:  fmt.Sprintf("%s/%s:last-slot-delivered", redisPrefix, prefix),
-		keyLastHashDelivered:  fmt.Sprintf("%s/%s:last-hash-delivered", redisPrefix, prefix),
+		readRetries:      opts.ReadRetries,
+		readRetryBackoff: opts.ReadRetryBackoff,
+		log:              log.WithField("module", "redis"),
+
+		prefixGetHeaderResponse:  fmt.Sprintf("%s:cache-gethead-response", namespace),
+		prefixExecPayloadCapella: fmt.Sprintf("%s:cache-execpayload-capella", namespace),
+		prefixExecPayloadDeneb:   fmt.Sprintf("%s:cache-execpayload-deneb", namespace),
+		prefixBidTrace:           fmt.Sprintf("%s:cache-bid-trace", namespace),
+
+		prefixBlockBuilderLatestBids:      fmt.Sprintf("%s:block-builder-latest-bid", namespace),       // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
+		prefixBlockBuilderLatestBidsValue: fmt.Sprintf("%s:block-builder-latest-bid-value", namespace), // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
+		prefixBlockBuilderLatestBidsTime:  fmt.Sprintf("%s:block-builder-latest-bid-time", namespace),  // hashmap for slot+parentHash+proposerPubkey with builderPubkey as field
+		prefixTopBidValue:                 fmt.Sprintf("%s:top-bid-value", namespace),                  // prefix:slot_parentHash_proposerPubkey
+		prefixFloorBid:                    fmt.Sprintf("%s:bid-floor", namespace),                      // prefix:slot_parentHash_proposerPubkey
+		prefixFloorBidValue:               fmt.Sprintf("%s:bid-floor-value", namespace),                // prefix:slot_parentHash_proposerPubkey
+		prefixBidHistory:                  fmt.Sprintf("%s:bid-history", namespace),                    // list per slot, prefix:slot
+		prefixBuilderOutcomes:             fmt.Sprintf("%s:builder-outcomes", namespace),               // list per builder, prefix:builderPubkey
+
+		keyValidatorRegistrationTimestamp: fmt.Sprintf("%s:validator-registration-timestamp", namespace),
+		keyRelayConfig:                    fmt.Sprintf("%s:relay-config", namespace),
+
+		keyStats:              fmt.Sprintf("%s:stats", namespace),
+		keyProposerDuties:     fmt.Sprintf("%s:proposer-duties", namespace),
+		keyBlockBuilderStatus: fmt.Sprintf("%s:block-builder-status", namespace),
+		keyBuilderReputation:  fmt.Sprintf("%s:builder-reputation", namespace),
+		keyLastSlotDelivered:  fmt.Sprintf("%s:last-slot-delivered", namespace),
+		keyLastHashDelivered:  fmt.Sprintf("%s:last-hash-delivered", namespace),
+		keyPruneLock:          fmt.Sprintf("%s:prune-lock", namespace),
 	}, nil
 }
 
@@ -153,6 +362,10 @@ func (r *RedisCache) keyExecPayloadCapella(slot uint64, proposerPubkey, blockHas
 	return fmt.Sprintf("%s:%d_%s_%s", r.prefixExecPayloadCapella, slot, proposerPubkey, blockHash)
 }
 
+func (r *RedisCache) keyExecPayloadDeneb(slot uint64, proposerPubkey, blockHash string) string {
+	return fmt.Sprintf("%s:%d_%s_%s", r.prefixExecPayloadDeneb, slot, proposerPubkey, blockHash)
+}
+
 func (r *RedisCache) keyCacheBidTrace(slot uint64, proposerPubkey, blockHash string) string {
 	return fmt.Sprintf("%s:%d_%s_%s", r.prefixBidTrace, slot, proposerPubkey, blockHash)
 }
@@ -187,8 +400,49 @@ func (r *RedisCache) keyFloorBidValue(slot uint64, parentHash, proposerPubkey st
 	return fmt.Sprintf("%s:%d_%s_%s", r.prefixFloorBidValue, slot, parentHash, proposerPubkey)
 }
 
+// keyBuilderOutcomes returns the key for the list of the latest delivered (1) and invalid (0) blocks of a builder
+func (r *RedisCache) keyBuilderOutcomes(builderPubkey string) string {
+	return fmt.Sprintf("%s:%s", r.prefixBuilderOutcomes, builderPubkey)
+}
+
+// keyBidHistory returns the key for the list of bids received in a slot
+func (r *RedisCache) keyBidHistory(slot uint64) string {
+	return fmt.Sprintf("%s:%d", r.prefixBidHistory, slot)
+}
+
+// isTransientRedisError returns true for errors which may go away on retry (i.e. network errors)
+func isTransientRedisError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF)
+}
+
+// withReadRetries calls fn until it succeeds or fails with a non-transient error, backing off exponentially between attempts
+func (r *RedisCache) withReadRetries(key string, fn func() error) error {
+	backoff := r.readRetryBackoff
+	err := fn()
+	for attempt := 1; attempt <= r.readRetries && isTransientRedisError(err); attempt++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		err = fn()
+	}
+	if r.readRetries > 0 && isTransientRedisError(err) {
+		r.log.WithError(err).WithFields(logrus.Fields{
+			"key":     key,
+			"retries": r.readRetries,
+		}).Error("redis read failed, retries exhausted")
+	}
+	return err
+}
+
 func (r *RedisCache) GetObj(key string, obj any) (err error) {
-	value, err := r.client.Get(context.Background(), key).Result()
+	var value string
+	err = r.withReadRetries(key, func() (err error) {
+		value, err = r.readClient.Get(context.Background(), key).Result()
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -237,15 +491,12 @@ func (r *RedisCache) GetValidatorRegistrationTimestamp(proposerPubkey boostTypes
 	return timestamp, err
 }
 
+// SetValidatorRegistrationTimestampIfNewer stores the timestamp only if it is later than the known one. Registrations
+// are saved concurrently, so the check and update of the validator's field happen atomically in a script (a WATCH of
+// the hash would abort the transactions for all other validators saved at the same time).
 func (r *RedisCache) SetValidatorRegistrationTimestampIfNewer(proposerPubkey boostTypes.PubkeyHex, timestamp uint64) error {
-	knownTimestamp, err := r.GetValidatorRegistrationTimestamp(proposerPubkey)
-	if err != nil {
-		return err
-	}
-	if knownTimestamp >= timestamp {
-		return nil
-	}
-	return r.SetValidatorRegistrationTimestamp(proposerPubkey, timestamp)
+	field := strings.ToLower(proposerPubkey.String())
+	return setRegistrationTimestampIfNewerScript.Run(context.Background(), r.client, []string{r.keyValidatorRegistrationTimestamp}, field, timestamp).Err()
 }
 
 func (r *RedisCache) SetValidatorRegistrationTimestamp(proposerPubkey boostTypes.PubkeyHex, timestamp uint64) error {
@@ -372,7 +623,11 @@ func (r *RedisCache) GetExecutionPayloadCapella(slot uint64, proposerPubkey, blo
 	capellaPayload := new(capella.ExecutionPayload)
 
 	key := r.keyExecPayloadCapella(slot, proposerPubkey, blockHash)
-	val, err := r.client.Get(context.Background(), key).Result()
+	var val string
+	err := r.withReadRetries(key, func() (err error) {
+		val, err = r.readClient.Get(context.Background(), key).Result()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -388,6 +643,33 @@ func (r *RedisCache) GetExecutionPayloadCapella(slot uint64, proposerPubkey, blo
 	return resp, nil
 }
 
+// SaveExecutionPayloadDeneb stores the execution payload together with its blobs bundle, as JSON because there is no SSZ
+// encoding of the blobs bundle available yet
+func (r *RedisCache) SaveExecutionPayloadDeneb(ctx context.Context, tx redis.Pipeliner, slot uint64, proposerPubkey, blockHash string, execPayload *common.DenebExecutionPayloadAndBlobsBundle) (err error) {
+	key := r.keyExecPayloadDeneb(slot, proposerPubkey, blockHash)
+	return r.SetObjPipelined(ctx, tx, key, execPayload, expiryBidCache)
+}
+
+func (r *RedisCache) GetExecutionPayloadDeneb(slot uint64, proposerPubkey, blockHash string) (*common.VersionedExecutionPayload, error) {
+	denebPayload := new(common.DenebExecutionPayloadAndBlobsBundle)
+
+	key := r.keyExecPayloadDeneb(slot, proposerPubkey, blockHash)
+	var val []byte
+	err := r.withReadRetries(key, func() (err error) {
+		val, err = r.readClient.Get(context.Background(), key).Bytes()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(val, denebPayload)
+	if err != nil {
+		return nil, err
+	}
+	return &common.VersionedExecutionPayload{Deneb: denebPayload}, nil //nolint:exhaustruct
+}
+
 func (r *RedisCache) SaveBidTrace(ctx context.Context, tx redis.Pipeliner, trace *common.BidTraceV2) (err error) {
 	key := r.keyCacheBidTrace(trace.Slot, trace.ProposerPubkey.String(), trace.BlockHash.String())
 	return r.SetObjPipelined(ctx, tx, key, trace, expiryBidCache)
@@ -449,8 +731,9 @@ type SaveBidAndUpdateTopBidResponse struct {
 	WasTopBidUpdated bool // Whether the top bid was updated
 	IsNewTopBid      bool // Whether the submitted bid became the new top bid
 
-	TopBidValue     *big.Int
-	PrevTopBidValue *big.Int
+	TopBidValue         *big.Int
+	PrevTopBidValue     *big.Int
+	TopBidBuilderPubkey string // Builder of the top bid, empty if the top bid wasn't selected again or is the floor bid
 
 	TimePrep         time.Duration
 	TimeSavePayload  time.Duration
@@ -500,7 +783,11 @@ func (r *RedisCache) SaveBidAndUpdateTopBid(ctx context.Context, tx redis.Pipeli
 	// Time to save things in Redis
 	//
 	// 1. Save the execution payload
-	err = r.SaveExecutionPayloadCapella(ctx, tx, payload.Slot(), payload.ProposerPubkey(), payload.BlockHash(), getPayloadResponse.Capella.Capella)
+	if getPayloadResponse.Deneb != nil {
+		err = r.SaveExecutionPayloadDeneb(ctx, tx, payload.Slot(), payload.ProposerPubkey(), payload.BlockHash(), getPayloadResponse.Deneb)
+	} else {
+		err = r.SaveExecutionPayloadCapella(ctx, tx, payload.Slot(), payload.ProposerPubkey(), payload.BlockHash(), getPayloadResponse.Capella.Capella)
+	}
 	if err != nil {
 		return state, err
 	}
@@ -534,9 +821,10 @@ func (r *RedisCache) SaveBidAndUpdateTopBid(ctx context.Context, tx redis.Pipeli
 	state.TimeSaveTrace = nextTime.Sub(prevTime)
 	prevTime = nextTime
 
-	// If top bid value hasn't change, abort now
+	// If top bid value hasn't change, abort now (unless the bid ties with the top bid, which is then selected again by
+	// builder reputation)
 	_, state.TopBidValue = builderBids.getTopBid()
-	if state.TopBidValue.Cmp(state.PrevTopBidValue) == 0 {
+	if state.TopBidValue.Cmp(state.PrevTopBidValue) == 0 && payload.Value().Cmp(state.TopBidValue) != 0 {
 		return state, nil
 	}
 
@@ -544,7 +832,10 @@ func (r *RedisCache) SaveBidAndUpdateTopBid(ctx context.Context, tx redis.Pipeli
 	if err != nil {
 		return state, err
 	}
-	state.IsNewTopBid = payload.Value().Cmp(state.TopBidValue) == 0
+	state.IsNewTopBid = strings.EqualFold(state.TopBidBuilderPubkey, payload.BuilderPubkey().String())
+	if state.IsNewTopBid {
+		state.WasTopBidUpdated = true
+	}
 
 	// Record time needed to update top bid
 	nextTime = time.Now().UTC()
@@ -599,10 +890,6 @@ func (r *RedisCache) _updateTopBid(ctx context.Context, tx redis.Pipeliner, stat
 		}
 	}
 
-	if len(builderBids.bidValues) == 0 {
-		return state, nil
-	}
-
 	// Load floor value (if not passed in already)
 	if floorValue == nil {
 		floorValue, err = r.GetFloorBidValue(ctx, tx, slot, parentHash, proposerPubkey)
@@ -611,18 +898,38 @@ func (r *RedisCache) _updateTopBid(ctx context.Context, tx redis.Pipeliner, stat
 		}
 	}
 
-	topBidBuilder := ""
-	topBidBuilder, state.TopBidValue = builderBids.getTopBid()
+	keyTopBid := r.keyCacheGetHeaderResponse(slot, parentHash, proposerPubkey)
+	keyTopBidValue := r.keyTopBidValue(slot, parentHash, proposerPubkey)
+
+	// Without any bids and no floor bid (i.e. after the last bid was cancelled), there's no top bid anymore
+	if len(builderBids.bidValues) == 0 && floorValue.Sign() == 0 {
+		err = tx.Del(ctx, keyTopBid, keyTopBidValue).Err()
+		if err != nil {
+			return state, err
+		}
+		state.TopBidValue = big.NewInt(0)
+		state.WasTopBidUpdated = state.PrevTopBidValue == nil || state.PrevTopBidValue.Sign() != 0
+		_, err = tx.Exec(ctx)
+		return state, err
+	}
+
+	topBidBuilders, topBidValue := builderBids.getTopBids()
+	topBidBuilder, err := r.selectByReputation(ctx, topBidBuilders)
+	if err != nil {
+		return state, err
+	}
+	state.TopBidValue = topBidValue
+	state.TopBidBuilderPubkey = topBidBuilder
 	keyBidSource := r.keyLatestBidByBuilder(slot, parentHash, proposerPubkey, topBidBuilder)
 
 	// If floor value is higher than this bid, use floor bid instead
 	if floorValue.Cmp(state.TopBidValue) == 1 {
 		state.TopBidValue = floorValue
+		state.TopBidBuilderPubkey = ""
 		keyBidSource = r.keyFloorBid(slot, parentHash, proposerPubkey)
 	}
 
 	// Copy winning bid to top bid cache
-	keyTopBid := r.keyCacheGetHeaderResponse(slot, parentHash, proposerPubkey)
 	c := tx.Copy(context.Background(), keyBidSource, keyTopBid, 0, true)
 	_, err = tx.Exec(ctx)
 	if err != nil {
@@ -642,7 +949,6 @@ func (r *RedisCache) _updateTopBid(ctx context.Context, tx redis.Pipeliner, stat
 	state.WasTopBidUpdated = state.PrevTopBidValue == nil || state.PrevTopBidValue.Cmp(state.TopBidValue) != 0
 
 	// 6. Finally, update the global top bid value
-	keyTopBidValue := r.keyTopBidValue(slot, parentHash, proposerPubkey)
 	err = tx.Set(context.Background(), keyTopBidValue, state.TopBidValue.String(), expiryBidCache).Err()
 	if err != nil {
 		return state, err
@@ -672,6 +978,22 @@ func (r *RedisCache) GetTopBidValue(ctx context.Context, tx redis.Pipeliner, slo
 	return topBidValue, nil
 }
 
+// GetBuilderLatestBid returns the getHeader response of the latest bid of a builder, or nil if the builder has no bid
+func (r *RedisCache) GetBuilderLatestBid(slot uint64, parentHash, proposerPubkey, builderPubkey string) (*common.GetHeaderResponse, error) {
+	key := r.keyLatestBidByBuilder(slot, parentHash, proposerPubkey, builderPubkey)
+	resp := new(common.GetHeaderResponse)
+	err := r.GetObj(key, resp)
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return resp, err
+}
+
+// GetNumBuilderBids returns the number of builders with a bid for the slot, parent hash and proposer
+func (r *RedisCache) GetNumBuilderBids(ctx context.Context, slot uint64, parentHash, proposerPubkey string) (int64, error) {
+	return r.client.HLen(ctx, r.keyBlockBuilderLatestBidsValue(slot, parentHash, proposerPubkey)).Result()
+}
+
 // GetBuilderLatestValue gets the latest bid value for a given slot+parent+proposer combination for a specific builder pubkey.
 func (r *RedisCache) GetBuilderLatestValue(slot uint64, parentHash, proposerPubkey, builderPubkey string) (topBidValue *big.Int, err error) {
 	keyLatestValue := r.keyBlockBuilderLatestBidsValue(slot, parentHash, proposerPubkey)
@@ -729,6 +1051,11 @@ func (r *RedisCache) GetFloorBidValue(ctx context.Context, tx redis.Pipeliner, s
 	return floorValue, nil
 }
 
+// Ping checks that the main Redis instance is reachable
+func (r *RedisCache) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 func (r *RedisCache) NewPipeline() redis.Pipeliner { //nolint:ireturn,nolintlint
 	return r.client.Pipeline()
 }
@@ -0,0 +1,49 @@
+package datastore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+// AddBidHistoryEntries appends bids to the bid history of their slot, keeping the latest maxEntries bids per slot.
+// The history of a slot expires after the given retention.
+func (r *RedisCache) AddBidHistoryEntries(ctx context.Context, entries []*common.BidHistoryEntry, maxEntries int, retention time.Duration) error {
+	tx := r.client.Pipeline()
+	for _, entry := range entries {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		key := r.keyBidHistory(entry.Slot)
+		tx.RPush(ctx, key, b)
+		tx.LTrim(ctx, key, int64(-maxEntries), -1)
+		tx.Expire(ctx, key, retention)
+	}
+	_, err := tx.Exec(ctx)
+	return err
+}
+
+// GetBidHistory returns the bids received in a slot, in the order they were received
+func (r *RedisCache) GetBidHistory(slot uint64) (entries []*common.BidHistoryEntry, err error) {
+	key := r.keyBidHistory(slot)
+	var values []string
+	err = r.withReadRetries(key, func() (err error) {
+		values, err = r.readonlyClient.LRange(context.Background(), key, 0, -1).Result()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries = make([]*common.BidHistoryEntry, len(values))
+	for i, value := range values {
+		entries[i] = new(common.BidHistoryEntry)
+		if err := json.Unmarshal([]byte(value), entries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
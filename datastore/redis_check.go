@@ -0,0 +1,298 @@
+package datastore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/go-redis/redis/v9"
+)
+
+// TTL of keys without expiry, and of keys which don't exist
+const (
+	ttlNoExpiry    = time.Duration(-1)
+	ttlKeyNotFound = time.Duration(-2)
+)
+
+var (
+	integrityScanBatchSize = int64(1000)
+
+	// suffix of all slot-specific keys: slot_hash_pubkey or slot_pubkey_hash, with a builder pubkey for the latest bids
+	reSlotKeySuffix = regexp.MustCompile(`^[0-9]+_0x[0-9a-fA-F]+_0x[0-9a-fA-F]+(/0x[0-9a-fA-F]+)?$`)
+)
+
+// IntegrityProblem is a key found by CheckIntegrity, with the reason why it is considered orphaned or malformed
+type IntegrityProblem struct {
+	Key    string
+	Reason string
+
+	// Orphaned as the key has no expiry, or as none of the counterparts (i.e. the execution payloads of a bid trace)
+	// exists. Without either, the key is orphaned as long as it exists.
+	noExpiry     bool
+	counterparts []string
+}
+
+// IntegrityReport is the result of CheckIntegrity
+type IntegrityReport struct {
+	NumKeysScanned int
+	Orphaned       []IntegrityProblem
+	Malformed      []IntegrityProblem
+	NumDeleted     int
+}
+
+// slotKeyPrefixes returns the prefixes of all slot-specific keys (prefix:slot_...). They are always stored with an expiry.
+func (r *RedisCache) slotKeyPrefixes() []string {
+	return []string{
+		r.prefixGetHeaderResponse,
+		r.prefixExecPayloadCapella,
+		r.prefixExecPayloadDeneb,
+		r.prefixBidTrace,
+		r.prefixBlockBuilderLatestBids,
+		r.prefixBlockBuilderLatestBidsValue,
+		r.prefixBlockBuilderLatestBidsTime,
+		r.prefixTopBidValue,
+		r.prefixFloorBid,
+		r.prefixFloorBidValue,
+	}
+}
+
+// CheckIntegrity scans the slot-specific keys of slots before the given one (i.e. the head slot) and reports:
+//
+// - malformed keys (unexpected key format, or a bid trace which can't be decoded)
+// - orphaned keys (without expiry, or a bid trace without execution payload and vice versa)
+//
+// With fix set, the reported keys are deleted. Keys of the current slots are skipped, as their bid trace and execution
+// payload may be written in between the scans, and orphaned keys are checked again before they are reported and
+// before they are deleted, so that it's safe to run alongside live traffic.
+func (r *RedisCache) CheckIntegrity(ctx context.Context, beforeSlot uint64, fix bool) (*IntegrityReport, error) {
+	report := &IntegrityReport{}               //nolint:exhaustruct
+	bidTraceSuffixes := make(map[string]bool)  // suffix -> whether the bid trace can be decoded
+	payloadSuffixes := make(map[string]string) // suffix -> prefix of the execution payload key
+
+	scanClient, err := r.scanClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, prefix := range r.slotKeyPrefixes() {
+		var cursor uint64
+		for {
+			keys, nextCursor, err := scanClient.Scan(ctx, cursor, prefix+":*", integrityScanBatchSize).Result()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, key := range keys {
+				suffix := strings.TrimPrefix(key, prefix+":")
+				if !reSlotKeySuffix.MatchString(suffix) {
+					report.NumKeysScanned++
+					report.Malformed = append(report.Malformed, IntegrityProblem{Key: key, Reason: "unexpected key format"}) //nolint:exhaustruct
+					continue
+				}
+				if keySlot, ok := slotOfKey(key, prefix); !ok || keySlot >= beforeSlot {
+					continue
+				}
+				report.NumKeysScanned++
+
+				ttl, err := r.client.TTL(ctx, key).Result()
+				if err != nil {
+					return nil, err
+				}
+				if ttl == ttlKeyNotFound { // expired or deleted in the meantime
+					continue
+				} else if ttl == ttlNoExpiry {
+					report.Orphaned = append(report.Orphaned, IntegrityProblem{Key: key, Reason: "no expiry", noExpiry: true}) //nolint:exhaustruct
+					continue
+				}
+
+				switch prefix {
+				case r.prefixBidTrace:
+					trace := new(common.BidTraceV2)
+					val, err := r.client.Get(ctx, key).Bytes()
+					if errors.Is(err, redis.Nil) {
+						continue
+					} else if err != nil {
+						return nil, err
+					}
+					if err := json.Unmarshal(val, trace); err != nil {
+						report.Malformed = append(report.Malformed, IntegrityProblem{Key: key, Reason: "invalid bid trace: " + err.Error()}) //nolint:exhaustruct
+						bidTraceSuffixes[suffix] = false
+						continue
+					}
+					bidTraceSuffixes[suffix] = true
+				case r.prefixExecPayloadCapella, r.prefixExecPayloadDeneb:
+					payloadSuffixes[suffix] = prefix
+				}
+			}
+
+			cursor = nextCursor
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+
+	// Bid traces and execution payloads are stored together, for the same slot, proposer and block hash
+	candidates := []IntegrityProblem{}
+	for suffix, valid := range bidTraceSuffixes {
+		if _, found := payloadSuffixes[suffix]; valid && !found {
+			candidates = append(candidates, IntegrityProblem{ //nolint:exhaustruct
+				Key:          r.prefixBidTrace + ":" + suffix,
+				Reason:       "bid trace without execution payload",
+				counterparts: []string{r.prefixExecPayloadCapella + ":" + suffix, r.prefixExecPayloadDeneb + ":" + suffix},
+			})
+		}
+	}
+	for suffix, prefix := range payloadSuffixes {
+		if valid, found := bidTraceSuffixes[suffix]; !found {
+			candidates = append(candidates, IntegrityProblem{ //nolint:exhaustruct
+				Key:          prefix + ":" + suffix,
+				Reason:       "execution payload without bid trace",
+				counterparts: []string{r.prefixBidTrace + ":" + suffix},
+			})
+		} else if !valid { // the malformed bid trace is reported (and deleted) as well
+			candidates = append(candidates, IntegrityProblem{Key: prefix + ":" + suffix, Reason: "execution payload of an invalid bid trace"}) //nolint:exhaustruct
+		}
+	}
+	for _, candidate := range candidates {
+		orphaned, err := r.isOrphaned(ctx, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if orphaned {
+			report.Orphaned = append(report.Orphaned, candidate)
+		}
+	}
+
+	if fix {
+		keys := make([]string, 0, len(report.Orphaned)+len(report.Malformed))
+		for _, problem := range report.Orphaned {
+			orphaned, err := r.isOrphaned(ctx, problem)
+			if err != nil {
+				return report, err
+			} else if orphaned {
+				keys = append(keys, problem.Key)
+			}
+		}
+		for _, problem := range report.Malformed {
+			keys = append(keys, problem.Key)
+		}
+		for _, key := range keys {
+			numDeleted, err := r.client.Del(ctx, key).Result()
+			if err != nil {
+				return report, err
+			}
+			report.NumDeleted += int(numDeleted)
+		}
+	}
+
+	return report, nil
+}
+
+// isOrphaned checks again whether the key of an orphaned problem is still orphaned, i.e. the key still has no expiry,
+// or none of its counterparts has been written in the meantime
+func (r *RedisCache) isOrphaned(ctx context.Context, problem IntegrityProblem) (bool, error) {
+	if problem.noExpiry {
+		ttl, err := r.client.TTL(ctx, problem.Key).Result()
+		return ttl == ttlNoExpiry, err
+	}
+	if len(problem.counterparts) > 0 {
+		numCounterparts, err := r.client.Exists(ctx, problem.counterparts...).Result()
+		if err != nil || numCounterparts > 0 {
+			return false, err
+		}
+	}
+	numExisting, err := r.client.Exists(ctx, problem.Key).Result()
+	return numExisting > 0, err
+}
+
+// scanClient returns the client to scan the keys with. In cluster mode, SCAN only covers a single node, which is the
+// primary of the hash slot of all keys.
+func (r *RedisCache) scanClient(ctx context.Context) (redis.Cmdable, error) { //nolint:ireturn
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		return cluster.MasterForKey(ctx, r.keyStats)
+	}
+	return r.client, nil
+}
+
+// slotOfKey returns the slot of a slot-specific key
+func slotOfKey(key, prefix string) (uint64, bool) {
+	slotStr, _, found := strings.Cut(strings.TrimPrefix(key, prefix+":"), "_")
+	if !found {
+		return 0, false
+	}
+	slot, err := strconv.ParseUint(slotStr, 10, 64)
+	return slot, err == nil
+}
+
+// PruneSlotsBefore deletes the slot-specific keys of slots before the given one, which are left behind if they were
+// written without expiry or with a long REDIS_BID_EXPIRY_SEC. The keys are scanned and deleted in batches, so it's safe
+// to run alongside live traffic. If archive is set, the bid traces are written to it as JSON lines before deletion.
+func (r *RedisCache) PruneSlotsBefore(ctx context.Context, slot uint64, archive io.Writer) (numDeleted int, err error) {
+	scanClient, err := r.scanClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, prefix := range r.slotKeyPrefixes() {
+		var cursor uint64
+		for {
+			keys, nextCursor, err := scanClient.Scan(ctx, cursor, prefix+":*", integrityScanBatchSize).Result()
+			if err != nil {
+				return numDeleted, err
+			}
+
+			oldKeys := make([]string, 0, len(keys))
+			for _, key := range keys {
+				if keySlot, ok := slotOfKey(key, prefix); ok && keySlot < slot {
+					oldKeys = append(oldKeys, key)
+				}
+			}
+			if len(oldKeys) > 0 {
+				if archive != nil && prefix == r.prefixBidTrace {
+					if err := r.archiveValues(ctx, oldKeys, archive); err != nil {
+						return numDeleted, err
+					}
+				}
+				if err := r.client.Del(ctx, oldKeys...).Err(); err != nil {
+					return numDeleted, err
+				}
+				numDeleted += len(oldKeys)
+			}
+
+			cursor = nextCursor
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+	return numDeleted, nil
+}
+
+// TryLockPruning takes the pruning lock for ttl unless another instance holds it, so that only one of the instances
+// sharing this Redis scans it for PruneSlotsBefore. The lock isn't released, it expires.
+func (r *RedisCache) TryLockPruning(ctx context.Context, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.keyPruneLock, 1, ttl).Result()
+}
+
+// archiveValues writes the values of the keys to w, one per line
+func (r *RedisCache) archiveValues(ctx context.Context, keys []string, w io.Writer) error {
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		str, ok := value.(string)
+		if !ok { // expired in the meantime
+			continue
+		}
+		if _, err := io.WriteString(w, str+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package datastore
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+var (
+	// The reputation of a builder is computed from its latest builderReputationWindow delivered payloads and invalid
+	// blocks, with invalid blocks weighted by builderReputationFailureWeight. It only decides between bids of equal
+	// value, 0 disables it.
+	builderReputationWindow           = cli.GetEnvInt("BUILDER_REPUTATION_WINDOW", 100)
+	builderReputationFailureWeight, _ = strconv.ParseFloat(common.GetEnv("BUILDER_REPUTATION_FAILURE_WEIGHT", "1"), 64)
+)
+
+// builderReputation returns the score of the outcomes (latest first): the delivered payloads minus the weighted invalid
+// blocks, per outcome. Between -builderReputationFailureWeight and 1, and 0 for builders without outcomes.
+func builderReputation(outcomes []string) float64 {
+	if len(outcomes) == 0 {
+		return 0
+	}
+	score := 0.0
+	for _, outcome := range outcomes {
+		if outcome == "1" {
+			score++
+		} else {
+			score -= builderReputationFailureWeight
+		}
+	}
+	return score / float64(len(outcomes))
+}
+
+// RecordBuilderOutcome adds a delivered payload (success) or an invalid block of the builder to its latest outcomes,
+// and updates its reputation
+func (r *RedisCache) RecordBuilderOutcome(ctx context.Context, builderPubkey string, success bool) error {
+	if builderReputationWindow <= 0 {
+		return nil
+	}
+
+	outcome := "0"
+	if success {
+		outcome = "1"
+	}
+	key := r.keyBuilderOutcomes(builderPubkey)
+	tx := r.client.TxPipeline()
+	tx.LPush(ctx, key, outcome)
+	tx.LTrim(ctx, key, 0, int64(builderReputationWindow-1))
+	c := tx.LRange(ctx, key, 0, -1)
+	if _, err := tx.Exec(ctx); err != nil {
+		return err
+	}
+
+	score := builderReputation(c.Val())
+	return r.client.HSet(ctx, r.keyBuilderReputation, builderPubkey, strconv.FormatFloat(score, 'f', -1, 64)).Err()
+}
+
+// selectByReputation returns the builder with the highest reputation of the builders with equal bids (sorted by
+// pubkey, the first one wins ties of the reputation)
+func (r *RedisCache) selectByReputation(ctx context.Context, builderPubkeys []string) (string, error) {
+	if len(builderPubkeys) == 0 {
+		return "", nil
+	}
+	if len(builderPubkeys) == 1 || builderReputationWindow <= 0 {
+		return builderPubkeys[0], nil
+	}
+
+	scores, err := r.client.HMGet(ctx, r.keyBuilderReputation, builderPubkeys...).Result()
+	if err != nil {
+		return "", err
+	}
+	best, bestScore := builderPubkeys[0], 0.0
+	for i, builderPubkey := range builderPubkeys {
+		score := 0.0
+		if s, ok := scores[i].(string); ok {
+			score, _ = strconv.ParseFloat(s, 64)
+		}
+		if i == 0 || score > bestScore {
+			best, bestScore = builderPubkey, score
+		}
+	}
+	return best, nil
+}
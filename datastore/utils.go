@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sort"
 
 	"github.com/go-redis/redis/v9"
 )
@@ -50,3 +51,24 @@ func (b *BuilderBids) getTopBid() (string, *big.Int) {
 	}
 	return topBidBuilderPubkey, topBidValue
 }
+
+// getTopBids returns the builders with the highest bid value, sorted by pubkey. Like getTopBid, bids need to be
+// above zero.
+func (b *BuilderBids) getTopBids() ([]string, *big.Int) {
+	topBidBuilderPubkeys := []string{}
+	topBidValue := big.NewInt(0)
+	for builderPubkey, bidValue := range b.bidValues {
+		if bidValue.Sign() <= 0 {
+			continue
+		}
+		switch bidValue.Cmp(topBidValue) {
+		case 1:
+			topBidValue = bidValue
+			topBidBuilderPubkeys = []string{builderPubkey}
+		case 0:
+			topBidBuilderPubkeys = append(topBidBuilderPubkeys, builderPubkey)
+		}
+	}
+	sort.Strings(topBidBuilderPubkeys)
+	return topBidBuilderPubkeys, topBidValue
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/sirupsen/logrus"
+	uberatomic "go.uber.org/atomic"
+)
+
+var (
+	ErrAlertWebhook = errors.New("alert webhook responded with error")
+
+	getPayloadAlertQueueSize = cli.GetEnvInt("ALERT_QUEUE_SIZE", 1000) // failures queued before new ones are dropped
+
+	// failed webhook posts are retried with exponential backoff
+	alertWebhookRetries = cli.GetEnvInt("ALERT_WEBHOOK_RETRIES", 5)
+	alertWebhookBackoff = time.Duration(cli.GetEnvInt("ALERT_WEBHOOK_BACKOFF_MS", 500)) * time.Millisecond
+	alertWebhookTimeout = 5 * time.Second
+)
+
+// getPayloadAlerts records getPayload requests whose execution payload couldn't be found in the database, and posts
+// them to the alert webhook if configured. A missed payload means a missed slot for the proposer, so this is the
+// failure operators need to hear about first. Failures are queued and handled by a single goroutine, so that the
+// getPayload response never waits for the database or the webhook.
+type getPayloadAlerts struct {
+	log        *logrus.Entry
+	db         database.IDatabaseService
+	webhookURL string
+	client     http.Client
+	entryC     chan *database.GetPayloadFailureEntry
+	numDropped uberatomic.Uint64
+}
+
+func newGetPayloadAlerts(log *logrus.Entry, db database.IDatabaseService, webhookURL string) *getPayloadAlerts {
+	return &getPayloadAlerts{
+		log:        log.WithField("component", "getPayloadAlerts"),
+		db:         db,
+		webhookURL: webhookURL,
+		client:     http.Client{Timeout: alertWebhookTimeout}, //nolint:exhaustruct
+		entryC:     make(chan *database.GetPayloadFailureEntry, getPayloadAlertQueueSize),
+	}
+}
+
+func (a *getPayloadAlerts) start() {
+	for entry := range a.entryC {
+		log := a.log.WithFields(logrus.Fields{
+			"slot":      entry.Slot,
+			"blockHash": entry.BlockHash,
+			"reason":    entry.Reason,
+		})
+		if err := a.db.InsertGetPayloadFailure(entry); err != nil {
+			log.WithError(err).Error("failed to save getPayload failure")
+		}
+		if a.webhookURL != "" {
+			if err := a.postWebhook(entry); err != nil {
+				log.WithError(err).Error("failed to post getPayload failure to the alert webhook")
+			}
+		}
+	}
+}
+
+// record queues the failure, or drops it if the queue is full. It is a no-op if alerts are disabled (nil).
+func (a *getPayloadAlerts) record(entry *database.GetPayloadFailureEntry) {
+	if a == nil {
+		return
+	}
+
+	select {
+	case a.entryC <- entry:
+	default:
+		numDropped := a.numDropped.Inc()
+		a.log.WithFields(logrus.Fields{
+			"slot":       entry.Slot,
+			"blockHash":  entry.BlockHash,
+			"numDropped": numDropped,
+		}).Error("getPayload alert queue full, dropping failure")
+	}
+}
+
+// alertWebhookPayload has a text field for Slack incoming webhooks, and the failure for other receivers
+type alertWebhookPayload struct {
+	Text    string                           `json:"text"`
+	Failure *database.GetPayloadFailureEntry `json:"failure"`
+}
+
+// postWebhook posts the failure to the webhook, retrying alertWebhookRetries times on errors
+func (a *getPayloadAlerts) postWebhook(entry *database.GetPayloadFailureEntry) error {
+	body, err := json.Marshal(alertWebhookPayload{
+		Text:    fmt.Sprintf("getPayload failed for slot %d (%s): proposer %s, block %s, %dms into the slot: %s", entry.Slot, entry.Reason, entry.ProposerPubkey, entry.BlockHash, entry.MsIntoSlot, entry.Error),
+		Failure: entry,
+	})
+	if err != nil {
+		return err
+	}
+
+	backoff := alertWebhookBackoff
+	for attempt := 0; ; attempt++ {
+		err = a.post(body)
+		if err == nil || attempt >= alertWebhookRetries {
+			return err
+		}
+		a.log.WithError(err).Warnf("alert webhook failed, retrying in %s", backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (a *getPayloadAlerts) post(body []byte) error {
+	resp, err := a.client.Post(a.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: status %d", ErrAlertWebhook, resp.StatusCode)
+	}
+	return nil
+}
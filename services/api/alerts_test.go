@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPayloadAlerts(t *testing.T) {
+	alertWebhookBackoff = time.Millisecond
+
+	numRequests := 0
+	received := make(chan alertWebhookPayload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// The first attempt fails and is retried
+		numRequests++
+		if numRequests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		payload := alertWebhookPayload{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&payload))
+		received <- payload
+	}))
+	t.Cleanup(srv.Close)
+
+	alerts := newGetPayloadAlerts(common.TestLog, database.MockDB{}, srv.URL)
+	go alerts.start()
+	alerts.record(&database.GetPayloadFailureEntry{Slot: 10, BlockHash: "0x01", Reason: database.GetPayloadFailureSubmissionFound}) //nolint:exhaustruct
+
+	select {
+	case payload := <-received:
+		require.Equal(t, uint64(10), payload.Failure.Slot)
+		require.Contains(t, payload.Text, "slot 10 (submission_found)")
+	case <-time.After(time.Second):
+		t.Fatal("alert not posted")
+	}
+	require.Equal(t, 2, numRequests)
+
+	// Disabled alerts don't record anything
+	var disabled *getPayloadAlerts
+	disabled.record(&database.GetPayloadFailureEntry{}) //nolint:exhaustruct
+}
+
+func TestGetPayloadAlertWebhookRetries(t *testing.T) {
+	alertWebhookBackoff = time.Millisecond
+
+	numRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		numRequests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+
+	alerts := newGetPayloadAlerts(common.TestLog, database.MockDB{}, srv.URL)
+	err := alerts.postWebhook(&database.GetPayloadFailureEntry{}) //nolint:exhaustruct
+	require.ErrorIs(t, err, ErrAlertWebhook)
+	require.Equal(t, alertWebhookRetries+1, numRequests)
+}
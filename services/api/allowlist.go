@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+)
+
+var ErrEmptyBuilderAllowlist = errors.New("builder allowlist is empty")
+
+// builderAllowlist holds the builder pubkeys accepted by submitBlock. The pubkeys are either a comma-separated list, or
+// read from a file with one pubkey per line (blank lines and #-comments are ignored), which can be reloaded.
+type builderAllowlist struct {
+	source string
+	isFile bool
+
+	mu      sync.RWMutex
+	pubkeys map[string]struct{}
+}
+
+func newBuilderAllowlist(source string) (*builderAllowlist, error) {
+	a := &builderAllowlist{source: source, isFile: !strings.HasPrefix(source, "0x")}
+	if err := a.reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// reload reads the allowlist file again. On error, the previous allowlist stays in use.
+func (a *builderAllowlist) reload() error {
+	entries := strings.Split(a.source, ",")
+	if a.isFile {
+		content, err := os.ReadFile(a.source)
+		if err != nil {
+			return err
+		}
+		entries = strings.Split(string(content), "\n")
+	}
+
+	pubkeys := make(map[string]struct{}, len(entries))
+	for i, entry := range entries {
+		entry, _, _ = strings.Cut(entry, "#")
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pubkey, err := boostTypes.HexToPubkey(entry)
+		if err != nil {
+			return fmt.Errorf("invalid builder allowlist entry %d (%s): %w", i+1, entry, err)
+		}
+		pubkeys[pubkey.String()] = struct{}{}
+	}
+	if len(pubkeys) == 0 {
+		return ErrEmptyBuilderAllowlist
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pubkeys = pubkeys
+	return nil
+}
+
+// isAllowed returns true if the builder is on the allowlist, or if there is no allowlist
+func (a *builderAllowlist) isAllowed(builderPubkey string) bool {
+	if a == nil {
+		return true
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	_, ok := a.pubkeys[strings.ToLower(builderPubkey)]
+	return ok
+}
+
+func (a *builderAllowlist) size() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.pubkeys)
+}
+
+// ReloadBuilderAllowlist reads the builder allowlist file again, to add or remove builders without a restart (i.e. on
+// SIGHUP)
+func (api *RelayAPI) ReloadBuilderAllowlist() error {
+	if api.builderAllowlist == nil || !api.builderAllowlist.isFile {
+		return nil
+	}
+	if err := api.builderAllowlist.reload(); err != nil {
+		return err
+	}
+	api.log.Infof("reloaded builder allowlist from %s: %d builders", api.opts.BuilderAllowlist, api.builderAllowlist.size())
+	return nil
+}
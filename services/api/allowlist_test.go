@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	allowlistPubkey1 = "0x84e975405f8691ad7118527ee9ee4ed2e4e8bae973f6e29aa9ca9ee4aea83605ae3536d22acc9aa1af0545064eacf82e"
+	allowlistPubkey2 = "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+)
+
+func TestBuilderAllowlist(t *testing.T) {
+	var noAllowlist *builderAllowlist
+	require.True(t, noAllowlist.isAllowed(allowlistPubkey1))
+
+	// Comma-separated list
+	a, err := newBuilderAllowlist(allowlistPubkey1 + ", " + allowlistPubkey2)
+	require.NoError(t, err)
+	require.Equal(t, 2, a.size())
+	require.True(t, a.isAllowed(allowlistPubkey2))
+
+	_, err = newBuilderAllowlist(allowlistPubkey1 + ",0x1234")
+	require.Error(t, err)
+
+	// File, with comments and case-insensitive matching
+	path := filepath.Join(t.TempDir(), "allowlist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# builders\n"+allowlistPubkey1+" # builder 1\n\n"), 0o600))
+	a, err = newBuilderAllowlist(path)
+	require.NoError(t, err)
+	require.True(t, a.isAllowed(allowlistPubkey1))
+	require.False(t, a.isAllowed(allowlistPubkey2))
+
+	require.NoError(t, os.WriteFile(path, []byte(allowlistPubkey1+"\n0xFA1ED37C3553D0CE1E9349B2C5063CF6E394D231C8D3E0DF75E9462257C081543086109FFDDAACC0AA76F33DC9661C83\n"), 0o600))
+	require.NoError(t, a.reload())
+	require.True(t, a.isAllowed(allowlistPubkey2))
+
+	// An invalid file keeps the previous allowlist
+	require.NoError(t, os.WriteFile(path, []byte("# no builders\n"), 0o600))
+	require.ErrorIs(t, a.reload(), ErrEmptyBuilderAllowlist)
+	require.NoError(t, os.WriteFile(path, []byte("not a pubkey\n"), 0o600))
+	require.Error(t, a.reload())
+	require.Equal(t, 2, a.size())
+
+	_, err = newBuilderAllowlist(filepath.Join(t.TempDir(), "missing.txt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestBuilderSubmitBlockAllowlist(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.headSlot.Store(1)
+
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	var builderPubkey phase0.BLSPubKey
+	copy(builderPubkey[:], bls.PublicKeyToBytes(pk))
+	bid := &common.BidTraceV2{BidTrace: v1.BidTrace{Slot: 2, BuilderPubkey: builderPubkey, Value: uint256.NewInt(1)}}
+	req := common.TestBuilderSubmitBlockRequest(sk, bid)
+
+	backend.relay.builderAllowlist, err = newBuilderAllowlist(allowlistPubkey1)
+	require.NoError(t, err)
+	rr := backend.request(http.MethodPost, pathSubmitNewBlock, &req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodeBuilderNotAllowed))
+
+	// Allowlisted builders pass the check
+	backend.relay.builderAllowlist, err = newBuilderAllowlist(allowlistPubkey1 + "," + builderPubkey.String())
+	require.NoError(t, err)
+	rr = backend.request(http.MethodPost, pathSubmitNewBlock, &req)
+	require.NotEqual(t, http.StatusForbidden, rr.Code)
+}
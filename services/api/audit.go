@@ -0,0 +1,74 @@
+package api
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/sirupsen/logrus"
+	uberatomic "go.uber.org/atomic"
+)
+
+var auditLogQueueSize = cli.GetEnvInt("AUDIT_LOG_QUEUE_SIZE", 10_000) // entries queued for the database before new ones are dropped
+
+// auditLog writes a record of every served bid and delivered payload to the database. Entries are queued and written
+// by a single goroutine, so that the response path never waits for the database.
+type auditLog struct {
+	log        *logrus.Entry
+	db         database.IDatabaseService
+	entryC     chan *database.AuditLogEntry
+	numDropped uberatomic.Uint64
+}
+
+func newAuditLog(log *logrus.Entry, db database.IDatabaseService) *auditLog {
+	return &auditLog{
+		log:    log.WithField("component", "auditLog"),
+		db:     db,
+		entryC: make(chan *database.AuditLogEntry, auditLogQueueSize),
+	}
+}
+
+func (a *auditLog) start() {
+	for entry := range a.entryC {
+		err := a.db.InsertAuditLogEntry(entry)
+		if err != nil {
+			a.log.WithError(err).WithFields(logrus.Fields{
+				"slot":      entry.Slot,
+				"action":    entry.Action,
+				"blockHash": entry.BlockHash,
+			}).Error("failed to save audit log entry")
+		}
+	}
+}
+
+// record queues the entry, or drops it if the queue is full. It is a no-op if the audit log is disabled (nil).
+func (a *auditLog) record(action string, slot uint64, proposerPubkey, builderPubkey string, value *big.Int, blockHash string) {
+	if a == nil {
+		return
+	}
+
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	entry := &database.AuditLogEntry{ //nolint:exhaustruct
+		Slot:           slot,
+		Action:         action,
+		ProposerPubkey: proposerPubkey,
+		BuilderPubkey:  builderPubkey,
+		Value:          value.String(),
+		BlockHash:      blockHash,
+		EventAt:        time.Now().UTC(),
+	}
+	select {
+	case a.entryC <- entry:
+	default:
+		numDropped := a.numDropped.Inc()
+		a.log.WithFields(logrus.Fields{
+			"slot":       slot,
+			"action":     action,
+			"blockHash":  blockHash,
+			"numDropped": numDropped,
+		}).Error("audit log queue full, dropping entry")
+	}
+}
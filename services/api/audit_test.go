@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+type auditLogTestDB struct {
+	database.MockDB
+	entryC chan *database.AuditLogEntry
+}
+
+func (db auditLogTestDB) InsertAuditLogEntry(entry *database.AuditLogEntry) error {
+	db.entryC <- entry
+	return nil
+}
+
+func TestAuditLog(t *testing.T) {
+	t.Run("records served bids", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		backend.relay.genesisInfo.Data.GenesisTime = uint64(time.Now().UTC().Unix())
+		db := auditLogTestDB{MockDB: database.MockDB{}, entryC: make(chan *database.AuditLogEntry, 1)}
+		backend.relay.db = db
+		backend.relay.auditLog = newAuditLog(backend.relay.log, db)
+		go backend.relay.auditLog.start()
+
+		slot := uint64(2)
+		backend.relay.headSlot.Store(slot)
+		parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+		proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+		builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+
+		opts := common.CreateTestBlockSubmissionOpts{
+			Slot:           slot,
+			ParentHash:     parentHash,
+			ProposerPubkey: proposerPubkey,
+		}
+		payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(99), &opts)
+		trace := &common.BidTraceV2{BidTrace: *payload.Message()}
+		_, err := backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+		require.NoError(t, err)
+		tx := backend.redis.NewPipeline()
+		require.NoError(t, backend.redis.SaveBidTrace(context.Background(), tx, trace))
+		_, err = tx.Exec(context.Background())
+		require.NoError(t, err)
+
+		rr := backend.request(http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey), nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		select {
+		case entry := <-db.entryC:
+			require.Equal(t, database.AuditActionGetHeader, entry.Action)
+			require.Equal(t, slot, entry.Slot)
+			require.Equal(t, proposerPubkey, entry.ProposerPubkey)
+			require.Equal(t, builderPubkey, entry.BuilderPubkey)
+			require.Equal(t, "99", entry.Value)
+			require.Equal(t, payload.BlockHash(), entry.BlockHash)
+		case <-time.After(time.Second):
+			t.Fatal("no audit log entry")
+		}
+	})
+
+	t.Run("drops entries when the queue is full", func(t *testing.T) {
+		a := newAuditLog(common.TestLog, database.MockDB{})
+		a.entryC = make(chan *database.AuditLogEntry, 1)
+		a.record(database.AuditActionGetHeader, 1, "", "", nil, "")
+		a.record(database.AuditActionGetHeader, 2, "", "", nil, "")
+		require.Equal(t, uint64(1), a.numDropped.Load())
+		require.Equal(t, "0", (<-a.entryC).Value)
+	})
+
+	t.Run("disabled audit log is a no-op", func(t *testing.T) {
+		var a *auditLog
+		a.record(database.AuditActionGetHeader, 1, "", "", big.NewInt(1), "")
+	})
+}
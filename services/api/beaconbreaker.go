@@ -0,0 +1,213 @@
+package api
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrBeaconCircuitOpen = errors.New("beacon node circuit breaker is open")
+
+	beaconBreakerFailures   = cli.GetEnvInt("BEACON_CIRCUIT_BREAKER_FAILURES", 5) // consecutive failures to open the circuit (0 to disable)
+	beaconBreakerCooldownMs = cli.GetEnvInt("BEACON_CIRCUIT_BREAKER_COOLDOWN_MS", 10_000)
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerHalfOpen
+	breakerOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerHalfOpen:
+		return "half-open"
+	case breakerOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// circuitBreaker fails fast after maxFailures consecutive failures. After the cooldown, a single call is let through
+// (half-open): if it succeeds the circuit closes again, otherwise it stays open for another cooldown.
+type circuitBreaker struct {
+	log         *logrus.Entry
+	maxFailures int
+	cooldown    time.Duration
+
+	mu          sync.Mutex
+	state       breakerState
+	numFailures int
+	openedAt    time.Time
+}
+
+func newCircuitBreaker(log *logrus.Entry, maxFailures int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		log:         log,
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+	}
+}
+
+// allow returns whether a call may proceed. Its result has to be reported with done.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerClosed:
+		return true
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.log.Info("beacon circuit breaker half-open, testing recovery")
+		return true
+	case breakerHalfOpen:
+		// only the test call is let through
+		return false
+	}
+	return true
+}
+
+// done records the result of a call
+func (b *circuitBreaker) done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		if b.state != breakerClosed {
+			b.log.Info("beacon circuit breaker closed")
+		}
+		b.state = breakerClosed
+		b.numFailures = 0
+		return
+	}
+
+	b.numFailures++
+	if b.state == breakerHalfOpen || (b.state == breakerClosed && b.numFailures >= b.maxFailures) {
+		b.log.WithError(err).WithField("numFailures", b.numFailures).Warn("beacon circuit breaker open")
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) getState() breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// breakerBeaconClient fails calls to the beacon node fast while the circuit breaker is open. Subscriptions are not
+// affected, and blocks are always published, because failing there means a missed slot.
+type breakerBeaconClient struct {
+	beaconclient.IMultiBeaconClient
+	breaker *circuitBreaker
+}
+
+func (c *breakerBeaconClient) BestSyncStatus() (*beaconclient.SyncStatusPayloadData, error) {
+	if !c.breaker.allow() {
+		return nil, ErrBeaconCircuitOpen
+	}
+	resp, err := c.IMultiBeaconClient.BestSyncStatus()
+	c.breaker.done(err)
+	return resp, err
+}
+
+func (c *breakerBeaconClient) GetStateValidators(stateID string) (*beaconclient.GetStateValidatorsResponse, error) {
+	if !c.breaker.allow() {
+		return nil, ErrBeaconCircuitOpen
+	}
+	resp, err := c.IMultiBeaconClient.GetStateValidators(stateID)
+	c.breaker.done(err)
+	return resp, err
+}
+
+func (c *breakerBeaconClient) GetProposerDuties(epoch uint64) (*beaconclient.ProposerDutiesResponse, error) {
+	if !c.breaker.allow() {
+		return nil, ErrBeaconCircuitOpen
+	}
+	resp, err := c.IMultiBeaconClient.GetProposerDuties(epoch)
+	c.breaker.done(err)
+	return resp, err
+}
+
+func (c *breakerBeaconClient) PublishBlock(block *common.SignedBeaconBlock) (code int, err error) {
+	code, err = c.IMultiBeaconClient.PublishBlock(block)
+	c.breaker.done(err)
+	return code, err
+}
+
+func (c *breakerBeaconClient) GetGenesis() (*beaconclient.GetGenesisResponse, error) {
+	if !c.breaker.allow() {
+		return nil, ErrBeaconCircuitOpen
+	}
+	resp, err := c.IMultiBeaconClient.GetGenesis()
+	c.breaker.done(err)
+	return resp, err
+}
+
+func (c *breakerBeaconClient) GetSpec() (*beaconclient.GetSpecResponse, error) {
+	if !c.breaker.allow() {
+		return nil, ErrBeaconCircuitOpen
+	}
+	resp, err := c.IMultiBeaconClient.GetSpec()
+	c.breaker.done(err)
+	return resp, err
+}
+
+func (c *breakerBeaconClient) GetForkSchedule() (*beaconclient.GetForkScheduleResponse, error) {
+	if !c.breaker.allow() {
+		return nil, ErrBeaconCircuitOpen
+	}
+	resp, err := c.IMultiBeaconClient.GetForkSchedule()
+	c.breaker.done(err)
+	return resp, err
+}
+
+func (c *breakerBeaconClient) GetBlock(blockID string) (*beaconclient.GetBlockResponse, error) {
+	if !c.breaker.allow() {
+		return nil, ErrBeaconCircuitOpen
+	}
+	resp, err := c.IMultiBeaconClient.GetBlock(blockID)
+	c.breaker.done(err)
+	return resp, err
+}
+
+func (c *breakerBeaconClient) GetRandao(slot uint64) (*beaconclient.GetRandaoResponse, error) {
+	if !c.breaker.allow() {
+		return nil, ErrBeaconCircuitOpen
+	}
+	resp, err := c.IMultiBeaconClient.GetRandao(slot)
+	c.breaker.done(err)
+	return resp, err
+}
+
+func (c *breakerBeaconClient) GetWithdrawals(slot uint64) (*beaconclient.GetWithdrawalsResponse, error) {
+	if !c.breaker.allow() {
+		return nil, ErrBeaconCircuitOpen
+	}
+	resp, err := c.IMultiBeaconClient.GetWithdrawals(slot)
+	c.breaker.done(err)
+	return resp, err
+}
+
+func (c *breakerBeaconClient) ClockOffset() (time.Duration, error) {
+	if !c.breaker.allow() {
+		return 0, ErrBeaconCircuitOpen
+	}
+	offset, err := c.IMultiBeaconClient.ClockOffset()
+	c.breaker.done(err)
+	return offset, err
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestBeacon = errors.New("beacon node error")
+
+// flappingBeaconClient fails the sync status while err is set, and counts the calls reaching it
+type flappingBeaconClient struct {
+	beaconclient.IMultiBeaconClient
+	err      error
+	numCalls int
+}
+
+func (c *flappingBeaconClient) BestSyncStatus() (*beaconclient.SyncStatusPayloadData, error) {
+	c.numCalls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &beaconclient.SyncStatusPayloadData{HeadSlot: 1}, nil //nolint:exhaustruct
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	inner := &flappingBeaconClient{err: errTestBeacon}
+	breaker := newCircuitBreaker(common.TestLog, 3, 50*time.Millisecond)
+	client := &breakerBeaconClient{IMultiBeaconClient: inner, breaker: breaker}
+
+	// Opens after the configured number of consecutive failures
+	for i := 0; i < 3; i++ {
+		_, err := client.BestSyncStatus()
+		require.ErrorIs(t, err, errTestBeacon)
+	}
+	require.Equal(t, breakerOpen, breaker.getState())
+
+	// Fails fast while open
+	_, err := client.BestSyncStatus()
+	require.ErrorIs(t, err, ErrBeaconCircuitOpen)
+	require.Equal(t, 3, inner.numCalls)
+
+	// After the cooldown a failing test call opens it again
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.BestSyncStatus()
+	require.ErrorIs(t, err, errTestBeacon)
+	require.Equal(t, breakerOpen, breaker.getState())
+	_, err = client.BestSyncStatus()
+	require.ErrorIs(t, err, ErrBeaconCircuitOpen)
+
+	// Only one test call is let through while half-open
+	time.Sleep(60 * time.Millisecond)
+	require.True(t, breaker.allow())
+	require.Equal(t, breakerHalfOpen, breaker.getState())
+	require.False(t, breaker.allow())
+
+	// A successful test call closes it
+	inner.err = nil
+	breaker.done(nil)
+	require.Equal(t, breakerClosed, breaker.getState())
+	_, err = client.BestSyncStatus()
+	require.NoError(t, err)
+}
+
+func TestReadyzBeaconCircuitBreaker(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.beaconBreaker = newCircuitBreaker(common.TestLog, 1, time.Minute)
+	backend.relay.beaconClient = &breakerBeaconClient{IMultiBeaconClient: &flappingBeaconClient{err: errTestBeacon}, breaker: backend.relay.beaconBreaker}
+
+	rr := backend.request(http.MethodGet, pathReadyz, nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	rr = backend.request(http.MethodGet, pathReadyz, nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	resp := new(ReadinessResponse)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, "open", resp.BeaconCircuitBreaker)
+	require.Equal(t, ErrBeaconCircuitOpen.Error(), resp.Beacon)
+}
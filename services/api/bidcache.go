@@ -0,0 +1,111 @@
+package api
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+type bidCacheEntry struct {
+	key  string
+	slot uint64
+	bid  *common.GetHeaderResponse
+}
+
+// bidCache is an in-memory LRU cache of the best bid per slot, parentHash and proposerPubkey, used to serve getHeader
+// without a Redis roundtrip. Entries of past slots are removed when the head slot advances.
+type bidCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List // front is most recently used
+}
+
+func newBidCache(maxEntries int) *bidCache {
+	return &bidCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func bidCacheKey(slot uint64, parentHash, proposerPubkey string) string {
+	return fmt.Sprintf("%d_%s_%s", slot, strings.ToLower(parentHash), strings.ToLower(proposerPubkey))
+}
+
+func (c *bidCache) get(slot uint64, parentHash, proposerPubkey string) (*common.GetHeaderResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.entries[bidCacheKey(slot, parentHash, proposerPubkey)]
+	if !found {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(*bidCacheEntry).bid, true //nolint:forcetypeassert
+}
+
+func (c *bidCache) set(slot uint64, parentHash, proposerPubkey string, bid *common.GetHeaderResponse) {
+	key := bidCacheKey(slot, parentHash, proposerPubkey)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, found := c.entries[key]; found {
+		el.Value.(*bidCacheEntry).bid = bid //nolint:forcetypeassert
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&bidCacheEntry{key: key, slot: slot, bid: bid})
+	for c.lru.Len() > c.maxEntries {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+func (c *bidCache) delete(slot uint64, parentHash, proposerPubkey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, found := c.entries[bidCacheKey(slot, parentHash, proposerPubkey)]; found {
+		c.removeElement(el)
+	}
+}
+
+// pruneBefore removes all entries for slots before the given one
+func (c *bidCache) pruneBefore(slot uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, el := range c.entries {
+		if el.Value.(*bidCacheEntry).slot < slot { //nolint:forcetypeassert
+			c.removeElement(el)
+		}
+	}
+}
+
+func (c *bidCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// countBySlot returns the number of cached bids of each slot
+func (c *bidCache) countBySlot() map[uint64]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[uint64]int)
+	for _, el := range c.entries {
+		counts[el.Value.(*bidCacheEntry).slot]++ //nolint:forcetypeassert
+	}
+	return counts
+}
+
+func (c *bidCache) removeElement(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*bidCacheEntry).key) //nolint:forcetypeassert
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidCache(t *testing.T) {
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	bid := &common.GetHeaderResponse{}
+
+	t.Run("get, set and delete", func(t *testing.T) {
+		cache := newBidCache(10)
+		_, found := cache.get(1, parentHash, proposerPubkey)
+		require.False(t, found)
+
+		cache.set(1, parentHash, proposerPubkey, bid)
+		cachedBid, found := cache.get(1, parentHash, proposerPubkey)
+		require.True(t, found)
+		require.Equal(t, bid, cachedBid)
+
+		cache.delete(1, parentHash, proposerPubkey)
+		_, found = cache.get(1, parentHash, proposerPubkey)
+		require.False(t, found)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		cache := newBidCache(2)
+		cache.set(1, parentHash, proposerPubkey, bid)
+		cache.set(2, parentHash, proposerPubkey, bid)
+		_, found := cache.get(1, parentHash, proposerPubkey)
+		require.True(t, found)
+
+		cache.set(3, parentHash, proposerPubkey, bid)
+		require.Equal(t, 2, cache.len())
+		_, found = cache.get(2, parentHash, proposerPubkey)
+		require.False(t, found)
+		_, found = cache.get(1, parentHash, proposerPubkey)
+		require.True(t, found)
+	})
+
+	t.Run("prunes past slots", func(t *testing.T) {
+		cache := newBidCache(10)
+		cache.set(1, parentHash, proposerPubkey, bid)
+		cache.set(2, parentHash, proposerPubkey, bid)
+		cache.set(3, parentHash, proposerPubkey, bid)
+
+		cache.pruneBefore(3)
+		require.Equal(t, 1, cache.len())
+		_, found := cache.get(3, parentHash, proposerPubkey)
+		require.True(t, found)
+	})
+}
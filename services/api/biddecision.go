@@ -0,0 +1,112 @@
+package api
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/sirupsen/logrus"
+)
+
+var bidDecisionQueueSize = cli.GetEnvInt("BID_DECISION_QUEUE_SIZE", 10_000) // decisions queued for the database before they are queued in goroutines
+
+// queuedBidDecision is a bid decision waiting to be saved, with the builder pubkey to be looked up in the bid trace
+type queuedBidDecision struct {
+	entry         *database.BidDecisionEntry
+	lookupBuilder bool
+}
+
+// recordBidDecision queues a bid getHeader served, with the time of the decision. Anyone can request a header for any
+// proposer, so all served headers are kept and the one the proposer signed is the one delivered in getPayload.
+// Decisions are never dropped: if the queue is full, they wait for it in a goroutine.
+func (api *RelayAPI) recordBidDecision(slot uint64, proposerPubkey, parentHash string, value *big.Int, blockHash string, lookupBuilder bool) {
+	decision := &queuedBidDecision{
+		entry: &database.BidDecisionEntry{ //nolint:exhaustruct
+			Slot:           slot,
+			ProposerPubkey: proposerPubkey,
+			ParentHash:     parentHash,
+			Value:          value.String(),
+			BlockHash:      blockHash,
+			DecidedAt:      time.Now().UTC(),
+		},
+		lookupBuilder: lookupBuilder,
+	}
+	select {
+	case api.bidDecisionC <- decision:
+	default:
+		api.log.WithFields(logrus.Fields{
+			"slot":      slot,
+			"blockHash": blockHash,
+		}).Warn("bid decision queue full")
+		go func() { api.bidDecisionC <- decision }()
+	}
+}
+
+// startBidDecisionProcessor saves the queued bid decisions
+func (api *RelayAPI) startBidDecisionProcessor() {
+	for decision := range api.bidDecisionC {
+		api.saveBidDecision(decision)
+	}
+}
+
+func (api *RelayAPI) saveBidDecision(decision *queuedBidDecision) {
+	entry := decision.entry
+	log := api.log.WithFields(logrus.Fields{
+		"slot":      entry.Slot,
+		"blockHash": entry.BlockHash,
+	})
+	if decision.lookupBuilder {
+		bidTrace, err := api.redis.GetBidTrace(entry.Slot, entry.ProposerPubkey, entry.BlockHash)
+		if err != nil || bidTrace == nil {
+			log.WithError(err).Warn("could not get bid trace for the bid decision")
+		} else {
+			entry.BuilderPubkey = bidTrace.BuilderPubkey.String()
+		}
+	}
+	if err := api.db.InsertBidDecision(entry); err != nil {
+		log.WithError(err).Error("failed to save bid decision")
+	}
+}
+
+func (api *RelayAPI) handleDataBidDecisions(w http.ResponseWriter, req *http.Request) {
+	args := req.URL.Query()
+	slotStr := args.Get("slot")
+	if slotStr == "" {
+		api.RespondError(w, http.StatusBadRequest, "missing slot argument")
+		return
+	}
+	slot, err := strconv.ParseUint(slotStr, 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
+		return
+	}
+	proposerPubkey := args.Get("proposer_pubkey")
+
+	entries, err := api.db.GetBidDecisions(slot)
+	if err != nil {
+		api.log.WithError(err).Error("error getting bid decisions")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]BidDecisionJSON, 0, len(entries))
+	for _, entry := range entries {
+		if proposerPubkey != "" && !strings.EqualFold(entry.ProposerPubkey, proposerPubkey) {
+			continue
+		}
+		response = append(response, BidDecisionJSON{
+			Slot:           entry.Slot,
+			ProposerPubkey: entry.ProposerPubkey,
+			ParentHash:     entry.ParentHash,
+			BuilderPubkey:  entry.BuilderPubkey,
+			Value:          entry.Value,
+			BlockHash:      entry.BlockHash,
+			DecidedAtMs:    entry.DecidedAt.UnixMilli(),
+		})
+	}
+	api.RespondOK(w, response)
+}
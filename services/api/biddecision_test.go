@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+type bidDecisionTestDB struct {
+	database.MockDB
+	mu        *sync.Mutex
+	decisions *[]*database.BidDecisionEntry
+}
+
+func (db bidDecisionTestDB) InsertBidDecision(entry *database.BidDecisionEntry) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	*db.decisions = append(*db.decisions, entry)
+	return nil
+}
+
+func (db bidDecisionTestDB) GetBidDecisions(slot uint64) ([]*database.BidDecisionEntry, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	entries := []*database.BidDecisionEntry{}
+	for _, entry := range *db.decisions {
+		if entry.Slot == slot {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func TestGetHeaderRecordsBidDecision(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.bidCache = newBidCache(10)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{GenesisTime: uint64(time.Now().UTC().Unix())}, //nolint:exhaustruct
+	}
+	db := bidDecisionTestDB{mu: &sync.Mutex{}, decisions: &[]*database.BidDecisionEntry{}}
+	backend.relay.db = db
+	go backend.relay.startBidDecisionProcessor()
+
+	// The decision is recorded even if the builder stats and audit log are dropped
+	for i := 0; i < cap(backend.relay.getHeaderBackgroundOps); i++ {
+		backend.relay.getHeaderBackgroundOps <- struct{}{}
+	}
+
+	slot := uint64(3)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	opts := common.CreateTestBlockSubmissionOpts{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey} //nolint:exhaustruct
+	_, _, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(12345), &opts)
+	backend.relay.bidCache.set(slot, parentHash, proposerPubkey, getHeaderResp)
+
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey)
+	start := time.Now()
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// The decision is saved off the request path
+	var decisions []*database.BidDecisionEntry
+	require.Eventually(t, func() bool {
+		decisions, _ = db.GetBidDecisions(slot)
+		return len(decisions) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, proposerPubkey, decisions[0].ProposerPubkey)
+	require.Equal(t, parentHash, decisions[0].ParentHash)
+	require.Equal(t, "12345", decisions[0].Value)
+	require.Equal(t, getHeaderResp.BlockHash().String(), decisions[0].BlockHash)
+	require.False(t, decisions[0].DecidedAt.Before(start.Truncate(time.Second)))
+
+	rr = backend.request(http.MethodGet, pathDataBidDecisions+"?slot=3", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := []BidDecisionJSON{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+	require.Equal(t, slot, resp[0].Slot)
+	require.Equal(t, decisions[0].DecidedAt.UnixMilli(), resp[0].DecidedAtMs)
+
+	rr = backend.request(http.MethodGet, pathDataBidDecisions+"?slot=3&proposer_pubkey=0x"+strings.ToUpper(proposerPubkey[2:]), nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Len(t, resp, 1)
+
+	rr = backend.request(http.MethodGet, pathDataBidDecisions+"?slot=3&proposer_pubkey="+builderPubkey, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "[]\n", rr.Body.String())
+
+	rr = backend.request(http.MethodGet, pathDataBidDecisions, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
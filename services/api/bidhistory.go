@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+	uberatomic "go.uber.org/atomic"
+)
+
+var (
+	bidHistoryQueueSize = cli.GetEnvInt("BID_HISTORY_QUEUE_SIZE", 10_000) // bids queued for Redis before new ones are dropped
+	bidHistoryBatchSize = 100                                             // bids written to Redis in one pipeline
+)
+
+// bidHistoryWriter appends the received bids to the bid history of their slot in Redis. Bids are queued and written
+// in batches by a single goroutine, so that the submission path never waits for Redis.
+type bidHistoryWriter struct {
+	log        *logrus.Entry
+	redis      relayRedis
+	maxEntries int
+	retention  time.Duration
+	entryC     chan *common.BidHistoryEntry
+	numDropped uberatomic.Uint64
+}
+
+func newBidHistoryWriter(log *logrus.Entry, redis relayRedis, maxEntries int, retention time.Duration) *bidHistoryWriter {
+	return &bidHistoryWriter{ //nolint:exhaustruct
+		log:        log.WithField("component", "bidHistory"),
+		redis:      redis,
+		maxEntries: maxEntries,
+		retention:  retention,
+		entryC:     make(chan *common.BidHistoryEntry, bidHistoryQueueSize),
+	}
+}
+
+func (h *bidHistoryWriter) start() {
+	batch := make([]*common.BidHistoryEntry, 0, bidHistoryBatchSize)
+	for entry := range h.entryC {
+		// Write the bids which queued up while the previous batch was written together
+		batch = append(batch[:0], entry)
+	drain:
+		for len(batch) < bidHistoryBatchSize {
+			select {
+			case entry := <-h.entryC:
+				batch = append(batch, entry)
+			default:
+				break drain
+			}
+		}
+
+		err := h.redis.AddBidHistoryEntries(context.Background(), batch, h.maxEntries, h.retention)
+		if err != nil {
+			h.log.WithError(err).WithField("numBids", len(batch)).Error("failed to save bid history")
+		}
+	}
+}
+
+// record queues the bid, or drops it if the queue is full. It is a no-op if the bid history is disabled (nil).
+func (h *bidHistoryWriter) record(entry *common.BidHistoryEntry) {
+	if h == nil {
+		return
+	}
+
+	select {
+	case h.entryC <- entry:
+	default:
+		numDropped := h.numDropped.Inc()
+		h.log.WithFields(logrus.Fields{
+			"slot":       entry.Slot,
+			"blockHash":  entry.BlockHash,
+			"numDropped": numDropped,
+		}).Error("bid history queue full, dropping bid")
+	}
+}
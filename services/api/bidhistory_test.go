@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidHistory(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.bidHistory = newBidHistoryWriter(backend.relay.log, backend.relay.redis, 2, time.Minute)
+
+	// Bids are queued until the writer runs
+	for _, value := range []string{"1", "2", "3"} {
+		backend.relay.bidHistory.record(&common.BidHistoryEntry{Slot: 10, Value: value}) //nolint:exhaustruct
+	}
+	go backend.relay.bidHistory.start()
+
+	getBidHistory := func(path string) (int, []*common.BidHistoryEntry) {
+		rr := backend.request(http.MethodGet, path, nil)
+		entries := []*common.BidHistoryEntry{}
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+		}
+		return rr.Code, entries
+	}
+	require.Eventually(t, func() bool {
+		_, entries := getBidHistory(pathDataBidHistory + "?slot=10")
+		return len(entries) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// The latest bids are kept, in order
+	_, entries := getBidHistory(pathDataBidHistory + "?slot=10")
+	require.Equal(t, "2", entries[0].Value)
+	require.Equal(t, "3", entries[1].Value)
+
+	code, entries := getBidHistory(pathDataBidHistory + "?slot=11")
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, entries)
+
+	code, _ = getBidHistory(pathDataBidHistory)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	// A full queue drops bids instead of blocking
+	writer := newBidHistoryWriter(backend.relay.log, backend.relay.redis, 2, time.Minute)
+	writer.entryC = make(chan *common.BidHistoryEntry, 1)
+	writer.record(&common.BidHistoryEntry{Slot: 12}) //nolint:exhaustruct
+	writer.record(&common.BidHistoryEntry{Slot: 12}) //nolint:exhaustruct
+	require.Equal(t, uint64(1), writer.numDropped.Load())
+
+	// Disabled bid history is a no-op
+	var disabled *bidHistoryWriter
+	disabled.record(&common.BidHistoryEntry{Slot: 12}) //nolint:exhaustruct
+}
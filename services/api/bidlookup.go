@@ -0,0 +1,62 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+// bidLookupCall is a best bid lookup in progress, or completed
+type bidLookupCall struct {
+	wg  sync.WaitGroup
+	bid *common.GetHeaderResponse
+	err error
+}
+
+// bidLookupGroup deduplicates concurrent best bid lookups (like singleflight): callers with the same key while a lookup
+// is in progress wait for it and get its result. The zero value is ready to use.
+type bidLookupGroup struct {
+	mu    sync.Mutex
+	calls map[string]*bidLookupCall
+}
+
+// do runs fn, unless a lookup with the same key is in progress. shared is true if the result was returned to more
+// than one caller. If fn panics, the waiting callers get no bid instead of blocking forever.
+func (g *bidLookupGroup) do(key string, fn func() (*common.GetHeaderResponse, error)) (bid *common.GetHeaderResponse, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*bidLookupCall)
+	}
+	if call, found := g.calls[key]; found {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.bid, true, call.err
+	}
+	call := new(bidLookupCall)
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+
+	call.bid, call.err = fn()
+	return call.bid, false, call.err
+}
+
+// getBestBidShared is getBestBid for getHeader, where identical concurrent requests (i.e. retries, or several mev-boost
+// instances of one proposer) share a single lookup. Bids are signed on submission, so there's nothing to sign per
+// request. The signed bid doesn't contain the proposer pubkey, but bids are stored per proposer, so the pubkey is part
+// of the key: another proposer must never be served a bid which was submitted for the proposer of the slot.
+func (api *RelayAPI) getBestBidShared(slot uint64, parentHash, proposerPubkey string) (*common.GetHeaderResponse, error) {
+	key := fmt.Sprintf("%d/%s/%s", slot, parentHash, proposerPubkey)
+	bid, _, err := api.bidLookups.do(key, func() (*common.GetHeaderResponse, error) {
+		return api.getBestBid(slot, parentHash, proposerPubkey)
+	})
+	return bid, err
+}
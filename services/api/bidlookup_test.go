@@ -0,0 +1,128 @@
+package api
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRedis counts the best bid lookups, which block until release is closed
+type countingRedis struct {
+	relayRedis
+	numCalls atomic.Int32
+	release  chan struct{}
+}
+
+func (r *countingRedis) GetBestBid(slot uint64, parentHash, proposerPubkey string) (*common.GetHeaderResponse, error) {
+	r.numCalls.Add(1)
+	<-r.release
+	return &common.GetHeaderResponse{}, nil //nolint:exhaustruct
+}
+
+func TestBidLookupGroup(t *testing.T) {
+	group := bidLookupGroup{} //nolint:exhaustruct
+	release := make(chan struct{})
+	var numCalls atomic.Int32
+	fn := func() (*common.GetHeaderResponse, error) {
+		numCalls.Add(1)
+		<-release
+		return &common.GetHeaderResponse{}, nil //nolint:exhaustruct
+	}
+
+	// Concurrent lookups with the same key share the first one's result
+	var wg sync.WaitGroup
+	bids := make([]*common.GetHeaderResponse, 5)
+	for i := range bids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bid, _, err := group.do("key", fn)
+			require.NoError(t, err)
+			bids[i] = bid
+		}(i)
+	}
+	require.Eventually(t, func() bool { return numCalls.Load() == 1 }, time.Second, time.Millisecond)
+
+	// A different key isn't shared
+	go func() { _, _, _ = group.do("other", fn) }()
+	require.Eventually(t, func() bool { return numCalls.Load() == 2 }, time.Second, time.Millisecond)
+
+	time.Sleep(20 * time.Millisecond) // let the other callers join the lookup
+	close(release)
+	wg.Wait()
+	for _, bid := range bids {
+		require.Same(t, bids[0], bid)
+	}
+
+	// Completed lookups are not cached
+	_, shared, err := group.do("key", fn)
+	require.NoError(t, err)
+	require.False(t, shared)
+	require.Equal(t, int32(3), numCalls.Load())
+}
+
+func TestBidLookupGroupPanic(t *testing.T) {
+	group := bidLookupGroup{} //nolint:exhaustruct
+	release := make(chan struct{})
+	var numCalls atomic.Int32
+	go func() {
+		defer func() { _ = recover() }()
+		_, _, _ = group.do("key", func() (*common.GetHeaderResponse, error) {
+			numCalls.Add(1)
+			<-release
+			panic("lookup failed")
+		})
+	}()
+	require.Eventually(t, func() bool { return numCalls.Load() == 1 }, time.Second, time.Millisecond)
+
+	// A caller waiting for the panicking lookup gets no bid
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bid, shared, err := group.do("key", func() (*common.GetHeaderResponse, error) { return nil, nil }) //nolint:nilnil
+		require.NoError(t, err)
+		require.Nil(t, bid)
+		require.True(t, shared)
+	}()
+	time.Sleep(20 * time.Millisecond) // let the caller join the lookup
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("caller blocked after the lookup panicked")
+	}
+
+	// The key is free again
+	bid, shared, err := group.do("key", func() (*common.GetHeaderResponse, error) { return &common.GetHeaderResponse{}, nil }) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.NotNil(t, bid)
+	require.False(t, shared)
+}
+
+func TestGetBestBidShared(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	redis := &countingRedis{relayRedis: backend.relay.redis, release: make(chan struct{})}
+	backend.relay.redis = redis
+	proposers := []string{"0x01", "0x02"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := backend.relay.getBestBidShared(10, "0xab", proposers[i%2])
+			require.NoError(t, err)
+		}(i)
+	}
+
+	// One lookup per proposer
+	require.Eventually(t, func() bool { return redis.numCalls.Load() == 2 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(redis.release)
+	wg.Wait()
+	require.Equal(t, int32(2), redis.numCalls.Load())
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	bidStreamBufferSize   = cli.GetEnvInt("BID_STREAM_BUFFER_SIZE", 256) // messages buffered per subscriber before it is dropped
+	bidStreamWriteTimeout = 5 * time.Second
+	bidStreamPingInterval = 30 * time.Second
+)
+
+// BidStreamMessage is sent to bid stream subscribers for every accepted block submission
+type BidStreamMessage struct {
+	Slot           uint64 `json:"slot,string"`
+	ParentHash     string `json:"parent_hash"`
+	BlockHash      string `json:"block_hash"`
+	BuilderPubkey  string `json:"builder_pubkey"`
+	ProposerPubkey string `json:"proposer_pubkey"`
+	Value          string `json:"value"`
+	IsTopBid       bool   `json:"is_top_bid"`
+	ReceivedAtMs   int64  `json:"received_at_ms,string"`
+}
+
+// bidStream fans out bids to websocket subscribers. Publishing never blocks: subscribers which don't keep up
+// with the stream and fill up their buffer are dropped.
+type bidStream struct {
+	mu          sync.Mutex
+	subscribers map[chan *BidStreamMessage]struct{}
+}
+
+func newBidStream() *bidStream {
+	return &bidStream{
+		subscribers: make(map[chan *BidStreamMessage]struct{}),
+	}
+}
+
+func (s *bidStream) subscribe() chan *BidStreamMessage {
+	c := make(chan *BidStreamMessage, bidStreamBufferSize)
+	s.mu.Lock()
+	s.subscribers[c] = struct{}{}
+	s.mu.Unlock()
+	return c
+}
+
+// unsubscribe removes the subscriber and closes its channel, unless it was already dropped
+func (s *bidStream) unsubscribe(c chan *BidStreamMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.subscribers[c]; found {
+		delete(s.subscribers, c)
+		close(c)
+	}
+}
+
+// publish sends the message to all subscribers, and returns the number of slow subscribers which were dropped
+func (s *bidStream) publish(msg *BidStreamMessage) (numDropped int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.subscribers {
+		select {
+		case c <- msg:
+		default:
+			delete(s.subscribers, c)
+			close(c)
+			numDropped++
+		}
+	}
+	return numDropped
+}
+
+func (s *bidStream) numSubscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+var bidStreamUpgrader = websocket.Upgrader{} //nolint:exhaustruct
+
+// handleBidStream upgrades the connection to a websocket and sends a BidStreamMessage for every accepted block submission
+func (api *RelayAPI) handleBidStream(w http.ResponseWriter, req *http.Request) {
+	log := api.log.WithField("method", "bidStream").WithField("ip", api.getClientIP(req))
+
+	conn, err := bidStreamUpgrader.Upgrade(w, req, nil)
+	if err != nil {
+		log.WithError(err).Info("bid stream websocket upgrade failed")
+		return
+	}
+	defer conn.Close()
+
+	c := api.bidStream.subscribe()
+	defer api.bidStream.unsubscribe(c)
+	log.Info("bid stream subscriber connected")
+
+	// The subscriber isn't expected to send anything. Reading is still needed to process control messages and notice a closed connection.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	pingTicker := time.NewTicker(bidStreamPingInterval)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			log.Info("bid stream subscriber disconnected")
+			return
+		case <-pingTicker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(bidStreamWriteTimeout))
+		case msg, ok := <-c:
+			if !ok {
+				log.Warn("bid stream subscriber too slow, dropping connection")
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow"), time.Now().Add(bidStreamWriteTimeout))
+				return
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(bidStreamWriteTimeout))
+			err = conn.WriteJSON(msg)
+		}
+		if err != nil {
+			log.WithError(err).Info("bid stream write failed")
+			return
+		}
+	}
+}
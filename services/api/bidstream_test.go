@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBidStream(t *testing.T) {
+	msg := &BidStreamMessage{Slot: 1, Value: "1"}
+
+	t.Run("drops slow subscribers", func(t *testing.T) {
+		stream := newBidStream()
+		fast := stream.subscribe()
+		slow := stream.subscribe()
+
+		for i := 0; i < bidStreamBufferSize; i++ {
+			require.Equal(t, 0, stream.publish(msg))
+			<-fast
+		}
+		require.Equal(t, 1, stream.publish(msg))
+		require.Equal(t, 1, stream.numSubscribers())
+		require.Equal(t, msg, <-fast)
+
+		// The dropped subscriber gets the buffered messages, and then the closed channel
+		for i := 0; i < bidStreamBufferSize; i++ {
+			<-slow
+		}
+		_, ok := <-slow
+		require.False(t, ok)
+		stream.unsubscribe(slow)
+	})
+
+	t.Run("sends bids to websocket subscribers", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		backend.relay.opts.AdminToken = "secret"
+		backend.relay.bidStream = newBidStream()
+		server := httptest.NewServer(backend.relay.getRouter())
+		defer server.Close()
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + pathBuilderBidsStream
+		_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		require.Error(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": []string{"Bearer secret"}})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.Eventually(t, func() bool { return backend.relay.bidStream.numSubscribers() == 1 }, time.Second, 10*time.Millisecond)
+		backend.relay.bidStream.publish(msg)
+
+		received := new(BidStreamMessage)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		require.NoError(t, conn.ReadJSON(received))
+		require.Equal(t, msg, received)
+	})
+
+	t.Run("not served without admin token", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		backend.relay.bidStream = newBidStream()
+		rr := backend.request(http.MethodGet, pathBuilderBidsStream, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -21,7 +21,7 @@ var (
 	ErrRequestClosed    = errors.New("request context closed")
 	ErrSimulationFailed = errors.New("simulation failed")
 	ErrJSONDecodeFailed = errors.New("json error")
-	ErrNoCapellaPayload = errors.New("capella payload is nil")
+	ErrNoCapellaPayload = errors.New("capella or deneb payload is nil")
 
 	maxConcurrentBlocks = int64(cli.GetEnvInt("BLOCKSIM_MAX_CONCURRENT", 4)) // 0 for no maximum
 	simRequestTimeout   = time.Duration(cli.GetEnvInt("BLOCKSIM_TIMEOUT_MS", 10000)) * time.Millisecond
@@ -70,10 +70,9 @@ func (b *BlockSimulationRateLimiter) Send(context context.Context, payload *comm
 	}
 
 	var simReq *jsonrpc.JSONRPCRequest
-	if payload.Capella == nil {
+	if payload.Capella == nil && payload.Deneb == nil {
 		return ErrNoCapellaPayload, nil
 	}
-	// TODO: add deneb support.
 
 	// Prepare headers
 	headers := http.Header{}
@@ -86,7 +85,11 @@ func (b *BlockSimulationRateLimiter) Send(context context.Context, payload *comm
 	}
 
 	// Create and fire off JSON-RPC request
-	simReq = jsonrpc.NewJSONRPCRequest("1", "flashbots_validateBuilderSubmissionV2", payload)
+	method := "flashbots_validateBuilderSubmissionV2"
+	if payload.Deneb != nil {
+		method = "flashbots_validateBuilderSubmissionV3" // with the blobs bundle and parent beacon block root
+	}
+	simReq = jsonrpc.NewJSONRPCRequest("1", method, payload)
 	_, requestErr, validationErr = SendJSONRPCRequest(&b.client, *simReq, b.blockSimURL, headers)
 	return requestErr, validationErr
 }
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Default body size limits: a deneb submission with the maximum of blobs is a few MB of JSON, and large node
+	// operators register tens of thousands of validators in one request (about 500 bytes each)
+	DefaultMaxSubmitBytes       = 10 * 1024 * 1024
+	DefaultMaxRegistrationBytes = 50 * 1024 * 1024
+)
+
+// body size limit of other requests, i.e. getPayload (a signed blinded block)
+var maxRequestBodyBytes = int64(cli.GetEnvInt("MAX_REQUEST_BODY_BYTES", 4*1024*1024))
+
+// maxBodyBytes returns the body size limit for requests to the route
+func (api *RelayAPI) maxBodyBytes(route string) int64 {
+	switch route {
+	case pathSubmitNewBlock:
+		return api.opts.MaxSubmitBytes
+	case pathRegisterValidator:
+		return api.opts.MaxRegistrationBytes
+	}
+	return maxRequestBodyBytes
+}
+
+// routeTemplate returns the path template of the matched route (i.e. with {slot} instead of the slot), or the path
+func routeTemplate(req *http.Request) string {
+	if currentRoute := mux.CurrentRoute(req); currentRoute != nil {
+		if tpl, err := currentRoute.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return req.URL.Path
+}
+
+// bodyLimitMiddleware rejects requests with a Content-Length above the route's limit with 413, and makes reading the
+// body fail once the limit is exceeded (i.e. for chunked requests), which the handlers answer with 413 as well
+func (api *RelayAPI) bodyLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		limit := api.maxBodyBytes(routeTemplate(req))
+		if limit > 0 && req.Body != nil && req.Body != http.NoBody {
+			if req.ContentLength > limit {
+				api.log.WithFields(logrus.Fields{
+					"path":          req.URL.Path,
+					"contentLength": req.ContentLength,
+					"ip":            api.getClientIP(req),
+				}).Warn("request body too large")
+				api.respondBodyTooLarge(w, limit)
+				return
+			}
+			req.Body = http.MaxBytesReader(w, req.Body, limit)
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// isBodyTooLarge returns true if reading the request body failed because it exceeded the limit
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+func (api *RelayAPI) respondBodyTooLarge(w http.ResponseWriter, limit int64) {
+	api.RespondErrorCode(w, http.StatusRequestEntityTooLarge, ErrorCodeRequestTooLarge, fmt.Sprintf("request body larger than %d bytes", limit))
+}
+
+// readAllLimited reads at most limit bytes, and fails with an *http.MaxBytesError if there are more (i.e. for the
+// decompressed body of a gzipped request, which the body limit doesn't cover)
+func readAllLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	b, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return b, err
+	} else if int64(len(b)) > limit {
+		return b[:limit], &http.MaxBytesError{Limit: limit}
+	}
+	return b, nil
+}
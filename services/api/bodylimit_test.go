@@ -0,0 +1,61 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAllLimited(t *testing.T) {
+	b, err := readAllLimited(strings.NewReader("12345"), 5)
+	require.NoError(t, err)
+	require.Equal(t, "12345", string(b))
+
+	_, err = readAllLimited(strings.NewReader("123456"), 5)
+	require.True(t, isBodyTooLarge(err))
+
+	b, err = readAllLimited(strings.NewReader("123456"), 0)
+	require.NoError(t, err)
+	require.Len(t, b, 6)
+}
+
+func TestBodyLimit(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.MaxRegistrationBytes = 100
+	backend.relay.opts.MaxSubmitBytes = 1000
+	body := []byte("[" + strings.Repeat(" ", 200) + "]")
+
+	// Rejected by content length, before the handler reads the body
+	rr := backend.requestBytes(http.MethodPost, pathRegisterValidator, body, nil)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodeRequestTooLarge))
+
+	// Without content length, once the handler has read more than the limit
+	req, err := http.NewRequest(http.MethodPost, pathRegisterValidator, io.NopCloser(bytes.NewReader(body)))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), req.ContentLength)
+	req.ContentLength = -1
+	rr = httptest.NewRecorder()
+	backend.relay.getRouter().ServeHTTP(rr, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+
+	// Other endpoints have their own limit
+	rr = backend.requestBytes(http.MethodPost, pathSubmitNewBlock, body, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// A gzipped submission is limited after decompression too
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, err = zw.Write([]byte("{" + strings.Repeat(" ", 2000) + "}"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.Less(t, gz.Len(), 1000)
+	rr = backend.requestBytes(http.MethodPost, pathSubmitNewBlock, gz.Bytes(), map[string]string{"Content-Encoding": "gzip"})
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
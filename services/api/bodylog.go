@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// appended to logged bodies which were cut off at the body limit
+const bodyLogTruncated = "... (truncated)"
+
+// bodyRecorder is a http.ResponseWriter that keeps a copy of the response
+type bodyRecorder struct {
+	statusRecorder
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// decodeBodyForLog returns the request body as it would be decoded by the handlers (gzip-compressed submissions unpacked).
+// Like in the handlers, at most limit bytes are unpacked, the rest of a larger body is cut off.
+func decodeBodyForLog(body []byte, contentEncoding string, limit int64) string {
+	if contentEncoding != "gzip" {
+		return string(body)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return string(body)
+	}
+	decoded, err := readAllLimited(r, limit)
+	if isBodyTooLarge(err) {
+		return string(decoded) + bodyLogTruncated
+	} else if err != nil {
+		return string(body)
+	}
+	return string(decoded)
+}
+
+// bodyLogMiddleware logs the full request and response bodies of a random sample of requests, for debugging builder
+// and proposer integrations. It is only added to the router if DebugSampleRate is above 0.
+func (api *RelayAPI) bodyLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if rand.Float64() >= api.opts.DebugSampleRate { //nolint:gosec
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		reqBody, err := io.ReadAll(req.Body)
+		if err != nil {
+			// Pass the error on, so that the handler responds as usual (i.e. with 413 if the body is too large)
+			api.log.WithError(err).Warn("body log: could not read request body")
+			req.Body = io.NopCloser(&replayReader{Reader: bytes.NewReader(reqBody), err: err})
+		} else {
+			req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		}
+
+		start := time.Now()
+		rw := &bodyRecorder{statusRecorder: statusRecorder{ResponseWriter: w, status: http.StatusOK}} //nolint:exhaustruct
+		next.ServeHTTP(rw, req)
+
+		api.log.WithFields(logrus.Fields{
+			"requestID":    getRequestID(req),
+			"method":       req.Method,
+			"path":         req.URL.Path,
+			"query":        req.URL.RawQuery,
+			"status":       rw.status,
+			"durationMs":   time.Since(start).Milliseconds(),
+			"requestBody":  decodeBodyForLog(reqBody, req.Header.Get("Content-Encoding"), api.maxBodyBytes(routeTemplate(req))),
+			"responseBody": rw.body.String(),
+		}).Info("sampled request")
+	})
+}
+
+// replayReader returns the data read before a read error, and then the error
+type replayReader struct {
+	io.Reader
+	err error
+}
+
+func (r *replayReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, r.err
+	}
+	return n, err
+}
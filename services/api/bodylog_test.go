@@ -0,0 +1,62 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLogMiddleware(t *testing.T) {
+	sampledEntries := func(hook *logtest.Hook) []*logrus.Entry {
+		entries := []*logrus.Entry{}
+		for _, entry := range hook.AllEntries() {
+			if entry.Message == "sampled request" {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+
+	t.Run("every request sampled", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		logger, hook := logtest.NewNullLogger()
+		backend.relay.log = logrus.NewEntry(logger)
+		backend.relay.opts.DebugSampleRate = 1
+
+		rr := backend.requestBytes(http.MethodPost, pathSubmitNewBlock, []byte("{"), nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		entries := sampledEntries(hook)
+		require.Len(t, entries, 1)
+		require.Equal(t, "{", entries[0].Data["requestBody"])
+		require.Equal(t, rr.Body.String(), entries[0].Data["responseBody"])
+		require.Equal(t, http.StatusBadRequest, entries[0].Data["status"])
+	})
+
+	t.Run("gzipped body unpacked up to the limit", func(t *testing.T) {
+		var gzipped bytes.Buffer
+		zw := gzip.NewWriter(&gzipped)
+		_, err := zw.Write(bytes.Repeat([]byte("a"), 1000))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		require.Equal(t, strings.Repeat("a", 1000), decodeBodyForLog(gzipped.Bytes(), "gzip", 1000))
+		require.Equal(t, strings.Repeat("a", 10)+bodyLogTruncated, decodeBodyForLog(gzipped.Bytes(), "gzip", 10))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		logger, hook := logtest.NewNullLogger()
+		backend.relay.log = logrus.NewEntry(logger)
+
+		rr := backend.requestBytes(http.MethodPost, pathSubmitNewBlock, []byte("{"), nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Empty(t, sampledEntries(hook))
+	})
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var (
+	ErrBuilderCAWithoutTLS = errors.New("builder CA requires a TLS certificate and key")
+	ErrInvalidBuilderCA    = errors.New("no certificates found in builder CA file")
+)
+
+// loadBuilderCA reads the CA bundle which builder client certificates need to be signed by
+func loadBuilderCA(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBuilderCA, caFile)
+	}
+	return pool, nil
+}
+
+// withBuilderClientAuth verifies client certificates against the builder CA, if one is given. Certificates are
+// optional in the handshake, so that the other routes stay open, and are required by submitBlock and cancelBid.
+func withBuilderClientAuth(cfg *tls.Config, builderCAs *x509.CertPool) *tls.Config {
+	if builderCAs != nil {
+		cfg.ClientCAs = builderCAs
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return cfg
+}
+
+// builderCertIdentity returns the common name of the verified client certificate of the request
+func builderCertIdentity(req *http.Request) (string, bool) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return req.TLS.VerifiedChains[0][0].Subject.CommonName, true
+}
+
+// isBuilderCertIdentity returns whether the client certificate with this common name may act for the builder: the
+// common name is either the builder pubkey, or the builder ID set with the builder's collateral
+func isBuilderCertIdentity(identity, builderPubkey string, builder *blockBuilderCacheEntry) bool {
+	if strings.EqualFold(identity, builderPubkey) {
+		return true
+	}
+	return builder != nil && builder.builderID != "" && identity == builder.builderID
+}
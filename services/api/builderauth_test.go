@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/attestantio/go-builder-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBuilderCA(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "ca.pem")
+	writeTestCert(t, certFile, filepath.Join(dir, "ca-key.pem"), "builder-ca")
+	pool, err := loadBuilderCA(certFile)
+	require.NoError(t, err)
+	require.NotNil(t, pool)
+
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("not a certificate"), 0o600))
+	_, err = loadBuilderCA(invalidFile)
+	require.ErrorIs(t, err, ErrInvalidBuilderCA)
+}
+
+func TestIsBuilderCertIdentity(t *testing.T) {
+	builder := &blockBuilderCacheEntry{builderID: "builder-1"} //nolint:exhaustruct
+	require.True(t, isBuilderCertIdentity(allowlistPubkey1, allowlistPubkey1, nil))
+	require.True(t, isBuilderCertIdentity("0x84E975405F8691AD7118527EE9EE4ED2E4E8BAE973F6E29AA9CA9EE4AEA83605AE3536D22ACC9AA1AF0545064EACF82E", allowlistPubkey1, nil))
+	require.True(t, isBuilderCertIdentity("builder-1", allowlistPubkey1, builder))
+	require.False(t, isBuilderCertIdentity("builder-2", allowlistPubkey1, builder))
+	require.False(t, isBuilderCertIdentity("", allowlistPubkey1, &blockBuilderCacheEntry{})) //nolint:exhaustruct
+	require.False(t, isBuilderCertIdentity(allowlistPubkey2, allowlistPubkey1, builder))
+}
+
+func TestBuilderSubmitBlockClientCert(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.headSlot.Store(1)
+	backend.relay.builderCAs = x509.NewCertPool()
+
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	var builderPubkey phase0.BLSPubKey
+	copy(builderPubkey[:], bls.PublicKeyToBytes(pk))
+	bid := &common.BidTraceV2{BidTrace: v1.BidTrace{Slot: 2, BuilderPubkey: builderPubkey, Value: uint256.NewInt(1)}}
+	submission := common.TestBuilderSubmitBlockRequest(sk, bid)
+	payload, err := json.Marshal(&submission)
+	require.NoError(t, err)
+
+	// certIdentity is the common name of the verified client certificate, none if empty
+	submit := func(certIdentity string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodPost, pathSubmitNewBlock, bytes.NewReader(payload))
+		require.NoError(t, err)
+		if certIdentity != "" {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: certIdentity}}       //nolint:exhaustruct
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}} //nolint:exhaustruct
+		}
+		rr := httptest.NewRecorder()
+		backend.relay.getRouter().ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := submit("")
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = submit(allowlistPubkey1)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodeBuilderNotAllowed))
+
+	// The builder pubkey or ID as common name pass the check
+	rr = submit(builderPubkey.String())
+	require.NotEqual(t, http.StatusForbidden, rr.Code)
+	require.NotEqual(t, http.StatusUnauthorized, rr.Code)
+
+	backend.relay.blockBuildersCache = map[string]*blockBuilderCacheEntry{
+		builderPubkey.String(): {builderID: "builder-1"}, //nolint:exhaustruct
+	}
+	rr = submit("builder-1")
+	require.NotEqual(t, http.StatusForbidden, rr.Code)
+	require.NotEqual(t, http.StatusUnauthorized, rr.Code)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+)
+
+// the cache gauges are updated periodically instead of on every change, to keep the locks of the caches off the
+// request paths. Go runtime memory stats are exported by the Go collector (go_memstats_*).
+var cacheMetricsInterval = time.Duration(cli.GetEnvInt("CACHE_METRICS_INTERVAL_SEC", 10)) * time.Second
+
+// startCacheMetricsUpdates updates the gauges of the in-memory caches every cacheMetricsInterval
+func (api *RelayAPI) startCacheMetricsUpdates() {
+	ticker := time.NewTicker(cacheMetricsInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		api.updateCacheMetrics()
+	}
+}
+
+// numRedisBids returns the number of builder bids in Redis for the slot, for all parent hashes of the slot's payload
+// attributes and the slot's proposer
+func (api *RelayAPI) numRedisBids(slot uint64) int {
+	api.proposerDutiesLock.RLock()
+	slotDuty := api.proposerDutiesMap[slot]
+	api.proposerDutiesLock.RUnlock()
+	if slotDuty == nil || slotDuty.Entry == nil {
+		return 0
+	}
+	proposerPubkey := slotDuty.Entry.Message.Pubkey.String()
+
+	parentHashes := []string{}
+	api.payloadAttributesLock.RLock()
+	for parentHash, attrs := range api.payloadAttributes {
+		if attrs.slot == slot {
+			parentHashes = append(parentHashes, parentHash)
+		}
+	}
+	api.payloadAttributesLock.RUnlock()
+
+	numBids := 0
+	for _, parentHash := range parentHashes {
+		n, err := api.redis.GetNumBuilderBids(context.Background(), slot, parentHash, proposerPubkey)
+		if err != nil {
+			api.log.WithError(err).WithField("slot", slot).Warn("could not count the bids in redis")
+			continue
+		}
+		numBids += int(n)
+	}
+	return numBids
+}
+
+func (api *RelayAPI) updateCacheMetrics() {
+	m := api.metrics
+	if m == nil {
+		return
+	}
+
+	// The bids are counted for the head and the next slot only, with relative slot labels to keep the series fixed
+	headSlot := api.headSlot.Load()
+	recentSlots := map[string]uint64{"head": headSlot, "next": headSlot + 1}
+	bidsBySlot := make(map[uint64]int)
+	if api.bidCache != nil {
+		for slot, n := range api.bidCache.countBySlot() {
+			bidsBySlot[slot] += n
+		}
+		m.cacheEntries.WithLabelValues("bids").Set(float64(api.bidCache.len()))
+	} else {
+		for _, slot := range recentSlots {
+			bidsBySlot[slot] += api.numRedisBids(slot)
+		}
+	}
+	if api.federatedBids != nil {
+		peerBids := api.federatedBids.countBySlot()
+		numPeerBids := 0
+		for slot, n := range peerBids {
+			bidsBySlot[slot] += n
+			numPeerBids += n
+		}
+		m.cacheEntries.WithLabelValues("peer_bids").Set(float64(numPeerBids))
+	}
+	for label, slot := range recentSlots {
+		m.cachedBids.WithLabelValues(label).Set(float64(bidsBySlot[slot]))
+	}
+	api.log.WithField("bidsBySlot", bidsBySlot).Debug("tracked bids")
+
+	if api.servedHeaders != nil {
+		m.cacheEntries.WithLabelValues("served_headers").Set(float64(api.servedHeaders.len()))
+	}
+	if api.registrationSigCache != nil {
+		m.cacheEntries.WithLabelValues("registration_signatures").Set(float64(api.registrationSigCache.len()))
+	}
+	m.cacheEntries.WithLabelValues("delivered_payloads").Set(float64(api.deliveredPayloads.len()))
+	m.cacheEntries.WithLabelValues("served_slots").Set(float64(api.missedSlots.len()))
+	m.cacheEntries.WithLabelValues("known_validators").Set(float64(api.datastore.NumKnownValidators()))
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateCacheMetrics(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.metrics = newRelayMetrics()
+	backend.relay.bidCache = newBidCache(10)
+
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	_, _, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(1), nil)
+	backend.relay.bidCache.set(3, parentHash, proposerPubkey, getHeaderResp)
+	backend.relay.bidCache.set(3, "0x01", proposerPubkey, getHeaderResp)
+	backend.relay.bidCache.set(4, parentHash, proposerPubkey, getHeaderResp)
+	backend.relay.missedSlots.recordServed(3, proposerPubkey, "0x01")
+	backend.relay.headSlot.Store(3)
+
+	backend.relay.updateCacheMetrics()
+	rr := backend.request(http.MethodGet, pathMetrics, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	require.Contains(t, body, `relay_cached_bids{slot="head"} 2`)
+	require.Contains(t, body, `relay_cached_bids{slot="next"} 1`)
+	require.Contains(t, body, `relay_cache_entries{cache="bids"} 3`)
+	require.Contains(t, body, `relay_cache_entries{cache="served_slots"} 1`)
+	require.Contains(t, body, `relay_cache_entries{cache="delivered_payloads"} 0`)
+
+	// the labels move on with the head slot
+	backend.relay.bidCache.pruneBefore(4)
+	backend.relay.headSlot.Store(4)
+	backend.relay.updateCacheMetrics()
+	body = backend.request(http.MethodGet, pathMetrics, nil).Body.String()
+	require.Contains(t, body, `relay_cached_bids{slot="head"} 1`)
+	require.Contains(t, body, `relay_cached_bids{slot="next"} 0`)
+}
+
+func TestUpdateCacheMetricsRedisBids(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.metrics = newRelayMetrics()
+
+	slot := uint64(4)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	pubkey, err := types.HexToPubkey(proposerPubkey)
+	require.NoError(t, err)
+	backend.relay.headSlot.Store(slot - 1)
+	backend.relay.proposerDutiesMap = map[uint64]*common.BuilderGetValidatorsResponseEntry{
+		slot: {Slot: slot, Entry: &types.SignedValidatorRegistration{Message: &types.RegisterValidatorRequestMessage{Pubkey: pubkey}}}, //nolint:exhaustruct
+	}
+	backend.relay.payloadAttributes[parentHash] = payloadAttributesHelper{slot: slot} //nolint:exhaustruct
+
+	// Without a bid cache, the latest bids of the builders are counted in Redis
+	for i, builderPubkey := range []string{
+		"0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83",
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+	} {
+		opts := common.CreateTestBlockSubmissionOpts{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey} //nolint:exhaustruct
+		payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(int64(i+1)), &opts)
+		_, err := backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), &common.BidTraceV2{BidTrace: *payload.Message()}, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil) //nolint:exhaustruct
+		require.NoError(t, err)
+	}
+
+	backend.relay.updateCacheMetrics()
+	body := backend.request(http.MethodGet, pathMetrics, nil).Body.String()
+	require.Contains(t, body, `relay_cached_bids{slot="head"} 0`)
+	require.Contains(t, body, `relay_cached_bids{slot="next"} 2`)
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+// handleCancelBid removes a builder's latest bid for a slot, parentHash and proposer, so that getHeader doesn't serve
+// it anymore. The cancellation has to be signed by the builder of the bid, has to be for the block hash of the builder's
+// latest bid (so it can't be replayed against a later bid), and has to arrive before the slot starts. A floor bid (set
+// by a non-cancellable submission) stays in place.
+func (api *RelayAPI) handleCancelBid(w http.ResponseWriter, req *http.Request) {
+	log := api.log.WithFields(logrus.Fields{
+		"method":    "cancelBid",
+		"requestID": getRequestID(req),
+	})
+
+	if !api.ffEnableCancellations {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeCancellationsDisabled, "cancellations are disabled")
+		return
+	}
+
+	certIdentity, hasCert := builderCertIdentity(req)
+	if api.builderCAs != nil {
+		if !hasCert {
+			log.Info("rejecting cancellation - no builder client certificate")
+			api.RespondErrorCode(w, http.StatusUnauthorized, ErrorCodeUnauthorized, "a builder client certificate is required")
+			return
+		}
+		log = log.WithField("certIdentity", certIdentity)
+	}
+
+	cancellation := new(common.SignedBuilderBidCancellation)
+	if err := json.NewDecoder(req.Body).Decode(cancellation); isBodyTooLarge(err) {
+		api.respondBodyTooLarge(w, maxRequestBodyBytes)
+		return
+	} else if err != nil {
+		log.WithError(err).Warn("could not decode cancellation")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
+		return
+	} else if cancellation.Message == nil {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "missing message")
+		return
+	}
+
+	msg := cancellation.Message
+	slot := msg.Slot
+	parentHash := msg.ParentHash.String()
+	proposerPubkey := msg.ProposerPubkey.String()
+	builderPubkey := msg.BuilderPubkey.String()
+	blockHash := msg.BlockHash.String()
+	log = log.WithFields(logrus.Fields{
+		"slot":           slot,
+		"parentHash":     parentHash,
+		"proposerPubkey": proposerPubkey,
+		"builderPubkey":  builderPubkey,
+		"blockHash":      blockHash,
+	})
+
+	if !api.builderAllowlist.isAllowed(builderPubkey) {
+		log.Info("rejecting cancellation - builder not on the allowlist")
+		api.RespondErrorCode(w, http.StatusForbidden, ErrorCodeBuilderNotAllowed, "builder not on the allowlist")
+		return
+	}
+	if api.builderCAs != nil && !isBuilderCertIdentity(certIdentity, builderPubkey, api.blockBuildersCache[builderPubkey]) {
+		log.Info("rejecting cancellation - builder client certificate doesn't match the builder")
+		api.RespondErrorCode(w, http.StatusForbidden, ErrorCodeBuilderNotAllowed, "builder client certificate doesn't match the builder pubkey or ID")
+		return
+	}
+
+	slotStartTimestamp := api.genesisInfo.Data.GenesisTime + (slot * common.SecondsPerSlot)
+	if slot <= api.headSlot.Load() || time.Now().UTC().Unix() >= int64(slotStartTimestamp) {
+		log.Info("cancellation too late")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeRequestTooLate, "cancellation too late, the slot has already started")
+		return
+	}
+
+	// The cancellation is verified against the builder pubkey of the bid it removes, so builders can only cancel their own bids
+	ok, err := api.verifySignature(false, msg, api.opts.EthNetDetails.DomainBuilder, msg.BuilderPubkey[:], cancellation.Signature[:])
+	if err != nil {
+		log.WithError(err).Warn("failed verifying cancellation signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "failed verifying cancellation signature")
+		return
+	} else if !ok {
+		log.Warn("invalid cancellation signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "invalid signature")
+		return
+	}
+
+	// The latest value tells whether the builder has a bid, the bid itself stays in Redis after a cancellation
+	bidValue, err := api.redis.GetBuilderLatestValue(slot, parentHash, proposerPubkey, builderPubkey)
+	if err != nil {
+		log.WithError(err).Error("could not get latest bid value of builder")
+		api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternal, "could not get latest bid of builder")
+		return
+	} else if bidValue.Sign() == 0 {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeBidNotFound, "no bid of this builder")
+		return
+	}
+	latestBid, err := api.redis.GetBuilderLatestBid(slot, parentHash, proposerPubkey, builderPubkey)
+	if err != nil {
+		log.WithError(err).Error("could not get latest bid of builder")
+		api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternal, "could not get latest bid of builder")
+		return
+	} else if latestBid == nil {
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeBidNotFound, "no bid of this builder")
+		return
+	} else if !strings.EqualFold(latestBid.BlockHash().String(), blockHash) {
+		log.WithField("latestBlockHash", latestBid.BlockHash().String()).Info("rejecting cancellation - not for the latest bid of the builder")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeBidNotFound, "the latest bid of the builder is for another block hash")
+		return
+	}
+
+	err = api.redis.DelBuilderBid(context.Background(), api.redis.NewTxPipeline(), slot, parentHash, proposerPubkey, builderPubkey)
+	if err != nil {
+		log.WithError(err).Error("failed cancelling bid")
+		api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternal, "failed cancelling bid")
+		return
+	}
+	if api.bidCache != nil {
+		api.bidCache.delete(slot, parentHash, proposerPubkey)
+	}
+
+	log.WithField("value", bidValue.String()).Info("bid cancelled")
+	api.RespondOK(w, NilResponse)
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelBid(t *testing.T) {
+	slot := uint64(11)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+
+	builderSk, builderBlsPk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	builderPubkey, err := types.BlsPublicKeyToPublicKey(builderBlsPk)
+	require.NoError(t, err)
+	competitorSk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+
+	setup := func(t *testing.T) *testBackend {
+		t.Helper()
+		backend := newTestBackend(t, 1)
+		backend.relay.ffEnableCancellations = true
+		backend.relay.headSlot.Store(slot - 1)
+		backend.relay.genesisInfo.Data.GenesisTime = uint64(time.Now().UTC().Unix()) // slot 11 starts in the future
+
+		// A cancellable bid of the builder
+		opts := common.CreateTestBlockSubmissionOpts{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey} //nolint:exhaustruct
+		payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey.String(), big.NewInt(10), &opts)
+		_, err := backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), &common.BidTraceV2{BidTrace: *payload.Message()}, payload, getPayloadResp, getHeaderResp, time.Now(), true, nil) //nolint:exhaustruct
+		require.NoError(t, err)
+		return backend
+	}
+
+	// certIdentity is the common name of the verified client certificate, none if empty
+	cancelBidWithCert := func(t *testing.T, backend *testBackend, sk *bls.SecretKey, certIdentity string) *HTTPErrorResp {
+		t.Helper()
+		msg := &common.BuilderBidCancellation{Slot: slot, BuilderPubkey: builderPubkey} // the test bids have an empty block hash
+		require.NoError(t, msg.ParentHash.UnmarshalText([]byte(parentHash)))
+		require.NoError(t, msg.ProposerPubkey.UnmarshalText([]byte(proposerPubkey)))
+		sig, err := types.SignMessage(msg, backend.relay.opts.EthNetDetails.DomainBuilder, sk)
+		require.NoError(t, err)
+		body, err := json.Marshal(&common.SignedBuilderBidCancellation{Message: msg, Signature: sig})
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, pathBuilderCancelBid, bytes.NewReader(body))
+		require.NoError(t, err)
+		if certIdentity != "" {
+			cert := &x509.Certificate{Subject: pkix.Name{CommonName: certIdentity}}       //nolint:exhaustruct
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}} //nolint:exhaustruct
+		}
+		rr := httptest.NewRecorder()
+		backend.relay.getRouter().ServeHTTP(rr, req)
+		if rr.Code == http.StatusOK {
+			return nil
+		}
+		resp := new(HTTPErrorResp)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		return resp
+	}
+
+	cancelBid := func(t *testing.T, backend *testBackend, sk *bls.SecretKey) *HTTPErrorResp {
+		t.Helper()
+		return cancelBidWithCert(t, backend, sk, "")
+	}
+
+	t.Run("cancels own bid", func(t *testing.T) {
+		backend := setup(t)
+		require.Nil(t, cancelBid(t, backend, builderSk))
+
+		bid, err := backend.redis.GetBestBid(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Nil(t, bid)
+
+		// Nothing left to cancel
+		resp := cancelBid(t, backend, builderSk)
+		require.Equal(t, ErrorCodeBidNotFound, resp.ErrorCode)
+	})
+
+	t.Run("cannot be replayed against a later bid", func(t *testing.T) {
+		backend := setup(t)
+		require.Nil(t, cancelBid(t, backend, builderSk))
+
+		// The builder submits another block
+		opts := common.CreateTestBlockSubmissionOpts{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey} //nolint:exhaustruct
+		payload, getPayloadResp, _ := common.CreateTestBlockSubmission(t, builderPubkey.String(), big.NewInt(5), &opts)
+		payload.Capella.ExecutionPayload.BlockHash = phase0.Hash32{0x01}
+		getHeaderResp, err := common.BuildGetHeaderResponse(payload, &bls.SecretKey{}, &types.PublicKey{}, types.Domain{})
+		require.NoError(t, err)
+		_, err = backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), &common.BidTraceV2{BidTrace: *payload.Message()}, payload, getPayloadResp, getHeaderResp, time.Now(), true, nil) //nolint:exhaustruct
+		require.NoError(t, err)
+
+		resp := cancelBid(t, backend, builderSk)
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+		require.Equal(t, ErrorCodeBidNotFound, resp.ErrorCode)
+
+		bid, err := backend.redis.GetBestBid(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(5), bid.Value())
+	})
+
+	t.Run("cannot cancel a competitor's bid", func(t *testing.T) {
+		backend := setup(t)
+		resp := cancelBid(t, backend, competitorSk)
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+		require.Equal(t, ErrorCodeInvalidSignature, resp.ErrorCode)
+
+		bid, err := backend.redis.GetBestBid(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(10), bid.Value())
+	})
+
+	t.Run("builder not on the allowlist", func(t *testing.T) {
+		backend := setup(t)
+		backend.relay.builderAllowlist, err = newBuilderAllowlist(allowlistPubkey1)
+		require.NoError(t, err)
+		resp := cancelBid(t, backend, builderSk)
+		require.Equal(t, http.StatusForbidden, resp.Code)
+		require.Equal(t, ErrorCodeBuilderNotAllowed, resp.ErrorCode)
+
+		bid, err := backend.redis.GetBestBid(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(10), bid.Value())
+	})
+
+	t.Run("builder client certificate", func(t *testing.T) {
+		backend := setup(t)
+		backend.relay.builderCAs = x509.NewCertPool()
+
+		resp := cancelBid(t, backend, builderSk)
+		require.Equal(t, http.StatusUnauthorized, resp.Code)
+
+		resp = cancelBidWithCert(t, backend, builderSk, allowlistPubkey1)
+		require.Equal(t, http.StatusForbidden, resp.Code)
+		require.Equal(t, ErrorCodeBuilderNotAllowed, resp.ErrorCode)
+
+		bid, err := backend.redis.GetBestBid(slot, parentHash, proposerPubkey)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(10), bid.Value())
+
+		require.Nil(t, cancelBidWithCert(t, backend, builderSk, builderPubkey.String()))
+	})
+
+	t.Run("too late", func(t *testing.T) {
+		backend := setup(t)
+		backend.relay.headSlot.Store(slot)
+		resp := cancelBid(t, backend, builderSk)
+		require.Equal(t, ErrorCodeRequestTooLate, resp.ErrorCode)
+	})
+
+	t.Run("cancellations disabled", func(t *testing.T) {
+		backend := setup(t)
+		backend.relay.ffEnableCancellations = false
+		resp := cancelBid(t, backend, builderSk)
+		require.Equal(t, ErrorCodeCancellationsDisabled, resp.ErrorCode)
+	})
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// the getHeader and getPayload cutoffs are relative to the slot start, so the local clock needs to agree with the
+	// beacon node's. Offsets above the threshold are logged as warnings.
+	clockSkewThreshold     = time.Duration(cli.GetEnvInt("CLOCK_SKEW_THRESHOLD_MS", 1000)) * time.Millisecond
+	clockSkewCheckInterval = time.Duration(cli.GetEnvInt("CLOCK_SKEW_CHECK_INTERVAL_SEC", 60)) * time.Second
+)
+
+// startClockSkewChecks compares the local clock with the beacon node's every clockSkewCheckInterval
+func (api *RelayAPI) startClockSkewChecks() {
+	ticker := time.NewTicker(clockSkewCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		api.checkClockSkew()
+	}
+}
+
+// checkClockSkew updates the clock skew gauge and warns if the beacon node's clock is too far ahead or behind
+func (api *RelayAPI) checkClockSkew() {
+	offset, err := api.beaconClient.ClockOffset()
+	if err != nil {
+		api.log.WithError(err).Warn("failed to compare the local clock with the beacon node")
+		return
+	}
+	api.clockSkewMs.Store(offset.Milliseconds())
+
+	log := api.log.WithFields(logrus.Fields{
+		"clockSkewMs": offset.Milliseconds(),
+		"thresholdMs": clockSkewThreshold.Milliseconds(),
+	})
+	if offset > clockSkewThreshold || offset < -clockSkewThreshold {
+		log.Warn("local clock differs from the beacon node's clock, getHeader and getPayload cutoffs will be off (check NTP)")
+	} else {
+		log.Debug("local clock in sync with the beacon node")
+	}
+}
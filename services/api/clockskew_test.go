@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckClockSkew(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	logger, hook := logtest.NewNullLogger()
+	backend.relay.log = logrus.NewEntry(logger)
+	beaconInstance := beaconclient.NewMockBeaconInstance()
+	backend.relay.beaconClient = beaconclient.NewMultiBeaconClient(backend.relay.log, []beaconclient.IBeaconInstance{beaconInstance})
+
+	beaconInstance.MockClockOffset = 200 * time.Millisecond
+	backend.relay.checkClockSkew()
+	require.Equal(t, int64(200), backend.relay.clockSkewMs.Load())
+	require.Empty(t, hook.AllEntries())
+
+	beaconInstance.MockClockOffset = -2 * time.Second
+	backend.relay.checkClockSkew()
+	require.Equal(t, int64(-2000), backend.relay.clockSkewMs.Load())
+	require.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+}
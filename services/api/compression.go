@@ -0,0 +1,143 @@
+package api
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NYTimes/gziphandler"
+)
+
+// DefaultCompressMinSize is the response size from which responses are compressed. Smaller responses, like most
+// getHeader and status responses, fit into a few packets and wouldn't get faster by compressing them.
+const DefaultCompressMinSize = gziphandler.DefaultMinSize
+
+// compressed compresses responses of the handler of at least opts.CompressMinSize bytes with gzip or deflate, as
+// negotiated by Accept-Encoding (gzip is preferred), unless opts.DisableCompression is set. It uses the fastest
+// compression level, since getPayload responses are on the critical path of the proposal.
+func (api *RelayAPI) compressed(next http.Handler) http.Handler {
+	if api.opts.DisableCompression {
+		return next
+	}
+	gz, err := gziphandler.GzipHandlerWithOpts(gziphandler.CompressionLevel(gzip.BestSpeed), gziphandler.MinSize(api.opts.CompressMinSize))
+	if err != nil {
+		// only for a negative min size, which NewRelayAPI replaces with the default
+		api.log.WithError(err).Error("invalid compression options, responses are not compressed")
+		return next
+	}
+	gzNext := gz(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		acceptEncoding := req.Header.Get("Accept-Encoding")
+		if acceptsEncoding(acceptEncoding, "gzip") || !acceptsEncoding(acceptEncoding, "deflate") {
+			gzNext.ServeHTTP(w, req)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		dw := &deflateResponseWriter{ResponseWriter: w, minSize: api.opts.CompressMinSize} //nolint:exhaustruct
+		defer dw.close()
+		next.ServeHTTP(dw, req)
+	})
+}
+
+// acceptsEncoding returns whether the Accept-Encoding header accepts the encoding (with a non-zero quality)
+func acceptsEncoding(acceptEncoding, encoding string) bool {
+	for _, spec := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(spec), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		quality, err := strconv.ParseFloat(q, 64)
+		return err == nil && quality > 0
+	}
+	return false
+}
+
+// deflateResponseWriter buffers the response until it reaches minSize bytes, and deflate-compresses it from there.
+// Smaller responses are written as is on close.
+type deflateResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+
+	code  int
+	buf   []byte
+	fw    *flate.Writer
+	plain bool // the handler encoded the response itself
+}
+
+func (w *deflateResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *deflateResponseWriter) Write(b []byte) (int, error) {
+	if w.fw != nil {
+		return w.fw.Write(b)
+	} else if w.plain {
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) < w.minSize {
+		return len(b), nil
+	}
+	if err := w.start(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// start writes the header and the buffered response, compressed unless the handler set a Content-Encoding
+func (w *deflateResponseWriter) start() (err error) {
+	if w.Header().Get("Content-Encoding") != "" {
+		w.plain = true
+	} else {
+		w.Header().Set("Content-Encoding", "deflate")
+		w.Header().Del("Content-Length")
+		if w.fw, err = flate.NewWriter(w.ResponseWriter, flate.BestSpeed); err != nil {
+			return err
+		}
+	}
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+	buf := w.buf
+	w.buf = nil
+	_, err = w.Write(buf)
+	return err
+}
+
+func (w *deflateResponseWriter) Flush() {
+	if w.fw == nil && !w.plain {
+		_ = w.start()
+	}
+	if w.fw != nil {
+		_ = w.fw.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close finishes the compressed response, or writes a response below the min size as is
+func (w *deflateResponseWriter) close() {
+	if w.fw != nil {
+		_ = w.fw.Close()
+		return
+	} else if w.plain {
+		return
+	}
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+	if len(w.buf) > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf)
+	}
+}
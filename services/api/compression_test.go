@@ -0,0 +1,64 @@
+package api
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressed(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.CompressMinSize = 1
+	path := pathDataBuilderBidsReceived + "?slot=1"
+	gzipHeaders := map[string]string{"Accept-Encoding": "gzip"}
+
+	// Responses above the min size are compressed if the client accepts gzip
+	uncompressed := backend.requestBytes(http.MethodGet, path, nil, nil)
+	require.Empty(t, uncompressed.Header().Get("Content-Encoding"))
+	rr := backend.requestBytes(http.MethodGet, path, nil, gzipHeaders)
+	require.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+	r, err := gzip.NewReader(rr.Body)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, uncompressed.Body.String(), string(decompressed))
+
+	// ..or deflate, gzip is preferred
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"Accept-Encoding": "deflate, gzip;q=0"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "deflate", rr.Header().Get("Content-Encoding"))
+	decompressed, err = io.ReadAll(flate.NewReader(rr.Body))
+	require.NoError(t, err)
+	require.Equal(t, uncompressed.Body.String(), string(decompressed))
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"Accept-Encoding": "deflate, gzip"})
+	require.Equal(t, "gzip", rr.Header().Get("Content-Encoding"))
+
+	// and sent as is below the min size
+	backend.relay.opts.CompressMinSize = DefaultCompressMinSize
+	rr = backend.requestBytes(http.MethodGet, path, nil, gzipHeaders)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"Accept-Encoding": "deflate"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+	require.Equal(t, uncompressed.Body.String(), rr.Body.String())
+
+	// or if disabled
+	backend.relay.opts.CompressMinSize = 1
+	backend.relay.opts.DisableCompression = true
+	rr = backend.requestBytes(http.MethodGet, path, nil, gzipHeaders)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("Content-Encoding"))
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	require.True(t, acceptsEncoding("gzip", "gzip"))
+	require.True(t, acceptsEncoding("br, GZIP;q=0.5", "gzip"))
+	require.False(t, acceptsEncoding("gzip;q=0", "gzip"))
+	require.False(t, acceptsEncoding("", "deflate"))
+	require.False(t, acceptsEncoding("gzip", "deflate"))
+}
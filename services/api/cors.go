@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"golang.org/x/exp/slices"
+)
+
+const corsMaxAgeSec = "600"
+
+// isCORSOriginAllowed returns true if the origin is in opts.CORSOrigins, or if all origins are allowed with "*"
+func (api *RelayAPI) isCORSOriginAllowed(origin string) bool {
+	return origin != "" && (slices.Contains(api.opts.CORSOrigins, "*") || slices.Contains(api.opts.CORSOrigins, origin))
+}
+
+// cors adds CORS headers for the allowed origins, and answers OPTIONS preflight requests. It is only meant
+// for read-only routes. Without configured origins, the handler is returned as is.
+func (api *RelayAPI) cors(next http.HandlerFunc) http.Handler {
+	if len(api.opts.CORSOrigins) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if api.isCORSOriginAllowed(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if req.Method == http.MethodOptions {
+			if api.isCORSOriginAllowed(origin) {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+				w.Header().Set("Access-Control-Max-Age", corsMaxAgeSec)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next(w, req)
+	})
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/go-redis/redis/v9"
+)
+
+// relayRedis is the part of datastore.RedisCache used by the API
+type relayRedis interface {
+	NewTxPipeline() redis.Pipeliner
+	Ping(ctx context.Context) error
+
+	GetBestBid(slot uint64, parentHash, proposerPubkey string) (*common.GetHeaderResponse, error)
+	GetBidTrace(slot uint64, proposerPubkey, blockHash string) (*common.BidTraceV2, error)
+	GetProposerDuties() (proposerDuties []common.BuilderGetValidatorsResponseEntry, err error)
+	GetValidatorRegistrationTimestamp(proposerPubkey types.PubkeyHex) (uint64, error)
+	GetLastSlotDelivered(ctx context.Context, tx redis.Pipeliner) (slot uint64, err error)
+	CheckAndSetLastSlotAndHashDelivered(slot uint64, hash string) (err error)
+	GetTopBidValue(ctx context.Context, tx redis.Pipeliner, slot uint64, parentHash, proposerPubkey string) (topBidValue *big.Int, err error)
+	GetFloorBidValue(ctx context.Context, tx redis.Pipeliner, slot uint64, parentHash, proposerPubkey string) (floorValue *big.Int, err error)
+	GetBuilderLatestValue(slot uint64, parentHash, proposerPubkey, builderPubkey string) (topBidValue *big.Int, err error)
+	GetBuilderLatestBid(slot uint64, parentHash, proposerPubkey, builderPubkey string) (*common.GetHeaderResponse, error)
+	GetNumBuilderBids(ctx context.Context, slot uint64, parentHash, proposerPubkey string) (int64, error)
+	GetBuilderLatestPayloadReceivedAt(ctx context.Context, tx redis.Pipeliner, slot uint64, builderPubkey, parentHash, proposerPubkey string) (int64, error)
+	SaveBidAndUpdateTopBid(ctx context.Context, tx redis.Pipeliner, trace *common.BidTraceV2, payload *common.BuilderSubmitBlockRequest, getPayloadResponse *common.GetPayloadResponse, getHeaderResponse *common.GetHeaderResponse, reqReceivedAt time.Time, isCancellationEnabled bool, floorValue *big.Int) (state datastore.SaveBidAndUpdateTopBidResponse, err error)
+	DelBuilderBid(ctx context.Context, tx redis.Pipeliner, slot uint64, parentHash, proposerPubkey, builderPubkey string) (err error)
+	TryLockPruning(ctx context.Context, ttl time.Duration) (bool, error)
+	PruneSlotsBefore(ctx context.Context, slot uint64, archive io.Writer) (numDeleted int, err error)
+	AddBidHistoryEntries(ctx context.Context, entries []*common.BidHistoryEntry, maxEntries int, retention time.Duration) error
+	GetBidHistory(slot uint64) (entries []*common.BidHistoryEntry, err error)
+	RecordBuilderOutcome(ctx context.Context, builderPubkey string, success bool) error
+}
+
+// relayDatastore is the part of datastore.Datastore used by the API
+type relayDatastore interface {
+	RefreshKnownValidators(beaconClient beaconclient.IMultiBeaconClient, slot uint64)
+	IsKnownValidator(pubkeyHex types.PubkeyHex) bool
+	GetKnownValidatorPubkeyByIndex(index uint64) (types.PubkeyHex, bool)
+	GetKnownValidatorStatus(pubkeyHex types.PubkeyHex) (string, bool)
+	NumKnownValidators() int
+	SaveValidatorRegistration(entry types.SignedValidatorRegistration) error
+	GetGetPayloadResponse(slot uint64, proposerPubkey, blockHash string) (*common.VersionedExecutionPayload, error)
+}
+
+// The metricsRedis, metricsDatastore and metricsDB wrappers record the latency of datastore operations, separately
+// from the latency of the handlers which call them. Operations which don't reach a backend are passed through.
+type metricsRedis struct {
+	relayRedis
+	metrics *relayMetrics
+}
+
+func (r *metricsRedis) observe(operation string, start time.Time, err *error) {
+	r.metrics.observeDatastoreOperation("redis", operation, start, *err)
+}
+
+func (r *metricsRedis) GetBestBid(slot uint64, parentHash, proposerPubkey string) (resp *common.GetHeaderResponse, err error) {
+	defer r.observe("getBestBid", time.Now(), &err)
+	return r.relayRedis.GetBestBid(slot, parentHash, proposerPubkey)
+}
+
+func (r *metricsRedis) GetBidTrace(slot uint64, proposerPubkey, blockHash string) (trace *common.BidTraceV2, err error) {
+	defer r.observe("getBidTrace", time.Now(), &err)
+	return r.relayRedis.GetBidTrace(slot, proposerPubkey, blockHash)
+}
+
+func (r *metricsRedis) GetProposerDuties() (proposerDuties []common.BuilderGetValidatorsResponseEntry, err error) {
+	defer r.observe("getProposerDuties", time.Now(), &err)
+	return r.relayRedis.GetProposerDuties()
+}
+
+func (r *metricsRedis) GetValidatorRegistrationTimestamp(proposerPubkey types.PubkeyHex) (timestamp uint64, err error) {
+	defer r.observe("getValidatorRegistrationTimestamp", time.Now(), &err)
+	return r.relayRedis.GetValidatorRegistrationTimestamp(proposerPubkey)
+}
+
+func (r *metricsRedis) GetLastSlotDelivered(ctx context.Context, tx redis.Pipeliner) (slot uint64, err error) {
+	defer r.observe("getLastSlotDelivered", time.Now(), &err)
+	return r.relayRedis.GetLastSlotDelivered(ctx, tx)
+}
+
+func (r *metricsRedis) CheckAndSetLastSlotAndHashDelivered(slot uint64, hash string) (err error) {
+	defer r.observe("checkAndSetLastSlotAndHashDelivered", time.Now(), &err)
+	return r.relayRedis.CheckAndSetLastSlotAndHashDelivered(slot, hash)
+}
+
+func (r *metricsRedis) GetTopBidValue(ctx context.Context, tx redis.Pipeliner, slot uint64, parentHash, proposerPubkey string) (topBidValue *big.Int, err error) {
+	defer r.observe("getTopBidValue", time.Now(), &err)
+	return r.relayRedis.GetTopBidValue(ctx, tx, slot, parentHash, proposerPubkey)
+}
+
+func (r *metricsRedis) GetFloorBidValue(ctx context.Context, tx redis.Pipeliner, slot uint64, parentHash, proposerPubkey string) (floorValue *big.Int, err error) {
+	defer r.observe("getFloorBidValue", time.Now(), &err)
+	return r.relayRedis.GetFloorBidValue(ctx, tx, slot, parentHash, proposerPubkey)
+}
+
+func (r *metricsRedis) GetBuilderLatestValue(slot uint64, parentHash, proposerPubkey, builderPubkey string) (topBidValue *big.Int, err error) {
+	defer r.observe("getBuilderLatestValue", time.Now(), &err)
+	return r.relayRedis.GetBuilderLatestValue(slot, parentHash, proposerPubkey, builderPubkey)
+}
+
+func (r *metricsRedis) GetBuilderLatestBid(slot uint64, parentHash, proposerPubkey, builderPubkey string) (resp *common.GetHeaderResponse, err error) {
+	defer r.observe("getBuilderLatestBid", time.Now(), &err)
+	return r.relayRedis.GetBuilderLatestBid(slot, parentHash, proposerPubkey, builderPubkey)
+}
+
+func (r *metricsRedis) GetNumBuilderBids(ctx context.Context, slot uint64, parentHash, proposerPubkey string) (numBids int64, err error) {
+	defer r.observe("getNumBuilderBids", time.Now(), &err)
+	return r.relayRedis.GetNumBuilderBids(ctx, slot, parentHash, proposerPubkey)
+}
+
+func (r *metricsRedis) GetBuilderLatestPayloadReceivedAt(ctx context.Context, tx redis.Pipeliner, slot uint64, builderPubkey, parentHash, proposerPubkey string) (receivedAt int64, err error) {
+	defer r.observe("getBuilderLatestPayloadReceivedAt", time.Now(), &err)
+	return r.relayRedis.GetBuilderLatestPayloadReceivedAt(ctx, tx, slot, builderPubkey, parentHash, proposerPubkey)
+}
+
+func (r *metricsRedis) SaveBidAndUpdateTopBid(ctx context.Context, tx redis.Pipeliner, trace *common.BidTraceV2, payload *common.BuilderSubmitBlockRequest, getPayloadResponse *common.GetPayloadResponse, getHeaderResponse *common.GetHeaderResponse, reqReceivedAt time.Time, isCancellationEnabled bool, floorValue *big.Int) (state datastore.SaveBidAndUpdateTopBidResponse, err error) {
+	defer r.observe("saveBidAndUpdateTopBid", time.Now(), &err)
+	return r.relayRedis.SaveBidAndUpdateTopBid(ctx, tx, trace, payload, getPayloadResponse, getHeaderResponse, reqReceivedAt, isCancellationEnabled, floorValue)
+}
+
+func (r *metricsRedis) DelBuilderBid(ctx context.Context, tx redis.Pipeliner, slot uint64, parentHash, proposerPubkey, builderPubkey string) (err error) {
+	defer r.observe("delBuilderBid", time.Now(), &err)
+	return r.relayRedis.DelBuilderBid(ctx, tx, slot, parentHash, proposerPubkey, builderPubkey)
+}
+
+func (r *metricsRedis) TryLockPruning(ctx context.Context, ttl time.Duration) (locked bool, err error) {
+	defer r.observe("tryLockPruning", time.Now(), &err)
+	return r.relayRedis.TryLockPruning(ctx, ttl)
+}
+
+func (r *metricsRedis) PruneSlotsBefore(ctx context.Context, slot uint64, archive io.Writer) (numDeleted int, err error) {
+	defer r.observe("pruneSlotsBefore", time.Now(), &err)
+	return r.relayRedis.PruneSlotsBefore(ctx, slot, archive)
+}
+
+func (r *metricsRedis) AddBidHistoryEntries(ctx context.Context, entries []*common.BidHistoryEntry, maxEntries int, retention time.Duration) (err error) {
+	defer r.observe("addBidHistoryEntries", time.Now(), &err)
+	return r.relayRedis.AddBidHistoryEntries(ctx, entries, maxEntries, retention)
+}
+
+func (r *metricsRedis) GetBidHistory(slot uint64) (entries []*common.BidHistoryEntry, err error) {
+	defer r.observe("getBidHistory", time.Now(), &err)
+	return r.relayRedis.GetBidHistory(slot)
+}
+
+func (r *metricsRedis) RecordBuilderOutcome(ctx context.Context, builderPubkey string, success bool) (err error) {
+	defer r.observe("recordBuilderOutcome", time.Now(), &err)
+	return r.relayRedis.RecordBuilderOutcome(ctx, builderPubkey, success)
+}
+
+// metricsDatastore times the operations which combine the backends (i.e. getPayload falls back from Redis to memcached
+// and the database)
+type metricsDatastore struct {
+	relayDatastore
+	metrics *relayMetrics
+}
+
+func (ds *metricsDatastore) observe(operation string, start time.Time, err *error) {
+	ds.metrics.observeDatastoreOperation("datastore", operation, start, *err)
+}
+
+func (ds *metricsDatastore) SaveValidatorRegistration(entry types.SignedValidatorRegistration) (err error) {
+	defer ds.observe("saveValidatorRegistration", time.Now(), &err)
+	return ds.relayDatastore.SaveValidatorRegistration(entry)
+}
+
+func (ds *metricsDatastore) GetGetPayloadResponse(slot uint64, proposerPubkey, blockHash string) (resp *common.VersionedExecutionPayload, err error) {
+	defer ds.observe("getGetPayloadResponse", time.Now(), &err)
+	return ds.relayDatastore.GetGetPayloadResponse(slot, proposerPubkey, blockHash)
+}
+
+type metricsDB struct {
+	database.IDatabaseService
+	metrics *relayMetrics
+}
+
+// observe doesn't count sql.ErrNoRows as error, it's the regular result of a lookup for a missing entry
+func (db *metricsDB) observe(operation string, start time.Time, err *error) {
+	if errors.Is(*err, sql.ErrNoRows) {
+		db.metrics.observeDatastoreOperation("db", operation, start, nil)
+		return
+	}
+	db.metrics.observeDatastoreOperation("db", operation, start, *err)
+}
+
+func (db *metricsDB) GetValidatorRegistration(pubkey string) (entry *database.ValidatorRegistrationEntry, err error) {
+	defer db.observe("getValidatorRegistration", time.Now(), &err)
+	return db.IDatabaseService.GetValidatorRegistration(pubkey)
+}
+
+func (db *metricsDB) GetValidatorRegistrationCountsByDay() (entries []*database.ValidatorRegistrationCountEntry, err error) {
+	defer db.observe("getValidatorRegistrationCountsByDay", time.Now(), &err)
+	return db.IDatabaseService.GetValidatorRegistrationCountsByDay()
+}
+
+func (db *metricsDB) InsertBidDecision(entry *database.BidDecisionEntry) (err error) {
+	defer db.observe("insertBidDecision", time.Now(), &err)
+	return db.IDatabaseService.InsertBidDecision(entry)
+}
+
+func (db *metricsDB) SaveBuilderBlockSubmission(payload *common.BuilderSubmitBlockRequest, requestError, validationError error, receivedAt, eligibleAt time.Time, wasSimulated, saveExecPayload bool, profile common.Profile, optimisticSubmission bool) (entry *database.BuilderBlockSubmissionEntry, err error) {
+	defer db.observe("saveBuilderBlockSubmission", time.Now(), &err)
+	return db.IDatabaseService.SaveBuilderBlockSubmission(payload, requestError, validationError, receivedAt, eligibleAt, wasSimulated, saveExecPayload, profile, optimisticSubmission)
+}
+
+func (db *metricsDB) GetBlockSubmissionEntry(slot uint64, proposerPubkey, blockHash string) (entry *database.BuilderBlockSubmissionEntry, err error) {
+	defer db.observe("getBlockSubmissionEntry", time.Now(), &err)
+	return db.IDatabaseService.GetBlockSubmissionEntry(slot, proposerPubkey, blockHash)
+}
+
+func (db *metricsDB) GetBuilderSubmissions(filters database.GetBuilderSubmissionsFilters) (entries []*database.BuilderBlockSubmissionEntry, err error) {
+	defer db.observe("getBuilderSubmissions", time.Now(), &err)
+	return db.IDatabaseService.GetBuilderSubmissions(filters)
+}
+
+func (db *metricsDB) SaveDeliveredPayload(bidTrace *common.BidTraceV2, signedBlindedBeaconBlock *common.SignedBlindedBeaconBlock, signedAt time.Time, publishMs uint64) (err error) {
+	defer db.observe("saveDeliveredPayload", time.Now(), &err)
+	return db.IDatabaseService.SaveDeliveredPayload(bidTrace, signedBlindedBeaconBlock, signedAt, publishMs)
+}
+
+func (db *metricsDB) GetRecentDeliveredPayloads(filters database.GetPayloadsFilters) (entries []*database.DeliveredPayloadEntry, err error) {
+	defer db.observe("getRecentDeliveredPayloads", time.Now(), &err)
+	return db.IDatabaseService.GetRecentDeliveredPayloads(filters)
+}
+
+func (db *metricsDB) GetBlockBuilders() (entries []*database.BlockBuilderEntry, err error) {
+	defer db.observe("getBlockBuilders", time.Now(), &err)
+	return db.IDatabaseService.GetBlockBuilders()
+}
+
+func (db *metricsDB) UpsertBlockBuilderEntryAfterSubmission(lastSubmission *database.BuilderBlockSubmissionEntry, isError bool) (err error) {
+	defer db.observe("upsertBlockBuilderEntryAfterSubmission", time.Now(), &err)
+	return db.IDatabaseService.UpsertBlockBuilderEntryAfterSubmission(lastSubmission, isError)
+}
+
+func (db *metricsDB) IncBlockBuilderStatsAfterGetHeader(builderPubkey string) (err error) {
+	defer db.observe("incBlockBuilderStatsAfterGetHeader", time.Now(), &err)
+	return db.IDatabaseService.IncBlockBuilderStatsAfterGetHeader(builderPubkey)
+}
+
+func (db *metricsDB) IncBlockBuilderStatsAfterGetPayload(builderPubkey string) (err error) {
+	defer db.observe("incBlockBuilderStatsAfterGetPayload", time.Now(), &err)
+	return db.IDatabaseService.IncBlockBuilderStatsAfterGetPayload(builderPubkey)
+}
+
+func (db *metricsDB) InsertTooLateGetPayload(slot uint64, proposerPubkey, blockHash string, slotStart, requestTime, decodeTime, msIntoSlot uint64) (err error) {
+	defer db.observe("insertTooLateGetPayload", time.Now(), &err)
+	return db.IDatabaseService.InsertTooLateGetPayload(slot, proposerPubkey, blockHash, slotStart, requestTime, decodeTime, msIntoSlot)
+}
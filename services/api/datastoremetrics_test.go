@@ -0,0 +1,45 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+// lookupErrorDB fails validator registration lookups with the given error
+type lookupErrorDB struct {
+	database.IDatabaseService
+	err error
+}
+
+func (db *lookupErrorDB) GetValidatorRegistration(pubkey string) (*database.ValidatorRegistrationEntry, error) {
+	return nil, db.err
+}
+
+func TestDatastoreMetrics(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.metrics = newRelayMetrics()
+	backend.relay.redis = &metricsRedis{relayRedis: backend.redis, metrics: backend.relay.metrics}
+
+	_, err := backend.relay.redis.GetBestBid(1, "0x01", "0x02")
+	require.NoError(t, err)
+
+	// Missing entries aren't errors, failed lookups are
+	db := &metricsDB{IDatabaseService: &lookupErrorDB{err: sql.ErrNoRows}, metrics: backend.relay.metrics}
+	_, err = db.GetValidatorRegistration("0x01")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	db.IDatabaseService = &lookupErrorDB{err: errors.New("connection refused")} //nolint:goerr113
+	_, err = db.GetValidatorRegistration("0x01")
+	require.Error(t, err)
+
+	rr := backend.request(http.MethodGet, pathMetrics, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `relay_datastore_operation_duration_seconds_count{backend="redis",operation="getBestBid"} 1`)
+	require.Contains(t, rr.Body.String(), `relay_datastore_operation_duration_seconds_count{backend="db",operation="getValidatorRegistration"} 2`)
+	require.Contains(t, rr.Body.String(), `relay_datastore_operation_errors_total{backend="db",operation="getValidatorRegistration"} 1`)
+	require.NotContains(t, rr.Body.String(), `relay_datastore_operation_errors_total{backend="redis"`)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+	uberatomic "go.uber.org/atomic"
+)
+
+// beaconDutiesCache holds the proposer duties of the current and the next epoch, as reported by the beacon node, for
+// all validators (the proposer duties from Redis only contain registered validators). The duties are fetched on epoch
+// boundaries, and again when a head event shows they changed because of a reorg.
+type beaconDutiesCache struct {
+	log          *logrus.Entry
+	beaconClient beaconclient.IMultiBeaconClient
+	isUpdating   uberatomic.Bool
+
+	mu            sync.RWMutex
+	epoch         uint64 // epoch of the current duties, the next epoch's duties are cached as well
+	dependentRoot string // dependent root of the current epoch's duties
+	headSlot      uint64
+	headBlock     string
+	duties        map[uint64]beaconclient.ProposerDutiesResponseData
+}
+
+func newBeaconDutiesCache(log *logrus.Entry, beaconClient beaconclient.IMultiBeaconClient) *beaconDutiesCache {
+	return &beaconDutiesCache{
+		log:          log.WithField("component", "beaconDutiesCache"),
+		beaconClient: beaconClient,
+		duties:       make(map[uint64]beaconclient.ProposerDutiesResponseData),
+	}
+}
+
+// update fetches the duties of the epoch and the next one. Only one update runs at a time, others are skipped.
+func (c *beaconDutiesCache) update(epoch uint64) {
+	if c.isUpdating.Swap(true) {
+		return
+	}
+	defer c.isUpdating.Store(false)
+
+	log := c.log.WithField("epoch", epoch)
+	current, err := c.beaconClient.GetProposerDuties(epoch)
+	if err != nil || current == nil {
+		log.WithError(err).Error("failed to get proposer duties")
+		return
+	}
+	next, err := c.beaconClient.GetProposerDuties(epoch + 1)
+	if err != nil || next == nil {
+		log.WithError(err).Error("failed to get proposer duties of the next epoch")
+		return
+	}
+
+	duties := make(map[uint64]beaconclient.ProposerDutiesResponseData, len(current.Data)+len(next.Data))
+	for _, duty := range append(current.Data, next.Data...) {
+		duties[duty.Slot] = duty
+	}
+
+	c.mu.Lock()
+	c.epoch = epoch
+	c.dependentRoot = current.DependentRoot
+	c.duties = duties
+	c.mu.Unlock()
+	log.WithField("dependentRoot", current.DependentRoot).Debug("updated beacon proposer duties")
+}
+
+// processHeadEvent refreshes the duties on a new epoch, and when the head changed unexpectedly: another block for the
+// same or an earlier slot, or a different dependent root for the current epoch's duties.
+func (c *beaconDutiesCache) processHeadEvent(event beaconclient.HeadEventData) {
+	epoch := event.Slot / common.SlotsPerEpoch
+
+	c.mu.Lock()
+	isNewEpoch := epoch > c.epoch || len(c.duties) == 0
+	isReorg := (event.Slot <= c.headSlot && event.Block != c.headBlock) ||
+		(epoch == c.epoch && event.CurrentDutyDependentRoot != "" && c.dependentRoot != "" && event.CurrentDutyDependentRoot != c.dependentRoot)
+	if event.Slot >= c.headSlot || isReorg {
+		c.headSlot = event.Slot
+		c.headBlock = event.Block
+	}
+	c.mu.Unlock()
+
+	if isReorg {
+		c.log.WithFields(logrus.Fields{
+			"slot":          event.Slot,
+			"block":         event.Block,
+			"dependentRoot": event.CurrentDutyDependentRoot,
+		}).Warn("head changed unexpectedly, updating proposer duties")
+	}
+	if isNewEpoch || isReorg {
+		c.update(epoch)
+	}
+}
+
+func (c *beaconDutiesCache) getProposerForSlot(slot uint64) (beaconclient.ProposerDutiesResponseData, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	duty, found := c.duties[slot]
+	return duty, found
+}
+
+// GetProposerForSlot returns the proposer duty of a slot in the current or the next epoch, as reported by the beacon node
+func (api *RelayAPI) GetProposerForSlot(slot uint64) (beaconclient.ProposerDutiesResponseData, bool) {
+	if api.beaconDuties == nil {
+		return beaconclient.ProposerDutiesResponseData{}, false
+	}
+	return api.beaconDuties.getProposerForSlot(slot)
+}
+
+// isProposerForSlot returns true if the beacon node reports the pubkey as the proposer of the slot. Without a known
+// duty for the slot (i.e. not yet loaded), nobody is the proposer.
+func (api *RelayAPI) isProposerForSlot(slot uint64, pubkey string) bool {
+	duty, found := api.GetProposerForSlot(slot)
+	return found && strings.EqualFold(duty.Pubkey, pubkey)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+// dutiesBeaconClient assigns every slot to the validator with index slot+offset, and counts the duties requests
+type dutiesBeaconClient struct {
+	beaconclient.IMultiBeaconClient
+	offset        uint64
+	dependentRoot string
+	numCalls      int
+}
+
+func (c *dutiesBeaconClient) GetProposerDuties(epoch uint64) (*beaconclient.ProposerDutiesResponse, error) {
+	c.numCalls++
+	resp := &beaconclient.ProposerDutiesResponse{DependentRoot: c.dependentRoot} //nolint:exhaustruct
+	for slot := epoch * common.SlotsPerEpoch; slot < (epoch+1)*common.SlotsPerEpoch; slot++ {
+		resp.Data = append(resp.Data, beaconclient.ProposerDutiesResponseData{Slot: slot, ValidatorIndex: slot + c.offset}) //nolint:exhaustruct
+	}
+	return resp, nil
+}
+
+func TestBeaconDutiesCache(t *testing.T) {
+	client := &dutiesBeaconClient{dependentRoot: "0x01"} //nolint:exhaustruct
+	cache := newBeaconDutiesCache(common.TestLog, client)
+	epochStart := 10 * common.SlotsPerEpoch
+
+	// The first head event loads the current and the next epoch
+	cache.processHeadEvent(beaconclient.HeadEventData{Slot: epochStart, Block: "0xa", CurrentDutyDependentRoot: "0x01"}) //nolint:exhaustruct
+	require.Equal(t, 2, client.numCalls)
+	duty, found := cache.getProposerForSlot(epochStart + common.SlotsPerEpoch + 1)
+	require.True(t, found)
+	require.Equal(t, epochStart+common.SlotsPerEpoch+1, duty.ValidatorIndex)
+	_, found = cache.getProposerForSlot(epochStart + 2*common.SlotsPerEpoch)
+	require.False(t, found)
+
+	// No refresh within the epoch
+	cache.processHeadEvent(beaconclient.HeadEventData{Slot: epochStart + 1, Block: "0xb", CurrentDutyDependentRoot: "0x01"}) //nolint:exhaustruct
+	require.Equal(t, 2, client.numCalls)
+
+	// Another block for the same slot is a reorg
+	client.offset = 1
+	cache.processHeadEvent(beaconclient.HeadEventData{Slot: epochStart + 1, Block: "0xc", CurrentDutyDependentRoot: "0x01"}) //nolint:exhaustruct
+	require.Equal(t, 4, client.numCalls)
+	duty, _ = cache.getProposerForSlot(epochStart + 1)
+	require.Equal(t, epochStart+2, duty.ValidatorIndex)
+
+	// So is a new dependent root for the current epoch's duties
+	client.dependentRoot = "0x02"
+	cache.processHeadEvent(beaconclient.HeadEventData{Slot: epochStart + 2, Block: "0xd", CurrentDutyDependentRoot: "0x02"}) //nolint:exhaustruct
+	require.Equal(t, 6, client.numCalls)
+
+	// The next epoch
+	cache.processHeadEvent(beaconclient.HeadEventData{Slot: epochStart + common.SlotsPerEpoch, Block: "0xe", CurrentDutyDependentRoot: "0x02"}) //nolint:exhaustruct
+	require.Equal(t, 8, client.numCalls)
+	_, found = cache.getProposerForSlot(epochStart + 2*common.SlotsPerEpoch)
+	require.True(t, found)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ErrorCode is the machine-readable part of an error response. Codes are stable, so that clients can act on them,
+// while messages are meant for humans and may change.
+type ErrorCode string
+
+const (
+	// Generic codes, used where a handler doesn't specify one (derived from the HTTP status)
+	ErrorCodeBadRequest         ErrorCode = "BAD_REQUEST"
+	ErrorCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrorCodeNotFound           ErrorCode = "NOT_FOUND"
+	ErrorCodeRequestTooLarge    ErrorCode = "REQUEST_TOO_LARGE"
+	ErrorCodeTooManyRequests    ErrorCode = "TOO_MANY_REQUESTS"
+	ErrorCodeInternal           ErrorCode = "INTERNAL_ERROR"
+	ErrorCodeBadGateway         ErrorCode = "BAD_GATEWAY"
+	ErrorCodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrorCodeTimeout            ErrorCode = "TIMEOUT"
+	ErrorCodeUnknown            ErrorCode = "UNKNOWN"
+
+	ErrorCodeInvalidRequest           ErrorCode = "INVALID_REQUEST"
+	ErrorCodeInvalidSlot              ErrorCode = "INVALID_SLOT"
+	ErrorCodeInvalidPubkey            ErrorCode = "INVALID_PUBKEY"
+	ErrorCodeInvalidHash              ErrorCode = "INVALID_HASH"
+	ErrorCodeInvalidSignature         ErrorCode = "INVALID_SIGNATURE"
+	ErrorCodeInvalidTimestamp         ErrorCode = "INVALID_TIMESTAMP"
+	ErrorCodeInvalidGasLimit          ErrorCode = "INVALID_GAS_LIMIT"
+	ErrorCodeInvalidPayload           ErrorCode = "INVALID_PAYLOAD"
+	ErrorCodeInvalidBlock             ErrorCode = "INVALID_BLOCK"
+	ErrorCodeWrongFork                ErrorCode = "WRONG_FORK"
+	ErrorCodeUnknownValidator         ErrorCode = "UNKNOWN_VALIDATOR"
+	ErrorCodeValidatorSlashedOrExited ErrorCode = "VALIDATOR_SLASHED_OR_EXITED"
+	ErrorCodeProposerMismatch         ErrorCode = "PROPOSER_MISMATCH"
+	ErrorCodeNoProposerDuty           ErrorCode = "NO_PROPOSER_DUTY"
+	ErrorCodeFeeRecipientMismatch     ErrorCode = "FEE_RECIPIENT_MISMATCH"
+	ErrorCodePayloadAttributesUnknown ErrorCode = "PAYLOAD_ATTRIBUTES_UNKNOWN"
+	ErrorCodePayloadNotFound          ErrorCode = "PAYLOAD_NOT_FOUND"
+	ErrorCodeBidNotFound              ErrorCode = "BID_NOT_FOUND"
+	ErrorCodePayloadAlreadyDelivered  ErrorCode = "PAYLOAD_ALREADY_DELIVERED"
+	ErrorCodeRequestTooLate           ErrorCode = "REQUEST_TOO_LATE"
+	ErrorCodeRequestTooEarly          ErrorCode = "REQUEST_TOO_EARLY"
+	ErrorCodePublishFailed            ErrorCode = "PUBLISH_FAILED"
+	ErrorCodePeerRelayFailed          ErrorCode = "PEER_RELAY_FAILED"
+	ErrorCodeSimulationFailed         ErrorCode = "SIMULATION_FAILED"
+	ErrorCodeSimulationTimeout        ErrorCode = "SIMULATION_TIMEOUT"
+	ErrorCodeOutdatedSubmission       ErrorCode = "OUTDATED_SUBMISSION"
+	ErrorCodeCancellationsDisabled    ErrorCode = "CANCELLATIONS_DISABLED"
+	ErrorCodeMaintenance              ErrorCode = "MAINTENANCE"
+	ErrorCodeBuilderNotAllowed        ErrorCode = "BUILDER_NOT_ALLOWED"
+)
+
+// APIError is an error response of the relay API: the HTTP status, a machine-readable code, and a message
+type APIError struct {
+	Status  int
+	Code    ErrorCode
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Response is the JSON envelope sent to the client. `code` stays the HTTP status as in the builder-specs, the
+// machine-readable code is in `error_code`.
+func (e *APIError) Response() HTTPErrorResp {
+	return HTTPErrorResp{
+		Code:      e.Status,
+		ErrorCode: e.Code,
+		Message:   e.Message,
+	}
+}
+
+func errorCodeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeBadRequest
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorCodeUnauthorized
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusRequestEntityTooLarge:
+		return ErrorCodeRequestTooLarge
+	case http.StatusTooManyRequests:
+		return ErrorCodeTooManyRequests
+	case http.StatusInternalServerError:
+		return ErrorCodeInternal
+	case http.StatusBadGateway:
+		return ErrorCodeBadGateway
+	case http.StatusServiceUnavailable:
+		return ErrorCodeServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrorCodeTimeout
+	}
+	return ErrorCodeUnknown
+}
+
+func (api *RelayAPI) RespondAPIError(w http.ResponseWriter, err *APIError) {
+	api.Respond(w, err.Status, err.Response())
+}
+
+// RespondErrorCode responds with an error with a specific machine-readable code
+func (api *RelayAPI) RespondErrorCode(w http.ResponseWriter, status int, code ErrorCode, message string) {
+	api.RespondAPIError(w, &APIError{Status: status, Code: code, Message: message})
+}
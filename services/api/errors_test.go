@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodeForStatus(t *testing.T) {
+	require.Equal(t, ErrorCodeBadRequest, errorCodeForStatus(http.StatusBadRequest))
+	require.Equal(t, ErrorCodeTooManyRequests, errorCodeForStatus(http.StatusTooManyRequests))
+	require.Equal(t, ErrorCodeRequestTooLarge, errorCodeForStatus(http.StatusRequestEntityTooLarge))
+	require.Equal(t, ErrorCodeInternal, errorCodeForStatus(http.StatusInternalServerError))
+	require.Equal(t, ErrorCodeTimeout, errorCodeForStatus(http.StatusGatewayTimeout))
+	require.Equal(t, ErrorCodeUnknown, errorCodeForStatus(http.StatusTeapot))
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	registrations, err := json.Marshal([]types.SignedValidatorRegistration{common.ValidPayloadRegisterValidator})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		payload      []byte
+		expectStatus int
+		expectCode   ErrorCode
+	}{
+		{"registerValidator empty request", http.MethodPost, pathRegisterValidator, nil, http.StatusBadRequest, ErrorCodeInvalidRequest},
+		{"registerValidator timestamp before genesis", http.MethodPost, pathRegisterValidator, registrations, http.StatusBadRequest, ErrorCodeInvalidTimestamp},
+		{"getHeader invalid pubkey", http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/10/%s/0x1234", parentHash), nil, http.StatusBadRequest, ErrorCodeInvalidPubkey},
+		{"getHeader invalid hash", http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/10/0x1234/%s", proposerPubkey), nil, http.StatusBadRequest, ErrorCodeInvalidHash},
+		{"getHeader slot too old", http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/1/%s/%s", parentHash, proposerPubkey), nil, http.StatusBadRequest, ErrorCodeInvalidSlot},
+		{"getPayload invalid request", http.MethodPost, pathGetPayload, []byte("{"), http.StatusBadRequest, ErrorCodeInvalidRequest},
+		{"submitBlock invalid request", http.MethodPost, pathSubmitNewBlock, []byte("{"), http.StatusBadRequest, ErrorCodeInvalidRequest},
+		{"submitBlock cancellations disabled", http.MethodPost, pathSubmitNewBlock + "?cancellations=1", []byte("{}"), http.StatusBadRequest, ErrorCodeCancellationsDisabled},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := newTestBackend(t, 1)
+			backend.relay.headSlot.Store(10)
+			backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+				Data: beaconclient.GetGenesisResponseData{
+					GenesisTime: uint64(time.Now().UTC().Unix()),
+				},
+			}
+
+			rr := backend.requestBytes(tc.method, tc.path, tc.payload, nil)
+			require.Equal(t, tc.expectStatus, rr.Code)
+
+			resp := new(HTTPErrorResp)
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+			require.Equal(t, tc.expectStatus, resp.Code)
+			require.Equal(t, tc.expectCode, resp.ErrorCode)
+			require.NotEmpty(t, resp.Message)
+		})
+	}
+}
@@ -0,0 +1,437 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	builderCapella "github.com/attestantio/go-builder-client/api/capella"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	consensusspec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/go-utils/cli"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/go-redis/redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidPeerRelayURL         = errors.New("invalid peer relay URL, expected https://0xPUBKEY@host")
+	ErrPeerRelaysWithoutBuilderAPI = errors.New("peer relays require the proposer and block builder APIs (to sign bids and know the upcoming slots)")
+	ErrPeerRelayInvalidBid         = errors.New("invalid bid from peer relay")
+
+	peerRelayPollIntervalMs = cli.GetEnvInt("PEER_RELAY_POLL_INTERVAL_MS", 500)
+	peerRelayTimeoutMs      = cli.GetEnvInt("PEER_RELAY_TIMEOUT_MS", 1000)
+
+	// The peer responds to getPayload only after publishing the block and its GETPAYLOAD_RESPONSE_DELAY_MS, so the
+	// proxied request gets the whole getPayload budget
+	peerRelayGetPayloadTimeoutMs = cli.GetEnvInt("PEER_RELAY_GETPAYLOAD_TIMEOUT_MS", 4000)
+)
+
+// peerRelay is another relay whose bids are offered to our proposers. Its pubkey is needed to verify the bids,
+// which we then sign again with our own key.
+type peerRelay struct {
+	url    *url.URL
+	pubkey boostTypes.PublicKey
+}
+
+// newPeerRelay parses a relay URL in the mev-boost format, with the relay pubkey as user (https://0xPUBKEY@host)
+func newPeerRelay(relayURL string) (*peerRelay, error) {
+	if !strings.HasPrefix(relayURL, "http") {
+		relayURL = "https://" + relayURL
+	}
+	u, err := url.Parse(relayURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPeerRelayURL, err.Error())
+	}
+	if u.User == nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPeerRelayURL, relayURL)
+	}
+
+	pubkey, err := boostTypes.HexToPubkey(u.User.Username())
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid pubkey: %s", ErrInvalidPeerRelayURL, err.Error())
+	}
+	u.User = nil
+	return &peerRelay{url: u, pubkey: pubkey}, nil
+}
+
+func (p *peerRelay) String() string {
+	return p.url.String()
+}
+
+// federatedBid is a bid of a peer relay, signed again by us. The payload stays with the peer, so getPayload for it
+// is proxied to the peer, which also publishes the block.
+type federatedBid struct {
+	slot      uint64
+	peer      *peerRelay
+	bid       *common.GetHeaderResponse
+	value     *big.Int
+	blockHash string
+
+	// proposerFeeRecipient is the proposer_fee_recipient of the bid trace of the peer, with --enforce-fee-recipient
+	proposerFeeRecipient string
+}
+
+// federatedBids keeps the best bid of the peer relays per slot, parent hash and proposer, and remembers which
+// peer each served block hash belongs to. Entries of past slots are removed when the head slot advances.
+type federatedBids struct {
+	mu          sync.Mutex
+	bids        map[string]*federatedBid // key: slot_parentHash_proposerPubkey
+	byBlockHash map[string]*federatedBid // key: slot_blockHash
+}
+
+func newFederatedBids() *federatedBids {
+	return &federatedBids{
+		bids:        make(map[string]*federatedBid),
+		byBlockHash: make(map[string]*federatedBid),
+	}
+}
+
+func federatedBidKey(slot uint64, parentHash, proposerPubkey string) string {
+	return fmt.Sprintf("%d_%s_%s", slot, strings.ToLower(parentHash), strings.ToLower(proposerPubkey))
+}
+
+func federatedBlockHashKey(slot uint64, blockHash string) string {
+	return fmt.Sprintf("%d_%s", slot, strings.ToLower(blockHash))
+}
+
+// set stores the bid if it is higher than the current best bid of the peers
+func (f *federatedBids) set(parentHash, proposerPubkey string, bid *federatedBid) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.byBlockHash[federatedBlockHashKey(bid.slot, bid.blockHash)] = bid
+	key := federatedBidKey(bid.slot, parentHash, proposerPubkey)
+	if current, found := f.bids[key]; found && current.value.Cmp(bid.value) >= 0 {
+		return
+	}
+	f.bids[key] = bid
+}
+
+func (f *federatedBids) get(slot uint64, parentHash, proposerPubkey string) (*federatedBid, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bid, found := f.bids[federatedBidKey(slot, parentHash, proposerPubkey)]
+	return bid, found
+}
+
+func (f *federatedBids) getByBlockHash(slot uint64, blockHash string) (*federatedBid, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bid, found := f.byBlockHash[federatedBlockHashKey(slot, blockHash)]
+	return bid, found
+}
+
+// pruneBefore removes all bids for slots before the given one
+func (f *federatedBids) pruneBefore(slot uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, bid := range f.bids {
+		if bid.slot < slot {
+			delete(f.bids, key)
+		}
+	}
+	for key, bid := range f.byBlockHash {
+		if bid.slot < slot {
+			delete(f.byBlockHash, key)
+		}
+	}
+}
+
+// startPeerRelayPolling regularly fetches the bids of the peer relays for the next slot
+func (api *RelayAPI) startPeerRelayPolling() {
+	api.log.Infof("polling %d peer relays for bids every %d ms", len(api.peerRelays), peerRelayPollIntervalMs)
+	ticker := time.NewTicker(time.Duration(peerRelayPollIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+	for range ticker.C {
+		api.pollPeerRelays(api.headSlot.Load() + 1)
+	}
+}
+
+// pollPeerRelays fetches the bids of all peer relays for the given slot, for each known parent hash. The proposer
+// comes from the proposer duties, and the parent hashes from the payload attributes.
+func (api *RelayAPI) pollPeerRelays(slot uint64) {
+	api.proposerDutiesLock.RLock()
+	duty := api.proposerDutiesMap[slot]
+	api.proposerDutiesLock.RUnlock()
+	if duty == nil {
+		return
+	}
+	proposerPubkey := duty.Entry.Message.Pubkey.String()
+
+	// No more bids once a payload was delivered for the slot
+	lastSlotDelivered, err := api.redis.GetLastSlotDelivered(context.Background(), api.redis.NewTxPipeline())
+	if err != nil && !errors.Is(err, redis.Nil) {
+		api.log.WithError(err).Warn("failed to get last slot delivered")
+	} else if slot <= lastSlotDelivered {
+		return
+	}
+
+	parentHashes := []string{}
+	api.payloadAttributesLock.RLock()
+	for _, attrs := range api.payloadAttributes {
+		if attrs.slot == slot {
+			parentHashes = append(parentHashes, attrs.parentHash)
+		}
+	}
+	api.payloadAttributesLock.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, parentHash := range parentHashes {
+		for _, peer := range api.peerRelays {
+			wg.Add(1)
+			go func(peer *peerRelay, parentHash string) {
+				defer wg.Done()
+				log := api.log.WithFields(logrus.Fields{
+					"peerRelay":  peer.String(),
+					"slot":       slot,
+					"parentHash": parentHash,
+					"pubkey":     proposerPubkey,
+				})
+				bid, err := api.fetchPeerBid(peer, slot, parentHash, proposerPubkey)
+				if err != nil {
+					log.WithError(err).Warn("failed to get bid from peer relay")
+					return
+				} else if bid == nil {
+					return
+				}
+				log.WithFields(logrus.Fields{
+					"value":     bid.value.String(),
+					"blockHash": bid.blockHash,
+				}).Debug("received bid from peer relay")
+				api.federatedBids.set(parentHash, proposerPubkey, bid)
+			}(peer, parentHash)
+		}
+	}
+	wg.Wait()
+}
+
+// fetchPeerBid calls getHeader on the peer relay, verifies the bid against the peer pubkey and signs it with our
+// key. It returns nil if the peer has no bid.
+func (api *RelayAPI) fetchPeerBid(peer *peerRelay, slot uint64, parentHash, proposerPubkey string) (*federatedBid, error) {
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey)
+	resp, err := api.peerRelayClient.Get(peer.url.String() + path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil //nolint:nilnil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: HTTP %d: %s", ErrPeerRelayInvalidBid, resp.StatusCode, string(body))
+	}
+
+	peerBid := new(common.GetHeaderResponse)
+	if err := json.Unmarshal(body, peerBid); err != nil {
+		return nil, err
+	}
+	bid, err := api.resignPeerBid(peer, parentHash, peerBid)
+	if err != nil {
+		return nil, err
+	}
+
+	fedBid := &federatedBid{
+		slot:      slot,
+		peer:      peer,
+		bid:       bid,
+		value:     bid.Value(),
+		blockHash: bid.BlockHash().String(),
+	}
+
+	// The fee recipient of the proposer is only in the bid trace, which the peer has
+	if api.opts.EnforceFeeRecipient {
+		fedBid.proposerFeeRecipient, err = api.fetchPeerBidFeeRecipient(peer, slot, fedBid.blockHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fedBid, nil
+}
+
+// resignPeerBid verifies the Capella or Deneb bid of the peer relay, and returns it signed with our key, as the
+// proposer only accepts bids signed by us
+func (api *RelayAPI) resignPeerBid(peer *peerRelay, parentHash string, peerBid *common.GetHeaderResponse) (*common.GetHeaderResponse, error) {
+	var msg boostTypes.HashTreeRoot
+	var msgParentHash phase0.Hash32
+	var msgPubkey phase0.BLSPubKey
+	var signature phase0.BLSSignature
+	switch {
+	case peerBid.Empty():
+		return nil, fmt.Errorf("%w: empty bid", ErrPeerRelayInvalidBid)
+	case peerBid.Deneb != nil:
+		denebMsg := peerBid.Deneb.Message
+		if denebMsg.Header == nil || denebMsg.Value == nil {
+			return nil, fmt.Errorf("%w: missing header or value", ErrPeerRelayInvalidBid)
+		}
+		msg, msgParentHash, msgPubkey, signature = denebMsg, denebMsg.Header.ParentHash, denebMsg.Pubkey, peerBid.Deneb.Signature
+	case peerBid.Capella != nil:
+		capellaMsg := peerBid.Capella.Capella.Message
+		if capellaMsg.Header == nil || capellaMsg.Value == nil {
+			return nil, fmt.Errorf("%w: missing header or value", ErrPeerRelayInvalidBid)
+		}
+		msg, msgParentHash, msgPubkey, signature = capellaMsg, capellaMsg.Header.ParentHash, capellaMsg.Pubkey, peerBid.Capella.Capella.Signature
+	default:
+		return nil, fmt.Errorf("%w: not a capella or deneb bid", ErrPeerRelayInvalidBid)
+	}
+
+	if msgParentHash.String() != strings.ToLower(parentHash) {
+		return nil, fmt.Errorf("%w: parent hash mismatch: %s", ErrPeerRelayInvalidBid, msgParentHash.String())
+	}
+	if msgPubkey != phase0.BLSPubKey(peer.pubkey) {
+		return nil, fmt.Errorf("%w: signed by %s instead of the peer pubkey", ErrPeerRelayInvalidBid, msgPubkey.String())
+	}
+	ok, err := boostTypes.VerifySignature(msg, api.opts.EthNetDetails.DomainBuilder, peer.pubkey[:], signature[:])
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("%w: invalid signature", ErrPeerRelayInvalidBid)
+	}
+
+	if peerBid.Deneb != nil {
+		builderBid := common.DenebBuilderBid{
+			Header:             peerBid.Deneb.Message.Header,
+			BlobKzgCommitments: peerBid.Deneb.Message.BlobKzgCommitments,
+			Value:              peerBid.Deneb.Message.Value,
+			Pubkey:             phase0.BLSPubKey(*api.publicKey),
+		}
+		sig, err := boostTypes.SignMessage(&builderBid, api.opts.EthNetDetails.DomainBuilder, api.blsSk)
+		if err != nil {
+			return nil, err
+		}
+		return &common.GetHeaderResponse{
+			Deneb: &common.DenebSignedBuilderBid{
+				Message:   &builderBid,
+				Signature: phase0.BLSSignature(sig),
+			},
+			Capella:   nil,
+			Bellatrix: nil,
+		}, nil
+	}
+
+	builderBid := builderCapella.BuilderBid{
+		Header: peerBid.Capella.Capella.Message.Header,
+		Value:  peerBid.Capella.Capella.Message.Value,
+		Pubkey: phase0.BLSPubKey(*api.publicKey),
+	}
+	sig, err := boostTypes.SignMessage(&builderBid, api.opts.EthNetDetails.DomainBuilder, api.blsSk)
+	if err != nil {
+		return nil, err
+	}
+	return &common.GetHeaderResponse{
+		Capella: &builderSpec.VersionedSignedBuilderBid{
+			Version: consensusspec.DataVersionCapella,
+			Capella: &builderCapella.SignedBuilderBid{
+				Message:   &builderBid,
+				Signature: phase0.BLSSignature(sig),
+			},
+			Bellatrix: nil,
+		},
+		Deneb:     nil,
+		Bellatrix: nil,
+	}, nil
+}
+
+// fetchPeerBidFeeRecipient returns the proposer_fee_recipient of the bid trace of the block on the data API of the
+// peer relay
+func (api *RelayAPI) fetchPeerBidFeeRecipient(peer *peerRelay, slot uint64, blockHash string) (string, error) {
+	path := fmt.Sprintf("%s?slot=%d&block_hash=%s", pathDataBuilderBidsReceived, slot, blockHash)
+	resp, err := api.peerRelayClient.Get(peer.url.String() + path)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: bid trace: HTTP %d: %s", ErrPeerRelayInvalidBid, resp.StatusCode, string(body))
+	}
+
+	bidTraces := []common.BidTraceV2WithTimestampJSON{}
+	if err := json.Unmarshal(body, &bidTraces); err != nil {
+		return "", err
+	}
+	for _, bidTrace := range bidTraces {
+		if strings.EqualFold(bidTrace.BlockHash, blockHash) {
+			return bidTrace.ProposerFeeRecipient, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrMissingBidTrace, blockHash)
+}
+
+// checkPeerBidFeeRecipient verifies that the proposer_fee_recipient of the peer bid is the one of the proposer's
+// registration on file for the slot
+func (api *RelayAPI) checkPeerBidFeeRecipient(slot uint64, bid *federatedBid) (registered, actual string, match bool, err error) {
+	api.proposerDutiesLock.RLock()
+	slotDuty := api.proposerDutiesMap[slot]
+	api.proposerDutiesLock.RUnlock()
+	if slotDuty == nil || slotDuty.Entry == nil {
+		return "", "", true, nil
+	}
+	registered = slotDuty.Entry.Message.FeeRecipient.String()
+	if bid.proposerFeeRecipient == "" {
+		return registered, "", false, ErrMissingBidTrace
+	}
+	return registered, bid.proposerFeeRecipient, strings.EqualFold(registered, bid.proposerFeeRecipient), nil
+}
+
+// proxyGetPayload forwards the signed blinded block to the peer relay which has the payload. The peer publishes
+// the block, and we check the returned payload against the signed header before responding with it.
+func (api *RelayAPI) proxyGetPayload(peer *peerRelay, body []byte, payload *common.SignedBlindedBeaconBlock) (*common.VersionedExecutionPayload, error) {
+	resp, err := api.peerRelayGetPayloadClient.Post(peer.url.String()+pathGetPayload, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer relay getPayload failed: HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	getPayloadResp := new(common.VersionedExecutionPayload)
+	if err := json.Unmarshal(respBody, getPayloadResp); err != nil {
+		return nil, err
+	}
+	if err := EqExecutionPayloadToHeader(payload, getPayloadResp); err != nil {
+		return nil, err
+	}
+	return getPayloadResp, nil
+}
+
+// countBySlot returns the number of best peer bids of each slot
+func (f *federatedBids) countBySlot() map[uint64]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	counts := make(map[uint64]int)
+	for _, bid := range f.bids {
+		counts[bid.slot]++
+	}
+	return counts
+}
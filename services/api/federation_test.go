@@ -0,0 +1,274 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	builderApi "github.com/attestantio/go-builder-client/api"
+	builderCapella "github.com/attestantio/go-builder-client/api/capella"
+	builderSpec "github.com/attestantio/go-builder-client/spec"
+	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	consensusspec "github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPeerRelay(t *testing.T) {
+	pubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+
+	peer, err := newPeerRelay("https://" + pubkey + "@relay.example.com")
+	require.NoError(t, err)
+	require.Equal(t, "https://relay.example.com", peer.String())
+	require.Equal(t, pubkey, peer.pubkey.String())
+
+	peer, err = newPeerRelay(pubkey + "@relay.example.com")
+	require.NoError(t, err)
+	require.Equal(t, "https://relay.example.com", peer.String())
+
+	_, err = newPeerRelay("https://relay.example.com")
+	require.ErrorIs(t, err, ErrInvalidPeerRelayURL)
+
+	_, err = newPeerRelay("https://0x1234@relay.example.com")
+	require.ErrorIs(t, err, ErrInvalidPeerRelayURL)
+}
+
+func TestFederatedBids(t *testing.T) {
+	backend := newTestBackend(t, 1)
+
+	// Capella slot, at slot start
+	slot := uint64(64)
+	backend.relay.capellaEpoch = 1
+	backend.relay.genesisInfo.Data.GenesisTime = uint64(time.Now().Unix()) - slot*common.SecondsPerSlot
+	backend.relay.headSlot.Store(slot - 1)
+
+	// Known proposer, with the duty for the slot
+	proposerSk, proposerBlsPk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	proposerPubkey, err := types.BlsPublicKeyToPublicKey(proposerBlsPk)
+	require.NoError(t, err)
+	proposerIndex := uint64(1)
+	beaconInstance := beaconclient.NewMockBeaconInstance()
+	beaconInstance.AddValidator(beaconclient.ValidatorResponseEntry{
+		Index:     proposerIndex,
+		Validator: beaconclient.ValidatorResponseValidatorData{Pubkey: proposerPubkey.String()},
+	})
+	beaconClient := beaconclient.NewMultiBeaconClient(common.TestLog, []beaconclient.IBeaconInstance{beaconInstance})
+	backend.relay.beaconClient = beaconClient
+	backend.datastore.RefreshKnownValidators(beaconClient, slot)
+	backend.relay.proposerDutiesMap = map[uint64]*common.BuilderGetValidatorsResponseEntry{
+		slot: {
+			Slot:           slot,
+			ValidatorIndex: proposerIndex,
+			Entry: &types.SignedValidatorRegistration{
+				Message: &types.RegisterValidatorRequestMessage{Pubkey: proposerPubkey},
+			},
+		},
+	}
+
+	// Execution payload of the peer relay bid, on top of a known parent
+	execPayload := new(consensuscapella.ExecutionPayload)
+	common.LoadGzippedJSON(t, "../../testdata/executionPayloadCapella_Goerli.json.gz", execPayload)
+	header, err := common.CapellaPayloadToPayloadHeader(execPayload)
+	require.NoError(t, err)
+	parentHash := header.ParentHash.String()
+	backend.relay.payloadAttributes[parentHash] = payloadAttributesHelper{slot: slot, parentHash: parentHash} //nolint:exhaustruct
+
+	// Peer relay, serving a bid signed with its own key and the payload on getPayload
+	peerSk, peerBlsPk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	peerPubkey, err := types.BlsPublicKeyToPublicKey(peerBlsPk)
+	require.NoError(t, err)
+	numPeerGetPayloads := 0
+	peerGetPayloadDelay := time.Duration(0)
+	peerProposerFeeRecipient := "0x0000000000000000000000000000000000000000"
+	peerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey.String()):
+			bid := &builderCapella.BuilderBid{Header: header, Value: uint256.NewInt(100), Pubkey: phase0.BLSPubKey(peerPubkey)}
+			sig, err := types.SignMessage(bid, backend.relay.opts.EthNetDetails.DomainBuilder, peerSk)
+			require.NoError(t, err)
+			resp := &builderSpec.VersionedSignedBuilderBid{ //nolint:exhaustruct
+				Version: consensusspec.DataVersionCapella,
+				Capella: &builderCapella.SignedBuilderBid{Message: bid, Signature: phase0.BLSSignature(sig)},
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+		case req.Method == http.MethodGet && req.URL.Path == pathDataBuilderBidsReceived:
+			bidTrace := common.BidTraceV2WithTimestampJSON{} //nolint:exhaustruct
+			bidTrace.BlockHash = req.URL.Query().Get("block_hash")
+			bidTrace.ProposerFeeRecipient = peerProposerFeeRecipient
+			require.NoError(t, json.NewEncoder(w).Encode([]common.BidTraceV2WithTimestampJSON{bidTrace}))
+		case req.Method == http.MethodPost && req.URL.Path == pathGetPayload:
+			numPeerGetPayloads++
+			time.Sleep(peerGetPayloadDelay)
+			resp := &builderApi.VersionedExecutionPayload{Version: consensusspec.DataVersionCapella, Capella: execPayload} //nolint:exhaustruct
+			require.NoError(t, json.NewEncoder(w).Encode(resp))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer peerServer.Close()
+
+	peer, err := newPeerRelay(strings.Replace(peerServer.URL, "http://", "http://"+peerPubkey.String()+"@", 1))
+	require.NoError(t, err)
+	backend.relay.peerRelays = []*peerRelay{peer}
+	backend.relay.peerRelayClient = http.Client{Timeout: time.Duration(peerRelayTimeoutMs) * time.Millisecond}
+	backend.relay.peerRelayGetPayloadClient = http.Client{Timeout: time.Duration(peerRelayGetPayloadTimeoutMs) * time.Millisecond}
+	backend.relay.federatedBids = newFederatedBids()
+
+	t.Run("reject bid not signed by the peer", func(t *testing.T) {
+		otherPeer := *peer
+		otherPeer.pubkey = types.PublicKey{0x01}
+		_, err := backend.relay.fetchPeerBid(&otherPeer, slot, parentHash, proposerPubkey.String())
+		require.ErrorIs(t, err, ErrPeerRelayInvalidBid)
+	})
+
+	t.Run("refuse peer bid not paying the registered fee recipient", func(t *testing.T) {
+		backend.relay.opts.EnforceFeeRecipient = true
+		peerProposerFeeRecipient = "0x0000000000000000000000000000000000000001"
+		defer func() {
+			backend.relay.opts.EnforceFeeRecipient = false
+			peerProposerFeeRecipient = "0x0000000000000000000000000000000000000000"
+			backend.relay.federatedBids = newFederatedBids()
+		}()
+
+		bid, err := backend.relay.fetchPeerBid(peer, slot, parentHash, proposerPubkey.String())
+		require.NoError(t, err)
+		require.Equal(t, peerProposerFeeRecipient, bid.proposerFeeRecipient)
+		backend.relay.federatedBids.set(parentHash, proposerPubkey.String(), bid)
+
+		rr := backend.request(http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey.String()), nil)
+		require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	})
+
+	t.Run("serve the peer bid signed by us", func(t *testing.T) {
+		backend.relay.pollPeerRelays(slot)
+
+		rr := backend.request(http.MethodGet, fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey.String()), nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(common.GetHeaderResponse)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, big.NewInt(100), resp.Value())
+		require.Equal(t, header.BlockHash, resp.BlockHash())
+
+		msg := resp.Capella.Capella.Message
+		require.Equal(t, phase0.BLSPubKey(*backend.relay.publicKey), msg.Pubkey)
+		ok, err := types.VerifySignature(msg, backend.relay.opts.EthNetDetails.DomainBuilder, backend.relay.publicKey[:], resp.Capella.Capella.Signature[:])
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("proxy getPayload to the peer", func(t *testing.T) {
+		// The peer responds after its response delay, longer than the timeout of the other peer relay requests
+		peerGetPayloadDelay = time.Duration(peerRelayTimeoutMs+200) * time.Millisecond
+		defer func() { peerGetPayloadDelay = 0 }()
+
+		blindedBlock := &apiv1capella.BlindedBeaconBlock{
+			Slot:          phase0.Slot(slot),
+			ProposerIndex: phase0.ValidatorIndex(proposerIndex),
+			Body: &apiv1capella.BlindedBeaconBlockBody{
+				ETH1Data:               &phase0.ETH1Data{DepositRoot: phase0.Root{}, DepositCount: 0, BlockHash: make([]byte, 32)},
+				ProposerSlashings:      []*phase0.ProposerSlashing{},
+				AttesterSlashings:      []*phase0.AttesterSlashing{},
+				Attestations:           []*phase0.Attestation{},
+				Deposits:               []*phase0.Deposit{},
+				VoluntaryExits:         []*phase0.SignedVoluntaryExit{},
+				SyncAggregate:          &altair.SyncAggregate{SyncCommitteeBits: make([]byte, 64)},
+				ExecutionPayloadHeader: header,
+				BLSToExecutionChanges:  []*consensuscapella.SignedBLSToExecutionChange{},
+			},
+		}
+		signature, err := types.SignMessage(blindedBlock, backend.relay.proposerDomain(slot), proposerSk)
+		require.NoError(t, err)
+		signedBlindedBlock := &apiv1capella.SignedBlindedBeaconBlock{
+			Message:   blindedBlock,
+			Signature: phase0.BLSSignature(signature),
+		}
+
+		rr := backend.request(http.MethodPost, pathGetPayload, signedBlindedBlock)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(common.VersionedExecutionPayload)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, execPayload.BlockHash, resp.Capella.Capella.BlockHash)
+
+		// The peer publishes the block
+		require.Equal(t, 1, numPeerGetPayloads)
+		require.Equal(t, 0, beaconInstance.NumPublishedBlocks())
+
+		lastSlotDelivered, err := backend.redis.GetLastSlotDelivered(context.Background(), backend.redis.NewTxPipeline())
+		require.NoError(t, err)
+		require.Equal(t, slot, lastSlotDelivered)
+	})
+
+	t.Run("no more peer bids once the slot was delivered", func(t *testing.T) {
+		federatedBids := backend.relay.federatedBids
+		defer func() { backend.relay.federatedBids = federatedBids }()
+		backend.relay.federatedBids = newFederatedBids()
+
+		backend.relay.pollPeerRelays(slot)
+		_, found := backend.relay.federatedBids.get(slot, parentHash, proposerPubkey.String())
+		require.False(t, found)
+	})
+
+	t.Run("prune bids of past slots", func(t *testing.T) {
+		_, found := backend.relay.federatedBids.getByBlockHash(slot, header.BlockHash.String())
+		require.True(t, found)
+		backend.relay.federatedBids.pruneBefore(slot + 1)
+		_, found = backend.relay.federatedBids.getByBlockHash(slot, header.BlockHash.String())
+		require.False(t, found)
+	})
+}
+
+func TestResignPeerBidDeneb(t *testing.T) {
+	backend := newTestBackend(t, 1)
+
+	peerSk, peerBlsPk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	peerPubkey, err := types.BlsPublicKeyToPublicKey(peerBlsPk)
+	require.NoError(t, err)
+	peer := &peerRelay{pubkey: peerPubkey} //nolint:exhaustruct
+
+	parentHash := phase0.Hash32{0x01}
+	peerBid := &common.DenebBuilderBid{
+		Header:             &deneb.ExecutionPayloadHeader{ParentHash: parentHash, BaseFeePerGas: new(uint256.Int), BlockHash: phase0.Hash32{0x02}}, //nolint:exhaustruct
+		BlobKzgCommitments: []deneb.KzgCommitment{{0x03}},
+		Value:              uint256.NewInt(100),
+		Pubkey:             phase0.BLSPubKey(peerPubkey),
+	}
+	sig, err := types.SignMessage(peerBid, backend.relay.opts.EthNetDetails.DomainBuilder, peerSk)
+	require.NoError(t, err)
+	body, err := json.Marshal(&common.GetHeaderResponse{Deneb: &common.DenebSignedBuilderBid{Message: peerBid, Signature: phase0.BLSSignature(sig)}}) //nolint:exhaustruct
+	require.NoError(t, err)
+	resp := new(common.GetHeaderResponse)
+	require.NoError(t, json.Unmarshal(body, resp))
+
+	_, err = backend.relay.resignPeerBid(peer, phase0.Hash32{0x04}.String(), resp)
+	require.ErrorIs(t, err, ErrPeerRelayInvalidBid)
+
+	bid, err := backend.relay.resignPeerBid(peer, parentHash.String(), resp)
+	require.NoError(t, err)
+	require.NotNil(t, bid.Deneb)
+	require.Equal(t, big.NewInt(100), bid.Value())
+	require.Equal(t, phase0.Hash32{0x02}, bid.BlockHash())
+	require.Equal(t, peerBid.BlobKzgCommitments, bid.Deneb.Message.BlobKzgCommitments)
+
+	msg := bid.Deneb.Message
+	require.Equal(t, phase0.BLSPubKey(*backend.relay.publicKey), msg.Pubkey)
+	ok, err := types.VerifySignature(msg, backend.relay.opts.EthNetDetails.DomainBuilder, backend.relay.publicKey[:], bid.Deneb.Signature[:])
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+)
+
+// getPayloadQueueTimeout is how long getPayload requests beyond the concurrency limit wait for a slot before 503
+var getPayloadQueueTimeout = time.Duration(cli.GetEnvInt("GETPAYLOAD_QUEUE_TIMEOUT_MS", 1000)) * time.Millisecond
+
+// getPayloadLimiter limits the number of concurrent getPayload requests, which publish blocks to the beacon node. A
+// proposer only sends a few per slot, so the limit is only reached by retry storms and floods.
+type getPayloadLimiter struct {
+	sem          chan struct{}
+	queueTimeout time.Duration
+}
+
+func newGetPayloadLimiter(maxConcurrent int, queueTimeout time.Duration) *getPayloadLimiter {
+	return &getPayloadLimiter{
+		sem:          make(chan struct{}, maxConcurrent),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// acquire returns whether a slot was free right away, and whether one was acquired after queueing for up to the queue
+// timeout. Acquired slots must be freed with release.
+func (l *getPayloadLimiter) acquire(ctx context.Context) (immediate, ok bool) {
+	select {
+	case l.sem <- struct{}{}:
+		return true, true
+	default:
+	}
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+	select {
+	case l.sem <- struct{}{}:
+		return false, true
+	case <-timer.C:
+		return false, false
+	case <-ctx.Done():
+		return false, false
+	}
+}
+
+func (l *getPayloadLimiter) release() {
+	<-l.sem
+}
+
+func (l *getPayloadLimiter) inFlight() int {
+	return len(l.sem)
+}
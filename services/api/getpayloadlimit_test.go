@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPayloadLimiter(t *testing.T) {
+	limiter := newGetPayloadLimiter(2, 10*time.Millisecond)
+	ctx := context.Background()
+
+	immediate, ok := limiter.acquire(ctx)
+	require.True(t, immediate)
+	require.True(t, ok)
+	immediate, ok = limiter.acquire(ctx)
+	require.True(t, immediate)
+	require.True(t, ok)
+	require.Equal(t, 2, limiter.inFlight())
+
+	// Beyond the limit, requests time out in the queue
+	immediate, ok = limiter.acquire(ctx)
+	require.False(t, immediate)
+	require.False(t, ok)
+
+	// ..or get a slot freed while queueing
+	go func() {
+		time.Sleep(time.Millisecond)
+		limiter.release()
+	}()
+	immediate, ok = limiter.acquire(ctx)
+	require.False(t, immediate)
+	require.True(t, ok)
+
+	// Canceled requests stop queueing
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	limiter.queueTimeout = time.Hour
+	_, ok = limiter.acquire(canceledCtx)
+	require.False(t, ok)
+}
+
+func TestGetPayloadConcurrencyLimit(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.metrics = newRelayMetrics()
+	backend.relay.getPayloadLimiter = newGetPayloadLimiter(1, time.Millisecond)
+	backend.relay.getPayloadLimiter.acquire(context.Background())
+	signedBlindedBlock, _, _ := newTestGetPayloadRequest(t, backend, 64)
+
+	// Requests without a valid proposer signature don't queue for the limit
+	rr := backend.request(http.MethodPost, pathGetPayload, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = backend.request(http.MethodPost, pathGetPayload, signedBlindedBlock)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodeServiceUnavailable))
+
+	rr = backend.request(http.MethodGet, pathMetrics, nil)
+	require.Contains(t, rr.Body.String(), "relay_getpayload_limited_total 1")
+
+	// With a free slot the request is processed
+	backend.relay.getPayloadLimiter.release()
+	rr = backend.request(http.MethodPost, pathGetPayload, signedBlindedBlock)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, 0, backend.relay.getPayloadLimiter.inFlight())
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// parseIPNets parses a list of CIDRs, where plain IPs stand for themselves
+func parseIPNets(entries []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %s", entry)
+			}
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// getClientIP returns the IP of the client, for the admin allowlist, the rate limits and logs. X-Forwarded-For is only
+// used if the request comes from one of the TrustedProxies, and then the last entry which isn't a trusted proxy itself
+// is the client: the ones before it are set by the client, which could otherwise evade the per-IP rate limits with a
+// new IP on every request.
+func (api *RelayAPI) getClientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(api.trustedProxies, ip) {
+		return host
+	}
+
+	forwardedFor := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(forwardedFor[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+		if !containsIP(api.trustedProxies, hop) {
+			break
+		}
+	}
+	return ip.String()
+}
+
+// requireAllowedIP responds with 403 to requests from IPs outside of the admin allowlist, before any token check.
+// Without an allowlist, all IPs are allowed.
+func (api *RelayAPI) requireAllowedIP(next http.Handler) http.Handler {
+	if len(api.adminAllowIPs) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		clientIP := api.getClientIP(req)
+		if ip := net.ParseIP(clientIP); ip == nil || !containsIP(api.adminAllowIPs, ip) {
+			api.log.WithFields(logrus.Fields{
+				"path": req.URL.Path,
+				"ip":   clientIP,
+			}).Warn("admin request from an IP outside of the allowlist")
+			api.RespondError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIPNets(t *testing.T) {
+	nets, err := parseIPNets([]string{"10.0.0.0/8", " 192.0.2.1", "2001:db8::1", ""})
+	require.NoError(t, err)
+	require.Len(t, nets, 3)
+	require.Equal(t, "192.0.2.1/32", nets[1].String())
+	require.Equal(t, "2001:db8::1/128", nets[2].String())
+
+	_, err = parseIPNets([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+	_, err = parseIPNets([]string{"bastion"})
+	require.Error(t, err)
+}
+
+func TestGetClientIP(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	var err error
+	backend.relay.trustedProxies, err = parseIPNets([]string{"172.16.0.0/24"})
+	require.NoError(t, err)
+
+	clientIP := func(remoteAddr, forwardedFor string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		return backend.relay.getClientIP(req)
+	}
+
+	// Only requests of trusted proxies are forwarded
+	require.Equal(t, "192.0.2.1", clientIP("192.0.2.1:1234", "10.1.2.3"))
+	require.Equal(t, "10.1.2.3", clientIP("172.16.0.1:1234", "10.1.2.3"))
+
+	// Entries set by the client are ignored, so a new IP doesn't get a new rate limit
+	require.Equal(t, "10.1.2.3", clientIP("172.16.0.1:1234", "198.51.100.1, 10.1.2.3"))
+	require.Equal(t, "10.1.2.3", clientIP("172.16.0.1:1234", "198.51.100.2, 10.1.2.3, 172.16.0.2"))
+}
+
+func TestAdminAllowIPs(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.PprofAPI = true
+	backend.relay.opts.PprofToken = "secret"
+	var err error
+	backend.relay.adminAllowIPs, err = parseIPNets([]string{"10.1.0.0/16"})
+	require.NoError(t, err)
+	backend.relay.trustedProxies, err = parseIPNets([]string{"172.16.0.1"})
+	require.NoError(t, err)
+
+	request := func(remoteAddr, forwardedFor, token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		backend.relay.getRouter().ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// The IP is checked before the token
+	require.Equal(t, http.StatusForbidden, request("192.0.2.1:1234", "", "secret"))
+	require.Equal(t, http.StatusUnauthorized, request("10.1.2.3:1234", "", "wrong"))
+	require.Equal(t, http.StatusOK, request("10.1.2.3:1234", "", "secret"))
+
+	// X-Forwarded-For is only used from trusted proxies, and only the entry added by the proxy counts
+	require.Equal(t, http.StatusForbidden, request("192.0.2.1:1234", "10.1.2.3", "secret"))
+	require.Equal(t, http.StatusOK, request("172.16.0.1:1234", "10.1.2.3", "secret"))
+	require.Equal(t, http.StatusForbidden, request("172.16.0.1:1234", "10.1.2.3, 192.0.2.1", "secret"))
+	require.Equal(t, http.StatusOK, request("172.16.0.1:1234", "192.0.2.1, 10.1.2.3, 172.16.0.1", "secret"))
+}
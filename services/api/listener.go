@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UnixSocketPrefix marks a listen address as the path of a Unix domain socket (unix:/path/to/sock)
+const UnixSocketPrefix = "unix:"
+
+// maximum length of a socket path (sun_path is 104 bytes on macOS/BSD and 108 on Linux, including the terminating zero)
+const maxUnixSocketPathLength = 103
+
+var (
+	ErrInvalidUnixSocketPath = errors.New("invalid unix socket path")
+
+	DefaultUnixSocketMode os.FileMode = 0o660
+)
+
+// unixSocketPath returns the socket path of a unix:/path/to/sock listen address
+func unixSocketPath(listenAddr string) (path string, isUnix bool) {
+	if !strings.HasPrefix(listenAddr, UnixSocketPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(listenAddr, UnixSocketPrefix), true
+}
+
+// validateUnixSocketPath checks that the socket can be created: the directory exists, and there's nothing but a
+// (stale) socket at the path
+func validateUnixSocketPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("%w: empty path", ErrInvalidUnixSocketPath)
+	} else if len(path) > maxUnixSocketPathLength {
+		return fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidUnixSocketPath, path, maxUnixSocketPathLength)
+	}
+
+	dir, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidUnixSocketPath, err)
+	} else if !dir.IsDir() {
+		return fmt.Errorf("%w: %s is not a directory", ErrInvalidUnixSocketPath, filepath.Dir(path))
+	}
+
+	fi, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidUnixSocketPath, err)
+	} else if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%w: %s exists and is not a socket", ErrInvalidUnixSocketPath, path)
+	}
+	return nil
+}
+
+// listen opens a TCP listener, or a Unix domain socket with the given file permissions for unix:/path/to/sock.
+// The socket file is removed when the listener is closed (i.e. by http.Server.Shutdown).
+func listen(listenAddr string, socketMode os.FileMode) (net.Listener, error) {
+	path, isUnix := unixSocketPath(listenAddr)
+	if !isUnix {
+		return net.Listen("tcp", listenAddr)
+	}
+
+	if err := validateUnixSocketPath(path); err != nil {
+		return nil, err
+	}
+
+	// A socket left behind by a previous process which didn't shut down cleanly
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return listenUnixSocket(path, socketMode)
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package api
+
+import (
+	"net"
+	"os"
+)
+
+// listenUnixSocket creates the socket and then sets its file permissions, there's no umask outside of Unix
+func listenUnixSocket(path string, socketMode os.FileMode) (net.Listener, error) {
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
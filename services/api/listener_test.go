@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateUnixSocketPath(t *testing.T) {
+	dir := t.TempDir()
+	regularFile := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(regularFile, []byte("x"), 0o600))
+
+	require.NoError(t, validateUnixSocketPath(filepath.Join(dir, "relay.sock")))
+	require.ErrorIs(t, validateUnixSocketPath(""), ErrInvalidUnixSocketPath)
+	require.ErrorIs(t, validateUnixSocketPath(filepath.Join(dir, "missing", "relay.sock")), ErrInvalidUnixSocketPath)
+	require.ErrorIs(t, validateUnixSocketPath(filepath.Join(regularFile, "relay.sock")), ErrInvalidUnixSocketPath)
+	require.ErrorIs(t, validateUnixSocketPath(regularFile), ErrInvalidUnixSocketPath)
+	require.ErrorIs(t, validateUnixSocketPath("/"+strings.Repeat("a", maxUnixSocketPathLength)), ErrInvalidUnixSocketPath)
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relay.sock")
+
+	ln, err := listen(UnixSocketPrefix+path, 0o600)
+	require.NoError(t, err)
+	fi, err := os.Lstat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { //nolint:gosec
+		w.WriteHeader(http.StatusOK)
+	})}
+	go srv.Serve(ln) //nolint:errcheck
+
+	client := http.Client{Transport: &http.Transport{ //nolint:exhaustruct
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path) //nolint:exhaustruct
+		},
+	}}
+	resp, err := client.Get("http://relay" + pathLivez)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The socket file is removed on shutdown
+	require.NoError(t, srv.Shutdown(context.Background()))
+	_, err = os.Lstat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// A stale socket is replaced
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false) //nolint:forcetypeassert
+	stale.Close()
+	ln, err = listen(UnixSocketPrefix+path, 0o600)
+	require.NoError(t, err)
+	ln.Close()
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package api
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// listenUnixSocket creates the socket with the umask set to the complement of the file permissions, so that it never
+// exists with broader permissions (as it would between creating and chmod'ing it). The umask is process-wide, but
+// only ever narrowed for files created concurrently.
+func listenUnixSocket(path string, socketMode os.FileMode) (net.Listener, error) {
+	oldUmask := syscall.Umask(int(^socketMode.Perm() & os.ModePerm))
+	defer syscall.Umask(oldUmask)
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var rejectionLogSampling = os.Getenv("DISABLE_REJECTION_LOG_SAMPLING") != "1"
+
+// maxRejectionLogKeys caps the number of sampled builder, reason and slot combinations, since the builder pubkey and slot
+// of a rejected submission aren't authenticated (yet). Rejections beyond it are logged as usual.
+var maxRejectionLogKeys = 10_000
+
+type rejectionKey struct {
+	slot          uint64
+	builderPubkey string
+	reason        string
+}
+
+// rejectionLogSampler collapses repeated rejections of the submissions of a builder for the same reason within a slot:
+// the first one is logged as usual, later ones only counted and logged as one summary per builder and reason once the
+// slot is over (or on shutdown). Only the slots submissions are accepted for (the two after the head slot) are sampled.
+// A nil sampler logs every rejection.
+type rejectionLogSampler struct {
+	log *logrus.Entry
+
+	mu         sync.Mutex
+	minSlot    uint64 // first slot which is sampled, the one after the head slot
+	seen       map[rejectionKey]bool
+	suppressed map[rejectionKey]int
+}
+
+func newRejectionLogSampler(log *logrus.Entry) *rejectionLogSampler {
+	return &rejectionLogSampler{
+		log:        log,
+		seen:       make(map[rejectionKey]bool),
+		suppressed: make(map[rejectionKey]int),
+	}
+}
+
+// logRejection logs the rejection of a submission for the slot at the level, unless the builder's submissions were
+// rejected for the same reason in the slot before
+func (s *rejectionLogSampler) logRejection(log *logrus.Entry, level logrus.Level, slot uint64, builderPubkey, reason string) {
+	if s == nil {
+		log.Log(level, reason)
+		return
+	}
+
+	key := rejectionKey{slot: slot, builderPubkey: builderPubkey, reason: reason}
+	s.mu.Lock()
+	isRepeated := s.seen[key]
+	if isRepeated {
+		s.suppressed[key]++
+	} else if slot >= s.minSlot && slot <= s.minSlot+1 && len(s.seen) < maxRejectionLogKeys {
+		s.seen[key] = true
+	}
+	s.mu.Unlock()
+
+	if !isRepeated {
+		log.Log(level, reason)
+	}
+}
+
+// flushBefore logs the summaries of the slots before the given one and forgets their rejections, and samples the given
+// slot and the one after it from now on
+func (s *rejectionLogSampler) flushBefore(slot uint64) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.minSlot = slot
+	summaries := make([]rejectionKey, 0)
+	counts := make(map[rejectionKey]int)
+	for key := range s.seen {
+		if key.slot >= slot {
+			continue
+		}
+		if n := s.suppressed[key]; n > 0 {
+			summaries = append(summaries, key)
+			counts[key] = n
+		}
+		delete(s.seen, key)
+		delete(s.suppressed, key)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].slot != summaries[j].slot {
+			return summaries[i].slot < summaries[j].slot
+		}
+		if summaries[i].builderPubkey != summaries[j].builderPubkey {
+			return summaries[i].builderPubkey < summaries[j].builderPubkey
+		}
+		return summaries[i].reason < summaries[j].reason
+	})
+	for _, key := range summaries {
+		// the count includes the first rejection, which was logged on its own
+		s.log.WithFields(logrus.Fields{
+			"slot":          key.slot,
+			"builderPubkey": key.builderPubkey,
+			"numRejected":   counts[key] + 1,
+			"reason":        key.reason,
+		}).Infof("rejected %d bids from builder %s this slot: %s", counts[key]+1, key.builderPubkey, key.reason)
+	}
+}
+
+// flush logs the summaries of all slots, i.e. on shutdown
+func (s *rejectionLogSampler) flush() {
+	s.flushBefore(^uint64(0))
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejectionLogSampler(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	log := logrus.NewEntry(logger)
+	s := newRejectionLogSampler(log)
+	s.flushBefore(10) // head slot 9
+
+	for i := 0; i < 3; i++ {
+		s.logRejection(log, logrus.InfoLevel, 10, "0xb1", "submission for past slot")
+	}
+	s.logRejection(log, logrus.WarnLevel, 10, "0xb1", "invalid builder signature")
+	s.logRejection(log, logrus.InfoLevel, 10, "0xb2", "submission for past slot")
+	s.logRejection(log, logrus.InfoLevel, 11, "0xb1", "submission for past slot")
+	s.logRejection(log, logrus.InfoLevel, 11, "0xb1", "submission for past slot")
+
+	// only the first rejection per slot, builder and reason is logged
+	require.Len(t, hook.AllEntries(), 4)
+	require.Equal(t, logrus.WarnLevel, hook.AllEntries()[1].Level)
+
+	// the summaries of the slots before are logged on the new slot
+	hook.Reset()
+	s.flushBefore(11)
+	require.Len(t, hook.AllEntries(), 1)
+	summary := hook.LastEntry()
+	require.Equal(t, "rejected 3 bids from builder 0xb1 this slot: submission for past slot", summary.Message)
+	require.Equal(t, uint64(10), summary.Data["slot"])
+	require.Equal(t, 3, summary.Data["numRejected"])
+
+	// past slots and slots too far ahead aren't sampled
+	hook.Reset()
+	s.logRejection(log, logrus.InfoLevel, 10, "0xb1", "submission for past slot")
+	s.logRejection(log, logrus.InfoLevel, 10, "0xb1", "submission for past slot")
+	s.logRejection(log, logrus.InfoLevel, 13, "0xb1", "submission for past slot")
+	s.logRejection(log, logrus.InfoLevel, 13, "0xb1", "submission for past slot")
+	require.Len(t, hook.AllEntries(), 4)
+
+	// summaries of a builder are ordered by reason
+	hook.Reset()
+	s.logRejection(log, logrus.InfoLevel, 12, "0xb1", "reason b")
+	s.logRejection(log, logrus.InfoLevel, 12, "0xb1", "reason b")
+	s.logRejection(log, logrus.InfoLevel, 12, "0xb1", "reason a")
+	s.logRejection(log, logrus.InfoLevel, 12, "0xb1", "reason a")
+	hook.Reset()
+	s.flushBefore(13)
+	require.Len(t, hook.AllEntries(), 3)
+	require.Equal(t, uint64(11), hook.AllEntries()[0].Data["slot"])
+	require.Equal(t, 2, hook.AllEntries()[0].Data["numRejected"])
+	require.Equal(t, "reason a", hook.AllEntries()[1].Data["reason"])
+	require.Equal(t, "reason b", hook.AllEntries()[2].Data["reason"])
+
+	// the number of sampled keys is capped, rejections beyond it are logged as usual
+	maxRejectionLogKeys = 1
+	defer func() { maxRejectionLogKeys = 10_000 }()
+	hook.Reset()
+	s.logRejection(log, logrus.InfoLevel, 13, "0xb1", "submission for past slot")
+	s.logRejection(log, logrus.InfoLevel, 13, "0xb2", "submission for past slot")
+	s.logRejection(log, logrus.InfoLevel, 13, "0xb2", "submission for past slot")
+	s.logRejection(log, logrus.InfoLevel, 13, "0xb1", "submission for past slot")
+	require.Len(t, hook.AllEntries(), 3)
+
+	// the rest is logged on shutdown
+	hook.Reset()
+	s.flush()
+	require.Len(t, hook.AllEntries(), 1)
+	require.Equal(t, uint64(13), hook.LastEntry().Data["slot"])
+	require.Equal(t, 2, hook.LastEntry().Data["numRejected"])
+
+	// without the sampler, every rejection is logged
+	hook.Reset()
+	var disabled *rejectionLogSampler
+	disabled.logRejection(log, logrus.InfoLevel, 10, "0xb1", "submission for past slot")
+	disabled.logRejection(log, logrus.InfoLevel, 10, "0xb1", "submission for past slot")
+	disabled.flush()
+	require.Len(t, hook.AllEntries(), 2)
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaintenanceStatus is returned by the internal maintenance endpoint
+type MaintenanceStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+// handleInternalMaintenance returns the maintenance mode, and sets it with POST/PUT ?enabled=true|false. In
+// maintenance mode getHeader responds with 204 and submitBlock with 503, while getPayload keeps working so that
+// proposers can complete the slots of bids which were already served.
+func (api *RelayAPI) handleInternalMaintenance(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPost || req.Method == http.MethodPut {
+		enabled, err := strconv.ParseBool(req.URL.Query().Get("enabled"))
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid enabled argument")
+			return
+		}
+
+		previous := api.maintenanceMode.Swap(enabled)
+		api.log.WithFields(logrus.Fields{
+			"enabled":  enabled,
+			"previous": previous,
+			"ip":       api.getClientIP(req),
+		}).Warn("maintenance mode updated")
+	}
+	api.RespondOK(w, MaintenanceStatus{Enabled: api.maintenanceMode.Load()})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternalMaintenance(t *testing.T) {
+	pubkey, secretkey, backend := startTestBackend(t)
+	backend.relay.opts.AdminToken = "secret"
+	authHeaders := map[string]string{"Authorization": "Bearer secret"}
+
+	// Valid submissions for the slot of startTestBackend, accepted without simulation
+	backend.relay.capellaEpoch = 1
+	backend.relay.blockSimRateLimiter = nil
+	var randaoHash boostTypes.Hash
+	require.NoError(t, randaoHash.FromSlice([]byte(randao)))
+	withdrawalsRoot, err := ComputeWithdrawalsRoot([]*consensuscapella.Withdrawal{})
+	require.NoError(t, err)
+	backend.relay.payloadAttributes[emptyHash] = payloadAttributesHelper{
+		slot:              slot,
+		withdrawalsRoot:   withdrawalsRoot,
+		payloadAttributes: beaconclient.PayloadAttributes{PrevRandao: randaoHash.String()}, //nolint:exhaustruct
+	}
+	submitBlock := func() *httptest.ResponseRecorder {
+		req := common.TestBuilderSubmitBlockRequest(secretkey, getTestBidTrace(*pubkey, collateral+1))
+		return backend.request(http.MethodPost, pathSubmitNewBlock, &req)
+	}
+
+	setMaintenance := func(args string) MaintenanceStatus {
+		rr := backend.requestBytes(http.MethodPost, pathInternalMaintenance+args, nil, authHeaders)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := MaintenanceStatus{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+	getReadiness := func() ReadinessResponse {
+		rr := backend.request(http.MethodGet, pathReadyz, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := ReadinessResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+
+	// Requires the admin token
+	rr := backend.request(http.MethodPost, pathInternalMaintenance+"?enabled=true", nil)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.False(t, backend.relay.maintenanceMode.Load())
+
+	rr = backend.requestBytes(http.MethodPost, pathInternalMaintenance+"?enabled=maybe", nil, authHeaders)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	require.True(t, setMaintenance("?enabled=true").Enabled)
+	rr = backend.requestBytes(http.MethodGet, pathInternalMaintenance, nil, authHeaders)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"enabled":true}`, rr.Body.String())
+
+	// Still ready, with the mode in the readiness response
+	require.True(t, getReadiness().Maintenance)
+
+	// No bids are served or accepted
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747/0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792", slot)
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	rr = submitBlock()
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodeMaintenance))
+
+	// getPayload requests are still handled
+	rr = backend.requestBytes(http.MethodPost, pathGetPayload, []byte("{}"), nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	require.False(t, setMaintenance("?enabled=false").Enabled)
+	require.False(t, getReadiness().Maintenance)
+	rr = submitBlock()
+	require.Equal(t, http.StatusOK, rr.Code)
+}
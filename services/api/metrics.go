@@ -0,0 +1,286 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	pathMetrics = "/metrics"
+
+	// latency buckets in seconds, fine-grained below one second (getHeader alone waits up to 500ms)
+	metricsLatencyBuckets = []float64{.005, .01, .025, .05, .1, .2, .3, .4, .5, .6, .75, 1, 1.5, 2, 5}
+
+	// datastore operations are mostly single Redis roundtrips, so the buckets start below a millisecond
+	metricsDatastoreLatencyBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+)
+
+// relayMetrics holds the Prometheus collectors of a RelayAPI instance, in a registry of its own
+type relayMetrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestErrors   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	builderSubmissions       *prometheus.CounterVec
+	builderBidsServed        *prometheus.CounterVec
+	builderPayloadsDelivered *prometheus.CounterVec
+	getHeaderNoBidSlots      prometheus.Counter
+	getPayloadLimited        prometheus.Counter
+	missedServedSlots        *prometheus.CounterVec
+
+	cachedBids   *prometheus.GaugeVec
+	cacheEntries *prometheus.GaugeVec
+
+	datastoreDuration *prometheus.HistogramVec
+	datastoreErrors   *prometheus.CounterVec
+}
+
+func newRelayMetrics() *relayMetrics {
+	m := &relayMetrics{
+		registry: prometheus.NewRegistry(),
+
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "relay",
+			Name:      "http_requests_total",
+			Help:      "Number of handled HTTP requests, by route and status code",
+		}, []string{"route", "method", "code"}),
+
+		requestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "relay",
+			Name:      "http_request_errors_total",
+			Help:      "Number of HTTP requests answered with a 4xx or 5xx status code, by route",
+		}, []string{"route", "method"}),
+
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "relay",
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of handled HTTP requests, by route",
+			Buckets:   metricsLatencyBuckets,
+		}, []string{"route", "method"}),
+
+		builderSubmissions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "relay",
+			Name:      "builder_submissions_total",
+			Help:      "Number of block submissions received, by builder",
+		}, []string{"builder_pubkey"}),
+
+		builderBidsServed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "relay",
+			Name:      "builder_bids_served_total",
+			Help:      "Number of bids served through getHeader, by builder",
+		}, []string{"builder_pubkey"}),
+
+		builderPayloadsDelivered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "relay",
+			Name:      "builder_payloads_delivered_total",
+			Help:      "Number of payloads delivered through getPayload, by builder",
+		}, []string{"builder_pubkey"}),
+
+		getHeaderNoBidSlots: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "relay",
+			Name:      "getheader_no_bid_slots_total",
+			Help:      "Number of slots for which getHeader returned 204 because there was no bid",
+		}),
+
+		getPayloadLimited: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "relay",
+			Name:      "getpayload_limited_total",
+			Help:      "Number of getPayload requests rejected with 503 because of the concurrency limit",
+		}),
+
+		missedServedSlots: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "relay",
+			Name:      "missed_served_slots_total",
+			Help:      "Number of slots without a block, for which getHeader served a bid, by whether the payload was delivered",
+		}, []string{"payload_delivered"}),
+
+		cachedBids: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "relay",
+			Name:      "cached_bids",
+			Help:      "Number of bids of the head and the next slot (best bids of the bid cache if enabled, otherwise the latest bids of the builders in Redis, and best peer relay bids)",
+		}, []string{"slot"}),
+
+		cacheEntries: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "relay",
+			Name:      "cache_entries",
+			Help:      "Number of entries of the in-memory caches, by cache",
+		}, []string{"cache"}),
+
+		datastoreDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "relay",
+			Name:      "datastore_operation_duration_seconds",
+			Help:      "Latency of datastore operations, by backend (redis, db, or datastore for operations using several) and operation",
+			Buckets:   metricsDatastoreLatencyBuckets,
+		}, []string{"backend", "operation"}),
+
+		datastoreErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "relay",
+			Name:      "datastore_operation_errors_total",
+			Help:      "Number of failed datastore operations, by backend and operation",
+		}, []string{"backend", "operation"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}), //nolint:exhaustruct
+		m.requests,
+		m.requestErrors,
+		m.requestDuration,
+		m.builderSubmissions,
+		m.builderBidsServed,
+		m.builderPayloadsDelivered,
+		m.getHeaderNoBidSlots,
+		m.getPayloadLimited,
+		m.missedServedSlots,
+		m.cachedBids,
+		m.cacheEntries,
+		m.datastoreDuration,
+		m.datastoreErrors,
+	)
+	return m
+}
+
+func (m *relayMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}) //nolint:exhaustruct
+}
+
+// middleware records request count, errors and latency for every matched route
+func (m *relayMetrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		route := req.URL.Path
+		if currentRoute := mux.CurrentRoute(req); currentRoute != nil {
+			if tpl, err := currentRoute.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		start := time.Now()
+		rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, req)
+
+		m.requests.WithLabelValues(route, req.Method, strconv.Itoa(rw.status)).Inc()
+		m.requestDuration.WithLabelValues(route, req.Method).Observe(time.Since(start).Seconds())
+		if rw.status >= http.StatusBadRequest {
+			m.requestErrors.WithLabelValues(route, req.Method).Inc()
+		}
+	})
+}
+
+// incBuilderSubmissions, incBuilderBidsServed and incBuilderPayloadsDelivered are no-ops if metrics are disabled
+func (m *relayMetrics) incBuilderSubmissions(builderPubkey string) {
+	if m != nil {
+		m.builderSubmissions.WithLabelValues(builderPubkey).Inc()
+	}
+}
+
+func (m *relayMetrics) incBuilderBidsServed(builderPubkey string) {
+	if m != nil {
+		m.builderBidsServed.WithLabelValues(builderPubkey).Inc()
+	}
+}
+
+func (m *relayMetrics) incBuilderPayloadsDelivered(builderPubkey string) {
+	if m != nil {
+		m.builderPayloadsDelivered.WithLabelValues(builderPubkey).Inc()
+	}
+}
+
+// incGetHeaderNoBidSlots is a no-op if metrics are disabled
+func (m *relayMetrics) incGetHeaderNoBidSlots() {
+	if m != nil {
+		m.getHeaderNoBidSlots.Inc()
+	}
+}
+
+// incGetPayloadLimited is a no-op if metrics are disabled
+func (m *relayMetrics) incGetPayloadLimited() {
+	if m != nil {
+		m.getPayloadLimited.Inc()
+	}
+}
+
+// incMissedServedSlots is a no-op if metrics are disabled
+func (m *relayMetrics) incMissedServedSlots(payloadDelivered bool) {
+	if m != nil {
+		m.missedServedSlots.WithLabelValues(strconv.FormatBool(payloadDelivered)).Inc()
+	}
+}
+
+func (m *relayMetrics) observeDatastoreOperation(backend, operation string, start time.Time, err error) {
+	m.datastoreDuration.WithLabelValues(backend, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.datastoreErrors.WithLabelValues(backend, operation).Inc()
+	}
+}
+
+// registerSigVerifyQueueDepth adds gauges for the number of signature verifications waiting for a worker
+func (m *relayMetrics) registerSigVerifyQueueDepth(v *sigVerifier) {
+	for _, highPriority := range []bool{true, false} {
+		highPriority := highPriority
+		priority := "normal"
+		if highPriority {
+			priority = "high"
+		}
+		m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   "relay",
+			Name:        "sig_verify_queue_depth",
+			Help:        "Number of signature verifications waiting for a worker, by priority",
+			ConstLabels: prometheus.Labels{"priority": priority},
+		}, func() float64 { return float64(v.queueDepth(highPriority)) }))
+	}
+}
+
+// registerBeaconBreakerState adds a gauge for the state of the beacon node circuit breaker
+func (m *relayMetrics) registerBeaconBreakerState(b *circuitBreaker) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "relay",
+		Name:      "beacon_circuit_breaker_state",
+		Help:      "State of the beacon node circuit breaker (0: closed, 1: half-open, 2: open)",
+	}, func() float64 { return float64(b.getState()) }))
+}
+
+// registerClockSkew adds a gauge for the offset of the beacon node's clock to the local clock
+func (m *relayMetrics) registerClockSkew(api *RelayAPI) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "relay",
+		Name:      "clock_skew_seconds",
+		Help:      "Offset of the beacon node's clock to the local clock (positive if the beacon node is ahead), from the Date header",
+	}, func() float64 { return float64(api.clockSkewMs.Load()) / 1000 }))
+}
+
+// statusRecorder is a http.ResponseWriter that remembers the status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// startMetricsServer serves /metrics on a separate listen address (i.e. bound to an internal interface)
+func (api *RelayAPI) startMetricsServer() {
+	router := http.NewServeMux()
+	router.Handle(pathMetrics, api.metrics.handler())
+	srv := &http.Server{ //nolint:exhaustruct
+		Addr:              api.opts.MetricsListenAddr,
+		Handler:           router,
+		ReadHeaderTimeout: api.opts.ReadHeaderTimeout,
+	}
+
+	api.log.Infof("serving metrics on %s%s", api.opts.MetricsListenAddr, pathMetrics)
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		api.log.WithError(err).Error("metrics server failed")
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type servedSlot struct {
+	proposerPubkey string
+	blockHash      string
+}
+
+// missedSlotDetector remembers the slots for which getHeader served a bid, to find the ones which got no block once
+// the head slot moves past them: the proposer got a header from us but didn't propose, which may be a problem with
+// the getPayload response or the block publishing. The zero value is ready to use.
+type missedSlotDetector struct {
+	mu     sync.Mutex
+	served map[uint64]servedSlot
+}
+
+func (d *missedSlotDetector) recordServed(slot uint64, proposerPubkey, blockHash string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.served == nil {
+		d.served = make(map[uint64]servedSlot)
+	}
+	d.served[slot] = servedSlot{proposerPubkey: proposerPubkey, blockHash: blockHash}
+}
+
+// processHead returns the served slots between prevHeadSlot and headSlot (exclusive), which got no block, and forgets
+// all served slots up to headSlot
+func (d *missedSlotDetector) processHead(prevHeadSlot, headSlot uint64) map[uint64]servedSlot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	missed := make(map[uint64]servedSlot)
+	for slot, served := range d.served {
+		if slot > headSlot {
+			continue
+		}
+		if slot > prevHeadSlot && slot < headSlot {
+			missed[slot] = served
+		}
+		delete(d.served, slot)
+	}
+	return missed
+}
+
+// checkMissedSlots logs and counts the slots missed by proposers which got a header from us. It's called before the
+// delivered payloads of the slots are pruned, to tell whether the proposer also called getPayload.
+func (api *RelayAPI) checkMissedSlots(prevHeadSlot, headSlot uint64) {
+	if prevHeadSlot == 0 {
+		return
+	}
+	for slot, served := range api.missedSlots.processHead(prevHeadSlot, headSlot) {
+		_, delivered := api.deliveredPayloads.get(slot, served.proposerPubkey, served.blockHash)
+		api.metrics.incMissedServedSlots(delivered)
+		api.log.WithFields(logrus.Fields{
+			"missedSlot":       slot,
+			"proposerPubkey":   served.proposerPubkey,
+			"blockHash":        served.blockHash,
+			"payloadDelivered": delivered,
+		}).Warn("proposer missed a slot for which we served a header")
+	}
+}
+
+func (d *missedSlotDetector) len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.served)
+}
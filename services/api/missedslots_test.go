@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissedSlotDetector(t *testing.T) {
+	detector := missedSlotDetector{} //nolint:exhaustruct
+	detector.recordServed(11, "0xaa", "0x01")
+	detector.recordServed(12, "0xbb", "0x02")
+	detector.recordServed(14, "0xcc", "0x03")
+
+	// head 10 -> 13: the served slots 11 and 12 got no block
+	missed := detector.processHead(10, 13)
+	require.Equal(t, map[uint64]servedSlot{
+		11: {proposerPubkey: "0xaa", blockHash: "0x01"},
+		12: {proposerPubkey: "0xbb", blockHash: "0x02"},
+	}, missed)
+
+	// slot 14 got a block
+	require.Empty(t, detector.processHead(13, 14))
+	require.Empty(t, detector.served)
+}
+
+func TestCheckMissedSlots(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.metrics = newRelayMetrics()
+
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	backend.relay.missedSlots.recordServed(11, proposerPubkey, "0x01")
+	backend.relay.missedSlots.recordServed(12, proposerPubkey, "0x02")
+	backend.relay.deliveredPayloads.set(12, proposerPubkey, "0x02", nil)
+
+	backend.relay.processNewSlot(10)
+	backend.relay.processNewSlot(13)
+
+	rr := backend.request(http.MethodGet, pathMetrics, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `relay_missed_served_slots_total{payload_delivered="false"} 1`)
+	require.Contains(t, rr.Body.String(), `relay_missed_served_slots_total{payload_delivered="true"} 1`)
+}
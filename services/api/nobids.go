@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/sirupsen/logrus"
+)
+
+var noBidWarnSlots = cli.GetEnvInt("NO_BID_WARN_SLOTS", 3) // consecutive slots without a bid before getHeader warns
+
+// noBidMessage is the message of the structured no-bid responses, see wantsNoBidResponse
+const noBidMessage = "no bid available, build locally"
+
+// Reasons for getHeader to not serve a bid
+const (
+	noBidReasonUserAgent       = "user_agent"
+	noBidReasonForced          = "forced"
+	noBidReasonMaintenance     = "maintenance"
+	noBidReasonValidatorStatus = "validator_status"
+	noBidReasonNotProposer     = "not_proposer"
+	noBidReasonOrphanedParent  = "orphaned_parent"
+	noBidReasonDryRun          = "dry_run"
+	noBidReasonTooLate         = "too_late"
+	noBidReasonNoBid           = "no_bid"
+	noBidReasonBelowMinValue   = "below_min_value"
+	noBidReasonFeeRecipient    = "fee_recipient_mismatch"
+	noBidReasonHeaderServed    = "header_already_served"
+)
+
+// noBidTracker counts the consecutive slots for which getHeader returned no bid. Only slots with getHeader requests
+// count, so slots of proposers which don't use the relay don't break a streak. The zero value is ready to use.
+type noBidTracker struct {
+	mu          sync.Mutex
+	lastSlot    uint64
+	consecutive int
+}
+
+// recordBid ends a streak of slots without bids
+func (t *noBidTracker) recordBid(slot uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if slot >= t.lastSlot {
+		t.lastSlot = slot
+		t.consecutive = 0
+	}
+}
+
+// recordNoBid returns the number of consecutive slots without bids, and whether the slot wasn't recorded before
+func (t *noBidTracker) recordNoBid(slot uint64) (consecutive int, isNewSlot bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if slot <= t.lastSlot && t.lastSlot != 0 {
+		return t.consecutive, false
+	}
+	t.lastSlot = slot
+	t.consecutive++
+	return t.consecutive, true
+}
+
+// wantsNoBidResponse returns whether the request asks for a NoBidResponse instead of 204, with the
+// X-MEVBoost-No-Bid-Response header or the no_bid_response query parameter. Older mev-boost versions only know 204.
+func wantsNoBidResponse(req *http.Request) bool {
+	return req.Header.Get(HeaderNoBidResponse) == "1" || req.URL.Query().Get("no_bid_response") == "1"
+}
+
+// respondNoContent answers getHeader without a bid: with 204 by default, or with a NoBidResponse naming the slot and
+// the reason if the request asks for it
+func (api *RelayAPI) respondNoContent(w http.ResponseWriter, req *http.Request, slot uint64, reason string) {
+	if !wantsNoBidResponse(req) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	api.RespondOK(w, &NoBidResponse{Slot: slot, Reason: reason, Message: noBidMessage})
+}
+
+// respondNoBid answers getHeader without a bid because there is no bid for the slot, counting the slot in the metrics and
+// warning if several consecutive slots had no bids (i.e. builders disconnected, or the head slot tracking broke)
+func (api *RelayAPI) respondNoBid(w http.ResponseWriter, req *http.Request, log *logrus.Entry, slot uint64) {
+	api.respondNoContent(w, req, slot, noBidReasonNoBid)
+
+	consecutive, isNewSlot := api.noBids.recordNoBid(slot)
+	if !isNewSlot {
+		return
+	}
+	api.metrics.incGetHeaderNoBidSlots()
+	if noBidWarnSlots > 0 && consecutive >= noBidWarnSlots {
+		log.WithField("consecutiveSlots", consecutive).Warn("no bids for several consecutive slots, builders may be disconnected or the head slot tracking broken")
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoBidTracker(t *testing.T) {
+	tracker := noBidTracker{} //nolint:exhaustruct
+
+	consecutive, isNewSlot := tracker.recordNoBid(10)
+	require.True(t, isNewSlot)
+	require.Equal(t, 1, consecutive)
+
+	// Repeated requests for a slot count once
+	consecutive, isNewSlot = tracker.recordNoBid(10)
+	require.False(t, isNewSlot)
+	require.Equal(t, 1, consecutive)
+
+	// Slots without requests don't break the streak
+	consecutive, _ = tracker.recordNoBid(12)
+	require.Equal(t, 2, consecutive)
+
+	// A served bid does, also for later requests of the slot
+	tracker.recordBid(13)
+	_, isNewSlot = tracker.recordNoBid(13)
+	require.False(t, isNewSlot)
+	consecutive, isNewSlot = tracker.recordNoBid(14)
+	require.True(t, isNewSlot)
+	require.Equal(t, 1, consecutive)
+
+	// Late requests for past slots are ignored
+	_, isNewSlot = tracker.recordNoBid(11)
+	require.False(t, isNewSlot)
+}
+
+func TestGetHeaderNoBidMetric(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.metrics = newRelayMetrics()
+	backend.relay.bidCache = newBidCache(10)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{GenesisTime: uint64(time.Now().UTC().Unix())}, //nolint:exhaustruct
+	}
+
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	getHeader := func(slot uint64) int {
+		path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey)
+		return backend.request(http.MethodGet, path, nil).Code
+	}
+
+	require.Equal(t, http.StatusNoContent, getHeader(1))
+	require.Equal(t, http.StatusNoContent, getHeader(1))
+	require.Equal(t, http.StatusNoContent, getHeader(2))
+
+	opts := common.CreateTestBlockSubmissionOpts{Slot: 3, ParentHash: parentHash, ProposerPubkey: proposerPubkey} //nolint:exhaustruct
+	_, _, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(1), &opts)
+	backend.relay.bidCache.set(3, parentHash, proposerPubkey, getHeaderResp)
+	require.Equal(t, http.StatusOK, getHeader(3))
+	require.Equal(t, 0, backend.relay.noBids.consecutive)
+
+	rr := backend.request(http.MethodGet, pathMetrics, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), "relay_getheader_no_bid_slots_total 2")
+}
+
+func TestGetHeaderNoBidResponse(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.bidCache = newBidCache(10)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{GenesisTime: uint64(time.Now().UTC().Unix())}, //nolint:exhaustruct
+	}
+
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", 1, parentHash, proposerPubkey)
+
+	// 204 by default
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Empty(t, rr.Body.String())
+
+	// asked for with the header
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{HeaderNoBidResponse: "1"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := new(NoBidResponse)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, NoBidResponse{Slot: 1, Reason: noBidReasonNoBid, Message: noBidMessage}, *resp)
+
+	// asked for with the query parameter
+	backend.relay.maintenanceMode.Store(true)
+	rr = backend.request(http.MethodGet, path+"?no_bid_response=1", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, noBidReasonMaintenance, resp.Reason)
+
+	// other requests still get 204
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
@@ -23,6 +23,8 @@ import (
 	"github.com/flashbots/mev-boost-relay/database"
 	"github.com/flashbots/mev-boost-relay/datastore"
 	"github.com/holiman/uint256"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
 )
 
@@ -306,12 +308,13 @@ func TestPrepareBuildersForSlot(t *testing.T) {
 
 func TestBuilderApiSubmitNewBlockOptimistic(t *testing.T) {
 	testCases := []struct {
-		description     string
-		wantStatus      common.BuilderStatus
-		simulationError error
-		expectDemotion  bool
-		httpCode        uint64
-		blockValue      uint64
+		description        string
+		wantStatus         common.BuilderStatus
+		optimisticDisabled bool
+		simulationError    error
+		expectDemotion     bool
+		httpCode           uint64
+		blockValue         uint64
 	}{
 		{
 			description: "success_value_less_than_collateral",
@@ -346,11 +349,35 @@ func TestBuilderApiSubmitNewBlockOptimistic(t *testing.T) {
 			httpCode:        400, // failure (in pessimistic mode, block sim failure happens in response path)
 			blockValue:      collateral + 1,
 		},
+		{
+			description: "failure_value_less_than_collateral",
+			wantStatus: common.BuilderStatus{
+				IsOptimistic: false,
+				IsHighPrio:   true,
+			},
+			simulationError: errFake,
+			expectDemotion:  true,
+			httpCode:        200, // accepted, the simulation fails asynchronously and demotes the builder
+			blockValue:      collateral - 1,
+		},
+		{
+			description: "failure_optimistic_mode_disabled",
+			wantStatus: common.BuilderStatus{
+				IsOptimistic: true,
+				IsHighPrio:   true,
+			},
+			optimisticDisabled: true,
+			simulationError:    errFake,
+			expectDemotion:     false,
+			httpCode:           400, // simulated in the response path, like builders without collateral
+			blockValue:         collateral - 1,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
 			pubkey, secretkey, backend := startTestBackend(t)
+			backend.relay.opts.Optimistic = !tc.optimisticDisabled
 			backend.relay.optimisticSlot.Store(slot)
 			backend.relay.capellaEpoch = 1
 			var randaoHash boostTypes.Hash
@@ -391,6 +418,33 @@ func TestBuilderApiSubmitNewBlockOptimistic(t *testing.T) {
 	}
 }
 
+func TestBuilderApiSubmitNewBlockWithoutSimulation(t *testing.T) {
+	pubkey, secretkey, backend := startTestBackend(t)
+	backend.relay.capellaEpoch = 1
+	var randaoHash boostTypes.Hash
+	err := randaoHash.FromSlice([]byte(randao))
+	require.NoError(t, err)
+	withRoot, err := ComputeWithdrawalsRoot([]*consensuscapella.Withdrawal{})
+	require.NoError(t, err)
+	backend.relay.payloadAttributes[emptyHash] = payloadAttributesHelper{
+		slot:            slot,
+		withdrawalsRoot: withRoot,
+		payloadAttributes: beaconclient.PayloadAttributes{
+			PrevRandao: randaoHash.String(),
+		},
+	}
+
+	// Without a block simulation URL, the submission is accepted without simulation
+	backend.relay.blockSimRateLimiter = nil
+	req := common.TestBuilderSubmitBlockRequest(secretkey, getTestBidTrace(*pubkey, collateral+1))
+	rr := backend.request(http.MethodPost, pathSubmitNewBlock, &req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	bid, err := backend.relay.redis.GetBidTrace(slot, req.ProposerPubkey(), req.BlockHash())
+	require.NoError(t, err)
+	require.NotNil(t, bid)
+}
+
 func TestInternalBuilderStatus(t *testing.T) {
 	pubkey, _, backend := startTestBackend(t)
 	// Set all to false initially.
@@ -418,6 +472,38 @@ func TestInternalBuilderStatus(t *testing.T) {
 	setAndGetStatus("?optimistic=true", common.BuilderStatus{IsHighPrio: true, IsBlacklisted: true, IsOptimistic: true})
 }
 
+func TestInternalBuilderBlacklist(t *testing.T) {
+	pubkey, _, backend := startTestBackend(t)
+	backend.relay.opts.AdminToken = "secret"
+	path := "/internal/v1/builder/blacklist"
+
+	// Requires the admin token.
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	rr = backend.requestBytes(http.MethodPost, "/internal/v1/builder/"+pubkey.String()+"?blacklisted=true", nil, map[string]string{"Authorization": "Bearer wrong"})
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	authHeaders := map[string]string{"Authorization": "Bearer secret"}
+	listBlacklist := func() []*database.BlockBuilderEntry {
+		rr := backend.requestBytes(http.MethodGet, path, nil, authHeaders)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := []*database.BlockBuilderEntry{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp
+	}
+	require.Len(t, listBlacklist(), 0)
+
+	rr = backend.requestBytes(http.MethodPost, "/internal/v1/builder/"+pubkey.String()+"?blacklisted=true", nil, authHeaders)
+	require.Equal(t, http.StatusOK, rr.Code)
+	blacklist := listBlacklist()
+	require.Len(t, blacklist, 1)
+	require.Equal(t, pubkey.String(), blacklist[0].BuilderPubkey)
+
+	rr = backend.requestBytes(http.MethodPost, "/internal/v1/builder/"+pubkey.String()+"?blacklisted=false", nil, authHeaders)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, listBlacklist(), 0)
+}
+
 func TestInternalBuilderCollateral(t *testing.T) {
 	pubkey, _, backend := startTestBackend(t)
 	path := "/internal/v1/builder/collateral/" + pubkey.String()
@@ -434,3 +520,24 @@ func TestInternalBuilderCollateral(t *testing.T) {
 	require.Equal(t, resp.BuilderID, "builder0x69")
 	require.Equal(t, resp.Collateral, "10000")
 }
+
+func TestWarnOptimisticBuildersDisabled(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	logger, hook := logtest.NewNullLogger()
+	backend.relay.log = logrus.NewEntry(logger)
+
+	backend.relay.db = &database.MockDB{Builders: map[string]*database.BlockBuilderEntry{
+		"0x01": {IsOptimistic: false}, //nolint:exhaustruct
+	}}
+	backend.relay.warnOptimisticBuildersDisabled()
+	require.Empty(t, hook.AllEntries())
+
+	backend.relay.db = &database.MockDB{Builders: map[string]*database.BlockBuilderEntry{
+		"0x01": {IsOptimistic: false}, //nolint:exhaustruct
+		"0x02": {IsOptimistic: true},  //nolint:exhaustruct
+	}}
+	backend.relay.warnOptimisticBuildersDisabled()
+	require.Len(t, hook.AllEntries(), 1)
+	require.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+	require.Contains(t, hook.LastEntry().Message, "1 builders are optimistic")
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/flashbots/mev-boost-relay/common"
+)
+
+type deliveredPayloadEntry struct {
+	slot    uint64
+	payload *common.VersionedExecutionPayload
+}
+
+// deliveredPayloadCache remembers the payloads delivered through getPayload, so that a retry of the same request
+// (i.e. after a timeout in mev-boost) gets the same response without publishing the block again. Entries of past
+// slots are removed when the head slot advances.
+type deliveredPayloadCache struct {
+	mu      sync.Mutex
+	entries map[string]*deliveredPayloadEntry
+}
+
+func newDeliveredPayloadCache() *deliveredPayloadCache {
+	return &deliveredPayloadCache{
+		entries: make(map[string]*deliveredPayloadEntry),
+	}
+}
+
+func deliveredPayloadKey(slot uint64, proposerPubkey, blockHash string) string {
+	return fmt.Sprintf("%d_%s_%s", slot, strings.ToLower(proposerPubkey), strings.ToLower(blockHash))
+}
+
+func (c *deliveredPayloadCache) get(slot uint64, proposerPubkey, blockHash string) (*common.VersionedExecutionPayload, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[deliveredPayloadKey(slot, proposerPubkey, blockHash)]
+	if !found {
+		return nil, false
+	}
+	return entry.payload, true
+}
+
+func (c *deliveredPayloadCache) set(slot uint64, proposerPubkey, blockHash string, payload *common.VersionedExecutionPayload) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[deliveredPayloadKey(slot, proposerPubkey, blockHash)] = &deliveredPayloadEntry{slot: slot, payload: payload}
+}
+
+// pruneBefore removes all entries for slots before the given one
+func (c *deliveredPayloadCache) pruneBefore(slot uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if entry.slot < slot {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *deliveredPayloadCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
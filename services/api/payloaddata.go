@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/flashbots/mev-boost-relay/datastore"
+)
+
+// handleDataPayload serves the execution payload the relay delivered for a block hash, from the datastore. Payloads
+// which were pruned (or never stored) are 404, like block hashes which weren't delivered.
+func (api *RelayAPI) handleDataPayload(w http.ResponseWriter, req *http.Request) {
+	blockHash := req.URL.Query().Get("block_hash")
+	if blockHash == "" {
+		api.RespondError(w, http.StatusBadRequest, "missing block_hash argument")
+		return
+	}
+	var hash boostTypes.Hash
+	if err := hash.UnmarshalText([]byte(blockHash)); err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid block_hash argument")
+		return
+	}
+	blockHash = strings.ToLower(blockHash)
+
+	deliveredPayloads, err := api.db.GetRecentDeliveredPayloads(database.GetPayloadsFilters{BlockHash: blockHash, Limit: 1}) //nolint:exhaustruct
+	if err != nil {
+		api.log.WithError(err).Error("error getting delivered payload")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if len(deliveredPayloads) == 0 {
+		api.RespondErrorCode(w, http.StatusNotFound, ErrorCodePayloadNotFound, "no payload delivered for this block hash")
+		return
+	}
+
+	delivered := deliveredPayloads[0]
+	payload, err := api.datastore.GetGetPayloadResponse(delivered.Slot, delivered.ProposerPubkey, delivered.BlockHash)
+	if errors.Is(err, datastore.ErrExecutionPayloadNotFound) {
+		api.RespondErrorCode(w, http.StatusNotFound, ErrorCodePayloadNotFound, "execution payload not stored anymore")
+		return
+	} else if err != nil {
+		api.log.WithError(err).WithField("blockHash", blockHash).Error("error getting execution payload")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	api.RespondOK(w, payload)
+}
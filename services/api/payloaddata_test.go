@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+type payloadDataTestDB struct {
+	database.MockDB
+	delivered []*database.DeliveredPayloadEntry
+}
+
+func (db payloadDataTestDB) GetRecentDeliveredPayloads(filters database.GetPayloadsFilters) ([]*database.DeliveredPayloadEntry, error) {
+	entries := []*database.DeliveredPayloadEntry{}
+	for _, entry := range db.delivered {
+		if entry.BlockHash == filters.BlockHash {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func TestDataApiPayload(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	slot := uint64(42)
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	prunedBlockHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+
+	execPayload := new(consensuscapella.ExecutionPayload)
+	common.LoadGzippedJSON(t, "../../testdata/executionPayloadCapella_Goerli.json.gz", execPayload)
+	blockHash := execPayload.BlockHash.String()
+	tx := backend.redis.NewPipeline()
+	require.NoError(t, backend.redis.SaveExecutionPayloadCapella(context.Background(), tx, slot, proposerPubkey, blockHash, execPayload))
+	_, err := tx.Exec(context.Background())
+	require.NoError(t, err)
+
+	backend.relay.db = payloadDataTestDB{delivered: []*database.DeliveredPayloadEntry{
+		{Slot: slot, ProposerPubkey: proposerPubkey, BlockHash: blockHash},
+		{Slot: slot - 1, ProposerPubkey: proposerPubkey, BlockHash: prunedBlockHash},
+	}}
+
+	// Not served without a token
+	rr := backend.request(http.MethodGet, pathDataPayload+"?block_hash="+blockHash, nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	backend.relay.opts.PayloadDataToken = "secret"
+	auth := map[string]string{"Authorization": "Bearer secret"}
+	rr = backend.requestBytes(http.MethodGet, pathDataPayload+"?block_hash="+blockHash, nil, nil)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = backend.requestBytes(http.MethodGet, pathDataPayload+"?block_hash=0x1234", nil, auth)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = backend.requestBytes(http.MethodGet, pathDataPayload+"?block_hash="+blockHash, nil, auth)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := new(common.VersionedExecutionPayload)
+	require.NoError(t, resp.UnmarshalJSON(rr.Body.Bytes()))
+	require.NotNil(t, resp.Capella)
+	require.Equal(t, blockHash, resp.Capella.Capella.BlockHash.String())
+
+	// Delivered, but the payload was pruned
+	rr = backend.requestBytes(http.MethodGet, pathDataPayload+"?block_hash="+prunedBlockHash, nil, auth)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodePayloadNotFound))
+
+	// Not delivered
+	rr = backend.requestBytes(http.MethodGet, pathDataPayload+"?block_hash=0x"+strings.Repeat("00", 32), nil, auth)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
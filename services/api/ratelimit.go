@@ -0,0 +1,78 @@
+package api
+
+import (
+	"golang.org/x/time/rate"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	// how long an IP can stay idle before its token bucket is dropped
+	ipRateLimiterIdleExpiry      = 3 * time.Minute
+	ipRateLimiterCleanupInterval = time.Minute
+)
+
+type ipRateLimiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// ipRateLimiter is a token-bucket rate limiter keyed by client IP
+type ipRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu          sync.Mutex
+	entries     map[string]*ipRateLimiterEntry
+	lastCleanup time.Time
+}
+
+func newIPRateLimiter(requestsPerSecond float64, burst int) *ipRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &ipRateLimiter{
+		limit:       rate.Limit(requestsPerSecond),
+		burst:       burst,
+		entries:     make(map[string]*ipRateLimiterEntry),
+		lastCleanup: time.Now(),
+	}
+}
+
+// allow consumes a token for the given IP. If none is available, it returns false and the duration
+// after which the next request would be allowed.
+func (l *ipRateLimiter) allow(ip string) (ok bool, retryAfter time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastCleanup) > ipRateLimiterCleanupInterval {
+		for key, entry := range l.entries {
+			if now.Sub(entry.lastSeen) > ipRateLimiterIdleExpiry {
+				delete(l.entries, key)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	entry, found := l.entries[ip]
+	if !found {
+		entry = &ipRateLimiterEntry{limiter: rate.NewLimiter(l.limit, l.burst)} //nolint:exhaustruct
+		l.entries[ip] = entry
+	}
+	entry.lastSeen = now
+
+	reservation := entry.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// retryAfterSeconds formats a duration for the Retry-After header (whole seconds, at least 1)
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+)
+
+// the registration counts are public and could be requested often, while computing them scans the registrations table
+var registrationCountsCacheDuration = time.Duration(cli.GetEnvInt("REGISTRATION_COUNTS_CACHE_SEC", 300)) * time.Second
+
+// registrationCountsCache holds the last computed registration counts. An expired cache is served while it is refreshed
+// in the background, and only requests before the first query wait for it. The zero value is ready to use.
+type registrationCountsCache struct {
+	refreshMu sync.Mutex // held during the database query, so that there's only one at a time
+
+	mu        sync.RWMutex
+	response  *ValidatorRegistrationCountsJSON
+	updatedAt time.Time
+}
+
+func (api *RelayAPI) getValidatorRegistrationCounts() (*ValidatorRegistrationCountsJSON, error) {
+	c := &api.registrationCounts
+	c.mu.RLock()
+	response, updatedAt := c.response, c.updatedAt
+	c.mu.RUnlock()
+
+	if response != nil {
+		if time.Since(updatedAt) >= registrationCountsCacheDuration && c.refreshMu.TryLock() {
+			go func() {
+				defer c.refreshMu.Unlock()
+				if _, err := api.refreshValidatorRegistrationCounts(); err != nil {
+					api.log.WithError(err).Warn("error updating validator registration counts, serving the previous ones")
+				}
+			}()
+		}
+		return response, nil
+	}
+
+	// Nothing to serve yet, wait for the query (or the one of a concurrent request)
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	c.mu.RLock()
+	response = c.response
+	c.mu.RUnlock()
+	if response != nil {
+		return response, nil
+	}
+	return api.refreshValidatorRegistrationCounts()
+}
+
+// refreshValidatorRegistrationCounts queries the registration counts and caches them. The caller holds refreshMu.
+func (api *RelayAPI) refreshValidatorRegistrationCounts() (*ValidatorRegistrationCountsJSON, error) {
+	entries, err := api.db.GetValidatorRegistrationCountsByDay()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ValidatorRegistrationCountsJSON{History: make([]ValidatorRegistrationDayCountJSON, len(entries))}
+	for i, entry := range entries {
+		response.History[i] = ValidatorRegistrationDayCountJSON{
+			Date:          entry.Day.UTC().Format("2006-01-02"),
+			NumValidators: entry.NumValidators,
+		}
+		response.NumValidators = entry.NumValidators
+	}
+	response.UpdatedAt = time.Now().UTC()
+
+	c := &api.registrationCounts
+	c.mu.Lock()
+	c.response = response
+	c.updatedAt = time.Now()
+	c.mu.Unlock()
+	return response, nil
+}
+
+func (api *RelayAPI) handleDataValidatorRegistrationCounts(w http.ResponseWriter, req *http.Request) {
+	response, err := api.getValidatorRegistrationCounts()
+	if err != nil {
+		api.log.WithError(err).Error("error getting validator registration counts")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	api.RespondOK(w, response)
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/database"
+	"github.com/stretchr/testify/require"
+)
+
+type registrationCountsTestDB struct {
+	database.MockDB
+	entries []*database.ValidatorRegistrationCountEntry
+	err     error
+	queries *int
+}
+
+func (db registrationCountsTestDB) GetValidatorRegistrationCountsByDay() ([]*database.ValidatorRegistrationCountEntry, error) {
+	*db.queries++
+	return db.entries, db.err
+}
+
+func TestDataApiValidatorRegistrationCounts(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	queries := 0
+	db := registrationCountsTestDB{
+		entries: []*database.ValidatorRegistrationCountEntry{
+			{Day: time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC), NumValidators: 100},
+			{Day: time.Date(2023, 9, 2, 0, 0, 0, 0, time.UTC), NumValidators: 150},
+		},
+		queries: &queries,
+	}
+	backend.relay.db = db
+
+	rr := backend.request(http.MethodGet, pathDataValidatorRegistrationCounts, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := new(ValidatorRegistrationCountsJSON)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, uint64(150), resp.NumValidators)
+	require.Equal(t, []ValidatorRegistrationDayCountJSON{
+		{Date: "2023-09-01", NumValidators: 100},
+		{Date: "2023-09-02", NumValidators: 150},
+	}, resp.History)
+	require.Contains(t, rr.Body.String(), `"num_validators":"150"`)
+
+	// Cached until it expires
+	rr = backend.request(http.MethodGet, pathDataValidatorRegistrationCounts, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, 1, queries)
+
+	// An expired cache is served right away, and refreshed in the background
+	backend.relay.registrationCounts.updatedAt = time.Now().Add(-registrationCountsCacheDuration)
+	db.entries = db.entries[:1]
+	backend.relay.db = db
+	rr = backend.request(http.MethodGet, pathDataValidatorRegistrationCounts, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"num_validators":"150"`)
+	require.Eventually(t, func() bool {
+		rr = backend.request(http.MethodGet, pathDataValidatorRegistrationCounts, nil)
+		return strings.Contains(rr.Body.String(), `"num_validators":"100"`)
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, 2, queries)
+
+	// The expired cache is still served if the refresh fails
+	backend.relay.registrationCounts.updatedAt = time.Now().Add(-registrationCountsCacheDuration)
+	db.err = errors.New("db down")
+	backend.relay.db = db
+	rr = backend.request(http.MethodGet, pathDataValidatorRegistrationCounts, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Eventually(t, func() bool {
+		backend.relay.registrationCounts.refreshMu.Lock()
+		defer backend.relay.registrationCounts.refreshMu.Unlock()
+		return queries == 3
+	}, time.Second, 10*time.Millisecond)
+
+	// and without a cache, the error is returned
+	backend.relay.registrationCounts.response = nil
+	rr = backend.request(http.MethodGet, pathDataValidatorRegistrationCounts, nil)
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+}
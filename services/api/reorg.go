@@ -0,0 +1,145 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+// reorgTrackerSlots is how many slots of head blocks are kept to detect reorgs
+var reorgTrackerSlots = 2 * common.SlotsPerEpoch
+
+type reorgBlockHash struct {
+	slot      uint64 // proposal slot of the payload attributes naming the block
+	blockHash string
+}
+
+// reorgTracker detects reorgs from the head events: another head block for an already seen slot. The execution block
+// hashes of the replaced blocks are remembered as orphaned, so that bids built on them aren't served anymore. Head
+// events only have the beacon block root, its execution block hash is known from the payload attributes (which name
+// the parent's root and hash).
+type reorgTracker struct {
+	mu          sync.Mutex
+	headSlot    uint64
+	headRoots   map[uint64]string         // slot -> beacon block root of the head
+	blockHashes map[string]reorgBlockHash // beacon block root -> execution block hash
+	orphaned    map[string]uint64         // lowercase execution block hash -> slot of the reorg
+}
+
+func newReorgTracker() *reorgTracker {
+	return &reorgTracker{
+		headRoots:   make(map[uint64]string),
+		blockHashes: make(map[string]reorgBlockHash),
+		orphaned:    make(map[string]uint64),
+	}
+}
+
+// setBlockHash records the execution block hash of a beacon block
+func (t *reorgTracker) setBlockHash(slot uint64, blockRoot, blockHash string) {
+	if blockRoot == "" || blockHash == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.blockHashes[blockRoot] = reorgBlockHash{slot: slot, blockHash: blockHash}
+}
+
+// processHeadEvent records the head block of the slot. On a reorg, it returns the replaced head roots (of the slot and
+// all later slots of the old chain) and the execution block hashes of those which are known, which are now orphaned.
+// Head events of already known heads are ignored: the events of all beacon nodes are processed, and a lagging node
+// reports heads which the chain has already moved on from.
+func (t *reorgTracker) processHeadEvent(slot uint64, blockRoot string) (oldRoots, orphanedHashes []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if headRoot, found := t.headRoots[slot]; found && headRoot == blockRoot {
+		return nil, nil
+	}
+
+	if slot <= t.headSlot {
+		for s := slot; s <= t.headSlot; s++ {
+			oldRoot, found := t.headRoots[s]
+			if !found {
+				continue
+			}
+			oldRoots = append(oldRoots, oldRoot)
+			if hash, found := t.blockHashes[oldRoot]; found {
+				t.orphaned[strings.ToLower(hash.blockHash)] = slot
+				orphanedHashes = append(orphanedHashes, hash.blockHash)
+			}
+		}
+		for s := slot + 1; s <= t.headSlot; s++ {
+			delete(t.headRoots, s)
+		}
+	}
+	if slot >= t.headSlot || len(oldRoots) > 0 {
+		t.headSlot = slot
+	}
+	t.headRoots[slot] = blockRoot
+	return oldRoots, orphanedHashes
+}
+
+func (t *reorgTracker) isOrphaned(blockHash string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, found := t.orphaned[strings.ToLower(blockHash)]
+	return found
+}
+
+// pruneBefore removes everything for slots before the given one
+func (t *reorgTracker) pruneBefore(slot uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for s := range t.headRoots {
+		if s < slot {
+			delete(t.headRoots, s)
+		}
+	}
+	for root, hash := range t.blockHashes {
+		if hash.slot < slot {
+			delete(t.blockHashes, root)
+		}
+	}
+	for hash, s := range t.orphaned {
+		if s < slot {
+			delete(t.orphaned, hash)
+		}
+	}
+}
+
+// processReorgs checks a head event for a reorg, and invalidates the payload attributes of the orphaned blocks, so
+// that submissions building on them are rejected
+func (api *RelayAPI) processReorgs(headEvent beaconclient.HeadEventData) {
+	oldRoots, orphanedHashes := api.reorgs.processHeadEvent(headEvent.Slot, headEvent.Block)
+	if len(oldRoots) == 0 {
+		return
+	}
+
+	api.log.WithFields(logrus.Fields{
+		"slot":           headEvent.Slot,
+		"oldHeadRoots":   strings.Join(oldRoots, ","),
+		"newHeadRoot":    headEvent.Block,
+		"orphanedHashes": strings.Join(orphanedHashes, ","),
+	}).Warn("reorg detected, not serving bids built on the orphaned blocks")
+
+	api.payloadAttributesLock.Lock()
+	for _, hash := range orphanedHashes {
+		delete(api.payloadAttributes, hash)
+	}
+	api.payloadAttributesLock.Unlock()
+}
+
+// refuseOrphanedParent responds to getHeader without a bid if the parent block was reorged out, and returns whether it did
+func (api *RelayAPI) refuseOrphanedParent(w http.ResponseWriter, req *http.Request, log *logrus.Entry, slot uint64, parentHash string) bool {
+	if !api.reorgs.isOrphaned(parentHash) {
+		return false
+	}
+	log.Warn("parent block was reorged out, not serving a bid")
+	api.respondNoContent(w, req, slot, noBidReasonOrphanedParent)
+	return true
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReorgTracker(t *testing.T) {
+	tracker := newReorgTracker()
+	tracker.setBlockHash(11, "0xroot10", "0xHASH10")
+	tracker.setBlockHash(12, "0xroot11", "0xhash11")
+
+	oldRoots, _ := tracker.processHeadEvent(10, "0xroot10")
+	require.Empty(t, oldRoots)
+	oldRoots, _ = tracker.processHeadEvent(11, "0xroot11")
+	require.Empty(t, oldRoots)
+
+	// The same head from another beacon node isn't a reorg
+	oldRoots, _ = tracker.processHeadEvent(11, "0xroot11")
+	require.Empty(t, oldRoots)
+
+	// Neither is the previous head from a lagging beacon node
+	oldRoots, _ = tracker.processHeadEvent(10, "0xroot10")
+	require.Empty(t, oldRoots)
+	require.False(t, tracker.isOrphaned("0xhash11"))
+	require.Equal(t, uint64(11), tracker.headSlot)
+
+	// Another block for slot 10 orphans the old chain from slot 10
+	oldRoots, orphanedHashes := tracker.processHeadEvent(10, "0xroot10b")
+	require.Equal(t, []string{"0xroot10", "0xroot11"}, oldRoots)
+	require.Equal(t, []string{"0xHASH10", "0xhash11"}, orphanedHashes)
+	require.True(t, tracker.isOrphaned("0xhash10"))
+	require.True(t, tracker.isOrphaned("0xhash11"))
+	require.False(t, tracker.isOrphaned("0xhash12"))
+
+	// The new chain continues normally
+	oldRoots, _ = tracker.processHeadEvent(11, "0xroot11b")
+	require.Empty(t, oldRoots)
+
+	tracker.pruneBefore(12)
+	require.False(t, tracker.isOrphaned("0xhash10"))
+	require.Empty(t, tracker.headRoots)
+	require.Len(t, tracker.blockHashes, 1)
+}
+
+func TestGetHeaderOrphanedParent(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.bidCache = newBidCache(10)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{GenesisTime: uint64(time.Now().UTC().Unix())}, //nolint:exhaustruct
+	}
+
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey)
+
+	opts := common.CreateTestBlockSubmissionOpts{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey} //nolint:exhaustruct
+	_, _, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(1), &opts)
+	backend.relay.bidCache.set(slot, parentHash, proposerPubkey, getHeaderResp)
+	backend.relay.payloadAttributes[parentHash] = payloadAttributesHelper{slot: slot} //nolint:exhaustruct
+	backend.relay.reorgs.setBlockHash(slot, "0xroot1", parentHash)
+
+	backend.relay.processReorgs(beaconclient.HeadEventData{Slot: 1, Block: "0xroot1"}) //nolint:exhaustruct
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// After the parent is reorged out, its payload attributes are removed and getHeader doesn't serve the bid
+	backend.relay.processReorgs(beaconclient.HeadEventData{Slot: 1, Block: "0xroot1b"}) //nolint:exhaustruct
+	require.NotContains(t, backend.relay.payloadAttributes, parentHash)
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/flashbots/go-utils/httplogger"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	maxRequestIDLength = 64
+)
+
+type requestIDContextKey struct{}
+
+// newRequestID returns a random 128-bit hex-encoded identifier
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// isValidRequestID allows reusing an incoming X-Request-ID (i.e. set by a load balancer) if it is short and printable
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// getRequestID returns the ID assigned to the request by the access-log middleware
+func getRequestID(req *http.Request) string {
+	id, _ := req.Context().Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// accessLogMiddleware assigns every request an ID, returns it in the X-Request-ID response header and
+// writes one access log line including the ID and client IP
+func (api *RelayAPI) accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestID := req.Header.Get(HeaderRequestID)
+		if !isValidRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(HeaderRequestID, requestID)
+		req = req.WithContext(context.WithValue(req.Context(), requestIDContextKey{}, requestID))
+
+		log := api.log.WithFields(logrus.Fields{
+			"requestID": requestID,
+			"ip":        api.getClientIP(req),
+		})
+		httplogger.LoggingMiddlewareLogrus(log, next).ServeHTTP(w, req)
+	})
+}
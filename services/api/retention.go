@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flashbots/go-utils/cli"
+	"github.com/sirupsen/logrus"
+)
+
+var retentionPruneInterval = time.Duration(cli.GetEnvInt("RETENTION_PRUNE_INTERVAL_SEC", 60)) * time.Second
+
+// retentionArchiveFile is the file in RetentionArchiveDir which the pruned bid traces are appended to
+const retentionArchiveFile = "bidtraces.jsonl"
+
+// startRetentionPruning periodically deletes the data of slots older than RetentionSlots from Redis, to keep its memory
+// bounded even if keys are left without expiry. Delivered payloads and block submissions are stored in the database.
+func (api *RelayAPI) startRetentionPruning() {
+	api.log.Infof("pruning redis data older than %d slots every %s", api.opts.RetentionSlots, retentionPruneInterval)
+	ticker := time.NewTicker(retentionPruneInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		api.pruneRetention()
+	}
+}
+
+// pruneRetention deletes the data of slots before headSlot - RetentionSlots. The instances sharing a Redis take turns:
+// whichever takes the lock prunes, and holds it for half the interval, so Redis is scanned once or twice per interval
+// however many instances there are.
+func (api *RelayAPI) pruneRetention() {
+	headSlot := api.headSlot.Load()
+	if headSlot <= api.opts.RetentionSlots {
+		return
+	}
+	beforeSlot := headSlot - api.opts.RetentionSlots
+	log := api.log.WithFields(logrus.Fields{
+		"headSlot":   headSlot,
+		"beforeSlot": beforeSlot,
+	})
+
+	locked, err := api.redis.TryLockPruning(context.Background(), retentionPruneInterval/2)
+	if err != nil {
+		log.WithError(err).Error("failed to take the pruning lock, not pruning")
+		return
+	} else if !locked {
+		log.Debug("another instance is pruning redis")
+		return
+	}
+
+	var archive io.Writer // nil interface if not archiving
+	if api.opts.RetentionArchiveDir != "" {
+		f, err := os.OpenFile(filepath.Join(api.opts.RetentionArchiveDir, retentionArchiveFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.WithError(err).Error("failed to open the retention archive, not pruning")
+			return
+		}
+		defer f.Close()
+		archive = f
+	}
+
+	start := time.Now()
+	numDeleted, err := api.redis.PruneSlotsBefore(context.Background(), beforeSlot, archive)
+	log = log.WithFields(logrus.Fields{
+		"numDeleted": numDeleted,
+		"durationMs": time.Since(start).Milliseconds(),
+	})
+	if err != nil {
+		log.WithError(err).Error("failed to prune old slots from redis")
+		return
+	}
+	if numDeleted > 0 {
+		log.Info("pruned old slots from redis")
+	} else {
+		log.Debug("no old slots to prune from redis")
+	}
+}
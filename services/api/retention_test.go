@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pruningRedis records the slots passed to PruneSlotsBefore, and archives one line per call. The pruning lock is held
+// by another instance if lockedElsewhere is set.
+type pruningRedis struct {
+	relayRedis
+	beforeSlots     []uint64
+	lockedElsewhere bool
+}
+
+func (r *pruningRedis) TryLockPruning(ctx context.Context, ttl time.Duration) (bool, error) {
+	return !r.lockedElsewhere, nil
+}
+
+func (r *pruningRedis) PruneSlotsBefore(ctx context.Context, slot uint64, archive io.Writer) (int, error) {
+	r.beforeSlots = append(r.beforeSlots, slot)
+	if archive != nil {
+		if _, err := io.WriteString(archive, "{}\n"); err != nil {
+			return 0, err
+		}
+	}
+	return 1, nil
+}
+
+func TestPruneRetention(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	redis := &pruningRedis{relayRedis: backend.relay.redis} //nolint:exhaustruct
+	backend.relay.redis = redis
+	backend.relay.opts.RetentionSlots = 32
+
+	// Nothing to prune before the retention is reached
+	backend.relay.headSlot.Store(32)
+	backend.relay.pruneRetention()
+	require.Empty(t, redis.beforeSlots)
+
+	backend.relay.headSlot.Store(100)
+	backend.relay.pruneRetention()
+	require.Equal(t, []uint64{68}, redis.beforeSlots)
+
+	// Another instance is pruning
+	redis.lockedElsewhere = true
+	backend.relay.pruneRetention()
+	require.Equal(t, []uint64{68}, redis.beforeSlots)
+	redis.lockedElsewhere = false
+
+	// The archive is appended to
+	backend.relay.opts.RetentionArchiveDir = t.TempDir()
+	backend.relay.pruneRetention()
+	backend.relay.pruneRetention()
+	archive, err := os.ReadFile(filepath.Join(backend.relay.opts.RetentionArchiveDir, retentionArchiveFile))
+	require.NoError(t, err)
+	require.Equal(t, "{}\n{}\n", string(archive))
+}
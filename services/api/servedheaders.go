@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+type servedHeaderEntry struct {
+	slot       uint64
+	parentHash string
+	bid        *common.GetHeaderResponse
+	fedBid     *federatedBid // set if the bid is from a peer relay
+}
+
+// servedHeaderCache remembers the first header served to each proposer in a slot, so that they're never served a
+// second, different header for the slot (which they might sign as well). Entries of past slots are removed when the
+// head slot advances. The headers are kept in memory, so it only works if a proposer's requests reach the same instance.
+type servedHeaderCache struct {
+	mu      sync.Mutex
+	entries map[string]*servedHeaderEntry
+}
+
+func newServedHeaderCache() *servedHeaderCache {
+	return &servedHeaderCache{
+		entries: make(map[string]*servedHeaderEntry),
+	}
+}
+
+func servedHeaderKey(slot uint64, proposerPubkey string) string {
+	return fmt.Sprintf("%d_%s", slot, strings.ToLower(proposerPubkey))
+}
+
+func (c *servedHeaderCache) get(slot uint64, proposerPubkey string) (*servedHeaderEntry, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[servedHeaderKey(slot, proposerPubkey)]
+	return entry, found
+}
+
+// getOrSet stores the entry as the served header of the slot, unless there already is one, and returns the stored one
+func (c *servedHeaderCache) getOrSet(proposerPubkey string, entry *servedHeaderEntry) *servedHeaderEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := servedHeaderKey(entry.slot, proposerPubkey)
+	if existing, found := c.entries[key]; found {
+		return existing
+	}
+	c.entries[key] = entry
+	return entry
+}
+
+// pruneBefore removes all entries for slots before the given one
+func (c *servedHeaderCache) pruneBefore(slot uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if entry.slot < slot {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// respondServedHeader responds to getHeader with the header which was already served in the slot, or with 204 if the
+// request is for another parent (i.e. after a reorg), since the proposer must not get a second header
+func (api *RelayAPI) respondServedHeader(w http.ResponseWriter, req *http.Request, log *logrus.Entry, served *servedHeaderEntry, parentHash string) {
+	log = log.WithFields(logrus.Fields{
+		"servedBlockHash":  served.bid.BlockHash().String(),
+		"servedParentHash": served.parentHash,
+	})
+	if !strings.EqualFold(served.parentHash, parentHash) {
+		log.Warn("refusing getHeader for another parent, a header was already served to the proposer in this slot")
+		api.respondNoContent(w, req, served.slot, noBidReasonHeaderServed)
+		return
+	}
+	log.Info("serving the header already served to the proposer in this slot")
+	api.RespondOK(w, served.bid)
+}
+
+func (c *servedHeaderCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
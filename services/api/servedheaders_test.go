@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServedHeaderCache(t *testing.T) {
+	cache := newServedHeaderCache()
+	first := &servedHeaderEntry{slot: 10, parentHash: "0x01"}  //nolint:exhaustruct
+	second := &servedHeaderEntry{slot: 10, parentHash: "0x01"} //nolint:exhaustruct
+
+	require.Same(t, first, cache.getOrSet("0xAB", first))
+	require.Same(t, first, cache.getOrSet("0xab", second))
+	served, found := cache.get(10, "0xab")
+	require.True(t, found)
+	require.Same(t, first, served)
+
+	// Other proposers and slots are separate
+	_, found = cache.get(10, "0xcd")
+	require.False(t, found)
+	next := &servedHeaderEntry{slot: 11} //nolint:exhaustruct
+	require.Same(t, next, cache.getOrSet("0xab", next))
+
+	cache.pruneBefore(11)
+	_, found = cache.get(10, "0xab")
+	require.False(t, found)
+
+	// Only one of concurrent first requests is served
+	var wg sync.WaitGroup
+	results := make([]*servedHeaderEntry, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = cache.getOrSet("0xef", &servedHeaderEntry{slot: 12}) //nolint:exhaustruct
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range results {
+		require.Same(t, results[0], result)
+	}
+}
+
+func TestGetHeaderSingleHeaderPerSlot(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.servedHeaders = newServedHeaderCache()
+	backend.relay.bidCache = newBidCache(10)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{GenesisTime: uint64(time.Now().UTC().Unix())}, //nolint:exhaustruct
+	}
+
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	otherParentHash := "0x23e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey)
+
+	setBid := func(parentHash string, value int64) {
+		opts := common.CreateTestBlockSubmissionOpts{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey} //nolint:exhaustruct
+		_, _, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(value), &opts)
+		backend.relay.bidCache.set(slot, parentHash, proposerPubkey, getHeaderResp)
+	}
+	getHeaderValue := func(path string) string {
+		rr := backend.request(http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := common.GetHeaderResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		return resp.Value().String()
+	}
+
+	setBid(parentHash, 99)
+	require.Equal(t, "99", getHeaderValue(path))
+
+	// A higher bid arriving later isn't served
+	setBid(parentHash, 200)
+	require.Equal(t, "99", getHeaderValue(path))
+
+	// Neither is a header for another parent
+	setBid(otherParentHash, 300)
+	otherPath := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, otherParentHash, proposerPubkey)
+	rr := backend.request(http.MethodGet, otherPath, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	rr = backend.requestBytes(http.MethodGet, otherPath, nil, map[string]string{HeaderNoBidResponse: "1"})
+	require.Equal(t, http.StatusOK, rr.Code)
+	noBidResp := new(NoBidResponse)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), noBidResp))
+	require.Equal(t, noBidReasonHeaderServed, noBidResp.Reason)
+
+	// The next slot starts over
+	backend.relay.servedHeaders.pruneBefore(slot + 1)
+	require.Equal(t, "200", getHeaderValue(path))
+}
@@ -5,12 +5,15 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/subtle"
+	"crypto/x509"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
@@ -20,15 +23,14 @@ import (
 	"sync"
 	"time"
 
-	"github.com/NYTimes/gziphandler"
 	builderCapella "github.com/attestantio/go-builder-client/api/capella"
 	"github.com/attestantio/go-eth2-client/api/v1/capella"
+	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/buger/jsonparser"
 	"github.com/flashbots/go-boost-utils/bls"
 	boostTypes "github.com/flashbots/go-boost-utils/types"
 	"github.com/flashbots/go-utils/cli"
-	"github.com/flashbots/go-utils/httplogger"
 	"github.com/flashbots/mev-boost-relay/beaconclient"
 	"github.com/flashbots/mev-boost-relay/common"
 	"github.com/flashbots/mev-boost-relay/database"
@@ -44,6 +46,11 @@ const (
 	ErrBlockAlreadyKnown  = "simulation failed: block already known"
 	ErrBlockRequiresReorg = "simulation failed: block requires a reorg"
 	ErrMissingTrieNode    = "missing trie node"
+
+	// getHeader debug headers, only sent with opts.DebugHeaders
+	HeaderBuilderPubkey = "X-MEVBoost-Builder-Pubkey"
+	HeaderBidValue      = "X-MEVBoost-Bid-Value"
+	HeaderNoBidResponse = "X-MEVBoost-No-Bid-Response"
 )
 
 var (
@@ -55,6 +62,10 @@ var (
 	ErrBuilderAPIWithoutSecretKey = errors.New("cannot start builder API without secret key")
 	ErrMismatchedForkVersions     = errors.New("can not find matching fork versions as retrieved from beacon node")
 	ErrMissingForkVersions        = errors.New("invalid fork version from beacon node")
+	ErrMismatchedGenesisTime      = errors.New("genesis time of the network config does not match the beacon node's")
+	ErrGetPayloadTimeout          = errors.New("timeout loading getPayload response")
+	ErrMissingBidTrace            = errors.New("no bid trace for the block")
+	ErrPprofWithoutToken          = errors.New("pprof needs a token, unless it listens on a loopback address")
 )
 
 var (
@@ -64,18 +75,37 @@ var (
 	pathGetHeader         = "/eth/v1/builder/header/{slot:[0-9]+}/{parent_hash:0x[a-fA-F0-9]+}/{pubkey:0x[a-fA-F0-9]+}"
 	pathGetPayload        = "/eth/v1/builder/blinded_blocks"
 
+	// Liveness and readiness probes
+	pathLivez  = "/livez"
+	pathReadyz = "/readyz"
+
+	// Relay pubkey, network and version
+	pathRelayStatus = "/relay/v1/status"
+
 	// Block builder API
 	pathBuilderGetValidators = "/relay/v1/builder/validators"
 	pathSubmitNewBlock       = "/relay/v1/builder/blocks"
+	pathBuilderBidsStream    = "/relay/v1/builder/bids/stream"
+	pathBuilderCancelBid     = "/relay/v1/builder/bids/cancel"
 
 	// Data API
-	pathDataProposerPayloadDelivered = "/relay/v1/data/bidtraces/proposer_payload_delivered"
-	pathDataBuilderBidsReceived      = "/relay/v1/data/bidtraces/builder_blocks_received"
-	pathDataValidatorRegistration    = "/relay/v1/data/validator_registration"
+	pathDataProposerPayloadDelivered    = "/relay/v1/data/bidtraces/proposer_payload_delivered"
+	pathDataBuilderBidsReceived         = "/relay/v1/data/bidtraces/builder_blocks_received"
+	pathDataValidatorRegistration       = "/relay/v1/data/validator_registration"
+	pathDataValidatorRegistrationCounts = "/relay/v1/data/validator_registration_counts"
+	pathDataBuilderStats                = "/relay/v1/data/builder_stats"
+	pathDataBidHistory                  = "/relay/v1/data/bid_history"
+	pathDataBidDecisions                = "/relay/v1/data/bid_decisions"
+	pathDataPayload                     = "/relay/v1/data/payload"
 
 	// Internal API
 	pathInternalBuilderStatus     = "/internal/v1/builder/{pubkey:0x[a-fA-F0-9]+}"
 	pathInternalBuilderCollateral = "/internal/v1/builder/collateral/{pubkey:0x[a-fA-F0-9]+}"
+	pathInternalBuilderBlacklist  = "/internal/v1/builder/blacklist"
+	pathInternalMaintenance       = "/internal/v1/maintenance"
+
+	// Debug API
+	pathDebugBestBid = "/relay/v1/debug/bid/{slot:[0-9]+}/{parent_hash:0x[a-fA-F0-9]+}/{pubkey:0x[a-fA-F0-9]+}"
 
 	// number of goroutines to save active validator
 	numValidatorRegProcessors = cli.GetEnvInt("NUM_VALIDATOR_REG_PROCESSORS", 10)
@@ -83,15 +113,21 @@ var (
 	// various timings
 	timeoutGetPayloadRetryMs  = cli.GetEnvInt("GETPAYLOAD_RETRY_TIMEOUT_MS", 100)
 	getHeaderRequestCutoffMs  = cli.GetEnvInt("GETHEADER_REQUEST_CUTOFF_MS", 3000)
-	getPayloadRequestCutoffMs = cli.GetEnvInt("GETPAYLOAD_REQUEST_CUTOFF_MS", 4000)
+	getHeaderMaxWaitMs        = cli.GetEnvInt("GETHEADER_MAX_WAIT_MS", 0)
+	getHeaderWaitUntilMs      = cli.GetEnvInt("GETHEADER_WAIT_UNTIL_MS", 500)
+	getHeaderMaxEarlyMs       = cli.GetEnvInt("GETHEADER_DEADLINE_MAX_EARLY_MS", 1000)
+	getHeaderMaxBackgroundOps = cli.GetEnvInt("GETHEADER_MAX_BACKGROUND_OPS", 1000) // served bids recorded concurrently, more are dropped
 	getPayloadResponseDelayMs = cli.GetEnvInt("GETPAYLOAD_RESPONSE_DELAY_MS", 1000)
+	getPayloadTimeoutGraceMs  = cli.GetEnvInt("GETPAYLOAD_TIMEOUT_GRACE_MS", 1000)
+	getPayloadDefaultTimeout  = 2 * time.Second
 
 	// api settings
-	apiReadTimeoutMs       = cli.GetEnvInt("API_TIMEOUT_READ_MS", 1500)
-	apiReadHeaderTimeoutMs = cli.GetEnvInt("API_TIMEOUT_READHEADER_MS", 600)
-	apiWriteTimeoutMs      = cli.GetEnvInt("API_TIMEOUT_WRITE_MS", 10000)
-	apiIdleTimeoutMs       = cli.GetEnvInt("API_TIMEOUT_IDLE_MS", 3000)
-	apiMaxHeaderBytes      = cli.GetEnvInt("API_MAX_HEADER_BYTES", 60000)
+	apiMaxHeaderBytes    = cli.GetEnvInt("API_MAX_HEADER_BYTES", 60000)
+	apiShutdownTimeoutMs = cli.GetEnvInt("API_SHUTDOWN_TIMEOUT_MS", 30000)
+	readyzRedisTimeoutMs = cli.GetEnvInt("READYZ_REDIS_TIMEOUT_MS", 500)
+
+	// registrations can be this far ahead of our clock, to allow for clock skew of the validator
+	registrationTimestampMaxSkewSec = cli.GetEnvInt("REGISTRATION_TIMESTAMP_MAX_SKEW_SEC", 10)
 
 	// maximum payload bytes for a block submission to be fast-tracked (large payloads slow down other fast-tracked requests!)
 	fastTrackPayloadSizeLimit = cli.GetEnvInt("FAST_TRACK_PAYLOAD_SIZE_LIMIT", 230_000)
@@ -104,19 +140,56 @@ var (
 
 // RelayAPIOpts contains the options for a relay
 type RelayAPIOpts struct {
-	Log *logrus.Entry
+	Log     *logrus.Entry
+	Version string // reported by the status endpoint
 
-	ListenAddr  string
+	ListenAddr  string // host:port, or unix:/path/to/sock for a Unix domain socket
 	BlockSimURL string
 
+	// File permissions of the Unix domain socket (default: 0660)
+	UnixSocketMode os.FileMode
+
+	// Terminate TLS with this certificate and key (both or none), reloaded with ReloadTLSCertificate
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// Require block submissions to present a client certificate signed by a CA of this bundle, with the builder pubkey
+	// or builder ID as common name (requires TLS)
+	BuilderCAFile string
+
 	BeaconClient beaconclient.IMultiBeaconClient
 	Datastore    *datastore.Datastore
 	Redis        *datastore.RedisCache
 	Memcached    *datastore.Memcached
 	DB           database.IDatabaseService
 
+	// Sync status of the beacon nodes if already waited for (i.e. by the startup checks), so StartServer doesn't wait
+	// again
+	SyncStatus *beaconclient.SyncStatusPayloadData
+
 	SecretKey *bls.SecretKey // used to sign bids (getHeader responses)
 
+	// Maximum time for loading a getPayload response before alerting (default: 2s)
+	GetPayloadTimeout time.Duration
+
+	// Timeouts of the API server (defaults: DefaultReadTimeout etc.). The write timeout needs to be longer than the
+	// getHeader wait (GETHEADER_MAX_WAIT_MS) and the SubmissionBatchWindow, plus the time to write the response.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// Refuse getPayload requests arriving later than this into the slot, so the proposer falls back to a local block
+	// instead of publishing ours too late (0 to disable)
+	GetPayloadCutoffMs int
+
+	// Reject submissions arriving earlier than this into the slot before the submission's slot, which are likely built
+	// on a stale parent because the slot's block isn't there yet (0 to disable)
+	SubmitStartMs int
+
+	// Minimum bid value for getHeader to return a bid, applied to the best bid of a slot regardless of builder (nil to disable)
+	MinBidValue *big.Int
+
 	// Network specific variables
 	EthNetDetails common.EthNetworkDetails
 
@@ -126,6 +199,129 @@ type RelayAPIOpts struct {
 	DataAPI         bool
 	PprofAPI        bool
 	InternalAPI     bool
+
+	// Number of best bids kept in memory for getHeader (0 to disable). Only bids submitted to this instance are cached.
+	BidCacheSize int
+
+	// Pubkeys of previous signing keys, accepted in place of the current one to rotate the key without downtime
+	PreviousPublicKeys []boostTypes.PublicKey
+
+	// Origins allowed to query the data API from the browser ("*" for any). CORS is disabled if empty.
+	CORSOrigins []string
+
+	// Serve a websocket feed of accepted block submissions (protected by AdminToken, only served if it is set)
+	BidStreamEnabled bool
+
+	// Number of workers for BLS signature verification (0 to verify in the request goroutine)
+	SigVerifyWorkers int
+
+	// How long the signature verification results of validator registrations are cached (0 to disable)
+	RegistrationSigCacheTTL time.Duration
+
+	// Maximum number of concurrent getPayload requests (0 for no limit), further requests queue briefly, then get 503
+	GetPayloadConcurrency int
+
+	// Bounds for the gas limit of validator registrations (0 means no bound)
+	MinGasLimit uint64
+	MaxGasLimit uint64
+
+	// Add the builder pubkey and value of the served bid to getHeader responses
+	DebugHeaders bool
+
+	// Fraction of requests (0-1) whose full request and response bodies are logged
+	DebugSampleRate float64
+
+	// Validate registrations and submissions, but don't store them and don't serve bids
+	DryRun bool
+
+	// Refuse to serve bids on getHeader which don't pay the fee recipient of the proposer's registration (mismatches of
+	// delivered blocks are always logged)
+	EnforceFeeRecipient bool
+
+	// Accept submissions of optimistic builders before the simulation completes, if the value is covered by their
+	// collateral. Builders whose blocks fail the simulation are demoted.
+	Optimistic bool
+
+	// pprof requests need to provide it as bearer token, only optional if pprof listens on a loopback address
+	PprofToken string
+	// Serve pprof on a separate listen address instead of the API listen address
+	PprofListenAddr string
+
+	// If set, requests to the internal API need to provide it as bearer token
+	AdminToken string
+
+	// If set, the delivered execution payloads are served on the data API, to requests with it as bearer token
+	PayloadDataToken string
+
+	// If set, admin and pprof requests from other IPs (CIDRs or plain IPs) are rejected with 403, before the token
+	// check. X-Forwarded-For is only used for client IPs of requests of the TrustedProxies (see getClientIP).
+	AdminAllowIPs  []string
+	TrustedProxies []string
+
+	// Per-IP rate limit for validator registrations (requests per second, 0 to disable)
+	RegistrationRateLimit      float64
+	RegistrationRateLimitBurst int
+
+	// Record served bids and delivered payloads in the audit log table
+	AuditLog bool
+
+	// URL to post getPayload requests to whose execution payload wasn't found (i.e. a Slack incoming webhook). They
+	// are saved in the database regardless.
+	AlertWebhook string
+
+	// Prometheus metrics, served on the API listen address unless MetricsListenAddr is set
+	MetricsEnabled    bool
+	MetricsListenAddr string
+
+	// URLs of peer relays (https://0xPUBKEY@host) whose bids are served as well, with getPayload proxied to them
+	PeerRelayURLs []string
+
+	// Maximum request body size of block submissions (after decompression) and validator registrations, larger
+	// requests are rejected with 413 (default: DefaultMaxSubmitBytes and DefaultMaxRegistrationBytes)
+	MaxSubmitBytes       int64
+	MaxRegistrationBytes int64
+
+	// Maximum number of registrations in one registerValidator request, larger batches are rejected with 400 before
+	// any signature is verified (0: no limit besides MaxRegistrationBytes)
+	MaxRegistrationsPerRequest int
+
+	// Compress responses of at least CompressMinSize bytes for clients accepting gzip or deflate, unless
+	// DisableCompression is set (default: DefaultCompressMinSize)
+	DisableCompression bool
+	CompressMinSize    int
+
+	// Only accept submissions from these builders: a comma-separated list of pubkeys, or a file with one pubkey per line
+	// (reloaded with ReloadBuilderAllowlist). Empty to accept all builders.
+	BuilderAllowlist string
+
+	// Serve only the first served header to a proposer in a slot, even if a higher bid arrives later
+	SingleHeaderPerSlot bool
+
+	// Only serve getHeader to the proposer of the slot according to the beacon node's duties, 204 to other pubkeys.
+	// Not for setups where a proxy requests headers on behalf of validators with other pubkeys.
+	ProposerOnlyHeaders bool
+
+	// getHeader waits for more bids until this far into the slot, instead of GETHEADER_MAX_WAIT_MS after the request,
+	// and at most this long for requests before the slot start. Without a known genesis time, it waits this long.
+	GetHeaderDeadlineIntoSlot time.Duration
+
+	// Refuse registrations and getHeader requests of validators which the beacon node reports as slashed or exited
+	// (the statuses of the datastore's known validators refresh)
+	RejectSlashedValidators bool
+
+	// Collect block submissions and save them together at the end of windows of this duration, aligned to the slot
+	// start (0 disables). Submissions received after the slot start are saved right away.
+	SubmissionBatchWindow time.Duration
+
+	// Delete bids, bid traces and payloads older than this many slots from Redis (0 disables), and append the deleted
+	// bid traces to a file in RetentionArchiveDir if set
+	RetentionSlots      uint64
+	RetentionArchiveDir string
+
+	// Keep the latest BidHistorySize received bids of each slot in Redis, for BidHistoryRetentionSlots slots, served
+	// at /relay/v1/data/bid_history (0 disables)
+	BidHistorySize           int
+	BidHistoryRetentionSlots uint64
 }
 
 type payloadAttributesHelper struct {
@@ -147,6 +343,7 @@ type blockSimOptions struct {
 type blockBuilderCacheEntry struct {
 	status     common.BuilderStatus
 	collateral *big.Int
+	builderID  string
 }
 
 type blockSimResult struct {
@@ -164,18 +361,29 @@ type RelayAPI struct {
 	blsSk     *bls.SecretKey
 	publicKey *boostTypes.PublicKey
 
-	srv        *http.Server
-	srvStarted uberatomic.Bool
+	srv          *http.Server
+	srvStarted   uberatomic.Bool
+	certReloader *certReloader
+
+	builderAllowlist *builderAllowlist // nil if all builders are accepted
+	builderCAs       *x509.CertPool    // nil if submissions don't require a client certificate
+	adminAllowIPs    []*net.IPNet      // empty if admin requests are accepted from all IPs
+	trustedProxies   []*net.IPNet
 
-	beaconClient beaconclient.IMultiBeaconClient
-	datastore    *datastore.Datastore
-	redis        *datastore.RedisCache
-	memcached    *datastore.Memcached
-	db           database.IDatabaseService
+	beaconClient  beaconclient.IMultiBeaconClient
+	beaconBreaker *circuitBreaker
+	datastore     relayDatastore
+	redis         relayRedis
+	memcached     *datastore.Memcached
+	db            database.IDatabaseService
 
+	now          func() time.Time // the clock for the timing checks of requests, replaced in tests
 	headSlot     uberatomic.Uint64
+	clockSkewMs  uberatomic.Int64 // beacon node clock minus local clock, from the last clock skew check
 	genesisInfo  *beaconclient.GetGenesisResponse
 	capellaEpoch uint64
+	denebEpoch   uint64
+	forkSchedule *common.ForkSchedule // picks the proposer domain by epoch, updated from the beacon node on start
 
 	proposerDutiesLock       sync.RWMutex
 	proposerDutiesResponse   *[]byte // raw http response
@@ -183,13 +391,69 @@ type RelayAPI struct {
 	proposerDutiesSlot       uint64
 	isUpdatingProposerDuties uberatomic.Bool
 
+	// proposer duties of all validators, from the beacon node (nil if the proposer API is disabled)
+	beaconDuties *beaconDutiesCache
+
+	// concurrent getHeader requests for the same slot, parent hash and proposer share one best bid lookup
+	bidLookups bidLookupGroup
+
+	// consecutive slots for which getHeader had no bid
+	noBids noBidTracker
+
+	// head blocks and the execution block hashes orphaned by reorgs
+	reorgs *reorgTracker
+
+	submissionBatcher *submissionBatcher // nil unless SubmissionBatchWindow is set
+
 	blockSimRateLimiter IBlockSimRateLimiter
 
+	metrics *relayMetrics
+
+	auditLog         *auditLog
+	getPayloadAlerts *getPayloadAlerts // nil unless the proposer API is enabled
+	bidHistory       *bidHistoryWriter // nil unless BidHistorySize is set
+
+	registrationRateLimiter *ipRateLimiter
+
+	bidCache *bidCache
+
+	bidStream *bidStream
+
+	sigVerifier          *sigVerifier
+	registrationSigCache *sigCache // nil if disabled
+
+	getPayloadLimiter *getPayloadLimiter // nil if disabled
+
+	rejectionLogs *rejectionLogSampler // nil if disabled
+
+	deliveredPayloads  *deliveredPayloadCache
+	missedSlots        missedSlotDetector
+	registrationCounts registrationCountsCache
+
+	// first header served to each proposer in the slot (nil unless SingleHeaderPerSlot is set)
+	servedHeaders *servedHeaderCache
+
+	peerRelays                []*peerRelay
+	peerRelayClient           http.Client
+	peerRelayGetPayloadClient http.Client
+	federatedBids             *federatedBids
+
 	validatorRegC chan boostTypes.SignedValidatorRegistration
+	bidDecisionC  chan *queuedBidDecision
 
 	// used to wait on any active getPayload calls on shutdown
 	getPayloadCallsInFlight sync.WaitGroup
 
+	// number of requests currently being handled (logged on shutdown)
+	requestsInFlight uberatomic.Int64
+
+	// slots for recording served bids off the getHeader request path, and how often none was free
+	getHeaderBackgroundOps        chan struct{}
+	numGetHeaderBackgroundDropped uberatomic.Uint64
+
+	// in maintenance mode no new bids are served or accepted, but getPayload still works
+	maintenanceMode uberatomic.Bool
+
 	// Feature flags
 	ffForceGetHeader204          bool
 	ffDisableLowPrioBuilders     bool
@@ -226,6 +490,11 @@ func NewRelayAPI(opts RelayAPIOpts) (api *RelayAPI, err error) {
 		return nil, ErrMissingDatastoreOpt
 	}
 
+	// pprof exposes memory contents and can be used to load the CPU, never serve it to everyone
+	if opts.PprofAPI && opts.PprofToken == "" && !isLoopbackListenAddr(opts.PprofListenAddr) {
+		return nil, ErrPprofWithoutToken
+	}
+
 	// If block-builder API is enabled, then ensure secret key is all set
 	var publicKey boostTypes.PublicKey
 	if opts.BlockBuilderAPI {
@@ -244,11 +513,14 @@ func NewRelayAPI(opts RelayAPIOpts) (api *RelayAPI, err error) {
 		}
 		opts.Log.Infof("Using BLS key: %s", publicKey.String())
 
-		// ensure pubkey is same across all relay instances
+		// ensure pubkey is same across all relay instances, unless rotating away from a previous key
 		_pubkey, err := opts.Redis.GetRelayConfig(datastore.RedisConfigFieldPubkey)
 		if err != nil {
 			return nil, err
-		} else if _pubkey == "" {
+		} else if _pubkey == "" || isPreviousPublicKey(opts.PreviousPublicKeys, _pubkey) {
+			if _pubkey != "" {
+				opts.Log.Infof("Rotating relay pubkey from previous key %s", _pubkey)
+			}
 			err := opts.Redis.SetRelayConfig(datastore.RedisConfigFieldPubkey, publicKey.String())
 			if err != nil {
 				return nil, err
@@ -268,13 +540,191 @@ func NewRelayAPI(opts RelayAPIOpts) (api *RelayAPI, err error) {
 		redis:        opts.Redis,
 		memcached:    opts.Memcached,
 		db:           opts.DB,
+		now:          time.Now,
 
 		payloadAttributes: make(map[string]payloadAttributesHelper),
+		reorgs:            newReorgTracker(),
 
 		proposerDutiesResponse: &[]byte{},
-		blockSimRateLimiter:    NewBlockSimulationRateLimiter(opts.BlockSimURL),
+		deliveredPayloads:      newDeliveredPayloadCache(),
+
+		validatorRegC:          make(chan boostTypes.SignedValidatorRegistration, 450_000),
+		bidDecisionC:           make(chan *queuedBidDecision, bidDecisionQueueSize),
+		getHeaderBackgroundOps: make(chan struct{}, getHeaderMaxBackgroundOps),
+	}
+
+	if api.opts.GetPayloadTimeout <= 0 {
+		api.opts.GetPayloadTimeout = getPayloadDefaultTimeout
+	}
+
+	getHeaderMaxWait, err := getHeaderLongestWait(time.Duration(getHeaderMaxWaitMs)*time.Millisecond, opts.GetHeaderDeadlineIntoSlot, time.Duration(getHeaderMaxEarlyMs)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	api.opts.setDefaultTimeouts()
+	if err := checkWriteTimeout(api.opts.WriteTimeout, getHeaderMaxWait, opts.SubmissionBatchWindow); err != nil {
+		return nil, err
+	}
+
+	if opts.MetricsEnabled {
+		api.metrics = newRelayMetrics()
+		api.datastore = &metricsDatastore{relayDatastore: opts.Datastore, metrics: api.metrics}
+		api.redis = &metricsRedis{relayRedis: opts.Redis, metrics: api.metrics}
+		api.db = &metricsDB{IDatabaseService: opts.DB, metrics: api.metrics}
+		api.metrics.registerClockSkew(api)
+	}
+
+	if beaconBreakerFailures > 0 {
+		api.beaconBreaker = newCircuitBreaker(api.log.WithField("component", "beaconBreaker"), beaconBreakerFailures, time.Duration(beaconBreakerCooldownMs)*time.Millisecond)
+		api.beaconClient = &breakerBeaconClient{IMultiBeaconClient: opts.BeaconClient, breaker: api.beaconBreaker}
+		if api.metrics != nil {
+			api.metrics.registerBeaconBreakerState(api.beaconBreaker)
+		}
+	}
+
+	if opts.AuditLog {
+		api.auditLog = newAuditLog(api.log, opts.DB)
+	}
+
+	if opts.BlockBuilderAPI && opts.BidHistorySize > 0 {
+		retention := time.Duration(opts.BidHistoryRetentionSlots) * common.DurationPerSlot
+		api.bidHistory = newBidHistoryWriter(api.log, api.redis, opts.BidHistorySize, retention)
+	}
+
+	if opts.ProposerAPI {
+		api.beaconDuties = newBeaconDutiesCache(api.log, api.beaconClient)
+		api.getPayloadAlerts = newGetPayloadAlerts(api.log, api.db, opts.AlertWebhook)
+	}
+
+	if opts.BidCacheSize > 0 {
+		api.bidCache = newBidCache(opts.BidCacheSize)
+	}
+
+	if opts.SingleHeaderPerSlot {
+		api.servedHeaders = newServedHeaderCache()
+	}
+
+	if opts.SigVerifyWorkers > 0 {
+		api.sigVerifier = newSigVerifier(opts.SigVerifyWorkers)
+		if api.metrics != nil {
+			api.metrics.registerSigVerifyQueueDepth(api.sigVerifier)
+		}
+	}
+
+	if opts.RegistrationSigCacheTTL > 0 {
+		api.registrationSigCache = newSigCache(opts.RegistrationSigCacheTTL, registrationSigCacheSize)
+	}
+
+	if opts.GetPayloadConcurrency > 0 {
+		api.getPayloadLimiter = newGetPayloadLimiter(opts.GetPayloadConcurrency, getPayloadQueueTimeout)
+	}
+
+	if rejectionLogSampling {
+		api.rejectionLogs = newRejectionLogSampler(api.log)
+	}
+
+	if opts.BidStreamEnabled {
+		if opts.AdminToken == "" {
+			api.log.Warn("bid stream requires an admin token, not serving it")
+		} else {
+			api.bidStream = newBidStream()
+		}
+	}
+
+	if opts.BlockSimURL != "" {
+		api.blockSimRateLimiter = NewBlockSimulationRateLimiter(opts.BlockSimURL)
+	} else if opts.BlockBuilderAPI {
+		api.log.Warn("no block simulation URL set, block submissions are accepted without simulation")
+	}
+
+	if opts.DryRun {
+		api.log.Warn("dry-run: registrations and block submissions are validated but not stored, getHeader always returns 204")
+	}
+
+	if len(opts.PeerRelayURLs) > 0 {
+		if !opts.ProposerAPI || !opts.BlockBuilderAPI {
+			return nil, ErrPeerRelaysWithoutBuilderAPI
+		}
+		for _, peerURL := range opts.PeerRelayURLs {
+			peer, err := newPeerRelay(peerURL)
+			if err != nil {
+				return nil, err
+			}
+			api.peerRelays = append(api.peerRelays, peer)
+		}
+		api.peerRelayClient = http.Client{Timeout: time.Duration(peerRelayTimeoutMs) * time.Millisecond}
+		api.peerRelayGetPayloadClient = http.Client{Timeout: time.Duration(peerRelayGetPayloadTimeoutMs) * time.Millisecond}
+		api.federatedBids = newFederatedBids()
+	}
+
+	if path, isUnix := unixSocketPath(opts.ListenAddr); isUnix {
+		if err := validateUnixSocketPath(path); err != nil {
+			return nil, err
+		}
+		if api.opts.UnixSocketMode == 0 {
+			api.opts.UnixSocketMode = DefaultUnixSocketMode
+		}
+	}
+
+	if api.opts.MaxSubmitBytes <= 0 {
+		api.opts.MaxSubmitBytes = DefaultMaxSubmitBytes
+	}
+	if api.opts.MaxRegistrationBytes <= 0 {
+		api.opts.MaxRegistrationBytes = DefaultMaxRegistrationBytes
+	}
+	if api.opts.CompressMinSize <= 0 {
+		api.opts.CompressMinSize = DefaultCompressMinSize
+	}
+
+	if (opts.TLSCertFile == "") != (opts.TLSKeyFile == "") {
+		return nil, ErrTLSCertAndKey
+	} else if opts.TLSCertFile != "" {
+		api.certReloader, err = newCertReloader(opts.TLSCertFile, opts.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.SubmissionBatchWindow > 0 {
+		api.submissionBatcher = newSubmissionBatcher(opts.SubmissionBatchWindow)
+		api.log.Infof("batching block submissions in windows of %s", opts.SubmissionBatchWindow)
+	}
+
+	if opts.BuilderCAFile != "" {
+		if api.certReloader == nil {
+			return nil, ErrBuilderCAWithoutTLS
+		}
+		api.builderCAs, err = loadBuilderCA(opts.BuilderCAFile)
+		if err != nil {
+			return nil, err
+		}
+		api.log.Infof("requiring builder client certificates signed by %s for submissions", opts.BuilderCAFile)
+	}
 
-		validatorRegC: make(chan boostTypes.SignedValidatorRegistration, 450_000),
+	api.forkSchedule, err = opts.EthNetDetails.ForkSchedule()
+	if err != nil {
+		return nil, fmt.Errorf("invalid fork schedule: %w", err)
+	}
+
+	if api.adminAllowIPs, err = parseIPNets(opts.AdminAllowIPs); err != nil {
+		return nil, fmt.Errorf("invalid admin IP allowlist: %w", err)
+	}
+	if api.trustedProxies, err = parseIPNets(opts.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	if opts.BuilderAllowlist != "" {
+		api.builderAllowlist, err = newBuilderAllowlist(opts.BuilderAllowlist)
+		if err != nil {
+			return nil, err
+		}
+		api.log.Infof("accepting submissions from %d allowlisted builders", api.builderAllowlist.size())
+	}
+
+	if opts.RegistrationRateLimit > 0 {
+		api.log.Infof("rate-limiting validator registrations to %.2f req/s per IP (burst: %d)", opts.RegistrationRateLimit, opts.RegistrationRateLimitBurst)
+		api.registrationRateLimiter = newIPRateLimiter(opts.RegistrationRateLimit, opts.RegistrationRateLimitBurst)
 	}
 
 	if os.Getenv("FORCE_GET_HEADER_204") == "1" {
@@ -319,6 +769,9 @@ func (api *RelayAPI) getRouter() http.Handler {
 	r := mux.NewRouter()
 
 	r.HandleFunc("/", api.handleRoot).Methods(http.MethodGet)
+	r.HandleFunc(pathLivez, api.handleLivez).Methods(http.MethodGet)
+	r.HandleFunc(pathReadyz, api.handleReadyz).Methods(http.MethodGet)
+	r.HandleFunc(pathRelayStatus, api.handleRelayStatus).Methods(http.MethodGet)
 
 	// Proposer API
 	if api.opts.ProposerAPI {
@@ -327,6 +780,11 @@ func (api *RelayAPI) getRouter() http.Handler {
 		r.HandleFunc(pathRegisterValidator, api.handleRegisterValidator).Methods(http.MethodPost)
 		r.HandleFunc(pathGetHeader, api.handleGetHeader).Methods(http.MethodGet)
 		r.HandleFunc(pathGetPayload, api.handleGetPayload).Methods(http.MethodPost)
+
+		// The best bid is only exposed with an admin token, as it is not public before getHeader
+		if api.opts.AdminToken != "" {
+			r.Handle(pathDebugBestBid, api.adminAuth(api.handleDebugBestBid)).Methods(http.MethodGet)
+		}
 	}
 
 	// Builder API
@@ -334,35 +792,101 @@ func (api *RelayAPI) getRouter() http.Handler {
 		api.log.Info("block builder API enabled")
 		r.HandleFunc(pathBuilderGetValidators, api.handleBuilderGetValidators).Methods(http.MethodGet)
 		r.HandleFunc(pathSubmitNewBlock, api.handleSubmitNewBlock).Methods(http.MethodPost)
+		r.HandleFunc(pathBuilderCancelBid, api.handleCancelBid).Methods(http.MethodPost)
 	}
 
 	// Data API
 	if api.opts.DataAPI {
 		api.log.Info("data API enabled")
-		r.HandleFunc(pathDataProposerPayloadDelivered, api.handleDataProposerPayloadDelivered).Methods(http.MethodGet)
-		r.HandleFunc(pathDataBuilderBidsReceived, api.handleDataBuilderBidsReceived).Methods(http.MethodGet)
-		r.HandleFunc(pathDataValidatorRegistration, api.handleDataValidatorRegistration).Methods(http.MethodGet)
+		dataMethods := []string{http.MethodGet}
+		if len(api.opts.CORSOrigins) > 0 {
+			api.log.Infof("data API CORS origins: %s", strings.Join(api.opts.CORSOrigins, ", "))
+			dataMethods = append(dataMethods, http.MethodOptions)
+		}
+		r.Handle(pathDataProposerPayloadDelivered, api.cors(api.handleDataProposerPayloadDelivered)).Methods(dataMethods...)
+		r.Handle(pathDataBuilderBidsReceived, api.cors(api.handleDataBuilderBidsReceived)).Methods(dataMethods...)
+		r.Handle(pathDataValidatorRegistration, api.cors(api.handleDataValidatorRegistration)).Methods(dataMethods...)
+		r.Handle(pathDataValidatorRegistrationCounts, api.cors(api.handleDataValidatorRegistrationCounts)).Methods(dataMethods...)
+		r.Handle(pathDataBuilderStats, api.cors(api.handleDataBuilderStats)).Methods(dataMethods...)
+		r.Handle(pathDataBidHistory, api.cors(api.handleDataBidHistory)).Methods(dataMethods...)
+		r.Handle(pathDataBidDecisions, api.cors(api.handleDataBidDecisions)).Methods(dataMethods...)
+
+		// Full payloads are large, so they are only served with a token
+		if api.opts.PayloadDataToken != "" {
+			r.Handle(pathDataPayload, api.requireBearerToken(api.opts.PayloadDataToken, http.HandlerFunc(api.handleDataPayload))).Methods(http.MethodGet)
+		}
 	}
 
 	// Pprof
-	if api.opts.PprofAPI {
+	if api.opts.PprofAPI && api.opts.PprofListenAddr == "" {
 		api.log.Info("pprof API enabled")
-		r.PathPrefix("/debug/pprof/").Handler(http.DefaultServeMux)
+		r.PathPrefix("/debug/pprof/").Handler(api.requireAllowedIP(api.requireBearerToken(api.opts.PprofToken, http.DefaultServeMux)))
 	}
 
 	// /internal/...
 	if api.opts.InternalAPI {
 		api.log.Info("internal API enabled")
-		r.HandleFunc(pathInternalBuilderStatus, api.handleInternalBuilderStatus).Methods(http.MethodGet, http.MethodPost, http.MethodPut)
-		r.HandleFunc(pathInternalBuilderCollateral, api.handleInternalBuilderCollateral).Methods(http.MethodPost, http.MethodPut)
+		if api.opts.AdminToken == "" {
+			api.log.Warn("internal API enabled without admin token")
+		}
+		r.Handle(pathInternalBuilderBlacklist, api.adminAuth(api.handleInternalBuilderBlacklist)).Methods(http.MethodGet)
+		r.Handle(pathInternalBuilderStatus, api.adminAuth(api.handleInternalBuilderStatus)).Methods(http.MethodGet, http.MethodPost, http.MethodPut)
+		r.Handle(pathInternalBuilderCollateral, api.adminAuth(api.handleInternalBuilderCollateral)).Methods(http.MethodPost, http.MethodPut)
+		r.Handle(pathInternalMaintenance, api.adminAuth(api.handleInternalMaintenance)).Methods(http.MethodGet, http.MethodPost, http.MethodPut)
+	}
+
+	// Prometheus metrics
+	if api.metrics != nil {
+		api.log.Info("metrics enabled")
+		r.Use(api.metrics.middleware)
+		if api.opts.MetricsListenAddr == "" {
+			r.Handle(pathMetrics, api.metrics.handler()).Methods(http.MethodGet)
+		}
+	}
+
+	r.Use(api.inFlightMiddleware)
+	r.Use(api.bodyLimitMiddleware)
+
+	if api.opts.DebugSampleRate > 0 {
+		api.log.Warnf("logging full request and response bodies of %.2f%% of requests", api.opts.DebugSampleRate*100)
+		r.Use(api.bodyLogMiddleware)
 	}
 
 	// r.Use(mux.CORSMethodMiddleware(r))
-	loggedRouter := httplogger.LoggingMiddlewareLogrus(api.log, r)
-	withGz := gziphandler.GzipHandler(loggedRouter)
+	loggedRouter := api.accessLogMiddleware(r)
+	withGz := api.compressed(loggedRouter)
+
+	// The websocket upgrade needs to hijack the connection, which the middlewares' response writers don't support.
+	// The accepted bids are not public before getHeader, so they are only streamed with an admin token.
+	if api.bidStream != nil && api.opts.AdminToken != "" {
+		api.log.Info("bid stream enabled")
+		bidStreamHandler := api.adminAuth(api.handleBidStream)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == pathBuilderBidsStream {
+				bidStreamHandler.ServeHTTP(w, req)
+				return
+			}
+			withGz.ServeHTTP(w, req)
+		})
+	}
 	return withGz
 }
 
+// startPprofServer serves /debug/pprof/ on a separate listen address
+func (api *RelayAPI) startPprofServer() {
+	srv := &http.Server{ //nolint:exhaustruct
+		Addr:              api.opts.PprofListenAddr,
+		Handler:           api.requireAllowedIP(api.requireBearerToken(api.opts.PprofToken, http.DefaultServeMux)),
+		ReadHeaderTimeout: api.opts.ReadHeaderTimeout,
+	}
+
+	api.log.Infof("serving pprof on %s/debug/pprof/", api.opts.PprofListenAddr)
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		api.log.WithError(err).Error("pprof server failed")
+	}
+}
+
 func (api *RelayAPI) isCapella(slot uint64) bool {
 	if api.capellaEpoch == 0 { // CL didn't yet have it
 		return false
@@ -371,6 +895,19 @@ func (api *RelayAPI) isCapella(slot uint64) bool {
 	return epoch >= api.capellaEpoch
 }
 
+func (api *RelayAPI) isDeneb(slot uint64) bool {
+	if api.denebEpoch == 0 { // CL didn't yet have it
+		return false
+	}
+	epoch := slot / common.SlotsPerEpoch
+	return epoch >= api.denebEpoch
+}
+
+// proposerDomain returns the beacon proposer signing domain for the fork active at the given slot
+func (api *RelayAPI) proposerDomain(slot uint64) boostTypes.Domain {
+	return api.forkSchedule.ProposerDomain(slot)
+}
+
 // StartServer starts the HTTP server for this instance
 func (api *RelayAPI) StartServer() (err error) {
 	if api.srvStarted.Swap(true) {
@@ -378,9 +915,12 @@ func (api *RelayAPI) StartServer() (err error) {
 	}
 
 	// Get best beacon-node status by head slot, process current slot and start slot updates
-	bestSyncStatus, err := api.beaconClient.BestSyncStatus()
-	if err != nil {
-		return err
+	bestSyncStatus := api.opts.SyncStatus
+	if bestSyncStatus == nil {
+		bestSyncStatus, err = beaconclient.WaitForSyncStatus(api.log, api.beaconClient)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Initialize block builder cache.
@@ -395,6 +935,9 @@ func (api *RelayAPI) StartServer() (err error) {
 		return err
 	}
 	api.log.Infof("genesis info: %d", api.genesisInfo.Data.GenesisTime)
+	if api.opts.EthNetDetails.GenesisTime != 0 && api.opts.EthNetDetails.GenesisTime != api.genesisInfo.Data.GenesisTime {
+		return fmt.Errorf("%w: %d (config) != %d (beacon node)", ErrMismatchedGenesisTime, api.opts.EthNetDetails.GenesisTime, api.genesisInfo.Data.GenesisTime)
+	}
 
 	forkSchedule, err := api.beaconClient.GetForkSchedule()
 	if err != nil {
@@ -407,22 +950,44 @@ func (api *RelayAPI) StartServer() (err error) {
 		switch fork.CurrentVersion {
 		case api.opts.EthNetDetails.CapellaForkVersionHex:
 			api.capellaEpoch = fork.Epoch
-			// TODO: add deneb support.
+		case api.opts.EthNetDetails.DenebForkVersionHex:
+			api.denebEpoch = fork.Epoch
+		}
+		// The beacon node's epochs take precedence over the preset and config
+		if err := api.forkSchedule.SetForkEpoch(fork.CurrentVersion, fork.Epoch); err != nil {
+			return err
 		}
 	}
+	api.log.Infof("proposer domain fork schedule: %s", api.forkSchedule)
 
 	// Print fork version information
-	// TODO: add deneb support.
-	if api.isCapella(currentSlot) {
+	if api.isDeneb(currentSlot) {
+		api.log.Infof("deneb fork detected (currentEpoch: %d / denebEpoch: %d)", currentEpoch, api.denebEpoch)
+	} else if api.isCapella(currentSlot) {
 		api.log.Infof("capella fork detected (currentEpoch: %d / capellaEpoch: %d)", currentEpoch, api.capellaEpoch)
+		if api.denebEpoch > 0 {
+			api.log.Infof("deneb fork scheduled (denebEpoch: %d)", api.denebEpoch)
+		}
 	} else {
 		return ErrMismatchedForkVersions
 	}
 
+	// Compare the local clock with the beacon node's now and periodically
+	api.checkClockSkew()
+	go api.startClockSkewChecks()
+
 	// start things for the block-builder API
 	if api.opts.BlockBuilderAPI {
 		// Get current proposer duties blocking before starting, to have them ready
 		api.updateProposerDuties(bestSyncStatus.HeadSlot)
+
+		if !api.opts.Optimistic {
+			api.warnOptimisticBuildersDisabled()
+		}
+
+		if api.bidHistory != nil {
+			go api.bidHistory.start()
+		}
 	}
 
 	// start things specific for the proposer API
@@ -432,6 +997,23 @@ func (api *RelayAPI) StartServer() (err error) {
 		for i := 0; i < numValidatorRegProcessors; i++ {
 			go api.startValidatorRegistrationDBProcessor()
 		}
+
+		if len(api.peerRelays) > 0 {
+			go api.startPeerRelayPolling()
+		}
+
+		go api.startBidDecisionProcessor()
+		if api.auditLog != nil {
+			go api.auditLog.start()
+		}
+		go api.getPayloadAlerts.start()
+
+		// Get the beacon node's proposer duties blocking before starting, to have them ready for getPayload
+		api.beaconDuties.update(currentEpoch)
+	}
+
+	if api.opts.RetentionSlots > 0 {
+		go api.startRetentionPruning()
 	}
 
 	// Process current slot
@@ -443,7 +1025,11 @@ func (api *RelayAPI) StartServer() (err error) {
 		api.beaconClient.SubscribeToHeadEvents(c)
 		for {
 			headEvent := <-c
+			api.processReorgs(headEvent)
 			api.processNewSlot(headEvent.Slot)
+			if api.beaconDuties != nil {
+				go api.beaconDuties.processHeadEvent(headEvent)
+			}
 		}
 	}()
 
@@ -460,28 +1046,59 @@ func (api *RelayAPI) StartServer() (err error) {
 		}()
 	}
 
+	// Serve pprof on a separate address if configured
+	if api.opts.PprofAPI && api.opts.PprofListenAddr != "" {
+		go api.startPprofServer()
+	}
+
+	// Serve metrics on a separate address if configured
+	if api.metrics != nil && api.opts.MetricsListenAddr != "" {
+		go api.startMetricsServer()
+	}
+	if api.metrics != nil {
+		go api.startCacheMetricsUpdates()
+	}
+
 	api.srv = &http.Server{
 		Addr:    api.opts.ListenAddr,
 		Handler: api.getRouter(),
 
-		ReadTimeout:       time.Duration(apiReadTimeoutMs) * time.Millisecond,
-		ReadHeaderTimeout: time.Duration(apiReadHeaderTimeoutMs) * time.Millisecond,
-		WriteTimeout:      time.Duration(apiWriteTimeoutMs) * time.Millisecond,
-		IdleTimeout:       time.Duration(apiIdleTimeoutMs) * time.Millisecond,
+		ReadTimeout:       api.opts.ReadTimeout,
+		ReadHeaderTimeout: api.opts.ReadHeaderTimeout,
+		WriteTimeout:      api.opts.WriteTimeout,
+		IdleTimeout:       api.opts.IdleTimeout,
 		MaxHeaderBytes:    apiMaxHeaderBytes,
 	}
 
-	err = api.srv.ListenAndServe()
+	ln, err := listen(api.opts.ListenAddr, api.opts.UnixSocketMode)
+	if err != nil {
+		return err
+	}
+
+	if api.certReloader != nil {
+		api.log.Infof("serving TLS with certificate %s", api.opts.TLSCertFile)
+		api.srv.TLSConfig = withBuilderClientAuth(api.certReloader.tlsConfig(), api.builderCAs)
+		err = api.srv.ServeTLS(ln, "", "")
+	} else {
+		err = api.srv.Serve(ln)
+	}
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
 	return err
 }
 
-// StopServer disables sending any bids on getHeader calls, waits a few seconds to catch any remaining getPayload call, and then shuts down the webserver
+// StopServer disables sending any bids on getHeader calls, waits a few seconds to catch any remaining getPayload call, and then
+// shuts down the webserver, draining in-flight requests for up to API_SHUTDOWN_TIMEOUT_MS
 func (api *RelayAPI) StopServer() (err error) {
 	api.log.Info("Stopping server...")
 
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(apiShutdownTimeoutMs)*time.Millisecond)
+	defer cancel()
+
+	// summarize the rejections of the current slot, after the in-flight submissions are done
+	defer api.rejectionLogs.flush()
+
 	if api.opts.ProposerAPI {
 		// stop sending bids
 		api.ffForceGetHeader204 = true
@@ -491,11 +1108,41 @@ func (api *RelayAPI) StopServer() (err error) {
 		time.Sleep(5 * time.Second)
 
 		// wait for any active getPayload call to finish
-		api.getPayloadCallsInFlight.Wait()
+		getPayloadCallsDone := make(chan struct{})
+		go func() {
+			api.getPayloadCallsInFlight.Wait()
+			close(getPayloadCallsDone)
+		}()
+		select {
+		case <-getPayloadCallsDone:
+		case <-ctx.Done():
+			api.log.Warn("timed out waiting for getPayload calls to finish")
+		}
+	}
+
+	if api.srv == nil {
+		return nil
+	}
+
+	// stop accepting new connections and wait for in-flight requests
+	numInFlight := api.requestsInFlight.Load()
+	api.log.Infof("Shutting down webserver, draining %d in-flight requests...", numInFlight)
+	err = api.srv.Shutdown(ctx)
+	if err != nil {
+		api.log.WithError(err).Warnf("could not drain all requests, %d still in flight", api.requestsInFlight.Load())
+		return err
 	}
+	api.log.Infof("Webserver stopped, drained %d in-flight requests", numInFlight)
+	return nil
+}
 
-	// shutdown
-	return api.srv.Shutdown(context.Background())
+// inFlightMiddleware keeps track of the number of requests being handled
+func (api *RelayAPI) inFlightMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		api.requestsInFlight.Inc()
+		defer api.requestsInFlight.Dec()
+		next.ServeHTTP(w, req)
+	})
 }
 
 func (api *RelayAPI) startValidatorRegistrationDBProcessor() {
@@ -616,6 +1263,13 @@ func (api *RelayAPI) processPayloadAttributes(payloadAttributes beaconclient.Pay
 		"payloadAttrParent": payloadAttributes.Data.ParentBlockHash,
 	})
 
+	// remember which execution block the parent beacon block has, to know which bids a reorg of it orphans
+	api.reorgs.setBlockHash(payloadAttrSlot, payloadAttributes.Data.ParentBlockRoot, payloadAttributes.Data.ParentBlockHash)
+	if api.reorgs.isOrphaned(payloadAttributes.Data.ParentBlockHash) {
+		log.Warn("ignoring payload attributes for a reorged out parent")
+		return
+	}
+
 	// discard payload attributes if already known
 	api.payloadAttributesLock.RLock()
 	_, ok := api.payloadAttributes[payloadAttributes.Data.ParentBlockHash]
@@ -675,6 +1329,23 @@ func (api *RelayAPI) processNewSlot(headSlot uint64) {
 
 	// store the head slot
 	api.headSlot.Store(headSlot)
+	api.checkMissedSlots(prevHeadSlot, headSlot)
+
+	// submissions for past slots aren't accepted anymore
+	api.rejectionLogs.flushBefore(headSlot + 1)
+
+	// bids for past slots can't be served anymore
+	if api.bidCache != nil {
+		api.bidCache.pruneBefore(headSlot)
+	}
+	api.deliveredPayloads.pruneBefore(headSlot)
+	api.servedHeaders.pruneBefore(headSlot)
+	if headSlot > reorgTrackerSlots {
+		api.reorgs.pruneBefore(headSlot - reorgTrackerSlots)
+	}
+	if api.federatedBids != nil {
+		api.federatedBids.pruneBefore(headSlot)
+	}
 
 	// only for builder-api
 	if api.opts.BlockBuilderAPI || api.opts.ProposerAPI {
@@ -747,6 +1418,25 @@ func (api *RelayAPI) updateProposerDuties(headSlot uint64) {
 	api.log.Infof("proposer duties updated: %s", strings.Join(_duties, ", "))
 }
 
+// warnOptimisticBuildersDisabled warns if there are optimistic builders, whose submissions are simulated before they're
+// accepted as optimistic processing is disabled
+func (api *RelayAPI) warnOptimisticBuildersDisabled() {
+	builders, err := api.db.GetBlockBuilders()
+	if err != nil {
+		api.log.WithError(err).Error("unable to read block builders from db")
+		return
+	}
+	numOptimistic := 0
+	for _, builder := range builders {
+		if builder.IsOptimistic {
+			numOptimistic++
+		}
+	}
+	if numOptimistic > 0 {
+		api.log.Warnf("optimistic processing is disabled, but %d builders are optimistic: their submissions are simulated before they're accepted", numOptimistic)
+	}
+}
+
 func (api *RelayAPI) prepareBuildersForSlot(headSlot uint64) {
 	// Wait until there are no optimistic blocks being processed. Then we can
 	// safely update the slot.
@@ -768,6 +1458,7 @@ func (api *RelayAPI) prepareBuildersForSlot(headSlot uint64) {
 				IsBlacklisted: v.IsBlacklisted,
 				IsOptimistic:  v.IsOptimistic,
 			},
+			builderID: v.BuilderID,
 		}
 		// Try to parse builder collateral string to big int.
 		builderCollateral, ok := big.NewInt(0).SetString(v.Collateral, 10)
@@ -782,8 +1473,9 @@ func (api *RelayAPI) prepareBuildersForSlot(headSlot uint64) {
 	api.blockBuildersCache = newCache
 }
 
+// RespondError responds with an error, with a generic machine-readable code derived from the HTTP status
 func (api *RelayAPI) RespondError(w http.ResponseWriter, code int, message string) {
-	api.Respond(w, code, HTTPErrorResp{code, message})
+	api.RespondErrorCode(w, code, errorCodeForStatus(code), message)
 }
 
 func (api *RelayAPI) RespondOK(w http.ResponseWriter, response any) {
@@ -812,6 +1504,65 @@ func (api *RelayAPI) handleStatus(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// handleRelayStatus returns the relay pubkey (to construct the relay URL and verify bid signatures), the network and
+// its fork versions, and the relay version
+func (api *RelayAPI) handleRelayStatus(w http.ResponseWriter, req *http.Request) {
+	resp := RelayStatusResponse{
+		Network:               api.opts.EthNetDetails.Name,
+		GenesisForkVersion:    api.opts.EthNetDetails.GenesisForkVersionHex,
+		GenesisValidatorsRoot: api.opts.EthNetDetails.GenesisValidatorsRootHex,
+		BellatrixForkVersion:  api.opts.EthNetDetails.BellatrixForkVersionHex,
+		CapellaForkVersion:    api.opts.EthNetDetails.CapellaForkVersionHex,
+		DenebForkVersion:      api.opts.EthNetDetails.DenebForkVersionHex,
+		Version:               api.opts.Version,
+	}
+	if api.opts.SecretKey != nil {
+		resp.Pubkey = api.publicKey.String()
+	}
+	api.RespondOK(w, resp)
+}
+
+// handleLivez returns 200 as long as the process is serving requests
+func (api *RelayAPI) handleLivez(w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleReadyz returns 200 if a beacon node is synced and Redis is reachable, and 503 otherwise. An instance in
+// maintenance mode stays ready, so that proposers can still fetch the payloads of the bids it has served.
+func (api *RelayAPI) handleReadyz(w http.ResponseWriter, req *http.Request) {
+	resp := ReadinessResponse{
+		Ready:       true,
+		Beacon:      "ok",
+		Redis:       "ok",
+		Maintenance: api.maintenanceMode.Load(),
+	}
+
+	if api.beaconBreaker != nil {
+		resp.BeaconCircuitBreaker = api.beaconBreaker.getState().String()
+	}
+	if _, err := api.beaconClient.BestSyncStatus(); err != nil {
+		resp.Ready = false
+		resp.Beacon = err.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), time.Duration(readyzRedisTimeoutMs)*time.Millisecond)
+	defer cancel()
+	if err := api.redis.Ping(ctx); err != nil {
+		resp.Ready = false
+		resp.Redis = err.Error()
+	}
+
+	if !resp.Ready {
+		api.log.WithFields(logrus.Fields{
+			"beacon": resp.Beacon,
+			"redis":  resp.Redis,
+		}).Warn("readiness check failed")
+		api.Respond(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+	api.RespondOK(w, resp)
+}
+
 // ---------------
 //  PROPOSER APIS
 // ---------------
@@ -825,6 +1576,7 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 	ua := req.UserAgent()
 	log := api.log.WithFields(logrus.Fields{
 		"method":        "registerValidator",
+		"requestID":     getRequestID(req),
 		"ua":            ua,
 		"mevBoostV":     common.GetMevBoostVersionFromUserAgent(ua),
 		"headSlot":      api.headSlot.Load(),
@@ -832,7 +1584,7 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 	})
 
 	start := time.Now().UTC()
-	registrationTimestampUpperBound := start.Unix() + 10 // 10 seconds from now
+	registrationTimestampUpperBound := start.Unix() + int64(registrationTimestampMaxSkewSec)
 
 	numRegTotal := 0
 	numRegProcessed := 0
@@ -841,27 +1593,55 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 	processingStoppedByError := false
 
 	// Setup error handling
-	handleError := func(_log *logrus.Entry, code int, msg string) {
+	handleError := func(_log *logrus.Entry, status int, code ErrorCode, msg string) {
 		processingStoppedByError = true
 		_log.Warnf("error: %s", msg)
-		api.RespondError(w, code, msg)
+		api.RespondErrorCode(w, status, code, msg)
+	}
+
+	// Rate-limit by client IP before doing any work
+	if api.registrationRateLimiter != nil {
+		ip := api.getClientIP(req)
+		if ok, retryAfter := api.registrationRateLimiter.allow(ip); !ok {
+			log.WithField("ip", ip).Debug("registration rate limit exceeded")
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(retryAfter)))
+			api.RespondErrorCode(w, http.StatusTooManyRequests, ErrorCodeTooManyRequests, "too many requests")
+			return
+		}
 	}
 
 	// Start processing
 	if req.ContentLength == 0 {
 		log.Info("empty request")
-		api.RespondError(w, http.StatusBadRequest, "empty request")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "empty request")
 		return
 	}
 
 	body, err := io.ReadAll(req.Body)
-	if err != nil {
+	if isBodyTooLarge(err) {
+		log.WithError(err).Warn("request body too large")
+		api.respondBodyTooLarge(w, api.opts.MaxRegistrationBytes)
+		return
+	} else if err != nil {
 		log.WithError(err).WithField("contentLength", req.ContentLength).Warn("failed to read request body")
-		api.RespondError(w, http.StatusBadRequest, "failed to read request body")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "failed to read request body")
 		return
 	}
 	req.Body.Close()
 
+	// Count the registrations before verifying any of them
+	if maxRegs := api.opts.MaxRegistrationsPerRequest; maxRegs > 0 {
+		numRegs := 0
+		_, _ = jsonparser.ArrayEach(body, func(value []byte, dataType jsonparser.ValueType, offset int, _err error) {
+			numRegs++
+		})
+		if numRegs > maxRegs {
+			log.WithField("numRegistrations", numRegs).Warn("too many registrations in one request")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, fmt.Sprintf("too many registrations: %d, the maximum is %d per request", numRegs, maxRegs))
+			return
+		}
+	}
+
 	parseRegistration := func(value []byte) (reg *boostTypes.SignedValidatorRegistration, err error) {
 		// Pubkey
 		_pubkey, err := jsonparser.GetUnsafeString(value, "message", "pubkey")
@@ -947,7 +1727,7 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 		// Extract immediately necessary registration fields
 		signedValidatorRegistration, err := parseRegistration(value)
 		if err != nil {
-			handleError(regLog, http.StatusBadRequest, err.Error())
+			handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
 			return
 		}
 
@@ -964,17 +1744,34 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 		// Ensure a valid timestamp (not too early, and not too far in the future)
 		registrationTimestamp := int64(signedValidatorRegistration.Message.Timestamp)
 		if registrationTimestamp < int64(api.genesisInfo.Data.GenesisTime) {
-			handleError(regLog, http.StatusBadRequest, "timestamp too early")
+			handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidTimestamp, "timestamp too early")
 			return
 		} else if registrationTimestamp > registrationTimestampUpperBound {
-			handleError(regLog, http.StatusBadRequest, "timestamp too far in the future")
+			handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidTimestamp, "timestamp too far in the future")
+			return
+		}
+
+		// Ensure the gas limit is within the configured bounds
+		gasLimit := signedValidatorRegistration.Message.GasLimit
+		if api.opts.MinGasLimit > 0 && gasLimit < api.opts.MinGasLimit {
+			handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidGasLimit, fmt.Sprintf("gas limit too low: %d < %d", gasLimit, api.opts.MinGasLimit))
+			return
+		} else if api.opts.MaxGasLimit > 0 && gasLimit > api.opts.MaxGasLimit {
+			handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidGasLimit, fmt.Sprintf("gas limit too high: %d > %d", gasLimit, api.opts.MaxGasLimit))
 			return
 		}
 
 		// Check if a real validator
 		isKnownValidator := api.datastore.IsKnownValidator(pkHex)
 		if !isKnownValidator {
-			handleError(regLog, http.StatusBadRequest, fmt.Sprintf("not a known validator: %s", pkHex.String()))
+			handleError(regLog, http.StatusBadRequest, ErrorCodeUnknownValidator, fmt.Sprintf("not a known validator: %s", pkHex.String()))
+			return
+		}
+
+		// Refuse slashed and exited validators
+		if status, refused := api.isSlashedOrExited(pkHex); refused {
+			regLog.WithField("validatorStatus", status).Warn("refusing registration of slashed or exited validator")
+			handleError(regLog, http.StatusBadRequest, ErrorCodeValidatorSlashedOrExited, fmt.Sprintf("validator is slashed or exited: %s (%s)", pkHex.String(), status))
 			return
 		}
 
@@ -987,17 +1784,23 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 			return
 		}
 
+		// Reject malformed pubkeys before the signature check, which would accept i.e. the point at infinity
+		if err := common.ValidateBLSPublicKey(signedValidatorRegistration.Message.Pubkey[:]); err != nil {
+			handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidPubkey, err.Error())
+			return
+		}
+
 		// Verify the signature
-		ok, err := boostTypes.VerifySignature(signedValidatorRegistration.Message, api.opts.EthNetDetails.DomainBuilder, signedValidatorRegistration.Message.Pubkey[:], signedValidatorRegistration.Signature[:])
+		ok, cached, err := api.verifyRegistrationSignature(signedValidatorRegistration.Message, signedValidatorRegistration.Signature[:])
 		if err != nil {
 			regLog.WithError(err).Error("error verifying registerValidator signature")
 			return
 		} else if !ok {
-			regLog.Info("invalid validator signature")
+			regLog.WithField("cachedResult", cached).Info("invalid validator signature")
 			if api.ffRegValContinueOnInvalidSig {
 				return
 			} else {
-				handleError(regLog, http.StatusBadRequest, fmt.Sprintf("failed to verify validator signature for %s", signedValidatorRegistration.Message.Pubkey.String()))
+				handleError(regLog, http.StatusBadRequest, ErrorCodeInvalidSignature, fmt.Sprintf("failed to verify validator signature for %s", signedValidatorRegistration.Message.Pubkey.String()))
 				return
 			}
 		}
@@ -1005,6 +1808,11 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 		// Now we have a new registration to process
 		numRegNew += 1
 
+		if api.opts.DryRun {
+			regLog.Info("dry-run: would save validator registration")
+			return
+		}
+
 		// Save to database
 		select {
 		case api.validatorRegC <- *signedValidatorRegistration:
@@ -1024,7 +1832,7 @@ func (api *RelayAPI) handleRegisterValidator(w http.ResponseWriter, req *http.Re
 	})
 
 	if err != nil {
-		handleError(log, http.StatusBadRequest, "error in traversing json")
+		handleError(log, http.StatusBadRequest, ErrorCodeInvalidRequest, "error in traversing json")
 		return
 	}
 
@@ -1042,7 +1850,7 @@ func (api *RelayAPI) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 
 	slot, err := strconv.ParseUint(slotStr, 10, 64)
 	if err != nil {
-		api.RespondError(w, http.StatusBadRequest, common.ErrInvalidSlot.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSlot, common.ErrInvalidSlot.Error())
 		return
 	}
 
@@ -1052,6 +1860,7 @@ func (api *RelayAPI) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 
 	log := api.log.WithFields(logrus.Fields{
 		"method":           "getHeader",
+		"requestID":        getRequestID(req),
 		"headSlot":         headSlot,
 		"slot":             slotStr,
 		"parentHash":       parentHashHex,
@@ -1064,64 +1873,269 @@ func (api *RelayAPI) handleGetHeader(w http.ResponseWriter, req *http.Request) {
 	})
 
 	if len(proposerPubkeyHex) != 98 {
-		api.RespondError(w, http.StatusBadRequest, common.ErrInvalidPubkey.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPubkey, common.ErrInvalidPubkey.Error())
 		return
 	}
 
 	if len(parentHashHex) != 66 {
-		api.RespondError(w, http.StatusBadRequest, common.ErrInvalidHash.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidHash, common.ErrInvalidHash.Error())
 		return
 	}
 
 	if slot < headSlot {
-		api.RespondError(w, http.StatusBadRequest, "slot is too old")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSlot, "slot is too old")
+		return
+	}
+
+	log.Debug("getHeader request received")
+
+	if slices.Contains(apiNoHeaderUserAgents, ua) {
+		log.Info("rejecting getHeader by user agent")
+		api.respondNoContent(w, req, slot, noBidReasonUserAgent)
+		return
+	}
+
+	if api.ffForceGetHeader204 {
+		log.Info("forced getHeader 204 response")
+		api.respondNoContent(w, req, slot, noBidReasonForced)
+		return
+	}
+
+	if api.maintenanceMode.Load() {
+		log.Info("maintenance mode: getHeader 204 response")
+		api.respondNoContent(w, req, slot, noBidReasonMaintenance)
+		return
+	}
+
+	if status, refused := api.isSlashedOrExited(boostTypes.PubkeyHex(proposerPubkeyHex)); refused {
+		log.WithField("validatorStatus", status).Warn("refusing getHeader of slashed or exited validator")
+		api.respondNoContent(w, req, slot, noBidReasonValidatorStatus)
+		return
+	}
+
+	if api.opts.ProposerOnlyHeaders && !api.isProposerForSlot(slot, proposerPubkeyHex) {
+		log.Info("refusing getHeader of a validator which is not the proposer of the slot")
+		api.respondNoContent(w, req, slot, noBidReasonNotProposer)
+		return
+	}
+
+	if api.refuseOrphanedParent(w, req, log, slot, parentHashHex) {
+		return
+	}
+
+	if api.opts.DryRun {
+		log.Info("dry-run: would respond with the best bid")
+		api.respondNoContent(w, req, slot, noBidReasonDryRun)
+		return
+	}
+
+	// Only allow requests for the current slot until a certain cutoff time
+	if getHeaderRequestCutoffMs > 0 && msIntoSlot > 0 && msIntoSlot > int64(getHeaderRequestCutoffMs) {
+		log.Info("getHeader sent too late")
+		api.respondNoContent(w, req, slot, noBidReasonTooLate)
+		return
+	}
+
+	// Early requests wait for more bids, up to a maximum and not beyond a point in the slot
+	waitTime := getHeaderWaitTime(msIntoSlot, getHeaderMaxWaitMs, getHeaderWaitUntilMs)
+	if deadline := api.opts.GetHeaderDeadlineIntoSlot; deadline > 0 {
+		waitTime = getHeaderDeadlineWaitTime(msIntoSlot, deadline, time.Duration(getHeaderMaxEarlyMs)*time.Millisecond, api.genesisInfo.Data.GenesisTime > 0)
+	}
+	if waitTime > 0 {
+		log.WithField("waitTimeMs", waitTime.Milliseconds()).Debug("waiting for more bids")
+		select {
+		case <-time.After(waitTime):
+		case <-req.Context().Done():
+			log.Info("getHeader request canceled while waiting for bids")
+			return
+		}
+	}
+
+	// With a single header per slot, the proposer only ever gets the header served first
+	if served, found := api.servedHeaders.get(slot, proposerPubkeyHex); found {
+		api.respondServedHeader(w, req, log, served, parentHashHex)
+		return
+	}
+
+	bid, err := api.getBestBidShared(slot, parentHashHex, proposerPubkeyHex)
+	if err != nil {
+		log.WithError(err).Error("could not get bid")
+		api.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Serve the bid of a peer relay if it beats ours
+	var fedBid *federatedBid
+	if api.federatedBids != nil {
+		if peerBid, found := api.federatedBids.get(slot, parentHashHex, proposerPubkeyHex); found && (bid.Empty() || peerBid.value.Cmp(bid.Value()) > 0) {
+			fedBid = peerBid
+			bid = peerBid.bid
+			log = log.WithFields(logrus.Fields{
+				"federated": true,
+				"peerRelay": peerBid.peer.String(),
+			})
+		}
+	}
+
+	if bid.Empty() || bid.Value().Cmp(big.NewInt(0)) == 0 {
+		api.respondNoBid(w, req, log, slot)
+		return
+	}
+
+	// Skip bids below the configured floor, to let the proposer build locally
+	if api.opts.MinBidValue != nil && bid.Value().Cmp(api.opts.MinBidValue) < 0 {
+		log.WithFields(logrus.Fields{
+			"value":       bid.Value().String(),
+			"minBidValue": api.opts.MinBidValue.String(),
+		}).Info("bid below minimum value")
+		api.respondNoContent(w, req, slot, noBidReasonBelowMinValue)
+		return
+	}
+
+	// The registration may have changed since the submission, refuse bids which don't pay the fee recipient on file
+	if api.opts.EnforceFeeRecipient {
+		var registered, actual string
+		var match bool
+		if fedBid != nil {
+			registered, actual, match, err = api.checkPeerBidFeeRecipient(slot, fedBid)
+		} else {
+			registered, actual, match, err = api.checkBidFeeRecipient(slot, proposerPubkeyHex, bid.BlockHash().String())
+		}
+		if err != nil {
+			log.WithError(err).Error("could not verify fee recipient")
+		} else if !match {
+			log.WithFields(logrus.Fields{
+				"registeredFeeRecipient": registered,
+				"actualFeeRecipient":     actual,
+			}).Error("refusing bid which does not pay the registered fee recipient")
+			api.respondNoContent(w, req, slot, noBidReasonFeeRecipient)
+			return
+		}
+	}
+
+	if api.servedHeaders != nil {
+		entry := &servedHeaderEntry{slot: slot, parentHash: parentHashHex, bid: bid, fedBid: fedBid}
+		if served := api.servedHeaders.getOrSet(proposerPubkeyHex, entry); served != entry {
+			// a concurrent request was served first
+			api.respondServedHeader(w, req, log, served, parentHashHex)
+			return
+		}
+	}
+
+	if api.opts.DebugHeaders && fedBid == nil {
+		api.setBidDebugHeaders(w, slot, proposerPubkeyHex, bid)
+	}
+
+	log.WithFields(logrus.Fields{
+		"value":     bid.Value().String(),
+		"blockHash": bid.BlockHash().String(),
+	}).Info("bid delivered")
+	api.RespondOK(w, bid)
+	api.noBids.recordBid(slot)
+
+	// The decision is queued (and never dropped) for dispute resolution, the builder of a peer relay's bid isn't known
+	api.recordBidDecision(slot, proposerPubkeyHex, parentHashHex, bid.Value(), bid.BlockHash().String(), fedBid == nil)
+
+	// Builder stats are tracked by the peer relay for its bids
+	if fedBid != nil {
+		api.auditLog.record(database.AuditActionGetHeader, slot, proposerPubkeyHex, "", bid.Value(), bid.BlockHash().String())
+		return
+	}
+
+	// Record the served bid and count it for the builder, off the request path (the builder pubkey is only in the bid trace)
+	api.runGetHeaderBackground(log, func() {
+		api.afterGetHeader(slot, proposerPubkeyHex, bid)
+	})
+}
+
+// runGetHeaderBackground runs fn in a goroutine, unless GETHEADER_MAX_BACKGROUND_OPS are already running (i.e. Redis
+// or the database are slow during a burst of requests), in which case fn is dropped instead of piling up goroutines
+func (api *RelayAPI) runGetHeaderBackground(log *logrus.Entry, fn func()) {
+	select {
+	case api.getHeaderBackgroundOps <- struct{}{}:
+	default:
+		numDropped := api.numGetHeaderBackgroundDropped.Inc()
+		log.WithField("numDropped", numDropped).Error("too many getHeader background operations, not recording the served bid")
+		return
+	}
+	go func() {
+		defer func() { <-api.getHeaderBackgroundOps }()
+		fn()
+	}()
+}
+
+// setBidDebugHeaders adds the builder pubkey and value of the served bid to the getHeader response headers
+func (api *RelayAPI) setBidDebugHeaders(w http.ResponseWriter, slot uint64, proposerPubkey string, bid *common.GetHeaderResponse) {
+	w.Header().Set(HeaderBidValue, bid.Value().String())
+	bidTrace, err := api.redis.GetBidTrace(slot, proposerPubkey, bid.BlockHash().String())
+	if err != nil {
+		api.log.WithError(err).Warn("could not get bid trace for debug headers")
 		return
+	} else if bidTrace != nil {
+		w.Header().Set(HeaderBuilderPubkey, bidTrace.BuilderPubkey.String())
 	}
+}
 
-	log.Debug("getHeader request received")
-
-	if slices.Contains(apiNoHeaderUserAgents, ua) {
-		log.Info("rejecting getHeader by user agent")
-		w.WriteHeader(http.StatusNoContent)
+func (api *RelayAPI) afterGetHeader(slot uint64, proposerPubkey string, bid *common.GetHeaderResponse) {
+	blockHash := bid.BlockHash().String()
+	api.missedSlots.recordServed(slot, proposerPubkey, blockHash)
+	bidTrace, err := api.redis.GetBidTrace(slot, proposerPubkey, blockHash)
+	if err != nil || bidTrace == nil {
+		api.log.WithError(err).WithField("blockHash", blockHash).Warn("could not get bid trace for builder stats")
+		api.auditLog.record(database.AuditActionGetHeader, slot, proposerPubkey, "", bid.Value(), blockHash)
 		return
 	}
 
-	if api.ffForceGetHeader204 {
-		log.Info("forced getHeader 204 response")
-		w.WriteHeader(http.StatusNoContent)
-		return
+	builderPubkey := bidTrace.BuilderPubkey.String()
+	api.auditLog.record(database.AuditActionGetHeader, slot, proposerPubkey, builderPubkey, bid.Value(), blockHash)
+	api.metrics.incBuilderBidsServed(builderPubkey)
+	err = api.db.IncBlockBuilderStatsAfterGetHeader(builderPubkey)
+	if err != nil {
+		api.log.WithError(err).Error("failed to increment builder-stats after getHeader")
 	}
+}
 
-	// Only allow requests for the current slot until a certain cutoff time
-	if getHeaderRequestCutoffMs > 0 && msIntoSlot > 0 && msIntoSlot > int64(getHeaderRequestCutoffMs) {
-		log.Info("getHeader sent too late")
-		w.WriteHeader(http.StatusNoContent)
-		return
+// getBestBid returns the best bid from the in-memory bid cache if possible, and otherwise from Redis
+func (api *RelayAPI) getBestBid(slot uint64, parentHash, proposerPubkey string) (*common.GetHeaderResponse, error) {
+	if api.bidCache != nil {
+		if bid, found := api.bidCache.get(slot, parentHash, proposerPubkey); found {
+			return bid, nil
+		}
 	}
+	return api.redis.GetBestBid(slot, parentHash, proposerPubkey)
+}
 
-	bid, err := api.redis.GetBestBid(slot, parentHashHex, proposerPubkeyHex)
+// handleDebugBestBid returns the bid trace of the bid getHeader would currently serve, without counting it as served
+func (api *RelayAPI) handleDebugBestBid(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	parentHashHex := vars["parent_hash"]
+	proposerPubkeyHex := vars["pubkey"]
+	slot, err := strconv.ParseUint(vars["slot"], 10, 64)
 	if err != nil {
-		log.WithError(err).Error("could not get bid")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondError(w, http.StatusBadRequest, common.ErrInvalidSlot.Error())
 		return
 	}
 
-	if bid.Empty() {
-		w.WriteHeader(http.StatusNoContent)
+	bid, err := api.getBestBid(slot, parentHashHex, proposerPubkeyHex)
+	if err != nil {
+		api.log.WithError(err).Error("could not get bid")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if bid == nil || bid.Empty() {
+		api.RespondError(w, http.StatusNotFound, "no bid found")
 		return
 	}
 
-	// Error on bid without value
-	if bid.Value().Cmp(big.NewInt(0)) == 0 {
-		w.WriteHeader(http.StatusNoContent)
+	bidTrace, err := api.redis.GetBidTrace(slot, proposerPubkeyHex, bid.BlockHash().String())
+	if err != nil {
+		api.log.WithError(err).Error("could not get bid trace")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if bidTrace == nil {
+		api.RespondError(w, http.StatusNotFound, "no bid trace found")
 		return
 	}
-
-	log.WithFields(logrus.Fields{
-		"value":     bid.Value().String(),
-		"blockHash": bid.BlockHash().String(),
-	}).Info("bid delivered")
-	api.RespondOK(w, bid)
+	api.RespondOK(w, bidTrace)
 }
 
 func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request) {
@@ -1133,6 +2147,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	receivedAt := time.Now().UTC()
 	log := api.log.WithFields(logrus.Fields{
 		"method":                "getPayload",
+		"requestID":             getRequestID(req),
 		"ua":                    ua,
 		"mevBoostV":             common.GetMevBoostVersionFromUserAgent(ua),
 		"contentLength":         req.ContentLength,
@@ -1153,25 +2168,38 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 
 	// Read the body first, so we can decode it later
 	body, err := io.ReadAll(req.Body)
-	if err != nil {
+	if isBodyTooLarge(err) {
+		log.WithError(err).Warn("getPayload request body too large")
+		api.respondBodyTooLarge(w, maxRequestBodyBytes)
+		return
+	} else if err != nil {
 		if strings.Contains(err.Error(), "i/o timeout") {
 			log.WithError(err).Error("getPayload request failed to decode (i/o timeout)")
-			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			api.RespondErrorCode(w, http.StatusGatewayTimeout, ErrorCodeTimeout, err.Error())
 			return
 		}
 
 		log.WithError(err).Error("could not read body of request from the beacon node")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
 		return
 	}
 
-	// Decode payload
+	// Decode payload, a capella block is missing the blob commitments of a deneb block
 	payload := new(common.SignedBlindedBeaconBlock)
-	// TODO: add deneb support.
-	payload.Capella = new(capella.SignedBlindedBeaconBlock)
-	if err := json.NewDecoder(bytes.NewReader(body)).Decode(payload.Capella); err != nil {
-		log.WithError(err).Warn("failed to decode capella getPayload request")
-		api.RespondError(w, http.StatusBadRequest, "failed to decode capella payload")
+	denebPayload := new(apiv1deneb.SignedBlindedBeaconBlock)
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(denebPayload); err == nil {
+		payload.Deneb = denebPayload
+	} else {
+		payload.Capella = new(capella.SignedBlindedBeaconBlock)
+		if err := json.NewDecoder(bytes.NewReader(body)).Decode(payload.Capella); err != nil {
+			log.WithError(err).Warn("failed to decode capella getPayload request")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "failed to decode capella payload")
+			return
+		}
+	}
+	if (payload.Deneb != nil) != api.isDeneb(payload.Slot()) {
+		log.WithField("isDenebPayload", payload.Deneb != nil).Warn("getPayload request for the wrong fork")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeWrongFork, "payload does not match the fork of the slot")
 		return
 	}
 
@@ -1189,7 +2217,13 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		"proposerIndex":        payload.ProposerIndex(),
 	})
 
-	// Ensure the proposer index is expected
+	// Ensure the proposer index is expected, by the beacon node's duties and the duties of registered validators
+	if duty, found := api.GetProposerForSlot(payload.Slot()); found && duty.ValidatorIndex != payload.ProposerIndex() {
+		log.WithField("expectedProposerIndex", duty.ValidatorIndex).Warn("not the expected proposer index (beacon node duties)")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeProposerMismatch, "not the expected proposer index")
+		return
+	}
+
 	api.proposerDutiesLock.RLock()
 	slotDuty := api.proposerDutiesMap[payload.Slot()]
 	api.proposerDutiesLock.RUnlock()
@@ -1199,7 +2233,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		log = log.WithField("feeRecipient", slotDuty.Entry.Message.FeeRecipient)
 		if slotDuty.ValidatorIndex != payload.ProposerIndex() {
 			log.WithField("expectedProposerIndex", slotDuty.ValidatorIndex).Warn("not the expected proposer index")
-			api.RespondError(w, http.StatusBadRequest, "not the expected proposer index")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeProposerMismatch, "not the expected proposer index")
 			return
 		}
 	}
@@ -1208,7 +2242,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	proposerPubkey, found := api.datastore.GetKnownValidatorPubkeyByIndex(payload.ProposerIndex())
 	if !found {
 		log.Errorf("could not find proposer pubkey for index %d", payload.ProposerIndex())
-		api.RespondError(w, http.StatusBadRequest, "could not match proposer index to pubkey")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeUnknownValidator, "could not match proposer index to pubkey")
 		return
 	}
 
@@ -1219,20 +2253,19 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	pk, err := boostTypes.HexToPubkey(proposerPubkey.String())
 	if err != nil {
 		log.WithError(err).Warn("could not convert pubkey to types.PublicKey")
-		api.RespondError(w, http.StatusBadRequest, "could not convert pubkey to types.PublicKey")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPubkey, "could not convert pubkey to types.PublicKey")
 		return
 	}
 
-	// Validate proposer signature (first attempt verifying the Capella signature)
-	// TODO: add deneb support.
-	ok, err := boostTypes.VerifySignature(payload.Message(), api.opts.EthNetDetails.DomainBeaconProposerCapella, pk[:], payload.Signature())
+	// Validate proposer signature, using the domain of the fork of the slot
+	ok, err := api.verifySignature(true, payload.Message(), api.proposerDomain(payload.Slot()), pk[:], payload.Signature())
 	if !ok || err != nil {
 		if api.ffLogInvalidSignaturePayload {
 			txt, _ := json.Marshal(payload) //nolint:errchkjson
 			fmt.Println("payload_invalid_sig_capella: ", string(txt), "pubkey:", proposerPubkey.String())
 		}
 		log.WithError(err).Warn("could not verify capella payload signature")
-		api.RespondError(w, http.StatusBadRequest, "could not verify payload signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "could not verify payload signature")
 		return
 	}
 
@@ -1240,34 +2273,108 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	log = log.WithField("timestampAfterSignatureVerify", time.Now().UTC().UnixMilli())
 	log.Info("getPayload request received")
 
+	// A retry of an already delivered request gets the same response, without publishing the block again
+	if deliveredPayload, found := api.deliveredPayloads.get(payload.Slot(), proposerPubkey.String(), payload.BlockHash()); found {
+		// Never return a payload which doesn't match the signed header
+		err = EqExecutionPayloadToHeader(payload, deliveredPayload)
+		if err != nil {
+			log.WithError(err).Warn("ExecutionPayloadHeader not matching delivered ExecutionPayload")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPayload, "invalid execution payload header")
+			return
+		}
+		log.Info("execution payload was already delivered, responding with the same payload")
+		api.RespondOK(w, deliveredPayload)
+		return
+	}
+
+	// Bids of peer relays were never submitted to us, the peer relay has the payload and publishes the block
+	if api.federatedBids != nil {
+		if fedBid, found := api.federatedBids.getByBlockHash(payload.Slot(), payload.BlockHash()); found {
+			log = log.WithFields(logrus.Fields{
+				"federated": true,
+				"peerRelay": fedBid.peer.String(),
+			})
+			if !api.checkAndSetPayloadDelivered(w, log, payload) {
+				return
+			}
+			log.Info("proxying getPayload to peer relay")
+			getPayloadResp, err := api.proxyGetPayload(fedBid.peer, body, payload)
+			if err != nil {
+				log.WithError(err).Error("failed to get execution payload from peer relay")
+				api.RespondErrorCode(w, http.StatusBadGateway, ErrorCodePeerRelayFailed, "failed to get execution payload from peer relay")
+				return
+			}
+			api.deliveredPayloads.set(payload.Slot(), proposerPubkey.String(), payload.BlockHash(), getPayloadResp)
+			api.RespondOK(w, getPayloadResp)
+			log.WithField("numTx", getPayloadResp.NumTx()).Info("execution payload delivered by peer relay")
+			api.auditLog.record(database.AuditActionGetPayload, payload.Slot(), proposerPubkey.String(), "", fedBid.value, payload.BlockHash())
+			return
+		}
+	}
+
+	// Limit the concurrent payload fetches and publishes, to not overwhelm the beacon node. Only requests with a valid
+	// proposer signature are counted, so that others can't take the slots of the proposer.
+	if api.getPayloadLimiter != nil {
+		immediate, ok := api.getPayloadLimiter.acquire(req.Context())
+		if !immediate {
+			log.WithFields(logrus.Fields{
+				"inFlight": api.getPayloadLimiter.inFlight(),
+				"queued":   ok,
+			}).Warn("getPayload concurrency limit reached")
+		}
+		if !ok {
+			api.metrics.incGetPayloadLimited()
+			api.RespondErrorCode(w, http.StatusServiceUnavailable, ErrorCodeServiceUnavailable, "too many concurrent getPayload requests")
+			return
+		}
+		defer api.getPayloadLimiter.release()
+	}
+
 	// TODO: store signed blinded block in database (always)
 
 	// Get the response - from Redis, Memcache or DB
 	// note that recent mev-boost versions only send getPayload to relays that provided the bid
-	getPayloadResp, err := api.datastore.GetGetPayloadResponse(payload.Slot(), proposerPubkey.String(), payload.BlockHash())
+	getPayloadResp, err := api.getPayloadResponseWithTimeout(log, payload.Slot(), proposerPubkey.String(), payload.BlockHash())
 	if err != nil || getPayloadResp == nil {
 		log.WithError(err).Warn("failed getting execution payload (1/2)")
 		time.Sleep(time.Duration(timeoutGetPayloadRetryMs) * time.Millisecond)
 
 		// Try again
-		getPayloadResp, err = api.datastore.GetGetPayloadResponse(payload.Slot(), proposerPubkey.String(), payload.BlockHash())
+		getPayloadResp, err = api.getPayloadResponseWithTimeout(log, payload.Slot(), proposerPubkey.String(), payload.BlockHash())
 		if err != nil || getPayloadResp == nil {
 			// Still not found! Error out now.
+			failure := &database.GetPayloadFailureEntry{ //nolint:exhaustruct
+				Slot:           payload.Slot(),
+				ProposerPubkey: proposerPubkey.String(),
+				BlockHash:      payload.BlockHash(),
+				Reason:         database.GetPayloadFailureError,
+				RequestID:      getRequestID(req),
+				UserAgent:      ua,
+				IP:             api.getClientIP(req),
+				MsIntoSlot:     msIntoSlot,
+				FailedAt:       time.Now().UTC(),
+			}
+			if err != nil {
+				failure.Error = err.Error()
+			}
 			if errors.Is(err, datastore.ErrExecutionPayloadNotFound) {
 				// Couldn't find the execution payload, maybe it never was submitted to our relay! Check that now
 				_, err := api.db.GetBlockSubmissionEntry(payload.Slot(), proposerPubkey.String(), payload.BlockHash())
 				if errors.Is(err, sql.ErrNoRows) {
+					failure.Reason = database.GetPayloadFailureNeverSubmitted
 					log.Warn("failed getting execution payload (2/2) - payload not found, block was never submitted to this relay")
-					api.RespondError(w, http.StatusBadRequest, "no execution payload for this request - block was never seen by this relay")
+					api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadNotFound, "no execution payload for this request - block was never seen by this relay")
 				} else if err != nil {
 					log.WithError(err).Error("failed getting execution payload (2/2) - payload not found, and error on checking bids")
 				} else {
+					failure.Reason = database.GetPayloadFailureSubmissionFound
 					log.Error("failed getting execution payload (2/2) - payload not found, but found bid in database")
 				}
 			} else { // some other error
 				log.WithError(err).Error("failed getting execution payload (2/2) - error")
 			}
-			api.RespondError(w, http.StatusBadRequest, "no execution payload for this request")
+			api.getPayloadAlerts.record(failure)
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadNotFound, "no execution payload for this request")
 			return
 		}
 	}
@@ -1276,27 +2383,10 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	log = log.WithField("timestampAfterLoadResponse", time.Now().UTC().UnixMilli())
 
 	// Check whether getPayload has already been called -- TODO: do we need to allow multiple submissions of one blinded block?
-	err = api.redis.CheckAndSetLastSlotAndHashDelivered(payload.Slot(), payload.BlockHash())
-	log = log.WithField("timestampAfterAlreadyDeliveredCheck", time.Now().UTC().UnixMilli())
-	if err != nil {
-		if errors.Is(err, datastore.ErrAnotherPayloadAlreadyDeliveredForSlot) {
-			// BAD VALIDATOR, 2x GETPAYLOAD FOR DIFFERENT PAYLOADS
-			log.Warn("validator called getPayload twice for different payload hashes")
-			api.RespondError(w, http.StatusBadRequest, "another payload for this slot was already delivered")
-			return
-		} else if errors.Is(err, datastore.ErrPastSlotAlreadyDelivered) {
-			// BAD VALIDATOR, 2x GETPAYLOAD FOR PAST SLOT
-			log.Warn("validator called getPayload for past slot")
-			api.RespondError(w, http.StatusBadRequest, "payload for this slot was already delivered")
-			return
-		} else if errors.Is(err, redis.TxFailedErr) {
-			// BAD VALIDATOR, 2x GETPAYLOAD + RACE
-			log.Warn("validator called getPayload twice (race)")
-			api.RespondError(w, http.StatusBadRequest, "payload for this slot was already delivered (race)")
-			return
-		}
-		log.WithError(err).Error("redis.CheckAndSetLastSlotAndHashDelivered failed")
+	if !api.checkAndSetPayloadDelivered(w, log, payload) {
+		return
 	}
+	log = log.WithField("timestampAfterAlreadyDeliveredCheck", time.Now().UTC().UnixMilli())
 
 	// Handle early/late requests
 	if msIntoSlot < 0 {
@@ -1308,10 +2398,10 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 			log.Info("waiting until slot start t=0")
 			time.Sleep(time.Duration(delayMillis) * time.Millisecond)
 		}
-	} else if getPayloadRequestCutoffMs > 0 && msIntoSlot > int64(getPayloadRequestCutoffMs) {
+	} else if api.opts.GetPayloadCutoffMs > 0 && msIntoSlot > int64(api.opts.GetPayloadCutoffMs) {
 		// Reject requests after cutoff time
 		log.Warn("getPayload sent too late")
-		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("sent too late - %d ms into slot", msIntoSlot))
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeRequestTooLate, fmt.Sprintf("sent too late - %d ms into slot, after the cutoff of %d ms", msIntoSlot, api.opts.GetPayloadCutoffMs))
 
 		go func() {
 			err := api.db.InsertTooLateGetPayload(payload.Slot(), proposerPubkey.String(), payload.BlockHash(), slotStartTimestamp, uint64(receivedAt.UnixMilli()), uint64(decodeTime.UnixMilli()), uint64(msIntoSlot))
@@ -1326,7 +2416,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	err = EqExecutionPayloadToHeader(payload, getPayloadResp)
 	if err != nil {
 		log.WithError(err).Warn("ExecutionPayloadHeader not matching known ExecutionPayload")
-		api.RespondError(w, http.StatusBadRequest, "invalid execution payload header")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPayload, "invalid execution payload header")
 		return
 	}
 
@@ -1337,7 +2427,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	code, err := api.beaconClient.PublishBlock(signedBeaconBlock) // errors are logged inside
 	if err != nil || code != http.StatusOK {
 		log.WithError(err).WithField("code", code).Error("failed to publish block")
-		api.RespondError(w, http.StatusBadRequest, "failed to publish block")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePublishFailed, "failed to publish block")
 		return
 	}
 	timeAfterPublish := time.Now().UTC().UnixMilli()
@@ -1345,6 +2435,8 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	log = log.WithField("timestampAfterPublishing", timeAfterPublish)
 	log.WithField("msNeededForPublishing", msNeededForPublishing).Info("block published through beacon node")
 
+	api.deliveredPayloads.set(payload.Slot(), proposerPubkey.String(), payload.BlockHash(), getPayloadResp)
+
 	// give the beacon network some time to propagate the block
 	time.Sleep(time.Duration(getPayloadResponseDelayMs) * time.Millisecond)
 
@@ -1361,8 +2453,33 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		bidTrace, err := api.redis.GetBidTrace(payload.Slot(), proposerPubkey.String(), payload.BlockHash())
 		if err != nil {
 			log.WithError(err).Error("failed to get bidTrace for delivered payload from redis")
+			bidTrace = nil
+		}
+
+		// Check that the block pays the proposer's registered fee recipient. The header was signed already, so refusing
+		// the payload would only have missed the slot: mismatches are enforced on getHeader, and only logged here.
+		registeredFeeRecipient, actualFeeRecipient, feeRecipientMatches, err := api.checkFeeRecipient(payload.Slot(), getPayloadResp, bidTrace)
+		if err != nil {
+			log.WithError(err).Error("could not verify fee recipient")
+		} else if !feeRecipientMatches {
+			log.WithFields(logrus.Fields{
+				"registeredFeeRecipient": registeredFeeRecipient,
+				"actualFeeRecipient":     actualFeeRecipient,
+				"blockFeeRecipient":      getPayloadResp.FeeRecipient(),
+			}).Error("CRITICAL: delivered block does not pay the registered fee recipient")
+		}
+
+		if bidTrace == nil {
 			bidTrace = &common.BidTraceV2{} //nolint:exhaustruct
+		} else if err := api.redis.RecordBuilderOutcome(context.Background(), bidTrace.BuilderPubkey.String(), true); err != nil {
+			log.WithError(err).Error("failed to update the builder reputation")
+		}
+
+		var value *big.Int
+		if bidTrace.Value != nil {
+			value = bidTrace.Value.ToBig()
 		}
+		api.auditLog.record(database.AuditActionGetPayload, payload.Slot(), proposerPubkey.String(), bidTrace.BuilderPubkey.String(), value, payload.BlockHash())
 
 		err = api.db.SaveDeliveredPayload(bidTrace, payload, decodeTime, msNeededForPublishing)
 		if err != nil {
@@ -1373,6 +2490,7 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 		}
 
 		// Increment builder stats
+		api.metrics.incBuilderPayloadsDelivered(bidTrace.BuilderPubkey.String())
 		err = api.db.IncBlockBuilderStatsAfterGetPayload(bidTrace.BuilderPubkey.String())
 		if err != nil {
 			log.WithError(err).Error("failed to increment builder-stats after getPayload")
@@ -1433,6 +2551,114 @@ func (api *RelayAPI) handleGetPayload(w http.ResponseWriter, req *http.Request)
 	}()
 }
 
+type getPayloadResponseResult struct {
+	resp *common.VersionedExecutionPayload
+	err  error
+}
+
+// getPayloadResponseWithTimeout loads the getPayload response from the datastore. If that takes longer than the getPayload
+// timeout, an error is logged (to alert on), but a response arriving within the grace period is still used.
+func (api *RelayAPI) getPayloadResponseWithTimeout(log *logrus.Entry, slot uint64, proposerPubkey, blockHash string) (*common.VersionedExecutionPayload, error) {
+	resultC := make(chan getPayloadResponseResult, 1)
+	go func() {
+		resp, err := api.datastore.GetGetPayloadResponse(slot, proposerPubkey, blockHash)
+		resultC <- getPayloadResponseResult{resp, err}
+	}()
+
+	timeout := time.NewTimer(api.opts.GetPayloadTimeout)
+	defer timeout.Stop()
+
+	select {
+	case res := <-resultC:
+		return res.resp, res.err
+	case <-timeout.C:
+		log.WithFields(logrus.Fields{
+			"slot":           slot,
+			"proposerPubkey": proposerPubkey,
+			"timeoutMs":      api.opts.GetPayloadTimeout.Milliseconds(),
+		}).Error("getPayload datastore lookup exceeded timeout")
+	}
+
+	grace := time.NewTimer(time.Duration(getPayloadTimeoutGraceMs) * time.Millisecond)
+	defer grace.Stop()
+
+	select {
+	case res := <-resultC:
+		return res.resp, res.err
+	case <-grace.C:
+		return nil, ErrGetPayloadTimeout
+	}
+}
+
+// checkAndSetPayloadDelivered marks the slot as delivered with the block hash of the payload. It responds with an error
+// and returns false if another payload, or a later slot, was already delivered.
+func (api *RelayAPI) checkAndSetPayloadDelivered(w http.ResponseWriter, log *logrus.Entry, payload *common.SignedBlindedBeaconBlock) bool {
+	err := api.redis.CheckAndSetLastSlotAndHashDelivered(payload.Slot(), payload.BlockHash())
+	if err != nil {
+		if errors.Is(err, datastore.ErrAnotherPayloadAlreadyDeliveredForSlot) {
+			// BAD VALIDATOR, 2x GETPAYLOAD FOR DIFFERENT PAYLOADS
+			log.Warn("validator called getPayload twice for different payload hashes")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadAlreadyDelivered, "another payload for this slot was already delivered")
+			return false
+		} else if errors.Is(err, datastore.ErrPastSlotAlreadyDelivered) {
+			// BAD VALIDATOR, 2x GETPAYLOAD FOR PAST SLOT
+			log.Warn("validator called getPayload for past slot")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadAlreadyDelivered, "payload for this slot was already delivered")
+			return false
+		} else if errors.Is(err, redis.TxFailedErr) {
+			// BAD VALIDATOR, 2x GETPAYLOAD + RACE
+			log.Warn("validator called getPayload twice (race)")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadAlreadyDelivered, "payload for this slot was already delivered (race)")
+			return false
+		}
+		log.WithError(err).Error("redis.CheckAndSetLastSlotAndHashDelivered failed")
+	}
+	return true
+}
+
+// checkFeeRecipient verifies that a block pays the fee recipient of the proposer's registration on file: either as the
+// block's fee recipient, or as proposer_fee_recipient of the bid trace (the payment the block simulation verified, nil
+// if the bid trace is not available). Without a registration on file for the slot, there's nothing to check against.
+func (api *RelayAPI) checkFeeRecipient(slot uint64, getPayloadResp *common.VersionedExecutionPayload, bidTrace *common.BidTraceV2) (registered, actual string, match bool, err error) {
+	api.proposerDutiesLock.RLock()
+	slotDuty := api.proposerDutiesMap[slot]
+	api.proposerDutiesLock.RUnlock()
+	if slotDuty == nil || slotDuty.Entry == nil {
+		return "", "", true, nil
+	}
+
+	registered = slotDuty.Entry.Message.FeeRecipient.String()
+	actual = getPayloadResp.FeeRecipient()
+	if strings.EqualFold(registered, actual) {
+		return registered, actual, true, nil
+	} else if bidTrace == nil {
+		return registered, "", false, ErrMissingBidTrace
+	}
+	actual = bidTrace.ProposerFeeRecipient.String()
+	return registered, actual, strings.EqualFold(registered, actual), nil
+}
+
+// checkBidFeeRecipient verifies that the proposer_fee_recipient of the bid is the one of the proposer's registration on
+// file for the slot
+func (api *RelayAPI) checkBidFeeRecipient(slot uint64, proposerPubkey, blockHash string) (registered, actual string, match bool, err error) {
+	api.proposerDutiesLock.RLock()
+	slotDuty := api.proposerDutiesMap[slot]
+	api.proposerDutiesLock.RUnlock()
+	if slotDuty == nil || slotDuty.Entry == nil {
+		return "", "", true, nil
+	}
+	registered = slotDuty.Entry.Message.FeeRecipient.String()
+
+	bidTrace, err := api.redis.GetBidTrace(slot, proposerPubkey, blockHash)
+	if err != nil {
+		return registered, "", false, err
+	} else if bidTrace == nil {
+		return registered, "", false, ErrMissingBidTrace
+	}
+	actual = bidTrace.ProposerFeeRecipient.String()
+	return registered, actual, strings.EqualFold(registered, actual), nil
+}
+
 // --------------------
 //
 //	BLOCK BUILDER APIS
@@ -1453,7 +2679,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	var prevTime, nextTime time.Time
 
 	headSlot := api.headSlot.Load()
-	receivedAt := time.Now().UTC()
+	receivedAt := api.now().UTC()
 	prevTime = receivedAt
 
 	args := req.URL.Query()
@@ -1461,6 +2687,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 
 	log := api.log.WithFields(logrus.Fields{
 		"method":                "submitNewBlock",
+		"requestID":             getRequestID(req),
 		"contentLength":         req.ContentLength,
 		"headSlot":              headSlot,
 		"cancellationEnabled":   isCancellationEnabled,
@@ -1476,10 +2703,26 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		}).Info("request finished")
 	}()
 
+	if api.maintenanceMode.Load() {
+		log.Info("maintenance mode: rejecting submission")
+		api.RespondErrorCode(w, http.StatusServiceUnavailable, ErrorCodeMaintenance, "relay is in maintenance mode")
+		return
+	}
+
+	certIdentity, hasCert := builderCertIdentity(req)
+	if api.builderCAs != nil {
+		if !hasCert {
+			log.Info("rejecting submission - no builder client certificate")
+			api.RespondErrorCode(w, http.StatusUnauthorized, ErrorCodeUnauthorized, "a builder client certificate is required")
+			return
+		}
+		log = log.WithField("certIdentity", certIdentity)
+	}
+
 	// If cancellations are disabled but builder requested it, return error
 	if isCancellationEnabled && !api.ffEnableCancellations {
 		log.Info("builder submitted with cancellations enabled, but feature flag is disabled")
-		api.RespondError(w, http.StatusBadRequest, "cancellations are disabled")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeCancellationsDisabled, "cancellations are disabled")
 		return
 	}
 
@@ -1491,16 +2734,19 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		r, err = gzip.NewReader(req.Body)
 		if err != nil {
 			log.WithError(err).Warn("could not create gzip reader")
-			api.RespondError(w, http.StatusBadRequest, err.Error())
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
 			return
 		}
 	}
 
-	limitReader := io.LimitReader(r, 10*1024*1024) // 10 MB
-	requestPayloadBytes, err := io.ReadAll(limitReader)
-	if err != nil {
+	requestPayloadBytes, err := readAllLimited(r, api.opts.MaxSubmitBytes)
+	if isBodyTooLarge(err) {
+		log.WithError(err).Warn("submission too large")
+		api.respondBodyTooLarge(w, api.opts.MaxSubmitBytes)
+		return
+	} else if err != nil {
 		log.WithError(err).Warn("could not read payload")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
 		return
 	}
 
@@ -1517,7 +2763,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 			// SSZ decoding failed. try JSON as fallback (some builders used octet-stream for json before)
 			if err2 := json.Unmarshal(requestPayloadBytes, payload); err2 != nil {
 				log.WithError(fmt.Errorf("%w / %w", err, err2)).Warn("could not decode payload - SSZ or JSON")
-				api.RespondError(w, http.StatusBadRequest, err.Error())
+				api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
 				return
 			}
 			log = log.WithField("reqContentType", "json")
@@ -1528,7 +2774,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		log = log.WithField("reqContentType", "json")
 		if err := json.Unmarshal(requestPayloadBytes, payload); err != nil {
 			log.WithError(err).Warn("could not decode payload - JSON")
-			api.RespondError(w, http.StatusBadRequest, err.Error())
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
 			return
 		}
 	}
@@ -1552,24 +2798,62 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	})
 
 	if payload.Message() == nil || !payload.HasExecutionPayload() {
-		api.RespondError(w, http.StatusBadRequest, "missing parts of the payload")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidRequest, "missing parts of the payload")
 		return
 	}
 
-	// TODO: add deneb support.
-	if payload.Capella == nil {
-		log.Info("rejecting submission - non capella payload for capella fork")
-		api.RespondError(w, http.StatusBadRequest, "not capella payload")
+	// repeated rejections of the builder's submissions for the same reason are summarized at the end of the slot
+	logRejection := func(log *logrus.Entry, level logrus.Level, reason string) {
+		api.rejectionLogs.logRejection(log, level, payload.Slot(), payload.BuilderPubkey().String(), reason)
+	}
+
+	if api.isDeneb(payload.Slot()) {
+		if payload.Deneb == nil {
+			logRejection(log, logrus.InfoLevel, "rejecting submission - non deneb payload for deneb fork")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeWrongFork, "not deneb payload")
+			return
+		}
+		if err := payload.Deneb.BlobsBundle.Validate(); err != nil {
+			logRejection(log.WithError(err), logrus.InfoLevel, "rejecting submission - invalid blobs bundle")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPayload, err.Error())
+			return
+		}
+		log = log.WithField("numBlobs", len(payload.Deneb.BlobsBundle.Blobs))
+	} else if payload.Capella == nil {
+		// includes deneb payloads, blob data is rejected before the deneb fork
+		logRejection(log, logrus.InfoLevel, "rejecting submission - non capella payload for capella fork")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeWrongFork, "not capella payload")
 		return
 	}
 
 	if payload.Slot() <= headSlot {
-		log.Info("submitNewBlock failed: submission for past slot")
-		api.RespondError(w, http.StatusBadRequest, "submission for past slot")
+		logRejection(log, logrus.InfoLevel, "submitNewBlock failed: submission for past slot")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSlot, "submission for past slot")
 		return
 	}
 
+	if api.opts.SubmitStartMs > 0 {
+		msIntoSlot := submissionMsIntoSlot(receivedAt, api.genesisInfo.Data.GenesisTime, payload.Slot())
+		if msIntoSlot < int64(api.opts.SubmitStartMs) {
+			logRejection(log.WithField("msIntoSlot", msIntoSlot), logrus.InfoLevel, "rejecting submission - too early in the slot")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeRequestTooEarly, fmt.Sprintf("sent too early - %d ms into slot, before the start of submissions at %d ms", msIntoSlot, api.opts.SubmitStartMs))
+			return
+		}
+	}
+
+	// The proposer pubkey has to match the registration for the slot, which was validated on registration
 	builderPubkey := payload.BuilderPubkey()
+	if err := common.ValidateBLSPublicKey(builderPubkey[:]); err != nil {
+		log.WithError(err).Info("rejecting submission - invalid builder pubkey")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPubkey, fmt.Sprintf("invalid builder pubkey: %s", err.Error()))
+		return
+	}
+	if !api.builderAllowlist.isAllowed(builderPubkey.String()) {
+		logRejection(log, logrus.InfoLevel, "rejecting submission - builder not on the allowlist")
+		api.RespondErrorCode(w, http.StatusForbidden, ErrorCodeBuilderNotAllowed, "builder not on the allowlist")
+		return
+	}
+
 	builderEntry, ok := api.blockBuildersCache[builderPubkey.String()]
 	if !ok {
 		log.Warnf("unable to read builder: %s from the builder cache, using low-prio and no collateral", builderPubkey.String())
@@ -1580,20 +2864,27 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 				IsBlacklisted: false,
 			},
 			collateral: big.NewInt(0),
+			builderID:  "",
 		}
 	}
 	log = log.WithField("builderIsHighPrio", builderEntry.status.IsHighPrio)
 
+	if api.builderCAs != nil && !isBuilderCertIdentity(certIdentity, builderPubkey.String(), builderEntry) {
+		logRejection(log, logrus.InfoLevel, "rejecting submission - builder client certificate doesn't match the builder")
+		api.RespondErrorCode(w, http.StatusForbidden, ErrorCodeBuilderNotAllowed, "builder client certificate doesn't match the builder pubkey or ID")
+		return
+	}
+
 	// Timestamp check
 	expectedTimestamp := api.genesisInfo.Data.GenesisTime + (payload.Slot() * common.SecondsPerSlot)
 	if payload.Timestamp() != expectedTimestamp {
 		log.Warnf("incorrect timestamp. got %d, expected %d", payload.Timestamp(), expectedTimestamp)
-		api.RespondError(w, http.StatusBadRequest, fmt.Sprintf("incorrect timestamp. got %d, expected %d", payload.Timestamp(), expectedTimestamp))
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidTimestamp, fmt.Sprintf("incorrect timestamp. got %d, expected %d", payload.Timestamp(), expectedTimestamp))
 		return
 	}
 
 	if builderEntry.status.IsBlacklisted {
-		log.Info("builder is blacklisted")
+		logRejection(log, logrus.InfoLevel, "builder is blacklisted")
 		time.Sleep(200 * time.Millisecond)
 		w.WriteHeader(http.StatusOK)
 		return
@@ -1601,7 +2892,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 
 	// In case only high-prio requests are accepted, fail others
 	if api.ffDisableLowPrioBuilders && !builderEntry.status.IsHighPrio {
-		log.Info("rejecting low-prio builder (ff-disable-low-prio-builders)")
+		logRejection(log, logrus.InfoLevel, "rejecting low-prio builder (ff-disable-low-prio-builders)")
 		time.Sleep(200 * time.Millisecond)
 		w.WriteHeader(http.StatusOK)
 		return
@@ -1614,30 +2905,30 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	slotDuty := api.proposerDutiesMap[payload.Slot()]
 	api.proposerDutiesLock.RUnlock()
 	if slotDuty == nil {
-		log.Warn("could not find slot duty")
-		api.RespondError(w, http.StatusBadRequest, "could not find slot duty")
+		logRejection(log, logrus.WarnLevel, "could not find slot duty")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeNoProposerDuty, "could not find slot duty")
 		return
 	} else if !strings.EqualFold(slotDuty.Entry.Message.FeeRecipient.String(), payload.ProposerFeeRecipient()) {
-		log.WithFields(logrus.Fields{
+		logRejection(log.WithFields(logrus.Fields{
 			"expectedFeeRecipient": slotDuty.Entry.Message.FeeRecipient.String(),
 			"actualFeeRecipient":   payload.ProposerFeeRecipient(),
-		}).Info("fee recipient does not match")
-		api.RespondError(w, http.StatusBadRequest, "fee recipient does not match")
+		}), logrus.InfoLevel, "fee recipient does not match")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeFeeRecipientMismatch, "fee recipient does not match")
 		return
 	}
 
 	// Don't accept blocks with 0 value
 	if payload.Value().Cmp(ZeroU256.BigInt()) == 0 || payload.NumTx() == 0 {
-		log.Info("submitNewBlock failed: block with 0 value or no txs")
-		w.WriteHeader(http.StatusOK)
+		logRejection(log, logrus.InfoLevel, "submitNewBlock failed: block with 0 value or no txs")
+		api.RespondOK(w, newSubmitBlockRejectedResponse(submitBlockReasonZeroValue))
 		return
 	}
 
 	// Sanity check the submission
 	err = SanityCheckBuilderBlockSubmission(payload)
 	if err != nil {
-		log.WithError(err).Info("block submission sanity checks failed")
-		api.RespondError(w, http.StatusBadRequest, err.Error())
+		logRejection(log.WithError(err), logrus.InfoLevel, "block submission sanity checks failed")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPayload, err.Error())
 		return
 	}
 
@@ -1647,15 +2938,15 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	attrs, ok := api.payloadAttributes[payload.ParentHash()]
 	api.payloadAttributesLock.RUnlock()
 	if !ok || payload.Slot() != attrs.slot {
-		log.Warn("payload attributes not (yet) known")
-		api.RespondError(w, http.StatusBadRequest, "payload attributes not (yet) known")
+		logRejection(log, logrus.WarnLevel, "payload attributes not (yet) known")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadAttributesUnknown, "payload attributes not (yet) known")
 		return
 	}
 
 	if payload.Random() != attrs.payloadAttributes.PrevRandao {
 		msg := fmt.Sprintf("incorrect prev_randao - got: %s, expected: %s", payload.Random(), attrs.payloadAttributes.PrevRandao)
-		log.Info(msg)
-		api.RespondError(w, http.StatusBadRequest, msg)
+		logRejection(log.WithField("error", msg), logrus.InfoLevel, "incorrect prev_randao")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPayload, msg)
 		return
 	}
 
@@ -1663,14 +2954,14 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		withdrawalsRoot, err := ComputeWithdrawalsRoot(payload.Withdrawals())
 		if err != nil {
 			log.WithError(err).Warn("could not compute withdrawals root from payload")
-			api.RespondError(w, http.StatusBadRequest, "could not compute withdrawals root")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPayload, "could not compute withdrawals root")
 			return
 		}
 
 		if withdrawalsRoot != attrs.withdrawalsRoot {
 			msg := fmt.Sprintf("incorrect withdrawals root - got: %s, expected: %s", withdrawalsRoot.String(), attrs.withdrawalsRoot.String())
-			log.Info(msg)
-			api.RespondError(w, http.StatusBadRequest, msg)
+			logRejection(log.WithField("error", msg), logrus.InfoLevel, "incorrect withdrawals root")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidPayload, msg)
 			return
 		}
 	}
@@ -1678,15 +2969,15 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	// Verify the signature
 	log = log.WithField("timestampBeforeSignatureCheck", time.Now().UTC().UnixMilli())
 	signature := payload.Signature()
-	ok, err = boostTypes.VerifySignature(payload.Message(), api.opts.EthNetDetails.DomainBuilder, builderPubkey[:], signature[:])
+	ok, err = api.verifySignature(false, payload.Message(), api.opts.EthNetDetails.DomainBuilder, builderPubkey[:], signature[:])
 	log = log.WithField("timestampAfterSignatureCheck", time.Now().UTC().UnixMilli())
 	if err != nil {
 		log.WithError(err).Warn("failed verifying builder signature")
-		api.RespondError(w, http.StatusBadRequest, "failed verifying builder signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "failed verifying builder signature")
 		return
 	} else if !ok {
-		log.Warn("invalid builder signature")
-		api.RespondError(w, http.StatusBadRequest, "invalid signature")
+		logRejection(log, logrus.WarnLevel, "invalid builder signature")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidSignature, "invalid signature")
 		return
 	}
 
@@ -1698,8 +2989,8 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	if err != nil && !errors.Is(err, redis.Nil) {
 		log.WithError(err).Error("failed to get delivered payload slot from redis")
 	} else if payload.Slot() <= slotLastPayloadDelivered {
-		log.Info("rejecting submission because payload for this slot was already delivered")
-		api.RespondError(w, http.StatusBadRequest, "payload for this slot was already delivered")
+		logRejection(log, logrus.InfoLevel, "rejecting submission because payload for this slot was already delivered")
+		api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodePayloadAlreadyDelivered, "payload for this slot was already delivered")
 		return
 	}
 
@@ -1707,12 +2998,20 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	// SUBMISSION SIGNATURE IS VALIDATED AND BID IS GENERALLY LOOKING GOOD
 	// -------------------------------------------------------------------
 
+	if api.opts.DryRun {
+		log.Info("dry-run: would simulate and store the block submission")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
 	// channel to send simulation result to the deferred function
 	simResultC := make(chan *blockSimResult, 1)
 	var eligibleAt time.Time // will be set once the bid is ready
 
 	// Deferred saving of the builder submission to database (whenever this function ends)
 	defer func() {
+		api.metrics.incBuilderSubmissions(payload.BuilderPubkey().String())
+
 		savePayloadToDatabase := !api.ffDisablePayloadDBStorage
 		var simResult *blockSimResult
 		select {
@@ -1732,6 +3031,13 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		if err != nil {
 			log.WithError(err).Error("failed to upsert block-builder-entry")
 		}
+
+		// Invalid blocks lower the builder's reputation, which decides between bids of equal value
+		if simResult.validationErr != nil {
+			if err := api.redis.RecordBuilderOutcome(context.Background(), payload.BuilderPubkey().String(), false); err != nil {
+				log.WithError(err).Error("failed to update the builder reputation")
+			}
+		}
 	}()
 
 	// Grab floor bid value
@@ -1753,15 +3059,18 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		err := api.redis.DelBuilderBid(context.Background(), tx, payload.Slot(), payload.ParentHash(), payload.ProposerPubkey(), payload.BuilderPubkey().String())
 		if err != nil {
 			log.WithError(err).Error("failed processing cancellable bid below floor")
-			api.RespondError(w, http.StatusInternalServerError, "failed processing cancellable bid below floor")
+			api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternal, "failed processing cancellable bid below floor")
 			return
 		}
-		api.Respond(w, http.StatusAccepted, "accepted bid below floor, skipped validation")
+		if api.bidCache != nil {
+			api.bidCache.delete(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey())
+		}
+		api.Respond(w, http.StatusAccepted, newSubmitBlockBelowFloorResponse(floorBidValue))
 		return
 	} else if !isCancellationEnabled && isBidAtOrBelowFloor { // without cancellations: if at or below floor -> ignore
 		simResultC <- &blockSimResult{false, false, nil, nil}
 		log.Info("submission below floor bid value, without cancellation")
-		api.RespondMsg(w, http.StatusAccepted, "accepted bid below floor, skipped validation")
+		api.Respond(w, http.StatusAccepted, newSubmitBlockBelowFloorResponse(floorBidValue))
 		return
 	}
 
@@ -1806,8 +3115,14 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 			RegisteredGasLimit:        slotDuty.Entry.Message.GasLimit,
 		},
 	}
-	// With sufficient collateral, process the block optimistically.
-	if builderEntry.status.IsOptimistic &&
+	if payload.Deneb != nil {
+		opts.req.ParentBeaconBlockRoot = attrs.payloadAttributes.ParentBeaconBlockRoot
+	}
+	// Without a block simulation URL, accept the block as is. With sufficient collateral, process the block optimistically.
+	if api.blockSimRateLimiter == nil {
+		simResultC <- &blockSimResult{false, false, nil, nil}
+	} else if api.opts.Optimistic &&
+		builderEntry.status.IsOptimistic &&
 		builderEntry.collateral.Cmp(payload.Value()) >= 0 &&
 		payload.Slot() == api.optimisticSlot.Load() {
 		go api.processOptimisticBlock(opts, simResultC)
@@ -1822,14 +3137,14 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		})
 		if requestErr != nil { // Request error
 			if os.IsTimeout(requestErr) {
-				api.RespondError(w, http.StatusGatewayTimeout, "validation request timeout")
+				api.RespondErrorCode(w, http.StatusGatewayTimeout, ErrorCodeSimulationTimeout, "validation request timeout")
 			} else {
-				api.RespondError(w, http.StatusBadRequest, requestErr.Error())
+				api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeSimulationFailed, requestErr.Error())
 			}
 			return
 		} else {
 			if validationErr != nil {
-				api.RespondError(w, http.StatusBadRequest, validationErr.Error())
+				api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeInvalidBlock, validationErr.Error())
 				return
 			}
 		}
@@ -1855,7 +3170,7 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 			log.WithError(err).Error("failed getting latest payload receivedAt from redis")
 		} else if receivedAt.UnixMilli() < latestPayloadReceivedAt {
 			log.Infof("already have a newer payload: now=%d / prev=%d", receivedAt.UnixMilli(), latestPayloadReceivedAt)
-			api.RespondError(w, http.StatusBadRequest, "already using a newer payload")
+			api.RespondErrorCode(w, http.StatusBadRequest, ErrorCodeOutdatedSubmission, "already using a newer payload")
 			return
 		}
 	}
@@ -1884,13 +3199,32 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 	//
 	// Save to Redis
 	//
-	updateBidResult, err := api.redis.SaveBidAndUpdateTopBid(context.Background(), tx, &bidTrace, payload, getPayloadResponse, getHeaderResponse, receivedAt, isCancellationEnabled, floorBidValue)
+	// With submission batching, the bid is saved at the end of the batch window (the bid cache is updated along, to keep
+	// the order of the top bid updates)
+	var updateBidResult datastore.SaveBidAndUpdateTopBidResponse
+	slotStart := time.Unix(int64(payload.Timestamp()), 0)
+	api.submissionBatcher.do(receivedAt, slotStart, func() {
+		updateBidResult, err = api.redis.SaveBidAndUpdateTopBid(context.Background(), tx, &bidTrace, payload, getPayloadResponse, getHeaderResponse, receivedAt, isCancellationEnabled, floorBidValue)
+		if err == nil {
+			api.updateBidCache(payload, getHeaderResponse, updateBidResult)
+		}
+	})
 	if err != nil {
 		log.WithError(err).Error("could not save bid and update top bids")
-		api.RespondError(w, http.StatusInternalServerError, "failed saving and updating bid")
+		api.RespondErrorCode(w, http.StatusInternalServerError, ErrorCodeInternal, "failed saving and updating bid")
 		return
 	}
 
+	api.bidHistory.record(&common.BidHistoryEntry{
+		Slot:           payload.Slot(),
+		ParentHash:     payload.ParentHash(),
+		BlockHash:      payload.BlockHash(),
+		BuilderPubkey:  payload.BuilderPubkey().String(),
+		ProposerPubkey: payload.ProposerPubkey(),
+		Value:          payload.Value().String(),
+		TimestampMs:    receivedAt.UnixMilli(),
+	})
+
 	// Add fields to logs
 	log = log.WithFields(logrus.Fields{
 		"timestampAfterBidUpdate":    time.Now().UTC().UnixMilli(),
@@ -1908,6 +3242,22 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		eligibleAt = time.Now().UTC()
 		log = log.WithField("timestampEligibleAt", eligibleAt.UnixMilli())
 
+		if api.bidStream != nil {
+			numDropped := api.bidStream.publish(&BidStreamMessage{
+				Slot:           payload.Slot(),
+				ParentHash:     payload.ParentHash(),
+				BlockHash:      payload.BlockHash(),
+				BuilderPubkey:  payload.BuilderPubkey().String(),
+				ProposerPubkey: payload.ProposerPubkey(),
+				Value:          payload.Value().String(),
+				IsTopBid:       updateBidResult.IsNewTopBid,
+				ReceivedAtMs:   receivedAt.UnixMilli(),
+			})
+			if numDropped > 0 {
+				log.WithField("numDropped", numDropped).Warn("dropped slow bid stream subscribers")
+			}
+		}
+
 		// Save to memcache in the background
 		if api.memcached != nil {
 			go func() {
@@ -1931,7 +3281,20 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 		"profileRedisUs":     pf.RedisUpdate,
 		"profileTotalUs":     pf.Total,
 	}).Info("received block from builder")
-	w.WriteHeader(http.StatusOK)
+	api.RespondOK(w, newSubmitBlockResponse(updateBidResult, payload.Value(), payload.BuilderPubkey().String()))
+}
+
+// updateBidCache keeps the bid cache in sync with the top bid (if the top bid now belongs to another builder, Redis needs
+// to be asked)
+func (api *RelayAPI) updateBidCache(payload *common.BuilderSubmitBlockRequest, getHeaderResponse *common.GetHeaderResponse, updateBidResult datastore.SaveBidAndUpdateTopBidResponse) {
+	if api.bidCache == nil || !updateBidResult.WasTopBidUpdated {
+		return
+	}
+	if updateBidResult.IsNewTopBid {
+		api.bidCache.set(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey(), getHeaderResponse)
+	} else {
+		api.bidCache.delete(payload.Slot(), payload.ParentHash(), payload.ProposerPubkey())
+	}
 }
 
 // ---------------
@@ -1939,6 +3302,58 @@ func (api *RelayAPI) handleSubmitNewBlock(w http.ResponseWriter, req *http.Reque
 //	INTERNAL APIS
 //
 // ---------------
+
+// isPreviousPublicKey returns true if pubkey is one of the previous relay pubkeys
+func isPreviousPublicKey(previousPublicKeys []boostTypes.PublicKey, pubkey string) bool {
+	for _, pk := range previousPublicKeys {
+		if strings.EqualFold(pk.String(), pubkey) {
+			return true
+		}
+	}
+	return false
+}
+
+// adminAuth requires the client IP to be in the admin allowlist and the admin token as bearer token, if configured
+func (api *RelayAPI) adminAuth(next http.HandlerFunc) http.Handler {
+	return api.requireAllowedIP(api.requireBearerToken(api.opts.AdminToken, next))
+}
+
+// requireBearerToken responds with 401 to requests without the given bearer token. An empty token allows all requests.
+func (api *RelayAPI) requireBearerToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		reqToken := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(reqToken), []byte(token)) != 1 {
+			api.log.WithFields(logrus.Fields{
+				"path": req.URL.Path,
+				"ip":   api.getClientIP(req),
+			}).Warn("unauthorized request")
+			api.RespondError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (api *RelayAPI) handleInternalBuilderBlacklist(w http.ResponseWriter, req *http.Request) {
+	builders, err := api.db.GetBlockBuilders()
+	if err != nil {
+		api.log.WithError(err).Error("could not get block builders")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	blacklist := []*database.BlockBuilderEntry{}
+	for _, builder := range builders {
+		if builder.IsBlacklisted {
+			blacklist = append(blacklist, builder)
+		}
+	}
+	api.RespondOK(w, blacklist)
+}
+
 func (api *RelayAPI) handleInternalBuilderStatus(w http.ResponseWriter, req *http.Request) {
 	vars := mux.Vars(req)
 	builderPubkey := vars["pubkey"]
@@ -2195,6 +3610,62 @@ func (api *RelayAPI) handleDataBuilderBidsReceived(w http.ResponseWriter, req *h
 	api.RespondOK(w, response)
 }
 
+func (api *RelayAPI) handleDataBuilderStats(w http.ResponseWriter, req *http.Request) {
+	builderPubkey := req.URL.Query().Get("builder_pubkey")
+	if builderPubkey != "" {
+		if err := checkBLSPublicKeyHex(builderPubkey); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid builder_pubkey argument")
+			return
+		}
+	}
+
+	builders, err := api.db.GetBlockBuilders()
+	if err != nil {
+		api.log.WithError(err).Error("error getting block builders")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := []BuilderStatsJSON{}
+	for _, builder := range builders {
+		if builderPubkey != "" && !strings.EqualFold(builder.BuilderPubkey, builderPubkey) {
+			continue
+		}
+		response = append(response, BuilderStatsJSON{
+			BuilderPubkey:          builder.BuilderPubkey,
+			BuilderID:              builder.BuilderID,
+			LastSubmissionSlot:     builder.LastSubmissionSlot,
+			NumSubmissionsTotal:    builder.NumSubmissionsTotal,
+			NumSubmissionsSimError: builder.NumSubmissionsSimError,
+			NumSentGetHeader:       builder.NumSentGetHeader,
+			NumSentGetPayload:      builder.NumSentGetPayload,
+		})
+	}
+
+	api.RespondOK(w, response)
+}
+
+func (api *RelayAPI) handleDataBidHistory(w http.ResponseWriter, req *http.Request) {
+	slotStr := req.URL.Query().Get("slot")
+	if slotStr == "" {
+		api.RespondError(w, http.StatusBadRequest, "missing slot argument")
+		return
+	}
+	slot, err := strconv.ParseUint(slotStr, 10, 64)
+	if err != nil {
+		api.RespondError(w, http.StatusBadRequest, "invalid slot argument")
+		return
+	}
+
+	entries, err := api.redis.GetBidHistory(slot)
+	if err != nil {
+		api.log.WithError(err).Error("error getting bid history")
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	api.RespondOK(w, entries)
+}
+
 func (api *RelayAPI) handleDataValidatorRegistration(w http.ResponseWriter, req *http.Request) {
 	pkStr := req.URL.Query().Get("pubkey")
 	if pkStr == "" {
@@ -2209,12 +3680,12 @@ func (api *RelayAPI) handleDataValidatorRegistration(w http.ResponseWriter, req
 		return
 	}
 
-	registrationEntry, err := api.db.GetValidatorRegistration(pkStr)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			api.RespondError(w, http.StatusBadRequest, "no registration found for validator "+pkStr)
-			return
-		}
+	// Registrations are stored with lowercase pubkeys
+	registrationEntry, err := api.db.GetValidatorRegistration(pk.String())
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && registrationEntry == nil) {
+		api.RespondError(w, http.StatusNotFound, "no registration found for validator "+pkStr)
+		return
+	} else if err != nil {
 		api.log.WithError(err).Error("error getting validator registration")
 		api.RespondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -4,17 +4,25 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	builderApi "github.com/attestantio/go-builder-client/api"
 	builderCapella "github.com/attestantio/go-builder-client/api/capella"
 	v1 "github.com/attestantio/go-builder-client/api/v1"
+	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/flashbots/go-boost-utils/bls"
@@ -143,39 +151,39 @@ func (be *testBackend) requestWithUA(method, path, userAgent string, payload any
 	return rr
 }
 
-// func generateSignedValidatorRegistration(sk *bls.SecretKey, feeRecipient types.Address, timestamp uint64) (*types.SignedValidatorRegistration, error) {
-// 	var err error
-// 	if sk == nil {
-// 		sk, _, err = bls.GenerateNewKeypair()
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 	}
-
-// 	blsPubKey, _ := bls.PublicKeyFromSecretKey(sk)
-
-// 	var pubKey types.PublicKey
-// 	err = pubKey.FromSlice(bls.PublicKeyToBytes(blsPubKey))
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	msg := &types.RegisterValidatorRequestMessage{
-// 		FeeRecipient: feeRecipient,
-// 		Timestamp:    timestamp,
-// 		Pubkey:       pubKey,
-// 		GasLimit:     278234191203,
-// 	}
-
-// 	sig, err := types.SignMessage(msg, builderSigningDomain, sk)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	return &types.SignedValidatorRegistration{
-// 		Message:   msg,
-// 		Signature: sig,
-// 	}, nil
-// }
+func generateSignedValidatorRegistration(sk *bls.SecretKey, feeRecipient types.Address, timestamp uint64) (*types.SignedValidatorRegistration, error) {
+	var err error
+	if sk == nil {
+		sk, _, err = bls.GenerateNewKeypair()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	blsPubKey, _ := bls.PublicKeyFromSecretKey(sk)
+
+	var pubKey types.PublicKey
+	err = pubKey.FromSlice(bls.PublicKeyToBytes(blsPubKey))
+	if err != nil {
+		return nil, err
+	}
+	msg := &types.RegisterValidatorRequestMessage{
+		FeeRecipient: feeRecipient,
+		Timestamp:    timestamp,
+		Pubkey:       pubKey,
+		GasLimit:     278234191203,
+	}
+
+	sig, err := types.SignMessage(msg, builderSigningDomain, sk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SignedValidatorRegistration{
+		Message:   msg,
+		Signature: sig,
+	}, nil
+}
 
 func TestWebserver(t *testing.T) {
 	t.Run("errors when webserver is already existing", func(t *testing.T) {
@@ -192,6 +200,102 @@ func TestWebserverRootHandler(t *testing.T) {
 	require.Equal(t, http.StatusOK, rr.Code)
 }
 
+func TestRelayPubkeyRotation(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	previousPubkey, err := backend.redis.GetRelayConfig(datastore.RedisConfigFieldPubkey)
+	require.NoError(t, err)
+	require.Equal(t, backend.relay.publicKey.String(), previousPubkey)
+
+	newSk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	opts := backend.relay.opts
+	opts.SecretKey = newSk
+
+	// A different key is rejected
+	_, err = NewRelayAPI(opts)
+	require.ErrorIs(t, err, ErrRelayPubkeyMismatch)
+
+	// Unless the stored pubkey is one of the previous pubkeys
+	opts.PreviousPublicKeys = []types.PublicKey{*backend.relay.publicKey}
+	relay, err := NewRelayAPI(opts)
+	require.NoError(t, err)
+	storedPubkey, err := backend.redis.GetRelayConfig(datastore.RedisConfigFieldPubkey)
+	require.NoError(t, err)
+	require.Equal(t, relay.publicKey.String(), storedPubkey)
+	require.NotEqual(t, previousPubkey, storedPubkey)
+}
+
+func TestPprofAuth(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.PprofAPI = true
+	backend.relay.opts.PprofToken = "secret"
+	path := "/debug/pprof/"
+
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"Authorization": "Bearer wrong"})
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"Authorization": "Bearer secret"})
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestPprofWithoutToken(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	opts := backend.relay.opts
+	opts.PprofAPI = true
+
+	// Not on the API listen address, or any other reachable one
+	_, err := NewRelayAPI(opts)
+	require.ErrorIs(t, err, ErrPprofWithoutToken)
+	opts.PprofListenAddr = "0.0.0.0:6060"
+	_, err = NewRelayAPI(opts)
+	require.ErrorIs(t, err, ErrPprofWithoutToken)
+
+	// Only on a loopback address
+	for _, addr := range []string{"127.0.0.1:6060", "[::1]:6060", "localhost:6060"} {
+		opts.PprofListenAddr = addr
+		_, err = NewRelayAPI(opts)
+		require.NoError(t, err, addr)
+	}
+
+	opts.PprofListenAddr = ""
+	opts.PprofToken = "secret"
+	_, err = NewRelayAPI(opts)
+	require.NoError(t, err)
+}
+
+func TestLivezReadyz(t *testing.T) {
+	backend := newTestBackend(t, 1)
+
+	rr := backend.request(http.MethodGet, pathLivez, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// the test backend has no beacon nodes
+	rr = backend.request(http.MethodGet, pathReadyz, nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	resp := new(ReadinessResponse)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.False(t, resp.Ready)
+	require.Equal(t, beaconclient.ErrBeaconNodeSyncing.Error(), resp.Beacon)
+	require.Equal(t, "ok", resp.Redis)
+
+	backend.relay.beaconClient = beaconclient.NewMockMultiBeaconClient()
+	rr = backend.request(http.MethodGet, pathReadyz, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRequestID(t *testing.T) {
+	backend := newTestBackend(t, 1)
+
+	rr := backend.request(http.MethodGet, "/", nil)
+	require.Len(t, rr.Header().Get(HeaderRequestID), 32)
+
+	rr = backend.requestBytes(http.MethodGet, "/", nil, map[string]string{HeaderRequestID: "abc-123"})
+	require.Equal(t, "abc-123", rr.Header().Get(HeaderRequestID))
+}
+
 func TestStatus(t *testing.T) {
 	backend := newTestBackend(t, 1)
 	path := "/eth/v1/builder/status"
@@ -199,6 +303,34 @@ func TestStatus(t *testing.T) {
 	require.Equal(t, http.StatusOK, rr.Code)
 }
 
+func TestRelayStatus(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.Version = "v0.1.2"
+
+	rr := backend.request(http.MethodGet, pathRelayStatus, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := RelayStatusResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, backend.relay.publicKey.String(), resp.Pubkey)
+	require.Equal(t, common.EthNetworkMainnet, resp.Network)
+	require.Equal(t, backend.relay.opts.EthNetDetails.CapellaForkVersionHex, resp.CapellaForkVersion)
+	require.Equal(t, backend.relay.opts.EthNetDetails.GenesisValidatorsRootHex, resp.GenesisValidatorsRoot)
+	require.Equal(t, "v0.1.2", resp.Version)
+}
+
+func TestMetrics(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.metrics = newRelayMetrics()
+
+	rr := backend.request(http.MethodGet, pathStatus, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = backend.request(http.MethodGet, pathMetrics, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `relay_http_requests_total{code="200",method="GET",route="/eth/v1/builder/status"} 1`)
+	require.Contains(t, rr.Body.String(), "relay_http_request_duration_seconds_bucket")
+}
+
 func TestRegisterValidator(t *testing.T) {
 	path := "/eth/v1/builder/validators"
 
@@ -233,34 +365,146 @@ func TestRegisterValidator(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, rr.Code)
 	})
 
-	// t.Run("Reject registration for >10sec into the future", func(t *testing.T) {
-	// 	backend := newTestBackend(t, 1)
+	t.Run("rate limited by IP", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		backend.relay.registrationRateLimiter = newIPRateLimiter(0.5, 1)
 
-	// 	// Allow +10 sec
-	// 	td := uint64(time.Now().Unix())
-	// 	payload, err := generateSignedValidatorRegistration(nil, types.Address{1}, td+10)
-	// 	require.NoError(t, err)
-	// 	err = backend.redis.SetKnownValidator(payload.Message.Pubkey.PubkeyHex(), 1)
-	// 	require.NoError(t, err)
-	// 	_, err = backend.datastore.RefreshKnownValidators()
-	// 	require.NoError(t, err)
+		rr := backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{common.ValidPayloadRegisterValidator})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
 
-	// 	rr := backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{*payload})
-	// 	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		rr = backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{common.ValidPayloadRegisterValidator})
+		require.Equal(t, http.StatusTooManyRequests, rr.Code)
+		require.Equal(t, "2", rr.Header().Get("Retry-After"))
+	})
 
-	// 	// Disallow +11 sec
-	// 	td = uint64(time.Now().Unix())
-	// 	payload, err = generateSignedValidatorRegistration(nil, types.Address{1}, td+12)
-	// 	require.NoError(t, err)
-	// 	err = backend.redis.SetKnownValidator(payload.Message.Pubkey.PubkeyHex(), 1)
-	// 	require.NoError(t, err)
-	// 	_, err = backend.datastore.RefreshKnownValidators()
-	// 	require.NoError(t, err)
+	t.Run("gas limit bounds", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		gasLimit := common.ValidPayloadRegisterValidator.Message.GasLimit
+		payload := []types.SignedValidatorRegistration{common.ValidPayloadRegisterValidator}
 
-	// 	rr = backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{*payload})
-	// 	require.Equal(t, http.StatusBadRequest, rr.Code)
-	// 	require.Contains(t, rr.Body.String(), "timestamp too far in the future")
-	// })
+		// At the bounds, the registration passes the gas limit check (and fails later, because the validator isn't known)
+		backend.relay.opts.MinGasLimit = gasLimit
+		backend.relay.opts.MaxGasLimit = gasLimit
+		rr := backend.request(http.MethodPost, path, payload)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "not a known validator")
+
+		backend.relay.opts.MinGasLimit = gasLimit + 1
+		rr = backend.request(http.MethodPost, path, payload)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "gas limit too low")
+
+		backend.relay.opts.MinGasLimit = 0
+		backend.relay.opts.MaxGasLimit = gasLimit - 1
+		rr = backend.request(http.MethodPost, path, payload)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "gas limit too high")
+	})
+
+	t.Run("timestamp skew into the future", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		sk, blsPk, err := bls.GenerateNewKeypair()
+		require.NoError(t, err)
+		pubkey, err := types.BlsPublicKeyToPublicKey(blsPk)
+		require.NoError(t, err)
+		addKnownValidator(backend, pubkey)
+
+		// Within the allowed skew
+		payload, err := generateSignedValidatorRegistration(sk, types.Address{1}, uint64(time.Now().Unix())+uint64(registrationTimestampMaxSkewSec))
+		require.NoError(t, err)
+		rr := backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{*payload})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		// Beyond the allowed skew
+		payload, err = generateSignedValidatorRegistration(sk, types.Address{1}, uint64(time.Now().Unix())+uint64(registrationTimestampMaxSkewSec)+2)
+		require.NoError(t, err)
+		rr = backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{*payload})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "timestamp too far in the future")
+	})
+
+	t.Run("out-of-order registrations", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		sk, blsPk, err := bls.GenerateNewKeypair()
+		require.NoError(t, err)
+		pubkey, err := types.BlsPublicKeyToPublicKey(blsPk)
+		require.NoError(t, err)
+		addKnownValidator(backend, pubkey)
+
+		now := uint64(time.Now().Unix())
+		newer, err := generateSignedValidatorRegistration(sk, types.Address{2}, now)
+		require.NoError(t, err)
+		older, err := generateSignedValidatorRegistration(sk, types.Address{1}, now-60)
+		require.NoError(t, err)
+
+		// The newer registration arrives first and is queued for saving
+		rr := backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{*newer})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, *newer, <-backend.relay.validatorRegC)
+		require.NoError(t, backend.datastore.SaveValidatorRegistration(*newer))
+
+		// The older one is accepted, but doesn't replace the newer one
+		rr = backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{*older})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Empty(t, backend.relay.validatorRegC)
+		timestamp, err := backend.redis.GetValidatorRegistrationTimestamp(pubkey.PubkeyHex())
+		require.NoError(t, err)
+		require.Equal(t, now, timestamp)
+	})
+
+	t.Run("invalid pubkey", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+
+		// The point at infinity, with the infinity signature which would pass the signature check
+		pubkey := types.PublicKey{0xc0}
+		addKnownValidator(backend, pubkey)
+		payload := types.SignedValidatorRegistration{
+			Message: &types.RegisterValidatorRequestMessage{
+				FeeRecipient: types.Address{1},
+				GasLimit:     30000000,
+				Timestamp:    uint64(time.Now().Unix()),
+				Pubkey:       pubkey,
+			},
+			Signature: types.Signature{0xc0},
+		}
+		rr := backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{payload})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), string(ErrorCodeInvalidPubkey))
+		require.Empty(t, backend.relay.validatorRegC)
+	})
+
+	t.Run("maximum registrations per request", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		backend.relay.opts.MaxRegistrationsPerRequest = 2
+		sk, blsPk, err := bls.GenerateNewKeypair()
+		require.NoError(t, err)
+		pubkey, err := types.BlsPublicKeyToPublicKey(blsPk)
+		require.NoError(t, err)
+		addKnownValidator(backend, pubkey)
+
+		payload, err := generateSignedValidatorRegistration(sk, types.Address{1}, uint64(time.Now().Unix()))
+		require.NoError(t, err)
+
+		// At the limit
+		rr := backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{*payload, *payload})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		// One above the limit is rejected before verifying the registrations
+		rr = backend.request(http.MethodPost, path, []types.SignedValidatorRegistration{*payload, *payload, *payload})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "too many registrations: 3, the maximum is 2 per request")
+	})
+}
+
+// addKnownValidator makes the pubkey a known validator, as if returned by the beacon node
+func addKnownValidator(backend *testBackend, pubkey types.PublicKey) {
+	beaconInstance := beaconclient.NewMockBeaconInstance()
+	beaconInstance.AddValidator(beaconclient.ValidatorResponseEntry{
+		Index:     1,
+		Validator: beaconclient.ValidatorResponseValidatorData{Pubkey: pubkey.String()},
+	})
+	beaconClient := beaconclient.NewMultiBeaconClient(common.TestLog, []beaconclient.IBeaconInstance{beaconInstance})
+	backend.datastore.RefreshKnownValidators(beaconClient, 64)
 }
 
 func TestGetHeader(t *testing.T) {
@@ -309,6 +553,294 @@ func TestGetHeader(t *testing.T) {
 	// Check 2: Request returns 204 if sending a filtered user agent
 	rr = backend.requestWithUA(http.MethodGet, path, "mev-boost/v1.5.0 Go-http-client/1.1", nil)
 	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	// Check 3: Request returns 204 if the bid is below the minimum bid value
+	backend.relay.opts.MinBidValue = big.NewInt(100)
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	backend.relay.opts.MinBidValue = big.NewInt(99)
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get(HeaderBuilderPubkey))
+
+	// Check 4: Debug headers contain the builder pubkey and value of the bid
+	tx := backend.redis.NewPipeline()
+	require.NoError(t, backend.redis.SaveBidTrace(context.Background(), tx, &common.BidTraceV2{BidTrace: *payload.Message()}))
+	_, err = tx.Exec(context.Background())
+	require.NoError(t, err)
+	backend.relay.opts.DebugHeaders = true
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, builderPubkey, rr.Header().Get(HeaderBuilderPubkey))
+	require.Equal(t, bidValue.String(), rr.Header().Get(HeaderBidValue))
+
+	// Check 5: With proposer-only headers, only the proposer of the slot gets the bid
+	backend.relay.opts.ProposerOnlyHeaders = true
+	backend.relay.beaconDuties = newBeaconDutiesCache(common.TestLog, backend.relay.beaconClient)
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	backend.relay.beaconDuties.duties[slot] = beaconclient.ProposerDutiesResponseData{Slot: slot, Pubkey: builderPubkey, ValidatorIndex: 1}
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	backend.relay.beaconDuties.duties[slot] = beaconclient.ProposerDutiesResponseData{Slot: slot, Pubkey: proposerPubkey, ValidatorIndex: 2}
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Check 6: Request returns 204 in dry-run mode
+	backend.relay.opts.DryRun = true
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestDebugBestBid(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.AdminToken = "secret"
+	auth := map[string]string{"Authorization": "Bearer secret"}
+
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	path := fmt.Sprintf("/relay/v1/debug/bid/%d/%s/%s", slot, parentHash, proposerPubkey)
+
+	opts := common.CreateTestBlockSubmissionOpts{
+		Slot:           slot,
+		ParentHash:     parentHash,
+		ProposerPubkey: proposerPubkey,
+	}
+	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(99), &opts)
+	trace := &common.BidTraceV2{BidTrace: *payload.Message()}
+	_, err := backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), trace, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil)
+	require.NoError(t, err)
+
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	rr = backend.requestBytes(http.MethodGet, path, nil, auth)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := new(common.BidTraceV2)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, builderPubkey, resp.BuilderPubkey.String())
+	require.Equal(t, "99", resp.Value.Dec())
+	require.Equal(t, payload.BlockHash(), resp.BlockHash.String())
+
+	rr = backend.requestBytes(http.MethodGet, fmt.Sprintf("/relay/v1/debug/bid/%d/%s/%s", slot+1, parentHash, proposerPubkey), nil, auth)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGetHeaderWaitTime(t *testing.T) {
+	testCases := []struct {
+		name       string
+		msIntoSlot int64
+		maxWaitMs  int
+		expected   time.Duration
+	}{
+		{"disabled", -1000, 0, 0},
+		{"early request waits the maximum", -1000, 300, 300 * time.Millisecond},
+		{"waits until the deadline", 400, 300, 100 * time.Millisecond},
+		{"late request doesn't wait", 600, 300, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, getHeaderWaitTime(tc.msIntoSlot, tc.maxWaitMs, 500))
+		})
+	}
+}
+
+func TestGetHeaderDeadlineWaitTime(t *testing.T) {
+	deadline := 300 * time.Millisecond
+	testCases := []struct {
+		name           string
+		msIntoSlot     int64
+		slotStartKnown bool
+		expected       time.Duration
+	}{
+		{"waits until the deadline", 100, true, 200 * time.Millisecond},
+		{"request before the slot start waits until the deadline", -500, true, 800 * time.Millisecond},
+		{"request long before the slot start waits at most the maximum", -5000, true, 1300 * time.Millisecond},
+		{"late request doesn't wait", 301, true, 0},
+		{"unknown slot start waits the deadline", 1000, false, deadline},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, getHeaderDeadlineWaitTime(tc.msIntoSlot, deadline, time.Second, tc.slotStartKnown))
+		})
+	}
+}
+
+func TestSubmissionMsIntoSlot(t *testing.T) {
+	genesisTime := uint64(1_606_824_023)
+	// the block of slot 10 is built during slot 9
+	slot9Start := time.Unix(int64(genesisTime+9*common.SecondsPerSlot), 0)
+	require.Equal(t, int64(0), submissionMsIntoSlot(slot9Start, genesisTime, 10))
+	require.Equal(t, int64(1500), submissionMsIntoSlot(slot9Start.Add(1500*time.Millisecond), genesisTime, 10))
+	require.Equal(t, int64(-500), submissionMsIntoSlot(slot9Start.Add(-500*time.Millisecond), genesisTime, 10))
+	require.Equal(t, int64(12_000), submissionMsIntoSlot(slot9Start.Add(12*time.Second), genesisTime, 10))
+}
+
+func TestRunGetHeaderBackground(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.getHeaderBackgroundOps = make(chan struct{}, 1)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	backend.relay.runGetHeaderBackground(common.TestLog, func() {
+		<-release
+		close(done)
+	})
+
+	// Operations beyond the limit are dropped
+	dropped := true
+	backend.relay.runGetHeaderBackground(common.TestLog, func() { dropped = false })
+	require.True(t, dropped)
+	require.Equal(t, uint64(1), backend.relay.numGetHeaderBackgroundDropped.Load())
+
+	// and run again once a slot is free
+	close(release)
+	<-done
+	ran := make(chan struct{})
+	require.Eventually(t, func() bool {
+		select {
+		case backend.relay.getHeaderBackgroundOps <- struct{}{}:
+			<-backend.relay.getHeaderBackgroundOps
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	backend.relay.runGetHeaderBackground(common.TestLog, func() { close(ran) })
+	<-ran
+}
+
+func TestBuilderSubmitBlockTooEarly(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.headSlot.Store(1)
+	backend.relay.opts.SubmitStartMs = 3000
+
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	var builderPubkey phase0.BLSPubKey
+	copy(builderPubkey[:], bls.PublicKeyToBytes(pk))
+	bid := &common.BidTraceV2{BidTrace: v1.BidTrace{Slot: 2, BuilderPubkey: builderPubkey, Value: uint256.NewInt(1)}}
+	req := common.TestBuilderSubmitBlockRequest(sk, bid)
+
+	// Slot 1, in which the block of slot 2 is built, starts at genesis + 12s
+	genesisTime := uint64(1_606_824_023)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{GenesisTime: genesisTime}, //nolint:exhaustruct
+	}
+	slotStart := time.Unix(int64(genesisTime+common.SecondsPerSlot), 0)
+	backend.relay.now = func() time.Time { return slotStart.Add(2999 * time.Millisecond) }
+	rr := backend.request(http.MethodPost, pathSubmitNewBlock, &req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodeRequestTooEarly))
+	require.Contains(t, rr.Body.String(), "2999 ms into slot")
+
+	backend.relay.now = func() time.Time { return slotStart.Add(3000 * time.Millisecond) }
+	rr = backend.request(http.MethodPost, pathSubmitNewBlock, &req)
+	require.NotContains(t, rr.Body.String(), string(ErrorCodeRequestTooEarly))
+
+	backend.relay.now = func() time.Time { return slotStart }
+	backend.relay.opts.SubmitStartMs = 0
+	rr = backend.request(http.MethodPost, pathSubmitNewBlock, &req)
+	require.NotContains(t, rr.Body.String(), string(ErrorCodeRequestTooEarly))
+}
+
+// newTestGetPayloadRequest saves the payload of a winning bid for a known proposer in the capella slot, and returns the
+// proposer's signed blinded block for it
+func newTestGetPayloadRequest(t *testing.T, backend *testBackend, slot uint64) (*apiv1capella.SignedBlindedBeaconBlock, *beaconclient.MockBeaconInstance, *bls.SecretKey) {
+	t.Helper()
+	backend.relay.capellaEpoch = 1
+	backend.relay.genesisInfo.Data.GenesisTime = uint64(time.Now().Unix()) - slot*common.SecondsPerSlot
+	backend.relay.headSlot.Store(slot - 1)
+
+	// Known proposer
+	proposerSk, proposerBlsPk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	proposerPubkey, err := types.BlsPublicKeyToPublicKey(proposerBlsPk)
+	require.NoError(t, err)
+	proposerIndex := uint64(1)
+	beaconInstance := beaconclient.NewMockBeaconInstance()
+	beaconInstance.AddValidator(beaconclient.ValidatorResponseEntry{
+		Index:     proposerIndex,
+		Validator: beaconclient.ValidatorResponseValidatorData{Pubkey: proposerPubkey.String()},
+	})
+	beaconClient := beaconclient.NewMultiBeaconClient(common.TestLog, []beaconclient.IBeaconInstance{beaconInstance})
+	backend.relay.beaconClient = beaconClient
+	backend.datastore.RefreshKnownValidators(beaconClient, slot)
+
+	// Execution payload of the winning bid
+	execPayload := new(consensuscapella.ExecutionPayload)
+	common.LoadGzippedJSON(t, "../../testdata/executionPayloadCapella_Goerli.json.gz", execPayload)
+	blockHash := execPayload.BlockHash.String()
+	tx := backend.redis.NewPipeline()
+	require.NoError(t, backend.redis.SaveExecutionPayloadCapella(context.Background(), tx, slot, proposerPubkey.String(), blockHash, execPayload))
+	require.NoError(t, backend.redis.SaveBidTrace(context.Background(), tx, &common.BidTraceV2{
+		BidTrace: v1.BidTrace{Slot: slot, ProposerPubkey: phase0.BLSPubKey(proposerPubkey), BlockHash: execPayload.BlockHash, Value: uint256.NewInt(1)},
+	}))
+	_, err = tx.Exec(context.Background())
+	require.NoError(t, err)
+
+	// Signed blinded block
+	header, err := common.CapellaPayloadToPayloadHeader(execPayload)
+	require.NoError(t, err)
+	blindedBlock := &apiv1capella.BlindedBeaconBlock{
+		Slot:          phase0.Slot(slot),
+		ProposerIndex: phase0.ValidatorIndex(proposerIndex),
+		Body: &apiv1capella.BlindedBeaconBlockBody{
+			ETH1Data:               &phase0.ETH1Data{DepositRoot: phase0.Root{}, DepositCount: 0, BlockHash: make([]byte, 32)},
+			ProposerSlashings:      []*phase0.ProposerSlashing{},
+			AttesterSlashings:      []*phase0.AttesterSlashing{},
+			Attestations:           []*phase0.Attestation{},
+			Deposits:               []*phase0.Deposit{},
+			VoluntaryExits:         []*phase0.SignedVoluntaryExit{},
+			SyncAggregate:          &altair.SyncAggregate{SyncCommitteeBits: make([]byte, 64)},
+			ExecutionPayloadHeader: header,
+			BLSToExecutionChanges:  []*consensuscapella.SignedBLSToExecutionChange{},
+		},
+	}
+	signature, err := types.SignMessage(blindedBlock, backend.relay.proposerDomain(slot), proposerSk)
+	require.NoError(t, err)
+	signedBlindedBlock := &apiv1capella.SignedBlindedBeaconBlock{
+		Message:   blindedBlock,
+		Signature: phase0.BLSSignature(signature),
+	}
+	return signedBlindedBlock, beaconInstance, proposerSk
+}
+
+func TestGetPayloadRetry(t *testing.T) {
+	path := "/eth/v1/builder/blinded_blocks"
+	backend := newTestBackend(t, 1)
+
+	// Capella slot, at slot start
+	slot := uint64(64)
+	signedBlindedBlock, beaconInstance, proposerSk := newTestGetPayloadRequest(t, backend, slot)
+	blindedBlock := signedBlindedBlock.Message
+
+	// First call publishes the block
+	rr := backend.request(http.MethodPost, path, signedBlindedBlock)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, 1, beaconInstance.NumPublishedBlocks())
+	firstResponse := rr.Body.String()
+
+	// The retry gets the same payload, without publishing again
+	rr = backend.request(http.MethodPost, path, signedBlindedBlock)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.Equal(t, firstResponse, rr.Body.String())
+	require.Equal(t, 1, beaconInstance.NumPublishedBlocks())
+
+	// A different block for the same slot is rejected
+	blindedBlock.Body.ExecutionPayloadHeader.GasUsed++
+	blindedBlock.Body.ExecutionPayloadHeader.BlockHash = phase0.Hash32{0x01}
+	signature, err := types.SignMessage(blindedBlock, backend.relay.proposerDomain(slot), proposerSk)
+	require.NoError(t, err)
+	signedBlindedBlock.Signature = phase0.BLSSignature(signature)
+	rr = backend.request(http.MethodPost, path, signedBlindedBlock)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, 1, beaconInstance.NumPublishedBlocks())
 }
 
 func TestBuilderApiGetValidators(t *testing.T) {
@@ -336,6 +868,83 @@ func TestBuilderApiGetValidators(t *testing.T) {
 	require.Equal(t, common.ValidPayloadRegisterValidator, *resp[0].Entry)
 }
 
+func TestDataAPICORS(t *testing.T) {
+	path := "/relay/v1/data/bidtraces/proposer_payload_delivered"
+	origin := map[string]string{"Origin": "https://dashboard.example"}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		rr := backend.requestBytes(http.MethodGet, path, nil, origin)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+
+		rr = backend.requestBytes(http.MethodOptions, path, nil, origin)
+		require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	})
+
+	t.Run("allowed origins", func(t *testing.T) {
+		backend := newTestBackend(t, 1)
+		backend.relay.opts.CORSOrigins = []string{"https://dashboard.example"}
+
+		rr := backend.requestBytes(http.MethodGet, path, nil, origin)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "https://dashboard.example", rr.Header().Get("Access-Control-Allow-Origin"))
+
+		rr = backend.requestBytes(http.MethodOptions, path, nil, origin)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		require.Equal(t, "https://dashboard.example", rr.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "GET, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
+
+		// other origins and routes don't get CORS headers
+		rr = backend.requestBytes(http.MethodGet, path, nil, map[string]string{"Origin": "https://other.example"})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+
+		rr = backend.requestBytes(http.MethodOptions, "/eth/v1/builder/validators", nil, origin)
+		require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+		require.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestDataApiGetBuilderStats(t *testing.T) {
+	path := "/relay/v1/data/builder_stats"
+	pubkey1 := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a44d4096b9e7a113ecc60256f0825fb5518861bad5e7"
+	pubkey2 := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+
+	backend := newTestBackend(t, 1)
+	backend.relay.db = database.MockDB{Builders: map[string]*database.BlockBuilderEntry{
+		pubkey1: {BuilderPubkey: pubkey1, NumSubmissionsTotal: 10, NumSubmissionsSimError: 1, NumSentGetHeader: 3, NumSentGetPayload: 2},
+		pubkey2: {BuilderPubkey: pubkey2, NumSubmissionsTotal: 5},
+	}}
+
+	t.Run("all builders", func(t *testing.T) {
+		rr := backend.request(http.MethodGet, path, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := []BuilderStatsJSON{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp, 2)
+	})
+
+	t.Run("filter by builder_pubkey", func(t *testing.T) {
+		rr := backend.request(http.MethodGet, path+"?builder_pubkey="+pubkey1, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		resp := []BuilderStatsJSON{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Equal(t, []BuilderStatsJSON{{
+			BuilderPubkey:          pubkey1,
+			NumSubmissionsTotal:    10,
+			NumSubmissionsSimError: 1,
+			NumSentGetHeader:       3,
+			NumSentGetPayload:      2,
+		}}, resp)
+	})
+
+	t.Run("invalid builder_pubkey", func(t *testing.T) {
+		rr := backend.request(http.MethodGet, path+"?builder_pubkey=0x123", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
 func TestDataApiGetDataProposerPayloadDelivered(t *testing.T) {
 	path := "/relay/v1/data/bidtraces/proposer_payload_delivered"
 
@@ -370,6 +979,48 @@ func TestDataApiGetDataProposerPayloadDelivered(t *testing.T) {
 	})
 }
 
+type registrationTestDB struct {
+	database.MockDB
+	entries map[string]*database.ValidatorRegistrationEntry
+}
+
+func (db registrationTestDB) GetValidatorRegistration(pubkey string) (*database.ValidatorRegistrationEntry, error) {
+	entry, found := db.entries[pubkey]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+func TestDataApiGetValidatorRegistration(t *testing.T) {
+	path := "/relay/v1/data/validator_registration"
+	reg := common.ValidPayloadRegisterValidator
+	pubkey := reg.Message.Pubkey.String()
+
+	entry := database.SignedValidatorRegistrationToEntry(reg)
+
+	backend := newTestBackend(t, 1)
+	backend.relay.db = registrationTestDB{entries: map[string]*database.ValidatorRegistrationEntry{pubkey: &entry}}
+
+	// Any case of the pubkey finds the registration
+	for _, pk := range []string{pubkey, "0x" + strings.ToUpper(pubkey[2:])} {
+		rr := backend.request(http.MethodGet, path+"?pubkey="+pk, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		resp := new(types.SignedValidatorRegistration)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		require.Equal(t, reg.Message.FeeRecipient, resp.Message.FeeRecipient)
+		require.Equal(t, reg.Message.GasLimit, resp.Message.GasLimit)
+		require.Equal(t, reg.Message.Timestamp, resp.Message.Timestamp)
+	}
+
+	rr := backend.request(http.MethodGet, path+"?pubkey=0x"+strings.Repeat("ab", 48), nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Contains(t, rr.Body.String(), "no registration found")
+
+	rr = backend.request(http.MethodGet, path+"?pubkey=0x1234", nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
 func TestBuilderSubmitBlockSSZ(t *testing.T) {
 	requestPayloadJSONBytes := common.LoadGzippedBytes(t, "../../testdata/submitBlockPayloadCapella_Goerli.json.gz")
 
@@ -386,7 +1037,38 @@ func TestBuilderSubmitBlockSSZ(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestBuilderSubmitBlockInvalidPubkey(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.headSlot.Store(1)
+
+	sk, blsPk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkey, err := types.BlsPublicKeyToPublicKey(blsPk)
+	require.NoError(t, err)
+	submit := func(builderPubkey phase0.BLSPubKey) *httptest.ResponseRecorder {
+		bid := &common.BidTraceV2{BidTrace: v1.BidTrace{
+			Slot:           2,
+			BuilderPubkey:  builderPubkey,
+			ProposerPubkey: phase0.BLSPubKey(pubkey),
+			Value:          uint256.NewInt(1),
+		}}
+		req := common.TestBuilderSubmitBlockRequest(sk, bid)
+		return backend.request(http.MethodPost, pathSubmitNewBlock, &req)
+	}
+
+	// A builder pubkey which isn't in the G1 subgroup, and the point at infinity
+	notInSubgroup := phase0.BLSPubKey{0x80}
+	notInSubgroup[47] = 0x04
+	for _, builderPubkey := range []phase0.BLSPubKey{notInSubgroup, {0xc0}} {
+		rr := submit(builderPubkey)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid builder pubkey")
+	}
+}
+
 func TestBuilderSubmitBlock(t *testing.T) {
+	t.Run("outcomes", testBuilderSubmitBlockOutcomes)
+
 	path := "/relay/v1/builder/blocks"
 	backend := newTestBackend(t, 1)
 
@@ -457,6 +1139,15 @@ func TestBuilderSubmitBlock(t *testing.T) {
 	require.Contains(t, rr.Body.String(), "invalid signature")
 	require.Equal(t, http.StatusBadRequest, rr.Code)
 
+	// Send a request with a timestamp which doesn't match the slot
+	req.Capella.ExecutionPayload.Timestamp = uint64(submissionTimestamp + 1)
+	reqBadTimestampBytes, err := req.Capella.MarshalJSON()
+	require.NoError(t, err)
+	req.Capella.ExecutionPayload.Timestamp = uint64(submissionTimestamp)
+	rr = backend.requestBytes(http.MethodPost, path, reqBadTimestampBytes, nil)
+	require.Contains(t, rr.Body.String(), fmt.Sprintf("incorrect timestamp. got %d, expected %d", submissionTimestamp+1, submissionTimestamp))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
 	// Send JSON+GZIP encoded request
 	headers := map[string]string{
 		"Content-Encoding": "gzip",
@@ -480,6 +1171,126 @@ func TestBuilderSubmitBlock(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, rr.Code)
 }
 
+// testBuilderSubmitBlockOutcomes checks the response bodies of accepted and rejected submissions
+func testBuilderSubmitBlockOutcomes(t *testing.T) {
+	pubkeyA, skA, backend := startTestBackend(t)
+	backend.relay.capellaEpoch = 1
+	backend.relay.ffEnableCancellations = true
+	backend.relay.blockSimRateLimiter = nil
+	withdrawalsRoot, err := ComputeWithdrawalsRoot([]*consensuscapella.Withdrawal{})
+	require.NoError(t, err)
+	var randaoHash types.Hash
+	require.NoError(t, randaoHash.FromSlice([]byte(randao)))
+	backend.relay.payloadAttributes[emptyHash] = payloadAttributesHelper{
+		slot:              slot,
+		withdrawalsRoot:   withdrawalsRoot,
+		payloadAttributes: beaconclient.PayloadAttributes{PrevRandao: randaoHash.String()}, //nolint:exhaustruct
+	}
+	skB, blsPubkeyB, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkeyB := phase0.BLSPubKey(blsPubkeyB.Bytes())
+
+	submit := func(sk *bls.SecretKey, pubkey phase0.BLSPubKey, value uint64, cancellations bool, expectedCode int) *SubmitBlockResponse {
+		t.Helper()
+		req := common.TestBuilderSubmitBlockRequest(sk, getTestBidTrace(pubkey, value))
+		path := pathSubmitNewBlock
+		if cancellations {
+			path += "?cancellations=1"
+		}
+		rr := backend.request(http.MethodPost, path, &req)
+		require.Equal(t, expectedCode, rr.Code, rr.Body.String())
+		resp := new(SubmitBlockResponse)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+		return resp
+	}
+
+	resp := submit(skA, *pubkeyA, 100, false, http.StatusOK)
+	require.Equal(t, &SubmitBlockResponse{Status: submitBlockStatusAcceptedBest, TopBidValue: "100"}, resp) //nolint:exhaustruct
+
+	resp = submit(skA, *pubkeyA, 50, false, http.StatusAccepted)
+	require.Equal(t, &SubmitBlockResponse{Status: submitBlockStatusRejected, Reason: submitBlockReasonBelowFloor, FloorValue: "100"}, resp) //nolint:exhaustruct
+
+	// A cancellable bid of another builder doesn't raise the floor
+	resp = submit(skB, pubkeyB, 150, true, http.StatusOK)
+	require.Equal(t, &SubmitBlockResponse{Status: submitBlockStatusAcceptedBest, TopBidValue: "150"}, resp) //nolint:exhaustruct
+
+	resp = submit(skA, *pubkeyA, 120, false, http.StatusOK)
+	require.Equal(t, &SubmitBlockResponse{Status: submitBlockStatusAcceptedNotBest, TopBidValue: "150"}, resp) //nolint:exhaustruct
+
+	resp = submit(skA, *pubkeyA, 0, false, http.StatusOK)
+	require.Equal(t, &SubmitBlockResponse{Status: submitBlockStatusRejected, Reason: submitBlockReasonZeroValue}, resp) //nolint:exhaustruct
+}
+
+func TestBuilderSubmitBlockDeneb(t *testing.T) {
+	path := "/relay/v1/builder/blocks"
+	backend := newTestBackend(t, 1)
+
+	headSlot := uint64(32)
+	submissionSlot := headSlot + 1
+	parentHash := "0xbd3291854dc822b7ec585925cda0e18f06af28fa2886e15f52d52dd4b6f94ed6"
+	feeRec, err := types.HexToAddress("0x5cc0dde14e7256340cc820415a6022a7d1c93a35")
+	require.NoError(t, err)
+	withdrawalsRoot, err := hexutil.Decode("0xb15ed76298ff84a586b1d875df08b6676c98dfe9c7cd73fab88450348d8e70c8")
+	require.NoError(t, err)
+
+	backend.relay.headSlot.Store(headSlot)
+	backend.relay.capellaEpoch = 1
+	backend.relay.proposerDutiesMap = map[uint64]*common.BuilderGetValidatorsResponseEntry{
+		submissionSlot: {
+			Slot:  submissionSlot,
+			Entry: &types.SignedValidatorRegistration{Message: &types.RegisterValidatorRequestMessage{FeeRecipient: feeRec}},
+		},
+	}
+	backend.relay.payloadAttributes = map[string]payloadAttributesHelper{
+		parentHash: {
+			slot:              submissionSlot,
+			parentHash:        parentHash,
+			payloadAttributes: beaconclient.PayloadAttributes{PrevRandao: "0x9962816e9d0a39fd4c80935338a741dc916d1545694e41eb5a505e1a3098f9e4"}, //nolint:exhaustruct
+			withdrawalsRoot:   phase0.Root(withdrawalsRoot),
+		},
+	}
+
+	capellaReq := new(builderCapella.SubmitBlockRequest)
+	common.LoadGzippedJSON(t, "../../testdata/submitBlockPayloadCapella_Goerli.json.gz", capellaReq)
+	capellaReq.Message.Slot = submissionSlot
+	capellaReq.ExecutionPayload.Timestamp = 1606824419
+	capellaReqBytes, err := json.Marshal(capellaReq)
+	require.NoError(t, err)
+	denebReqBytes, err := json.Marshal(common.CapellaToDenebSubmitBlockRequest(capellaReq, 2))
+	require.NoError(t, err)
+
+	t.Run("reject blobs before deneb", func(t *testing.T) {
+		rr := backend.requestBytes(http.MethodPost, path, denebReqBytes, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "not capella payload")
+	})
+
+	backend.relay.denebEpoch = 1
+
+	t.Run("reject capella payload after deneb", func(t *testing.T) {
+		rr := backend.requestBytes(http.MethodPost, path, capellaReqBytes, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "not deneb payload")
+	})
+
+	t.Run("reject blobs without commitments", func(t *testing.T) {
+		req := common.CapellaToDenebSubmitBlockRequest(capellaReq, 2)
+		req.BlobsBundle.Commitments = req.BlobsBundle.Commitments[:1]
+		b, err := json.Marshal(req)
+		require.NoError(t, err)
+		rr := backend.requestBytes(http.MethodPost, path, b, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), common.ErrBlobsBundleMismatch.Error())
+	})
+
+	t.Run("accept deneb payload", func(t *testing.T) {
+		// passes all checks up to the signature
+		rr := backend.requestBytes(http.MethodPost, path, denebReqBytes, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "invalid signature")
+	})
+}
+
 func gzipBytes(t *testing.T, b []byte) []byte {
 	t.Helper()
 	var buf bytes.Buffer
@@ -489,3 +1300,126 @@ func gzipBytes(t *testing.T, b []byte) []byte {
 	require.NoError(t, zw.Close())
 	return buf.Bytes()
 }
+
+func TestEqExecutionPayloadToHeaderDeneb(t *testing.T) {
+	capellaReq := new(builderCapella.SubmitBlockRequest)
+	common.LoadGzippedJSON(t, "../../testdata/submitBlockPayloadCapella_Goerli.json.gz", capellaReq)
+	denebReq := common.CapellaToDenebSubmitBlockRequest(capellaReq, 1)
+	denebReq.BlobsBundle.Commitments[0][0] = 0x01
+	header, err := common.DenebPayloadToPayloadHeader(denebReq.ExecutionPayload)
+	require.NoError(t, err)
+
+	blindedBlock := &common.SignedBlindedBeaconBlock{ //nolint:exhaustruct
+		Deneb: &apiv1deneb.SignedBlindedBeaconBlock{ //nolint:exhaustruct
+			Message: &apiv1deneb.BlindedBeaconBlock{ //nolint:exhaustruct
+				Body: &apiv1deneb.BlindedBeaconBlockBody{ //nolint:exhaustruct
+					ExecutionPayloadHeader: header,
+					BlobKzgCommitments:     []deneb.KzgCommitment{{0x01}},
+				},
+			},
+		},
+	}
+	payload := &common.VersionedExecutionPayload{ //nolint:exhaustruct
+		Deneb: &common.DenebExecutionPayloadAndBlobsBundle{
+			ExecutionPayload: denebReq.ExecutionPayload,
+			BlobsBundle:      denebReq.BlobsBundle,
+		},
+	}
+	require.NoError(t, EqExecutionPayloadToHeader(blindedBlock, payload))
+
+	// The proposer signed other blobs
+	blindedBlock.Deneb.Message.Body.BlobKzgCommitments = []deneb.KzgCommitment{{0x02}}
+	require.ErrorIs(t, EqExecutionPayloadToHeader(blindedBlock, payload), ErrBlobCommitmentsMismatch)
+
+	// A capella payload for a deneb block
+	payload = &common.VersionedExecutionPayload{Capella: &builderApi.VersionedExecutionPayload{}} //nolint:exhaustruct
+	require.ErrorIs(t, EqExecutionPayloadToHeader(blindedBlock, payload), ErrPayloadMismatchDeneb)
+}
+
+func TestCheckFeeRecipient(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	slot := uint64(42)
+	registeredFeeRecipient := [20]byte{0x01}
+	builderFeeRecipient := [20]byte{0x02}
+
+	getPayloadResp := &common.VersionedExecutionPayload{ //nolint:exhaustruct
+		Capella: &builderApi.VersionedExecutionPayload{ //nolint:exhaustruct
+			Capella: &consensuscapella.ExecutionPayload{FeeRecipient: registeredFeeRecipient}, //nolint:exhaustruct
+		},
+	}
+	bidTrace := func(proposerFeeRecipient [20]byte) *common.BidTraceV2 {
+		return &common.BidTraceV2{BidTrace: v1.BidTrace{Slot: slot, Value: uint256.NewInt(1), ProposerFeeRecipient: proposerFeeRecipient}} //nolint:exhaustruct
+	}
+
+	// Without a registration on file, there's nothing to check
+	_, _, match, err := backend.relay.checkFeeRecipient(slot, getPayloadResp, nil)
+	require.NoError(t, err)
+	require.True(t, match)
+
+	backend.relay.proposerDutiesMap = map[uint64]*common.BuilderGetValidatorsResponseEntry{
+		slot: {Slot: slot, Entry: &types.SignedValidatorRegistration{Message: &types.RegisterValidatorRequestMessage{FeeRecipient: registeredFeeRecipient}}}, //nolint:exhaustruct
+	}
+
+	// The block's fee recipient is the registered one
+	_, _, match, err = backend.relay.checkFeeRecipient(slot, getPayloadResp, nil)
+	require.NoError(t, err)
+	require.True(t, match)
+
+	// The builder is the block's fee recipient, without a bid trace to verify the payment
+	getPayloadResp.Capella.Capella.FeeRecipient = builderFeeRecipient
+	_, _, match, err = backend.relay.checkFeeRecipient(slot, getPayloadResp, nil)
+	require.ErrorIs(t, err, ErrMissingBidTrace)
+	require.False(t, match)
+
+	// The builder is the block's fee recipient, and pays the registered fee recipient
+	_, _, match, err = backend.relay.checkFeeRecipient(slot, getPayloadResp, bidTrace(registeredFeeRecipient))
+	require.NoError(t, err)
+	require.True(t, match)
+
+	// The builder pays someone else
+	registered, actual, match, err := backend.relay.checkFeeRecipient(slot, getPayloadResp, bidTrace(builderFeeRecipient))
+	require.NoError(t, err)
+	require.False(t, match)
+	require.Equal(t, types.Address(registeredFeeRecipient).String(), registered)
+	require.Equal(t, types.Address(builderFeeRecipient).String(), actual)
+}
+
+func TestGetHeaderEnforceFeeRecipient(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.bidCache = newBidCache(10)
+	backend.relay.opts.EnforceFeeRecipient = true
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{
+		Data: beaconclient.GetGenesisResponseData{GenesisTime: uint64(time.Now().UTC().Unix())}, //nolint:exhaustruct
+	}
+
+	slot := uint64(3)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	proposerPubkey := "0x6ae5932d1e248d987d51b58665b81848814202d7b23b343d20f2a167d12f07dcb01ca41c42fdd60b7fca9c4b90890792"
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	registeredFeeRecipient := [20]byte{0x01}
+	opts := common.CreateTestBlockSubmissionOpts{Slot: slot, ParentHash: parentHash, ProposerPubkey: proposerPubkey} //nolint:exhaustruct
+	_, _, getHeaderResp := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(12345), &opts)
+	backend.relay.bidCache.set(slot, parentHash, proposerPubkey, getHeaderResp)
+	backend.relay.proposerDutiesMap = map[uint64]*common.BuilderGetValidatorsResponseEntry{
+		slot: {Slot: slot, Entry: &types.SignedValidatorRegistration{Message: &types.RegisterValidatorRequestMessage{FeeRecipient: registeredFeeRecipient}}}, //nolint:exhaustruct
+	}
+	saveBidTrace := func(proposerFeeRecipient [20]byte) {
+		trace := &common.BidTraceV2{BidTrace: v1.BidTrace{Slot: slot, Value: uint256.NewInt(12345), ProposerFeeRecipient: proposerFeeRecipient}} //nolint:exhaustruct
+		trace.ProposerPubkey, _ = common.StrToPhase0Pubkey(proposerPubkey)
+		trace.BlockHash = getHeaderResp.BlockHash()
+		tx := backend.redis.NewTxPipeline()
+		require.NoError(t, backend.redis.SaveBidTrace(context.Background(), tx, trace))
+		_, err := tx.Exec(context.Background())
+		require.NoError(t, err)
+	}
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, proposerPubkey)
+
+	// The bid pays someone else than the registered fee recipient
+	saveBidTrace([20]byte{0x02})
+	rr := backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	saveBidTrace(registeredFeeRecipient)
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
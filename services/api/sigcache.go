@@ -0,0 +1,124 @@
+package api
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/go-utils/cli"
+)
+
+var registrationSigCacheSize = cli.GetEnvInt("REGISTRATION_SIG_CACHE_SIZE", 100_000)
+
+type sigCacheEntry struct {
+	key       [32]byte
+	ok        bool
+	expiresAt time.Time
+}
+
+// sigCache remembers the results of signature verifications for a short time, so that clients re-submitting the same
+// registration (i.e. every slot) don't cost a BLS verification each time. Entries are keyed by the message root, domain,
+// pubkey and signature, so any change to the registration is verified again.
+type sigCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[[32]byte]*list.Element
+	expiry  *list.List // by expiry, front expires last
+}
+
+func newSigCache(ttl time.Duration, maxEntries int) *sigCache {
+	return &sigCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[[32]byte]*list.Element),
+		expiry:     list.New(),
+	}
+}
+
+func sigCacheKey(obj boostTypes.HashTreeRoot, domain boostTypes.Domain, pubkey, sig []byte) ([32]byte, error) {
+	root, err := obj.HashTreeRoot()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	h := sha256.New()
+	h.Write(root[:])
+	h.Write(domain[:])
+	h.Write(pubkey)
+	h.Write(sig)
+	var key [32]byte
+	copy(key[:], h.Sum(nil))
+	return key, nil
+}
+
+func (c *sigCache) get(key [32]byte) (ok, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.entries[key]
+	if !found {
+		return false, false
+	}
+	entry := el.Value.(*sigCacheEntry) //nolint:forcetypeassert
+	if time.Now().After(entry.expiresAt) {
+		return false, false
+	}
+	return entry.ok, true
+}
+
+// set stores the result. Expired entries are removed first, and if the cache is still full the entry which expires
+// first is evicted. All entries have the same ttl, so the expiry list is in insertion order and set is O(1) amortized.
+func (c *sigCache) set(key [32]byte, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for el := c.expiry.Back(); el != nil && now.After(el.Value.(*sigCacheEntry).expiresAt); el = c.expiry.Back() { //nolint:forcetypeassert
+		c.removeElement(el)
+	}
+
+	if el, found := c.entries[key]; found {
+		c.removeElement(el)
+	}
+	c.entries[key] = c.expiry.PushFront(&sigCacheEntry{key: key, ok: ok, expiresAt: now.Add(c.ttl)})
+	for c.expiry.Len() > c.maxEntries {
+		c.removeElement(c.expiry.Back())
+	}
+}
+
+func (c *sigCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.expiry.Len()
+}
+
+func (c *sigCache) removeElement(el *list.Element) {
+	c.expiry.Remove(el)
+	delete(c.entries, el.Value.(*sigCacheEntry).key) //nolint:forcetypeassert
+}
+
+// verifyRegistrationSignature verifies the signature of a validator registration, using the cached result of an
+// identical registration if there is one
+func (api *RelayAPI) verifyRegistrationSignature(msg *boostTypes.RegisterValidatorRequestMessage, sig []byte) (ok, cached bool, err error) {
+	if api.registrationSigCache == nil {
+		ok, err = api.verifySignature(false, msg, api.opts.EthNetDetails.DomainBuilder, msg.Pubkey[:], sig)
+		return ok, false, err
+	}
+
+	key, err := sigCacheKey(msg, api.opts.EthNetDetails.DomainBuilder, msg.Pubkey[:], sig)
+	if err != nil {
+		return false, false, err
+	}
+	if ok, found := api.registrationSigCache.get(key); found {
+		return ok, true, nil
+	}
+	ok, err = api.verifySignature(false, msg, api.opts.EthNetDetails.DomainBuilder, msg.Pubkey[:], sig)
+	if err != nil {
+		return false, false, err
+	}
+	api.registrationSigCache.set(key, ok)
+	return ok, false, nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRegistrationSignatureCache(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	backend.relay.registrationSigCache = newSigCache(time.Minute, 2)
+
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	msg := &boostTypes.RegisterValidatorRequestMessage{GasLimit: 30_000_000, Timestamp: 1}
+	copy(msg.Pubkey[:], bls.PublicKeyToBytes(pk))
+	sig, err := boostTypes.SignMessage(msg, backend.relay.opts.EthNetDetails.DomainBuilder, sk)
+	require.NoError(t, err)
+
+	ok, cached, err := backend.relay.verifyRegistrationSignature(msg, sig[:])
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.False(t, cached)
+
+	// An identical registration uses the cached result
+	ok, cached, err = backend.relay.verifyRegistrationSignature(msg, sig[:])
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, cached)
+
+	// A changed registration is verified again
+	msg2 := *msg
+	msg2.Timestamp = 2
+	ok, cached, err = backend.relay.verifyRegistrationSignature(&msg2, sig[:])
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.False(t, cached)
+
+	// The cache is full: the oldest entry is evicted
+	msg3 := *msg
+	msg3.Timestamp = 3
+	_, _, err = backend.relay.verifyRegistrationSignature(&msg3, sig[:])
+	require.NoError(t, err)
+	require.Equal(t, 2, backend.relay.registrationSigCache.len())
+	_, cached, err = backend.relay.verifyRegistrationSignature(&msg3, sig[:])
+	require.NoError(t, err)
+	require.True(t, cached)
+	_, cached, err = backend.relay.verifyRegistrationSignature(msg, sig[:])
+	require.NoError(t, err)
+	require.False(t, cached)
+
+	backend.relay.registrationSigCache = newSigCache(-time.Second, 2)
+	_, _, err = backend.relay.verifyRegistrationSignature(msg, sig[:])
+	require.NoError(t, err)
+	_, cached, err = backend.relay.verifyRegistrationSignature(msg, sig[:])
+	require.NoError(t, err)
+	require.False(t, cached, "expired entries aren't used")
+}
+
+func TestSigCacheExpiry(t *testing.T) {
+	c := newSigCache(time.Minute, 10)
+	for i := 0; i < 5; i++ {
+		c.set([32]byte{byte(i)}, true)
+	}
+	require.Equal(t, 5, c.len())
+
+	// Setting a key again moves it to the front, so it's evicted last
+	c.set([32]byte{0}, false)
+	c.maxEntries = 4
+	c.set([32]byte{5}, true)
+	require.Equal(t, 4, c.len())
+	_, found := c.get([32]byte{1})
+	require.False(t, found)
+	ok, found := c.get([32]byte{0})
+	require.True(t, found)
+	require.False(t, ok)
+
+	// Expired entries are removed on the next set
+	for _, el := range c.entries {
+		el.Value.(*sigCacheEntry).expiresAt = time.Now().Add(-time.Second) //nolint:forcetypeassert
+	}
+	c.set([32]byte{6}, true)
+	require.Equal(t, 1, c.len())
+}
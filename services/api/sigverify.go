@@ -0,0 +1,90 @@
+package api
+
+import (
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/go-utils/cli"
+)
+
+var sigVerifyQueueSize = cli.GetEnvInt("SIG_VERIFY_QUEUE_SIZE", 1024) // jobs queued per priority before callers block
+
+type sigVerifyJob struct {
+	obj    boostTypes.HashTreeRoot
+	domain boostTypes.Domain
+	pubkey []byte
+	sig    []byte
+	result chan sigVerifyResult
+}
+
+type sigVerifyResult struct {
+	ok  bool
+	err error
+}
+
+// sigVerifier runs BLS signature verifications on a fixed number of workers, so that a burst of requests (i.e. validator
+// registrations) queues up instead of using all cores. Workers pick high-priority jobs (getPayload) before any other.
+type sigVerifier struct {
+	highC   chan *sigVerifyJob
+	normalC chan *sigVerifyJob
+}
+
+func newSigVerifier(numWorkers int) *sigVerifier {
+	v := &sigVerifier{
+		highC:   make(chan *sigVerifyJob, sigVerifyQueueSize),
+		normalC: make(chan *sigVerifyJob, sigVerifyQueueSize),
+	}
+	for i := 0; i < numWorkers; i++ {
+		go v.worker()
+	}
+	return v
+}
+
+func (v *sigVerifier) worker() {
+	for {
+		select {
+		case job := <-v.highC:
+			job.run()
+			continue
+		default:
+		}
+
+		select {
+		case job := <-v.highC:
+			job.run()
+		case job := <-v.normalC:
+			job.run()
+		}
+	}
+}
+
+func (j *sigVerifyJob) run() {
+	ok, err := boostTypes.VerifySignature(j.obj, j.domain, j.pubkey, j.sig)
+	j.result <- sigVerifyResult{ok, err}
+}
+
+// verify queues the verification and waits for the result. It blocks while the queue is full.
+func (v *sigVerifier) verify(highPriority bool, obj boostTypes.HashTreeRoot, domain boostTypes.Domain, pubkey, sig []byte) (bool, error) {
+	job := &sigVerifyJob{obj: obj, domain: domain, pubkey: pubkey, sig: sig, result: make(chan sigVerifyResult, 1)}
+	if highPriority {
+		v.highC <- job
+	} else {
+		v.normalC <- job
+	}
+	res := <-job.result
+	return res.ok, res.err
+}
+
+// queueDepth returns the number of jobs waiting for a worker
+func (v *sigVerifier) queueDepth(highPriority bool) int {
+	if highPriority {
+		return len(v.highC)
+	}
+	return len(v.normalC)
+}
+
+// verifySignature verifies the signature on the worker pool if enabled, and otherwise in the calling goroutine
+func (api *RelayAPI) verifySignature(highPriority bool, obj boostTypes.HashTreeRoot, domain boostTypes.Domain, pubkey, sig []byte) (bool, error) {
+	if api.sigVerifier == nil {
+		return boostTypes.VerifySignature(obj, domain, pubkey, sig)
+	}
+	return api.sigVerifier.verify(highPriority, obj, domain, pubkey, sig)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigVerifier(t *testing.T) {
+	sk, pk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	pubkey := bls.PublicKeyToBytes(pk)
+
+	msg := &boostTypes.RegisterValidatorRequestMessage{GasLimit: 30_000_000, Timestamp: 1}
+	domain := boostTypes.ComputeDomain(boostTypes.DomainTypeAppBuilder, boostTypes.ForkVersion{}, boostTypes.Root{})
+	sig, err := boostTypes.SignMessage(msg, domain, sk)
+	require.NoError(t, err)
+
+	v := newSigVerifier(2)
+	for _, highPriority := range []bool{true, false} {
+		ok, err := v.verify(highPriority, msg, domain, pubkey, sig[:])
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		msg2 := *msg
+		msg2.Timestamp = 2
+		ok, err = v.verify(highPriority, &msg2, domain, pubkey, sig[:])
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+	require.Equal(t, 0, v.queueDepth(true))
+	require.Equal(t, 0, v.queueDepth(false))
+}
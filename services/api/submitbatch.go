@@ -0,0 +1,77 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// submissionBatcher collects block submissions in fixed windows and commits them together at the end of the window,
+// so that the top bid only changes at window boundaries and builders submitting within a window compete on value
+// rather than on latency. The windows end at multiples of the window duration before the slot start, and submissions
+// received at or after the slot start are committed right away, so that getHeader never waits for a window.
+type submissionBatcher struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	batches map[int64]*submissionBatch // end of the window (unix ms) -> submissions to commit
+}
+
+type submissionBatch struct {
+	commits []func()
+	done    chan struct{}
+}
+
+func newSubmissionBatcher(window time.Duration) *submissionBatcher {
+	return &submissionBatcher{
+		window:  window,
+		batches: make(map[int64]*submissionBatch),
+	}
+}
+
+// windowEnd returns the end of the window a submission received at receivedAt belongs to, or false if it isn't batched
+func (b *submissionBatcher) windowEnd(receivedAt, slotStart time.Time) (time.Time, bool) {
+	untilSlotStart := slotStart.Sub(receivedAt)
+	if untilSlotStart <= 0 {
+		return time.Time{}, false
+	}
+	return slotStart.Add(-(untilSlotStart / b.window) * b.window), true
+}
+
+// do runs commit at the end of the submission's window, after the commits of the submissions of the window which
+// completed validation before it, and returns after it ran. Without batching (nil), commit runs right away.
+func (b *submissionBatcher) do(receivedAt, slotStart time.Time, commit func()) {
+	if b == nil {
+		commit()
+		return
+	}
+	end, batched := b.windowEnd(receivedAt, slotStart)
+	if !batched {
+		commit()
+		return
+	}
+
+	b.mu.Lock()
+	batch, found := b.batches[end.UnixMilli()]
+	if !found {
+		batch = &submissionBatch{done: make(chan struct{})}
+		b.batches[end.UnixMilli()] = batch
+		time.AfterFunc(time.Until(end), func() { b.flush(end) })
+	}
+	batch.commits = append(batch.commits, commit)
+	b.mu.Unlock()
+
+	<-batch.done
+}
+
+// flush commits the submissions of the window in the order they were added
+func (b *submissionBatcher) flush(end time.Time) {
+	b.mu.Lock()
+	batch := b.batches[end.UnixMilli()]
+	delete(b.batches, end.UnixMilli())
+	b.mu.Unlock()
+
+	for _, commit := range batch.commits {
+		commit()
+	}
+	close(batch.done)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmissionBatcherWindowEnd(t *testing.T) {
+	b := newSubmissionBatcher(500 * time.Millisecond)
+	slotStart := time.UnixMilli(12_000)
+
+	end, batched := b.windowEnd(time.UnixMilli(11_200), slotStart)
+	require.True(t, batched)
+	require.Equal(t, time.UnixMilli(11_500), end)
+
+	end, batched = b.windowEnd(time.UnixMilli(11_999), slotStart)
+	require.True(t, batched)
+	require.Equal(t, slotStart, end)
+
+	// No batching from the slot start on
+	_, batched = b.windowEnd(slotStart, slotStart)
+	require.False(t, batched)
+	_, batched = b.windowEnd(time.UnixMilli(12_100), slotStart)
+	require.False(t, batched)
+}
+
+func TestSubmissionBatcher(t *testing.T) {
+	var noBatcher *submissionBatcher
+	ran := false
+	noBatcher.do(time.Now(), time.Now().Add(time.Second), func() { ran = true })
+	require.True(t, ran)
+
+	b := newSubmissionBatcher(100 * time.Millisecond)
+	slotStart := time.Now().Add(time.Second)
+
+	// Submissions of a window are committed together, in order, at the end of the window
+	var mu sync.Mutex
+	var order []int
+	var committedAt []time.Time
+	var wg sync.WaitGroup
+	receivedAt := time.Now()
+	end, _ := b.windowEnd(receivedAt, slotStart)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go b.do(receivedAt, slotStart, func(i int) func() {
+			return func() {
+				defer wg.Done()
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, i)
+				committedAt = append(committedAt, time.Now())
+			}
+		}(i))
+		time.Sleep(5 * time.Millisecond) // keep the order of the submissions
+	}
+	wg.Wait()
+	require.Equal(t, []int{0, 1, 2}, order)
+	for _, at := range committedAt {
+		require.False(t, at.Before(end))
+	}
+	require.Empty(t, b.batches)
+
+	// Submissions after the slot start aren't delayed
+	ran = false
+	b.do(slotStart, slotStart, func() { ran = true })
+	require.True(t, ran)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/flashbots/mev-boost-relay/datastore"
+)
+
+// Outcomes of a block submission, see SubmitBlockResponse. Submissions refused with an error (i.e. an invalid
+// signature or simulation failure) get the usual error response instead.
+const (
+	submitBlockStatusAcceptedBest    = "accepted_best"
+	submitBlockStatusAcceptedNotBest = "accepted_not_best"
+	submitBlockStatusRejected        = "rejected"
+)
+
+// Reasons for a submission to be rejected without an error
+const (
+	submitBlockReasonZeroValue  = "zero_value"
+	submitBlockReasonBelowFloor = "below_floor"
+)
+
+// newSubmitBlockResponse returns the outcome of a submission for which the bid was saved (or not) in Redis. The bid is
+// the best one if the top bid is worth its value and is the builder's, since of equal bids the top bid is the one of the
+// builder with the best reputation.
+func newSubmitBlockResponse(updateBidResult datastore.SaveBidAndUpdateTopBidResponse, value *big.Int, builderPubkey string) *SubmitBlockResponse {
+	resp := &SubmitBlockResponse{ //nolint:exhaustruct
+		Status:      submitBlockStatusAcceptedNotBest,
+		TopBidValue: bigIntString(updateBidResult.TopBidValue),
+	}
+	if !updateBidResult.WasBidSaved {
+		resp.Status = submitBlockStatusRejected
+		resp.Reason = submitBlockReasonBelowFloor
+	} else if updateBidResult.TopBidValue != nil && value.Cmp(updateBidResult.TopBidValue) == 0 && strings.EqualFold(updateBidResult.TopBidBuilderPubkey, builderPubkey) {
+		resp.Status = submitBlockStatusAcceptedBest
+	}
+	return resp
+}
+
+// newSubmitBlockRejectedResponse returns the outcome of a submission rejected before being saved
+func newSubmitBlockRejectedResponse(reason string) *SubmitBlockResponse {
+	return &SubmitBlockResponse{ //nolint:exhaustruct
+		Status: submitBlockStatusRejected,
+		Reason: reason,
+	}
+}
+
+// newSubmitBlockBelowFloorResponse returns the outcome of a submission rejected for the floor bid value
+func newSubmitBlockBelowFloorResponse(floorValue *big.Int) *SubmitBlockResponse {
+	resp := newSubmitBlockRejectedResponse(submitBlockReasonBelowFloor)
+	resp.FloorValue = bigIntString(floorValue)
+	return resp
+}
+
+func bigIntString(value *big.Int) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/flashbots/mev-boost-relay/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitBlockResponse(t *testing.T) {
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	otherBuilderPubkey := "0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249"
+
+	resp := newSubmitBlockResponse(datastore.SaveBidAndUpdateTopBidResponse{WasBidSaved: true, IsNewTopBid: true, TopBidValue: big.NewInt(3), TopBidBuilderPubkey: builderPubkey}, big.NewInt(3), builderPubkey) //nolint:exhaustruct
+	require.Equal(t, &SubmitBlockResponse{Status: submitBlockStatusAcceptedBest, TopBidValue: "3"}, resp)                                                                                                        //nolint:exhaustruct
+
+	// An equal bid of a builder with a better reputation is the top bid
+	resp = newSubmitBlockResponse(datastore.SaveBidAndUpdateTopBidResponse{WasBidSaved: true, TopBidValue: big.NewInt(4), TopBidBuilderPubkey: otherBuilderPubkey}, big.NewInt(4), builderPubkey) //nolint:exhaustruct
+	require.Equal(t, &SubmitBlockResponse{Status: submitBlockStatusAcceptedNotBest, TopBidValue: "4"}, resp)                                                                                      //nolint:exhaustruct
+
+	resp = newSubmitBlockResponse(datastore.SaveBidAndUpdateTopBidResponse{WasBidSaved: true, TopBidValue: big.NewInt(5)}, big.NewInt(4), builderPubkey) //nolint:exhaustruct
+	require.Equal(t, &SubmitBlockResponse{Status: submitBlockStatusAcceptedNotBest, TopBidValue: "5"}, resp)                                             //nolint:exhaustruct
+
+	resp = newSubmitBlockResponse(datastore.SaveBidAndUpdateTopBidResponse{TopBidValue: big.NewInt(5)}, big.NewInt(4), builderPubkey)      //nolint:exhaustruct
+	require.Equal(t, &SubmitBlockResponse{Status: submitBlockStatusRejected, Reason: submitBlockReasonBelowFloor, TopBidValue: "5"}, resp) //nolint:exhaustruct
+
+	// The values are left out if not known
+	respBytes, err := json.Marshal(newSubmitBlockRejectedResponse(submitBlockReasonZeroValue))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"status":"rejected","reason":"zero_value"}`, string(respBytes))
+
+	respBytes, err = json.Marshal(newSubmitBlockBelowFloorResponse(big.NewInt(7)))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"status":"rejected","reason":"below_floor","floor_value":"7"}`, string(respBytes))
+}
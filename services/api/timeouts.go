@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Default timeouts of the API server
+const (
+	DefaultReadTimeout       = 1500 * time.Millisecond
+	DefaultReadHeaderTimeout = 600 * time.Millisecond
+	DefaultWriteTimeout      = 10 * time.Second
+	DefaultIdleTimeout       = 3 * time.Second
+)
+
+// writeTimeoutMargin is the time a response needs to be written after a handler stopped waiting
+var writeTimeoutMargin = time.Second
+
+var (
+	ErrWriteTimeoutTooShort  = errors.New("write timeout is too short")
+	ErrGetHeaderWaitConflict = errors.New("getHeader deadline into the slot and GETHEADER_MAX_WAIT_MS are mutually exclusive")
+)
+
+// setDefaultTimeouts sets the server timeouts which are not configured to their defaults
+func (opts *RelayAPIOpts) setDefaultTimeouts() {
+	setDefault := func(timeout *time.Duration, defaultTimeout time.Duration) {
+		if *timeout <= 0 {
+			*timeout = defaultTimeout
+		}
+	}
+	setDefault(&opts.ReadTimeout, DefaultReadTimeout)
+	setDefault(&opts.ReadHeaderTimeout, DefaultReadHeaderTimeout)
+	setDefault(&opts.WriteTimeout, DefaultWriteTimeout)
+	setDefault(&opts.IdleTimeout, DefaultIdleTimeout)
+}
+
+// checkWriteTimeout returns an error if the write timeout would cut off requests which wait on purpose: getHeader
+// waiting for more bids, and block submissions waiting for the end of their batch window. The write timeout starts
+// when the request headers are read, so it needs to cover the wait plus the time to write the response.
+func checkWriteTimeout(writeTimeout, getHeaderMaxWait, submissionBatchWindow time.Duration) error {
+	maxWait := getHeaderMaxWait
+	if submissionBatchWindow > maxWait {
+		maxWait = submissionBatchWindow
+	}
+	if writeTimeout < maxWait+writeTimeoutMargin {
+		return fmt.Errorf("%w: %s, needs to be at least %s (longest wait %s plus %s)", ErrWriteTimeoutTooShort, writeTimeout, maxWait+writeTimeoutMargin, maxWait, writeTimeoutMargin)
+	}
+	return nil
+}
+
+// getHeaderLongestWait returns how long getHeader waits for bids at most, with either the fixed maximum wait or the
+// deadline into the slot (for requests up to maxEarly before the slot start)
+func getHeaderLongestWait(maxWait, deadlineIntoSlot, maxEarly time.Duration) (time.Duration, error) {
+	if deadlineIntoSlot <= 0 {
+		return maxWait, nil
+	} else if maxWait > 0 {
+		return 0, ErrGetHeaderWaitConflict
+	}
+	return deadlineIntoSlot + maxEarly, nil
+}
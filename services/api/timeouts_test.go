@@ -0,0 +1,39 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDefaultTimeouts(t *testing.T) {
+	opts := RelayAPIOpts{WriteTimeout: 20 * time.Second} //nolint:exhaustruct
+	opts.setDefaultTimeouts()
+	require.Equal(t, DefaultReadTimeout, opts.ReadTimeout)
+	require.Equal(t, DefaultReadHeaderTimeout, opts.ReadHeaderTimeout)
+	require.Equal(t, 20*time.Second, opts.WriteTimeout)
+	require.Equal(t, DefaultIdleTimeout, opts.IdleTimeout)
+}
+
+func TestCheckWriteTimeout(t *testing.T) {
+	require.NoError(t, checkWriteTimeout(DefaultWriteTimeout, 0, 0))
+	require.NoError(t, checkWriteTimeout(2*time.Second, time.Second, 500*time.Millisecond))
+
+	// The longest wait plus the margin needs to fit
+	require.ErrorIs(t, checkWriteTimeout(time.Second, 500*time.Millisecond, 0), ErrWriteTimeoutTooShort)
+	require.ErrorIs(t, checkWriteTimeout(2*time.Second, 0, 1500*time.Millisecond), ErrWriteTimeoutTooShort)
+}
+
+func TestGetHeaderLongestWait(t *testing.T) {
+	wait, err := getHeaderLongestWait(time.Second, 0, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, wait)
+
+	wait, err = getHeaderLongestWait(0, 300*time.Millisecond, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, 1300*time.Millisecond, wait)
+
+	_, err = getHeaderLongestWait(time.Second, 300*time.Millisecond, time.Second)
+	require.ErrorIs(t, err, ErrGetHeaderWaitConflict)
+}
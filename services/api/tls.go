@@ -0,0 +1,64 @@
+package api
+
+import (
+	"crypto/tls"
+	"errors"
+	"sync"
+)
+
+var ErrTLSCertAndKey = errors.New("both TLS certificate and key are required")
+
+// certReloader serves the TLS certificate for the API server, and allows replacing it while the server is running
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the certificate and key from disk. On error, the previous certificate stays in use.
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	return nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}
+}
+
+// ReloadTLSCertificate loads the TLS certificate and key again, to rotate them without a restart (i.e. on SIGHUP)
+func (api *RelayAPI) ReloadTLSCertificate() error {
+	if api.certReloader == nil {
+		return nil
+	}
+	if err := api.certReloader.reload(); err != nil {
+		return err
+	}
+	api.log.Infof("reloaded TLS certificate from %s", api.opts.TLSCertFile)
+	return nil
+}
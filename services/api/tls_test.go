@@ -0,0 +1,77 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCert writes a self-signed certificate and its key to the given files
+func writeTestCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeTestCert(t, certFile, keyFile, "first")
+
+	backend := newTestBackend(t, 1)
+	backend.relay.opts.TLSCertFile = certFile
+	r, err := newCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	backend.relay.certReloader = r
+
+	commonName := func() string {
+		cert, err := r.getCertificate(nil)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return leaf.Subject.CommonName
+	}
+	require.Equal(t, "first", commonName())
+
+	// The new certificate is served after the reload
+	writeTestCert(t, certFile, keyFile, "second")
+	require.Equal(t, "first", commonName())
+	require.NoError(t, backend.relay.ReloadTLSCertificate())
+	require.Equal(t, "second", commonName())
+
+	// A broken certificate is rejected, and the previous one stays in use
+	require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0o600))
+	require.Error(t, backend.relay.ReloadTLSCertificate())
+	require.Equal(t, "second", commonName())
+}
+
+func TestTLSOptsValidation(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	opts := backend.relay.opts
+	opts.TLSCertFile = "cert.pem"
+	_, err := NewRelayAPI(opts)
+	require.ErrorIs(t, err, ErrTLSCertAndKey)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"time"
 
 	boostTypes "github.com/flashbots/go-boost-utils/types"
 )
@@ -13,11 +14,89 @@ var (
 	ErrInvalidTransaction = errors.New("invalid transaction")
 )
 
-type HTTPErrorResp struct {
-	Code    int    `json:"code"`
+// ReadinessResponse is returned by /readyz, describing the state of each dependency
+type ReadinessResponse struct {
+	Ready  bool   `json:"ready"`
+	Beacon string `json:"beacon"`
+	Redis  string `json:"redis"`
+
+	// State of the beacon node circuit breaker (closed, half-open or open), if enabled
+	BeaconCircuitBreaker string `json:"beacon_circuit_breaker,omitempty"`
+
+	// Whether the relay is in maintenance mode (no new bids are served or accepted)
+	Maintenance bool `json:"maintenance"`
+}
+
+// RelayStatusResponse is returned by the relay status endpoint
+type RelayStatusResponse struct {
+	Pubkey                string `json:"pubkey,omitempty"` // empty if the relay has no secret key (i.e. only serves the data API)
+	Network               string `json:"network"`
+	GenesisForkVersion    string `json:"genesis_fork_version"`
+	GenesisValidatorsRoot string `json:"genesis_validators_root"`
+	BellatrixForkVersion  string `json:"bellatrix_fork_version"`
+	CapellaForkVersion    string `json:"capella_fork_version"`
+	DenebForkVersion      string `json:"deneb_fork_version"`
+	Version               string `json:"version"`
+}
+
+// BuilderStatsJSON is returned by the builder_stats data endpoint
+type BuilderStatsJSON struct {
+	BuilderPubkey          string `json:"builder_pubkey"`
+	BuilderID              string `json:"builder_id"`
+	LastSubmissionSlot     uint64 `json:"last_submission_slot,string"`
+	NumSubmissionsTotal    uint64 `json:"num_submissions_total,string"`
+	NumSubmissionsSimError uint64 `json:"num_submissions_simerror,string"`
+	NumSentGetHeader       uint64 `json:"num_sent_getheader,string"`
+	NumSentGetPayload      uint64 `json:"num_sent_getpayload,string"`
+}
+
+// ValidatorRegistrationCountsJSON is returned by the validator_registration_counts data endpoint. The history has the
+// number of unique registered validators at the end of each day (UTC) with new registrations.
+type ValidatorRegistrationCountsJSON struct {
+	NumValidators uint64                              `json:"num_validators,string"`
+	History       []ValidatorRegistrationDayCountJSON `json:"history"`
+	UpdatedAt     time.Time                           `json:"updated_at"`
+}
+
+type ValidatorRegistrationDayCountJSON struct {
+	Date          string `json:"date"`
+	NumValidators uint64 `json:"num_validators,string"`
+}
+
+// BidDecisionJSON is returned by the bid_decisions data endpoint: the bid getHeader committed to serve to the proposer
+type BidDecisionJSON struct {
+	Slot           uint64 `json:"slot,string"`
+	ProposerPubkey string `json:"proposer_pubkey"`
+	ParentHash     string `json:"parent_hash"`
+	BuilderPubkey  string `json:"builder_pubkey"`
+	Value          string `json:"value"`
+	BlockHash      string `json:"block_hash"`
+	DecidedAtMs    int64  `json:"decided_at_ms,string"`
+}
+
+// NoBidResponse is returned by getHeader instead of 204 if there is no bid to serve and the request asks for it, so
+// that the proposer's mev-boost can log why it builds the block locally
+type NoBidResponse struct {
+	Slot    uint64 `json:"slot,string"`
+	Reason  string `json:"reason"`
 	Message string `json:"message"`
 }
 
+// SubmitBlockResponse is the outcome of a block submission which wasn't refused with an error: whether the bid is the
+// top bid, and the current top bid value (or the floor bid value the bid didn't beat)
+type SubmitBlockResponse struct {
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+	TopBidValue string `json:"top_bid_value,omitempty"`
+	FloorValue  string `json:"floor_value,omitempty"`
+}
+
+type HTTPErrorResp struct {
+	Code      int       `json:"code"`
+	ErrorCode ErrorCode `json:"error_code"`
+	Message   string    `json:"message"`
+}
+
 var NilResponse = struct{}{}
 
 var ZeroU256 = boostTypes.IntToU256(0)
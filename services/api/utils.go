@@ -2,6 +2,8 @@ package api
 
 import (
 	"errors"
+	"net"
+	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
@@ -18,6 +20,8 @@ var (
 	ErrNoWithdrawals            = errors.New("no withdrawals")
 	ErrPayloadMismatchBellatrix = errors.New("bellatrix beacon-block but no bellatrix payload")
 	ErrPayloadMismatchCapella   = errors.New("capella beacon-block but no capella payload")
+	ErrPayloadMismatchDeneb     = errors.New("deneb beacon-block but no deneb payload")
+	ErrBlobCommitmentsMismatch  = errors.New("beacon-block and blobs bundle commitments mismatch")
 	ErrHeaderHTRMismatch        = errors.New("beacon-block and payload header mismatch")
 )
 
@@ -33,6 +37,48 @@ func SanityCheckBuilderBlockSubmission(payload *common.BuilderSubmitBlockRequest
 	return nil
 }
 
+// getHeaderWaitTime returns how long a getHeader request received msIntoSlot milliseconds into the slot should wait for
+// more bids: until waitUntilMs into the slot, but no longer than maxWaitMs. Late requests don't wait at all.
+func getHeaderWaitTime(msIntoSlot int64, maxWaitMs, waitUntilMs int) time.Duration {
+	if maxWaitMs <= 0 {
+		return 0
+	}
+	waitMs := int64(waitUntilMs) - msIntoSlot
+	if waitMs <= 0 {
+		return 0
+	} else if waitMs > int64(maxWaitMs) {
+		waitMs = int64(maxWaitMs)
+	}
+	return time.Duration(waitMs) * time.Millisecond
+}
+
+// getHeaderDeadlineWaitTime returns how long a getHeader request received msIntoSlot milliseconds into the slot should
+// wait for more bids to respond at the deadline into the slot. Requests before the slot start wait until the deadline
+// too, but requests more than maxEarly before it only wait as much as one maxEarly before it, to stay within the write
+// timeout. If the slot start is unknown, it waits the deadline as a fixed duration.
+func getHeaderDeadlineWaitTime(msIntoSlot int64, deadline, maxEarly time.Duration, slotStartKnown bool) time.Duration {
+	if !slotStartKnown {
+		return deadline
+	}
+	waitTime := deadline - time.Duration(msIntoSlot)*time.Millisecond
+	if waitTime <= 0 {
+		return 0
+	} else if waitTime > deadline+maxEarly {
+		return deadline + maxEarly
+	}
+	return waitTime
+}
+
+// submissionMsIntoSlot returns how far into the slot before the submission's slot, in which the block is built on the
+// previous slot's block, the submission was received
+func submissionMsIntoSlot(receivedAt time.Time, genesisTime, slot uint64) int64 {
+	if slot == 0 {
+		return receivedAt.UnixMilli() - int64(genesisTime*1000)
+	}
+	buildSlotStart := genesisTime + (slot-1)*common.SecondsPerSlot
+	return receivedAt.UnixMilli() - int64(buildSlotStart*1000)
+}
+
 func checkBLSPublicKeyHex(pkHex string) error {
 	var proposerPubkey boostTypes.PublicKey
 	return proposerPubkey.UnmarshalText([]byte(pkHex))
@@ -100,5 +146,56 @@ func EqExecutionPayloadToHeader(bb *common.SignedBlindedBeaconBlock, payload *co
 		return nil
 	}
 
+	if bb.Deneb != nil { // process Deneb beacon block
+		if payload.Deneb == nil {
+			return ErrPayloadMismatchDeneb
+		}
+
+		bbHeaderHtr, err := bb.Deneb.Message.Body.ExecutionPayloadHeader.HashTreeRoot()
+		if err != nil {
+			return err
+		}
+
+		payloadHeader, err := common.DenebPayloadToPayloadHeader(payload.Deneb.ExecutionPayload)
+		if err != nil {
+			return err
+		}
+		payloadHeaderHtr, err := payloadHeader.HashTreeRoot()
+		if err != nil {
+			return err
+		}
+
+		if bbHeaderHtr != payloadHeaderHtr {
+			return ErrHeaderHTRMismatch
+		}
+
+		// the proposer signed the commitments of the blobs we deliver
+		commitments := payload.Deneb.BlobsBundle.Commitments
+		if len(bb.Deneb.Message.Body.BlobKzgCommitments) != len(commitments) {
+			return ErrBlobCommitmentsMismatch
+		}
+		for i, commitment := range bb.Deneb.Message.Body.BlobKzgCommitments {
+			if commitment != commitments[i] {
+				return ErrBlobCommitmentsMismatch
+			}
+		}
+
+		// deneb block and payload are equal
+		return nil
+	}
+
 	return ErrNoPayloads
 }
+
+// isLoopbackListenAddr returns whether the listen address (host:port) only accepts connections from the same host
+func isLoopbackListenAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/flashbots/go-boost-utils/types"
+)
+
+// isSlashedOrExited returns true, and the validator's status, if slashed and exited validators are refused and the
+// validator was slashed or has exited. The status is the one of the last known validators refresh of the datastore,
+// and validators which are not known (yet) are not refused.
+func (api *RelayAPI) isSlashedOrExited(pubkey types.PubkeyHex) (status string, refused bool) {
+	if !api.opts.RejectSlashedValidators {
+		return "", false
+	}
+	status, found := api.datastore.GetKnownValidatorStatus(types.NewPubkeyHex(pubkey.String()))
+	if !found {
+		return "", false
+	}
+	refused = status == "active_slashed" || strings.HasPrefix(status, "exited") || strings.HasPrefix(status, "withdrawal")
+	return status, refused
+}
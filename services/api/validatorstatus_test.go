@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+// setKnownValidators refreshes the known validators of the test backend's datastore with the given entries
+func setKnownValidators(backend *testBackend, entries ...beaconclient.ValidatorResponseEntry) {
+	beaconInstance := beaconclient.NewMockBeaconInstance()
+	for _, entry := range entries {
+		beaconInstance.AddValidator(entry)
+	}
+	beaconClient := beaconclient.NewMultiBeaconClient(common.TestLog, []beaconclient.IBeaconInstance{beaconInstance})
+	backend.datastore.RefreshKnownValidators(beaconClient, 64)
+}
+
+func TestIsSlashedOrExited(t *testing.T) {
+	pubkeys := []string{
+		"0x8a1d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca249",
+		"0xb5d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca24a",
+		"0xa5d7b8dd64e0aafe7ea7b6c95065c9364cf99d38470c12ee807d55f7de1529ad29ce2c422e0b65e3d5a05c02caca24b",
+	}
+	backend := newTestBackend(t, 1)
+	setKnownValidators(backend,
+		beaconclient.ValidatorResponseEntry{Index: 1, Status: "active_ongoing", Validator: beaconclient.ValidatorResponseValidatorData{Pubkey: pubkeys[0]}},                //nolint:exhaustruct
+		beaconclient.ValidatorResponseEntry{Index: 2, Status: "active_slashed", Validator: beaconclient.ValidatorResponseValidatorData{Pubkey: pubkeys[1], Slashed: true}}, //nolint:exhaustruct
+		beaconclient.ValidatorResponseEntry{Index: 3, Status: "exited_unslashed", Validator: beaconclient.ValidatorResponseValidatorData{Pubkey: pubkeys[2]}},              //nolint:exhaustruct
+	)
+
+	// Nothing is refused unless enabled
+	_, refused := backend.relay.isSlashedOrExited(types.PubkeyHex(pubkeys[1]))
+	require.False(t, refused)
+
+	backend.relay.opts.RejectSlashedValidators = true
+	_, refused = backend.relay.isSlashedOrExited(types.PubkeyHex(pubkeys[0]))
+	require.False(t, refused)
+	status, refused := backend.relay.isSlashedOrExited(types.PubkeyHex(pubkeys[1]))
+	require.True(t, refused)
+	require.Equal(t, "active_slashed", status)
+	status, refused = backend.relay.isSlashedOrExited(types.PubkeyHex(pubkeys[2]))
+	require.True(t, refused)
+	require.Equal(t, "exited_unslashed", status)
+
+	// Unknown validators are not refused
+	_, refused = backend.relay.isSlashedOrExited(types.PubkeyHex("0x01"))
+	require.False(t, refused)
+}
+
+func TestRegisterValidatorSlashed(t *testing.T) {
+	backend := newTestBackend(t, 1)
+	pubkey := common.ValidPayloadRegisterValidator.Message.Pubkey
+	backend.relay.opts.RejectSlashedValidators = true
+	setKnownValidators(backend, beaconclient.ValidatorResponseEntry{ //nolint:exhaustruct
+		Index:     1,
+		Status:    "active_slashed",
+		Validator: beaconclient.ValidatorResponseValidatorData{Pubkey: pubkey.String(), Slashed: true}, //nolint:exhaustruct
+	})
+
+	rr := backend.request(http.MethodPost, pathRegisterValidator, []types.SignedValidatorRegistration{common.ValidPayloadRegisterValidator})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), string(ErrorCodeValidatorSlashedOrExited))
+	require.Empty(t, backend.relay.validatorRegC)
+
+	// getHeader is refused as well, although there is a bid
+	slot := uint64(2)
+	parentHash := "0x13e606c7b3d1faad7e83503ce3dedce4c6bb89b0c28ffb240d713c7b110b9747"
+	backend.relay.headSlot.Store(slot)
+	backend.relay.genesisInfo = &beaconclient.GetGenesisResponse{Data: beaconclient.GetGenesisResponseData{GenesisTime: uint64(time.Now().UTC().Unix())}} //nolint:exhaustruct
+	opts := common.CreateTestBlockSubmissionOpts{Slot: slot, ParentHash: parentHash, ProposerPubkey: pubkey.String()}                                     //nolint:exhaustruct
+	payload, getPayloadResp, getHeaderResp := common.CreateTestBlockSubmission(t, "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83", big.NewInt(99), &opts)
+	_, err := backend.redis.SaveBidAndUpdateTopBid(context.Background(), backend.redis.NewPipeline(), &common.BidTraceV2{BidTrace: *payload.Message()}, payload, getPayloadResp, getHeaderResp, time.Now(), false, nil) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	path := fmt.Sprintf("/eth/v1/builder/header/%d/%s/%s", slot, parentHash, pubkey.String())
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	rr = backend.request(http.MethodGet, path+"?no_bid_response=1", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	resp := new(NoBidResponse)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), resp))
+	require.Equal(t, noBidReasonValidatorStatus, resp.Reason)
+
+	// The bid is served to validators which are not slashed or exited
+	backend.relay.opts.RejectSlashedValidators = false
+	rr = backend.request(http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
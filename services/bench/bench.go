@@ -0,0 +1,377 @@
+// Package bench measures the latency of getHeader (and optionally getPayload) requests to a running relay under
+// concurrent load, for capacity planning and tuning of the getHeader wait times.
+//
+// The requests are validly signed with the given proposer key, for the given slot. getPayload requests are signed
+// blinded blocks (of the fork of the slot) with the header of the first served bid, so they are only accepted by the
+// relay if the proposer has a duty in the slot. Only one block is signed, since a second proposal for the slot would be
+// slashable, and getPayload refuses public networks unless forced. Only use getPayload against test networks.
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidRelayURL = errors.New("invalid relay URL")
+	ErrInvalidOpts     = errors.New("invalid bench options")
+	ErrPublicNetwork   = errors.New("refusing to sign a proposal on a public network")
+)
+
+// publicNetworks are the networks on which getPayload requests are only sent if forced
+var publicNetworks = map[string]bool{
+	common.EthNetworkMainnet: true,
+	common.EthNetworkGoerli:  true,
+	common.EthNetworkSepolia: true,
+	common.EthNetworkRopsten: true,
+}
+
+var (
+	pathGetHeader  = "/eth/v1/builder/header/%d/%s/%s"
+	pathGetPayload = "/eth/v1/builder/blinded_blocks"
+)
+
+type Opts struct {
+	Log           *logrus.Entry
+	RelayURL      string
+	EthNetDetails common.EthNetworkDetails
+
+	ProposerSk    *bls.SecretKey
+	ProposerIndex uint64
+	Slot          uint64
+	ParentHash    string
+
+	Concurrency int
+	Requests    int  // number of getHeader requests, each followed by a getPayload request if GetPayload is set
+	GetPayload  bool // send a getPayload request after each served bid, all with the blinded block of the first one
+	Timeout     time.Duration
+
+	// Send getPayload requests on a public network (mainnet or public testnets), where the signed proposal can get the
+	// proposer slashed
+	Force bool
+}
+
+// Stats are the latencies and results of the requests to one endpoint
+type Stats struct {
+	Name        string
+	Requests    int
+	Errors      int         // requests which failed or got a status code of 400 or above
+	StatusCodes map[int]int // responses by status code
+	Duration    time.Duration
+
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+func (s *Stats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+func (s *Stats) RequestsPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Requests) / s.Duration.Seconds()
+}
+
+// recorder collects the results of the requests to one endpoint from all workers
+type recorder struct {
+	mu          sync.Mutex
+	latencies   []time.Duration
+	errors      int
+	statusCodes map[int]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{statusCodes: make(map[int]int)}
+}
+
+func (r *recorder) record(latency time.Duration, statusCode int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies = append(r.latencies, latency)
+	if statusCode > 0 {
+		r.statusCodes[statusCode]++
+	}
+	if err != nil || statusCode >= http.StatusBadRequest {
+		r.errors++
+	}
+}
+
+func (r *recorder) stats(name string, duration time.Duration) *Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	return &Stats{
+		Name:        name,
+		Requests:    len(r.latencies),
+		Errors:      r.errors,
+		StatusCodes: r.statusCodes,
+		Duration:    duration,
+		P50:         percentile(r.latencies, 0.5),
+		P90:         percentile(r.latencies, 0.9),
+		P99:         percentile(r.latencies, 0.99),
+		Max:         percentile(r.latencies, 1),
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+type bench struct {
+	opts     *Opts
+	client   *http.Client
+	relayURL string
+
+	proposerPubkey boostTypes.PublicKey
+	proposerDomain boostTypes.Domain
+	fork           string // name of the fork of the slot
+
+	// the blinded block signed for the first served bid, which is sent in all getPayload requests
+	signedBlockMu  sync.Mutex
+	signedBlock    any
+	signedBlockErr error
+
+	getHeader  *recorder
+	getPayload *recorder
+}
+
+// Run sends the requests on opts.Concurrency workers and returns the stats of getHeader, and of getPayload if enabled
+func Run(ctx context.Context, opts *Opts) ([]*Stats, error) {
+	if opts.Concurrency <= 0 || opts.Requests <= 0 {
+		return nil, fmt.Errorf("%w: concurrency and requests must be positive", ErrInvalidOpts)
+	}
+	if opts.ProposerSk == nil {
+		return nil, fmt.Errorf("%w: proposer secret key is required", ErrInvalidOpts)
+	}
+	if opts.GetPayload && publicNetworks[opts.EthNetDetails.Name] && !opts.Force {
+		return nil, fmt.Errorf("%w: %s", ErrPublicNetwork, opts.EthNetDetails.Name)
+	}
+	u, err := url.Parse(opts.RelayURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRelayURL, opts.RelayURL)
+	}
+
+	b := &bench{
+		opts:       opts,
+		client:     &http.Client{Timeout: opts.Timeout}, //nolint:exhaustruct
+		relayURL:   strings.TrimSuffix(u.String(), "/"),
+		getHeader:  newRecorder(),
+		getPayload: newRecorder(),
+	}
+	blsPubkey, err := bls.PublicKeyFromSecretKey(opts.ProposerSk)
+	if err != nil {
+		return nil, err
+	}
+	if b.proposerPubkey, err = boostTypes.BlsPublicKeyToPublicKey(blsPubkey); err != nil {
+		return nil, err
+	}
+	forkSchedule, err := opts.EthNetDetails.ForkSchedule()
+	if err != nil {
+		return nil, err
+	}
+	b.proposerDomain = forkSchedule.ProposerDomain(opts.Slot)
+	b.fork = forkSchedule.ForkAtEpoch(opts.Slot / common.SlotsPerEpoch).Name
+	if opts.GetPayload && b.fork != common.ForkVersionStringCapella && b.fork != common.ForkVersionStringDeneb {
+		return nil, fmt.Errorf("%w: getPayload for fork %s", ErrInvalidOpts, b.fork)
+	}
+
+	jobs := make(chan struct{}, opts.Requests)
+	for i := 0; i < opts.Requests; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				b.run(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+	duration := time.Since(start)
+
+	results := []*Stats{b.getHeader.stats("getHeader", duration)}
+	if opts.GetPayload {
+		results = append(results, b.getPayload.stats("getPayload", duration))
+	}
+	return results, nil
+}
+
+// run sends one getHeader request, and a getPayload request for the served bid if enabled
+func (b *bench) run(ctx context.Context) {
+	path := fmt.Sprintf(pathGetHeader, b.opts.Slot, b.opts.ParentHash, b.proposerPubkey.String())
+	bid := new(common.GetHeaderResponse)
+	start := time.Now()
+	code, err := b.request(ctx, http.MethodGet, path, nil, bid)
+	b.getHeader.record(time.Since(start), code, err)
+	if err != nil {
+		b.opts.Log.WithError(err).Debug("getHeader failed")
+		return
+	}
+	if !b.opts.GetPayload || code == http.StatusNoContent {
+		return
+	}
+
+	signedBlindedBlock, err := b.slotSignedBlindedBlock(bid)
+	if err != nil {
+		b.getPayload.record(0, 0, err)
+		b.opts.Log.WithError(err).Debug("could not sign the blinded block")
+		return
+	}
+	start = time.Now()
+	code, err = b.request(ctx, http.MethodPost, pathGetPayload, signedBlindedBlock, nil)
+	b.getPayload.record(time.Since(start), code, err)
+	if err != nil {
+		b.opts.Log.WithError(err).Debug("getPayload failed")
+	}
+}
+
+// slotSignedBlindedBlock returns the signed blinded block of the slot, which is signed for the first served bid only
+func (b *bench) slotSignedBlindedBlock(bid *common.GetHeaderResponse) (any, error) {
+	b.signedBlockMu.Lock()
+	defer b.signedBlockMu.Unlock()
+	if b.signedBlock == nil && b.signedBlockErr == nil {
+		b.signedBlock, b.signedBlockErr = b.signedBlindedBlock(bid)
+	}
+	return b.signedBlock, b.signedBlockErr
+}
+
+func (b *bench) signedBlindedBlock(bid *common.GetHeaderResponse) (any, error) {
+	slot := phase0.Slot(b.opts.Slot)
+	proposerIndex := phase0.ValidatorIndex(b.opts.ProposerIndex)
+	eth1Data := &phase0.ETH1Data{DepositRoot: phase0.Root{}, DepositCount: 0, BlockHash: make([]byte, 32)}
+	syncAggregate := &altair.SyncAggregate{SyncCommitteeBits: make([]byte, 64)} //nolint:exhaustruct
+
+	if b.fork == common.ForkVersionStringDeneb {
+		if bid.Deneb == nil || bid.Deneb.Message == nil {
+			return nil, fmt.Errorf("%w: not a deneb bid", ErrInvalidOpts)
+		}
+		blindedBlock := &apiv1deneb.BlindedBeaconBlock{ //nolint:exhaustruct
+			Slot:          slot,
+			ProposerIndex: proposerIndex,
+			Body: &apiv1deneb.BlindedBeaconBlockBody{ //nolint:exhaustruct
+				ETH1Data:               eth1Data,
+				ProposerSlashings:      []*phase0.ProposerSlashing{},
+				AttesterSlashings:      []*phase0.AttesterSlashing{},
+				Attestations:           []*phase0.Attestation{},
+				Deposits:               []*phase0.Deposit{},
+				VoluntaryExits:         []*phase0.SignedVoluntaryExit{},
+				SyncAggregate:          syncAggregate,
+				ExecutionPayloadHeader: bid.Deneb.Message.Header,
+				BLSToExecutionChanges:  []*consensuscapella.SignedBLSToExecutionChange{},
+				BlobKzgCommitments:     bid.Deneb.Message.BlobKzgCommitments,
+			},
+		}
+		signature, err := boostTypes.SignMessage(blindedBlock, b.proposerDomain, b.opts.ProposerSk)
+		if err != nil {
+			return nil, err
+		}
+		return &apiv1deneb.SignedBlindedBeaconBlock{Message: blindedBlock, Signature: phase0.BLSSignature(signature)}, nil
+	}
+
+	if bid.Capella == nil || bid.Capella.Capella == nil {
+		return nil, fmt.Errorf("%w: not a capella bid", ErrInvalidOpts)
+	}
+	blindedBlock := &apiv1capella.BlindedBeaconBlock{ //nolint:exhaustruct
+		Slot:          slot,
+		ProposerIndex: proposerIndex,
+		Body: &apiv1capella.BlindedBeaconBlockBody{ //nolint:exhaustruct
+			ETH1Data:               eth1Data,
+			ProposerSlashings:      []*phase0.ProposerSlashing{},
+			AttesterSlashings:      []*phase0.AttesterSlashing{},
+			Attestations:           []*phase0.Attestation{},
+			Deposits:               []*phase0.Deposit{},
+			VoluntaryExits:         []*phase0.SignedVoluntaryExit{},
+			SyncAggregate:          syncAggregate,
+			ExecutionPayloadHeader: bid.Capella.Capella.Message.Header,
+			BLSToExecutionChanges:  []*consensuscapella.SignedBLSToExecutionChange{},
+		},
+	}
+	signature, err := boostTypes.SignMessage(blindedBlock, b.proposerDomain, b.opts.ProposerSk)
+	if err != nil {
+		return nil, err
+	}
+	return &apiv1capella.SignedBlindedBeaconBlock{Message: blindedBlock, Signature: phase0.BLSSignature(signature)}, nil
+}
+
+// request sends a JSON request to the relay and decodes the response into dst (if not nil). The body is read in full,
+// so the latency includes the transfer of the response.
+func (b *bench) request(ctx context.Context, method, path string, payload, dst any) (int, error) {
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(payloadBytes)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.relayURL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w: %d / %s", common.ErrHTTPErrorResponse, resp.StatusCode, strings.TrimSpace(string(respBytes)))
+	}
+	if dst != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.Unmarshal(respBytes, dst); err != nil {
+			return resp.StatusCode, fmt.Errorf("could not decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
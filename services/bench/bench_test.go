@@ -0,0 +1,158 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+	require.Equal(t, 50*time.Millisecond, percentile(latencies, 0.5))
+	require.Equal(t, 90*time.Millisecond, percentile(latencies, 0.9))
+	require.Equal(t, 99*time.Millisecond, percentile(latencies, 0.99))
+	require.Equal(t, 100*time.Millisecond, percentile(latencies, 1))
+	require.Equal(t, time.Duration(0), percentile(nil, 0.5))
+}
+
+func TestRun(t *testing.T) {
+	netDetails, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
+	require.NoError(t, err)
+	sk, blsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+
+	const slot = 7_000_000
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	_, _, getHeaderResp1 := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(1), nil)
+	_, _, getHeaderResp2 := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(2), nil)
+
+	var numGetHeader, numGetPayload atomic.Int64
+	var signaturesMu sync.Mutex
+	signatures := make(map[phase0.BLSSignature]bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/eth/v1/builder/header/") {
+			// every other request gets no bid, and the bids alternate
+			n := numGetHeader.Add(1)
+			switch n % 4 {
+			case 1:
+				_ = json.NewEncoder(w).Encode(getHeaderResp1)
+			case 3:
+				_ = json.NewEncoder(w).Encode(getHeaderResp2)
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+
+		numGetPayload.Add(1)
+		block := new(apiv1capella.SignedBlindedBeaconBlock)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(block))
+		ok, err := boostTypes.VerifySignature(block.Message, netDetails.DomainBeaconProposerCapella, bls.PublicKeyToBytes(blsPubkey), block.Signature[:])
+		require.NoError(t, err)
+		require.True(t, ok)
+		signaturesMu.Lock()
+		signatures[block.Signature] = true
+		signaturesMu.Unlock()
+		http.Error(w, "no execution payload for this request", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	opts := &Opts{
+		Log:           logrus.NewEntry(logrus.New()),
+		RelayURL:      srv.URL,
+		EthNetDetails: *netDetails,
+		ProposerSk:    sk,
+		Slot:          slot,
+		ParentHash:    "0x01",
+		Concurrency:   4,
+		Requests:      10,
+		GetPayload:    true,
+		Timeout:       time.Second,
+	}
+
+	// getPayload signs a proposal, which isn't sent on public networks unless forced
+	_, err = Run(context.Background(), opts)
+	require.ErrorIs(t, err, ErrPublicNetwork)
+
+	opts.Force = true
+	results, err := Run(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	getHeader := results[0]
+	require.Equal(t, "getHeader", getHeader.Name)
+	require.Equal(t, 10, getHeader.Requests)
+	require.Equal(t, 0, getHeader.Errors)
+	require.Equal(t, map[int]int{http.StatusOK: 5, http.StatusNoContent: 5}, getHeader.StatusCodes)
+	require.LessOrEqual(t, getHeader.P50, getHeader.P99)
+
+	getPayload := results[1]
+	require.Equal(t, 5, getPayload.Requests)
+	require.Equal(t, 5, getPayload.Errors)
+	require.InDelta(t, 1.0, getPayload.ErrorRate(), 0.001)
+	require.Equal(t, int64(5), numGetPayload.Load())
+
+	// only one block is signed for the slot, although different bids were served
+	require.Len(t, signatures, 1)
+
+	opts.Concurrency = 0
+	_, err = Run(context.Background(), opts)
+	require.ErrorIs(t, err, ErrInvalidOpts)
+}
+
+func TestSignedBlindedBlockDeneb(t *testing.T) {
+	netDetails, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
+	require.NoError(t, err)
+	forkSchedule, err := netDetails.ForkSchedule()
+	require.NoError(t, err)
+	sk, blsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	relaySk, relayBlsPubkey, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	relayPubkey, err := boostTypes.BlsPublicKeyToPublicKey(relayBlsPubkey)
+	require.NoError(t, err)
+
+	builderPubkey := "0xfa1ed37c3553d0ce1e9349b2c5063cf6e394d231c8d3e0df75e9462257c081543086109ffddaacc0aa76f33dc9661c83"
+	payload, _, capellaBid := common.CreateTestBlockSubmission(t, builderPubkey, big.NewInt(1), nil)
+	denebPayload := &common.BuilderSubmitBlockRequest{Deneb: common.CapellaToDenebSubmitBlockRequest(payload.Capella, 1)} //nolint:exhaustruct
+	denebBid, err := common.BuildGetHeaderResponse(denebPayload, relaySk, &relayPubkey, netDetails.DomainBuilder)
+	require.NoError(t, err)
+
+	slot := netDetails.ForkEpochs[common.ForkVersionStringDeneb] * common.SlotsPerEpoch
+	b := &bench{ //nolint:exhaustruct
+		opts:           &Opts{ProposerSk: sk, Slot: slot}, //nolint:exhaustruct
+		proposerDomain: forkSchedule.ProposerDomain(slot),
+		fork:           forkSchedule.ForkAtEpoch(slot / common.SlotsPerEpoch).Name,
+	}
+	signedBlindedBlock, err := b.signedBlindedBlock(denebBid)
+	require.NoError(t, err)
+	block, ok := signedBlindedBlock.(*apiv1deneb.SignedBlindedBeaconBlock)
+	require.True(t, ok)
+	require.Len(t, block.Message.Body.BlobKzgCommitments, 1)
+	ok, err = boostTypes.VerifySignature(block.Message, netDetails.DomainBeaconProposerDeneb, bls.PublicKeyToBytes(blsPubkey), block.Signature[:])
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// a capella bid for a deneb slot
+	_, err = b.signedBlindedBlock(capellaBid)
+	require.ErrorIs(t, err, ErrInvalidOpts)
+}
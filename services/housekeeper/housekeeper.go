@@ -81,7 +81,7 @@ func (hk *Housekeeper) Start() (err error) {
 	}
 
 	// Get best beacon-node status by head slot, process current slot and start slot updates
-	bestSyncStatus, err := hk.beaconClient.BestSyncStatus()
+	bestSyncStatus, err := beaconclient.WaitForSyncStatus(hk.log, hk.beaconClient)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,517 @@
+// Package selftest runs the full relay flow against a running relay: a validator registration, a block submission
+// for the validator's next proposal, getHeader and getPayload, all with synthetic but validly signed messages.
+//
+// The validator key needs to be the key of an active validator (i.e. of a devnet), since the relay only accepts
+// registrations of known validators and submissions for proposer duties. The submitted block is not a valid block,
+// so the slot is missed if the relay's getPayload publishes it. The blinded block of getPayload is a signed proposal
+// of the validator for its duty, which is slashable if the validator also proposes another block in the slot, so Run
+// refuses public networks unless forced. Only use it on test networks.
+package selftest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-builder-client/api/capella"
+	apiv1 "github.com/attestantio/go-builder-client/api/v1"
+	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/holiman/uint256"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidRelayURL = errors.New("invalid relay URL")
+	ErrNoProposerDuty  = errors.New("no upcoming proposer duty for the validator")
+	ErrUnexpectedBid   = errors.New("getHeader returned another bid")
+	ErrInvalidBidSig   = errors.New("invalid relay signature on the bid")
+	ErrUnexpectedBlock = errors.New("getPayload returned another block")
+	ErrPublicNetwork   = errors.New("refusing to sign a proposal on a public network")
+	ErrUnsupportedFork = errors.New("unsupported fork")
+)
+
+var (
+	pathStatus            = "/eth/v1/builder/status"
+	pathRegisterValidator = "/eth/v1/builder/validators"
+	pathGetHeader         = "/eth/v1/builder/header/%d/%s/%s"
+	pathGetPayload        = "/eth/v1/builder/blinded_blocks"
+	pathBuilderValidators = "/relay/v1/builder/validators"
+	pathSubmitNewBlock    = "/relay/v1/builder/blocks"
+
+	// time between the head event of the slot before the proposal and the submission, for the relay to receive the
+	// payload attributes
+	payloadAttributesDelay = 500 * time.Millisecond
+	pollInterval           = time.Second
+)
+
+// BeaconNode is the part of the beacon node API needed to build a submission for the next slot
+type BeaconNode interface {
+	GetGenesis() (*beaconclient.GetGenesisResponse, error)
+	CurrentSlot() (uint64, error)
+	GetBlock(blockID string) (*beaconclient.GetBlockResponse, error)
+	GetRandao(slot uint64) (*beaconclient.GetRandaoResponse, error)
+	GetWithdrawals(slot uint64) (*beaconclient.GetWithdrawalsResponse, error)
+}
+
+type Opts struct {
+	Log           *logrus.Entry
+	RelayURL      string // the relay pubkey can be given as user (https://0xPUBKEY@host) to verify the bid signature
+	Beacon        BeaconNode
+	EthNetDetails common.EthNetworkDetails
+
+	ValidatorSk  *bls.SecretKey
+	BuilderSk    *bls.SecretKey
+	FeeRecipient boostTypes.Address
+	GasLimit     uint64
+	BidValue     *big.Int
+
+	// Maximum time to wait for a proposer duty of the validator
+	DutyTimeout time.Duration
+
+	// Run on a public network (mainnet or public testnets), where the signed proposal can get the validator slashed
+	Force bool
+}
+
+// publicNetworks are the networks on which the self-test only runs if forced
+var publicNetworks = map[string]bool{
+	common.EthNetworkMainnet: true,
+	common.EthNetworkGoerli:  true,
+	common.EthNetworkSepolia: true,
+	common.EthNetworkRopsten: true,
+}
+
+// StepResult is the outcome of one step of the self-test
+type StepResult struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+type selfTest struct {
+	opts        *Opts
+	log         *logrus.Entry
+	client      *http.Client
+	relayURL    string
+	relayPubkey *boostTypes.PublicKey
+
+	validatorPubkey boostTypes.PublicKey
+	builderPubkey   boostTypes.PublicKey
+	forkSchedule    *common.ForkSchedule
+
+	// set by the steps, for the following ones
+	fork       string // name of the fork of the duty slot
+	duty       *common.BuilderGetValidatorsResponseEntry
+	submission *common.BuilderSubmitBlockRequest
+	bid        *common.GetHeaderResponse
+}
+
+// Run runs the steps in order, and stops at the first failed one since each step depends on the previous ones
+func Run(ctx context.Context, opts *Opts) ([]StepResult, error) {
+	if publicNetworks[opts.EthNetDetails.Name] && !opts.Force {
+		return nil, fmt.Errorf("%w: %s", ErrPublicNetwork, opts.EthNetDetails.Name)
+	}
+	t := &selfTest{ //nolint:exhaustruct
+		opts:   opts,
+		log:    opts.Log,
+		client: &http.Client{Timeout: 10 * time.Second}, //nolint:exhaustruct
+	}
+	if err := t.parseRelayURL(opts.RelayURL); err != nil {
+		return nil, err
+	}
+	var err error
+	if t.validatorPubkey, err = pubkeyFromSecretKey(opts.ValidatorSk); err != nil {
+		return nil, err
+	}
+	if t.builderPubkey, err = pubkeyFromSecretKey(opts.BuilderSk); err != nil {
+		return nil, err
+	}
+	if t.forkSchedule, err = opts.EthNetDetails.ForkSchedule(); err != nil {
+		return nil, err
+	}
+
+	steps := []struct {
+		name string
+		fn   func(ctx context.Context) error
+	}{
+		{"status", t.status},
+		{"registerValidator", t.registerValidator},
+		{"proposerDuty", t.waitForProposerDuty},
+		{"submitBlock", t.submitBlock},
+		{"getHeader", t.getHeader},
+		{"getPayload", t.getPayload},
+	}
+	results := make([]StepResult, 0, len(steps))
+	for _, step := range steps {
+		start := time.Now()
+		err := step.fn(ctx)
+		results = append(results, StepResult{Name: step.name, Err: err, Duration: time.Since(start)})
+		if err != nil {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (t *selfTest) parseRelayURL(relayURL string) error {
+	u, err := url.Parse(relayURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidRelayURL, relayURL)
+	}
+	if u.User != nil {
+		pubkey, err := boostTypes.HexToPubkey(u.User.Username())
+		if err != nil {
+			return fmt.Errorf("%w: invalid pubkey: %s", ErrInvalidRelayURL, err.Error())
+		}
+		t.relayPubkey = &pubkey
+		u.User = nil
+	}
+	t.relayURL = strings.TrimSuffix(u.String(), "/")
+	return nil
+}
+
+// request sends a JSON request to the relay and decodes the response into dst (if not nil)
+func (t *selfTest) request(ctx context.Context, method, path string, payload, dst any) (int, error) {
+	var body io.Reader
+	if payload != nil {
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(payloadBytes)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, t.relayURL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w: %d / %s", common.ErrHTTPErrorResponse, resp.StatusCode, strings.TrimSpace(string(respBytes)))
+	}
+	if dst != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.Unmarshal(respBytes, dst); err != nil {
+			return resp.StatusCode, fmt.Errorf("could not decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func (t *selfTest) status(ctx context.Context) error {
+	_, err := t.request(ctx, http.MethodGet, pathStatus, nil, nil)
+	return err
+}
+
+func (t *selfTest) registerValidator(ctx context.Context) error {
+	msg := &boostTypes.RegisterValidatorRequestMessage{
+		FeeRecipient: t.opts.FeeRecipient,
+		GasLimit:     t.opts.GasLimit,
+		Timestamp:    uint64(time.Now().Unix()),
+		Pubkey:       t.validatorPubkey,
+	}
+	signature, err := boostTypes.SignMessage(msg, t.opts.EthNetDetails.DomainBuilder, t.opts.ValidatorSk)
+	if err != nil {
+		return err
+	}
+	registrations := []boostTypes.SignedValidatorRegistration{{Message: msg, Signature: signature}}
+	_, err = t.request(ctx, http.MethodPost, pathRegisterValidator, registrations, nil)
+	return err
+}
+
+// waitForProposerDuty waits until the relay lists a proposer duty of the validator, and the beacon node's head is the
+// slot before it
+func (t *selfTest) waitForProposerDuty(ctx context.Context) error {
+	timeout := time.NewTimer(t.opts.DutyTimeout)
+	defer timeout.Stop()
+
+	for {
+		headSlot, err := t.opts.Beacon.CurrentSlot()
+		if err != nil {
+			return err
+		}
+
+		if t.duty == nil || t.duty.Slot <= headSlot {
+			t.duty = nil
+			duties := []common.BuilderGetValidatorsResponseEntry{}
+			if _, err := t.request(ctx, http.MethodGet, pathBuilderValidators, nil, &duties); err != nil {
+				return err
+			}
+			for i, duty := range duties {
+				if duty.Slot > headSlot && duty.Entry != nil && duty.Entry.Message.Pubkey == t.validatorPubkey {
+					t.duty = &duties[i]
+					t.log.Infof("found proposer duty in slot %d, head slot is %d", duty.Slot, headSlot)
+					break
+				}
+			}
+		}
+
+		// the duty is in the next slot: submit once the relay has the payload attributes
+		ready := t.duty != nil && t.duty.Slot == headSlot+1
+		wait := pollInterval
+		if ready {
+			wait = payloadAttributesDelay
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C:
+			return fmt.Errorf("%w within %s", ErrNoProposerDuty, t.opts.DutyTimeout)
+		case <-time.After(wait):
+			if ready {
+				return nil
+			}
+		}
+	}
+}
+
+func (t *selfTest) submitBlock(ctx context.Context) error {
+	slot := t.duty.Slot
+	t.fork = t.forkSchedule.ForkAtEpoch(slot / common.SlotsPerEpoch).Name
+	if t.fork != common.ForkVersionStringCapella && t.fork != common.ForkVersionStringDeneb {
+		return fmt.Errorf("%w: %s in slot %d", ErrUnsupportedFork, t.fork, slot)
+	}
+
+	genesis, err := t.opts.Beacon.GetGenesis()
+	if err != nil {
+		return err
+	}
+	parent, err := t.opts.Beacon.GetBlock(fmt.Sprint(slot - 1))
+	if err != nil {
+		return err
+	}
+	randao, err := t.opts.Beacon.GetRandao(slot - 1)
+	if err != nil {
+		return err
+	}
+	prevRandao, err := common.StrToPhase0Hash(randao.Data.Randao)
+	if err != nil {
+		return err
+	}
+	withdrawals, err := t.opts.Beacon.GetWithdrawals(slot - 1)
+	if err != nil {
+		return err
+	}
+
+	var blockHash phase0.Hash32
+	if _, err := rand.Read(blockHash[:]); err != nil {
+		return err
+	}
+	value, overflow := uint256.FromBig(t.opts.BidValue)
+	if overflow {
+		return fmt.Errorf("bid value %s is too large", t.opts.BidValue)
+	}
+	parentHash := phase0.Hash32(parent.Data.Message.Body.ExecutionPayload.BlockHash)
+	feeRecipient := bellatrix.ExecutionAddress(t.duty.Entry.Message.FeeRecipient)
+	gasLimit := t.duty.Entry.Message.GasLimit
+	blockNumber := parent.Data.Message.Body.ExecutionPayload.BlockNumber + 1
+	timestamp := genesis.Data.GenesisTime + slot*common.SecondsPerSlot
+	transactions := []bellatrix.Transaction{{0x03}}
+
+	bidTrace := &apiv1.BidTrace{
+		Slot:                 slot,
+		ParentHash:           parentHash,
+		BlockHash:            blockHash,
+		BuilderPubkey:        phase0.BLSPubKey(t.builderPubkey),
+		ProposerPubkey:       phase0.BLSPubKey(t.validatorPubkey),
+		ProposerFeeRecipient: feeRecipient,
+		GasLimit:             gasLimit,
+		GasUsed:              21000,
+		Value:                value,
+	}
+	signature, err := boostTypes.SignMessage(bidTrace, t.opts.EthNetDetails.DomainBuilder, t.opts.BuilderSk)
+	if err != nil {
+		return err
+	}
+
+	if t.fork == common.ForkVersionStringDeneb {
+		t.submission = &common.BuilderSubmitBlockRequest{ //nolint:exhaustruct
+			Deneb: &common.DenebSubmitBlockRequest{
+				Message:   bidTrace,
+				Signature: phase0.BLSSignature(signature),
+				ExecutionPayload: &deneb.ExecutionPayload{ //nolint:exhaustruct
+					ParentHash:    parentHash,
+					FeeRecipient:  feeRecipient,
+					PrevRandao:    prevRandao,
+					BlockNumber:   blockNumber,
+					GasLimit:      gasLimit,
+					GasUsed:       bidTrace.GasUsed,
+					Timestamp:     timestamp,
+					BaseFeePerGas: new(uint256.Int),
+					BlockHash:     blockHash,
+					Transactions:  transactions,
+					Withdrawals:   withdrawals.Data.Withdrawals,
+				},
+				BlobsBundle: &common.BlobsBundle{
+					Commitments: []deneb.KzgCommitment{},
+					Proofs:      []deneb.KzgProof{},
+					Blobs:       []deneb.Blob{},
+				},
+			},
+		}
+	} else {
+		t.submission = &common.BuilderSubmitBlockRequest{ //nolint:exhaustruct
+			Capella: &capella.SubmitBlockRequest{
+				Message:   bidTrace,
+				Signature: phase0.BLSSignature(signature),
+				ExecutionPayload: &consensuscapella.ExecutionPayload{ //nolint:exhaustruct
+					ParentHash:   parentHash,
+					FeeRecipient: feeRecipient,
+					PrevRandao:   prevRandao,
+					BlockNumber:  blockNumber,
+					GasLimit:     gasLimit,
+					GasUsed:      bidTrace.GasUsed,
+					Timestamp:    timestamp,
+					BlockHash:    blockHash,
+					Transactions: transactions,
+					Withdrawals:  withdrawals.Data.Withdrawals,
+				},
+			},
+		}
+	}
+	_, err = t.request(ctx, http.MethodPost, pathSubmitNewBlock, t.submission, nil)
+	return err
+}
+
+func (t *selfTest) getHeader(ctx context.Context) error {
+	path := fmt.Sprintf(pathGetHeader, t.duty.Slot, t.submission.ParentHash(), t.validatorPubkey.String())
+	bid := new(common.GetHeaderResponse)
+	code, err := t.request(ctx, http.MethodGet, path, nil, bid)
+	if err != nil {
+		return err
+	} else if code == http.StatusNoContent || bid.Empty() {
+		return fmt.Errorf("%w: no bid", ErrUnexpectedBid)
+	}
+
+	if bid.BlockHash() != t.submission.Message().BlockHash {
+		return fmt.Errorf("%w: block hash %s, value %s", ErrUnexpectedBid, bid.BlockHash().String(), bid.Value())
+	}
+
+	var msg boostTypes.HashTreeRoot
+	var msgPubkey, signature []byte
+	switch {
+	case t.fork == common.ForkVersionStringDeneb && bid.Deneb != nil && bid.Deneb.Message != nil:
+		msg, msgPubkey, signature = bid.Deneb.Message, bid.Deneb.Message.Pubkey[:], bid.Deneb.Signature[:]
+	case t.fork == common.ForkVersionStringCapella && bid.Capella != nil && bid.Capella.Capella != nil:
+		msg, msgPubkey, signature = bid.Capella.Capella.Message, bid.Capella.Capella.Message.Pubkey[:], bid.Capella.Capella.Signature[:]
+	default:
+		return fmt.Errorf("%w: not a %s bid", ErrUnexpectedBid, t.fork)
+	}
+	if t.relayPubkey != nil {
+		ok, err := boostTypes.VerifySignature(msg, t.opts.EthNetDetails.DomainBuilder, t.relayPubkey[:], signature)
+		if err != nil {
+			return err
+		} else if !ok || !bytes.Equal(msgPubkey, t.relayPubkey[:]) {
+			return ErrInvalidBidSig
+		}
+	}
+	t.bid = bid
+	return nil
+}
+
+func (t *selfTest) getPayload(ctx context.Context) error {
+	slot := phase0.Slot(t.duty.Slot)
+	proposerIndex := phase0.ValidatorIndex(t.duty.ValidatorIndex)
+	eth1Data := &phase0.ETH1Data{DepositRoot: phase0.Root{}, DepositCount: 0, BlockHash: make([]byte, 32)}
+	syncAggregate := &altair.SyncAggregate{SyncCommitteeBits: make([]byte, 64)} //nolint:exhaustruct
+	domain := t.forkSchedule.ProposerDomain(t.duty.Slot)
+
+	var signedBlindedBlock any
+	if t.fork == common.ForkVersionStringDeneb {
+		blindedBlock := &apiv1deneb.BlindedBeaconBlock{ //nolint:exhaustruct
+			Slot:          slot,
+			ProposerIndex: proposerIndex,
+			Body: &apiv1deneb.BlindedBeaconBlockBody{ //nolint:exhaustruct
+				ETH1Data:               eth1Data,
+				ProposerSlashings:      []*phase0.ProposerSlashing{},
+				AttesterSlashings:      []*phase0.AttesterSlashing{},
+				Attestations:           []*phase0.Attestation{},
+				Deposits:               []*phase0.Deposit{},
+				VoluntaryExits:         []*phase0.SignedVoluntaryExit{},
+				SyncAggregate:          syncAggregate,
+				ExecutionPayloadHeader: t.bid.Deneb.Message.Header,
+				BLSToExecutionChanges:  []*consensuscapella.SignedBLSToExecutionChange{},
+				BlobKzgCommitments:     t.bid.Deneb.Message.BlobKzgCommitments,
+			},
+		}
+		signature, err := boostTypes.SignMessage(blindedBlock, domain, t.opts.ValidatorSk)
+		if err != nil {
+			return err
+		}
+		signedBlindedBlock = &apiv1deneb.SignedBlindedBeaconBlock{Message: blindedBlock, Signature: phase0.BLSSignature(signature)}
+	} else {
+		blindedBlock := &apiv1capella.BlindedBeaconBlock{ //nolint:exhaustruct
+			Slot:          slot,
+			ProposerIndex: proposerIndex,
+			Body: &apiv1capella.BlindedBeaconBlockBody{ //nolint:exhaustruct
+				ETH1Data:               eth1Data,
+				ProposerSlashings:      []*phase0.ProposerSlashing{},
+				AttesterSlashings:      []*phase0.AttesterSlashing{},
+				Attestations:           []*phase0.Attestation{},
+				Deposits:               []*phase0.Deposit{},
+				VoluntaryExits:         []*phase0.SignedVoluntaryExit{},
+				SyncAggregate:          syncAggregate,
+				ExecutionPayloadHeader: t.bid.Capella.Capella.Message.Header,
+				BLSToExecutionChanges:  []*consensuscapella.SignedBLSToExecutionChange{},
+			},
+		}
+		signature, err := boostTypes.SignMessage(blindedBlock, domain, t.opts.ValidatorSk)
+		if err != nil {
+			return err
+		}
+		signedBlindedBlock = &apiv1capella.SignedBlindedBeaconBlock{Message: blindedBlock, Signature: phase0.BLSSignature(signature)}
+	}
+
+	payload := new(common.VersionedExecutionPayload)
+	if _, err := t.request(ctx, http.MethodPost, pathGetPayload, signedBlindedBlock, payload); err != nil {
+		return err
+	}
+	var payloadBlockHash phase0.Hash32
+	switch {
+	case t.fork == common.ForkVersionStringDeneb && payload.Deneb != nil && payload.Deneb.ExecutionPayload != nil:
+		payloadBlockHash = payload.Deneb.ExecutionPayload.BlockHash
+	case t.fork == common.ForkVersionStringCapella && payload.Capella != nil && payload.Capella.Capella != nil:
+		payloadBlockHash = payload.Capella.Capella.BlockHash
+	default:
+		return fmt.Errorf("%w: not a %s payload", ErrUnexpectedBlock, t.fork)
+	}
+	if payloadBlockHash != t.submission.Message().BlockHash {
+		return ErrUnexpectedBlock
+	}
+	return nil
+}
+
+func pubkeyFromSecretKey(sk *bls.SecretKey) (boostTypes.PublicKey, error) {
+	blsPubkey, err := bls.PublicKeyFromSecretKey(sk)
+	if err != nil {
+		return boostTypes.PublicKey{}, err
+	}
+	return boostTypes.BlsPublicKeyToPublicKey(blsPubkey)
+}
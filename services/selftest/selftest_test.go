@@ -0,0 +1,233 @@
+package selftest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	consensuscapella "github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/flashbots/go-boost-utils/bls"
+	boostTypes "github.com/flashbots/go-boost-utils/types"
+	"github.com/flashbots/mev-boost-relay/beaconclient"
+	"github.com/flashbots/mev-boost-relay/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testBeacon struct {
+	headSlot uint64
+}
+
+func (b *testBeacon) GetGenesis() (*beaconclient.GetGenesisResponse, error) {
+	return &beaconclient.GetGenesisResponse{Data: beaconclient.GetGenesisResponseData{GenesisTime: 1606824023}}, nil //nolint:exhaustruct
+}
+
+func (b *testBeacon) CurrentSlot() (uint64, error) {
+	return b.headSlot, nil
+}
+
+func (b *testBeacon) GetBlock(blockID string) (*beaconclient.GetBlockResponse, error) {
+	block := new(beaconclient.GetBlockResponse)
+	block.Data.Message.Slot = b.headSlot
+	block.Data.Message.Body.ExecutionPayload.BlockNumber = 100
+	block.Data.Message.Body.ExecutionPayload.BlockHash = boostTypes.Hash{0x01}
+	return block, nil
+}
+
+func (b *testBeacon) GetRandao(slot uint64) (*beaconclient.GetRandaoResponse, error) {
+	randao := new(beaconclient.GetRandaoResponse)
+	randao.Data.Randao = "0x0202020202020202020202020202020202020202020202020202020202020202"
+	return randao, nil
+}
+
+func (b *testBeacon) GetWithdrawals(slot uint64) (*beaconclient.GetWithdrawalsResponse, error) {
+	withdrawals := new(beaconclient.GetWithdrawalsResponse)
+	withdrawals.Data.Withdrawals = []*consensuscapella.Withdrawal{{Index: 1, ValidatorIndex: 2, Amount: 3}} //nolint:exhaustruct
+	return withdrawals, nil
+}
+
+// newTestRelay serves the relay endpoints of the self-test, checking the signatures, with a proposer duty of the
+// registered validator in dutySlot
+func newTestRelay(t *testing.T, netDetails *common.EthNetworkDetails, dutySlot uint64) (*httptest.Server, boostTypes.PublicKey) {
+	t.Helper()
+	relaySk, relayBlsPk, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	relayPk, err := boostTypes.BlsPublicKeyToPublicKey(relayBlsPk)
+	require.NoError(t, err)
+
+	var registration *boostTypes.SignedValidatorRegistration
+	var getHeaderResp *common.GetHeaderResponse
+	var getPayloadResp *common.GetPayloadResponse
+	respond := func(w http.ResponseWriter, resp any, err error) {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+	verify := func(obj boostTypes.HashTreeRoot, domain boostTypes.Domain, pubkey, signature []byte) error {
+		ok, err := boostTypes.VerifySignature(obj, domain, pubkey, signature)
+		if err != nil || !ok {
+			return fmt.Errorf("invalid signature: %v", err)
+		}
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathStatus, func(w http.ResponseWriter, _ *http.Request) {})
+	mux.HandleFunc(pathRegisterValidator, func(w http.ResponseWriter, req *http.Request) {
+		registrations := []boostTypes.SignedValidatorRegistration{}
+		err := json.NewDecoder(req.Body).Decode(&registrations)
+		if err == nil {
+			registration = &registrations[0]
+			err = verify(registration.Message, netDetails.DomainBuilder, registration.Message.Pubkey[:], registration.Signature[:])
+		}
+		respond(w, nil, err)
+	})
+	mux.HandleFunc(pathBuilderValidators, func(w http.ResponseWriter, _ *http.Request) {
+		respond(w, []common.BuilderGetValidatorsResponseEntry{{Slot: dutySlot, ValidatorIndex: 5, Entry: registration}}, nil)
+	})
+	mux.HandleFunc(pathSubmitNewBlock, func(w http.ResponseWriter, req *http.Request) {
+		payload := new(common.BuilderSubmitBlockRequest)
+		err := json.NewDecoder(req.Body).Decode(payload)
+		if err == nil {
+			signature := payload.Signature()
+			builderPubkey := payload.BuilderPubkey()
+			err = verify(payload.Message(), netDetails.DomainBuilder, builderPubkey[:], signature[:])
+		}
+		if err == nil && payload.Timestamp() != 1606824023+dutySlot*common.SecondsPerSlot {
+			err = fmt.Errorf("incorrect timestamp %d", payload.Timestamp())
+		}
+		if err == nil {
+			getHeaderResp, err = common.BuildGetHeaderResponse(payload, relaySk, &relayPk, netDetails.DomainBuilder)
+		}
+		if err == nil {
+			getPayloadResp, err = common.BuildGetPayloadResponse(payload)
+		}
+		respond(w, nil, err)
+	})
+	mux.HandleFunc("/eth/v1/builder/header/", func(w http.ResponseWriter, req *http.Request) {
+		if getHeaderResp == nil || !strings.HasPrefix(req.URL.Path, fmt.Sprintf("/eth/v1/builder/header/%d/0x01", dutySlot)) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		respond(w, getHeaderResp, nil)
+	})
+	mux.HandleFunc(pathGetPayload, func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		domain := netDetails.DomainBeaconProposerCapella
+		if getPayloadResp.Deneb != nil {
+			domain = netDetails.DomainBeaconProposerDeneb
+			signedBlindedBlock := new(apiv1deneb.SignedBlindedBeaconBlock)
+			err = json.Unmarshal(body, signedBlindedBlock)
+			if err == nil {
+				err = verify(signedBlindedBlock.Message, domain, registration.Message.Pubkey[:], signedBlindedBlock.Signature[:])
+			}
+		} else {
+			signedBlindedBlock := new(apiv1capella.SignedBlindedBeaconBlock)
+			err = json.Unmarshal(body, signedBlindedBlock)
+			if err == nil {
+				err = verify(signedBlindedBlock.Message, domain, registration.Message.Pubkey[:], signedBlindedBlock.Signature[:])
+			}
+		}
+		respond(w, getPayloadResp, err)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, relayPk
+}
+
+func TestRun(t *testing.T) {
+	payloadAttributesDelay = time.Millisecond
+	pollInterval = time.Millisecond
+
+	netDetails, err := common.NewEthNetworkDetails(common.EthNetworkGoerli)
+	require.NoError(t, err)
+	validatorSk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+	builderSk, _, err := bls.GenerateNewKeypair()
+	require.NoError(t, err)
+
+	capellaSlot := netDetails.ForkEpochs[common.ForkVersionStringCapella] * common.SlotsPerEpoch
+	denebSlot := netDetails.ForkEpochs[common.ForkVersionStringDeneb] * common.SlotsPerEpoch
+
+	newOpts := func(relayURL string, headSlot uint64) *Opts {
+		return &Opts{
+			Log:           common.TestLog,
+			RelayURL:      relayURL,
+			Beacon:        &testBeacon{headSlot: headSlot},
+			EthNetDetails: *netDetails,
+			ValidatorSk:   validatorSk,
+			BuilderSk:     builderSk,
+			GasLimit:      30_000_000,
+			BidValue:      big.NewInt(1),
+			DutyTimeout:   100 * time.Millisecond,
+			Force:         true,
+		}
+	}
+
+	for name, dutySlot := range map[string]uint64{"capella": capellaSlot + 10, "deneb": denebSlot + 10} {
+		dutySlot := dutySlot
+		t.Run("all steps pass on "+name, func(t *testing.T) {
+			srv, relayPk := newTestRelay(t, netDetails, dutySlot)
+			relayURL := strings.Replace(srv.URL, "http://", "http://"+relayPk.String()+"@", 1)
+			results, err := Run(context.Background(), newOpts(relayURL, dutySlot-1))
+			require.NoError(t, err)
+			require.Len(t, results, 6)
+			for _, result := range results {
+				require.NoError(t, result.Err, result.Name)
+			}
+		})
+	}
+
+	t.Run("refuses public networks unless forced", func(t *testing.T) {
+		opts := newOpts("http://localhost", capellaSlot)
+		opts.Force = false
+		_, err := Run(context.Background(), opts)
+		require.ErrorIs(t, err, ErrPublicNetwork)
+	})
+
+	t.Run("unsupported fork", func(t *testing.T) {
+		srv, _ := newTestRelay(t, netDetails, 10)
+		results, err := Run(context.Background(), newOpts(srv.URL, 9))
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		require.ErrorIs(t, results[3].Err, ErrUnsupportedFork)
+	})
+
+	t.Run("bid signed by another relay key", func(t *testing.T) {
+		srv, _ := newTestRelay(t, netDetails, capellaSlot+10)
+		_, otherPk, err := bls.GenerateNewKeypair()
+		require.NoError(t, err)
+		otherPubkey, err := boostTypes.BlsPublicKeyToPublicKey(otherPk)
+		require.NoError(t, err)
+		relayURL := strings.Replace(srv.URL, "http://", "http://"+otherPubkey.String()+"@", 1)
+		results, err := Run(context.Background(), newOpts(relayURL, capellaSlot+9))
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+		require.ErrorIs(t, results[4].Err, ErrInvalidBidSig)
+	})
+
+	t.Run("stops without a proposer duty", func(t *testing.T) {
+		srv, _ := newTestRelay(t, netDetails, capellaSlot+10)
+		results, err := Run(context.Background(), newOpts(srv.URL, capellaSlot+10))
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		require.Equal(t, "proposerDuty", results[2].Name)
+		require.ErrorIs(t, results[2].Err, ErrNoProposerDuty)
+	})
+
+	_, err = Run(context.Background(), newOpts("localhost", 9))
+	require.ErrorIs(t, err, ErrInvalidRelayURL)
+}